@@ -1,21 +1,33 @@
 package main
 
 import (
+	"context"
 	"entry-task/httpserver/config"
 	"entry-task/httpserver/internal/handler"
+	"entry-task/httpserver/internal/middleware"
+	"entry-task/httpserver/internal/reconciler"
 	"entry-task/httpserver/internal/router"
-	pb "entry-task/proto/user"
+	"entry-task/httpserver/pkg/grpcclient"
+	"entry-task/httpserver/pkg/storage"
+	"entry-task/pkg/admin"
+	"entry-task/pkg/tracing"
+	pb "entry-task/proto/user/v2"
+	"errors"
 	"flag"
 	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc/filters"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 
-	log "entry-task/httpserver/pkg/logger"
+	log "entry-task/pkg/logger"
 )
 
 var (
@@ -47,51 +59,146 @@ func main() {
 	log.Info("HTTP Server 启动中...")
 	log.Info("配置加载成功", zap.String("config_path", *configPath))
 
+	// 初始化链路追踪
+	shutdownTracing, err := tracing.Init(&tracing.Config{
+		ServiceName: cfg.Tracing.GetServiceName(),
+		Exporter:    cfg.Tracing.Exporter,
+		Endpoint:    cfg.Tracing.Endpoint,
+		Insecure:    cfg.Tracing.Insecure,
+		FilePath:    cfg.Tracing.FilePath,
+		SampleRatio: cfg.Tracing.SampleRatio,
+		Logger:      log.Logger,
+	})
+	if err != nil {
+		log.Fatal("初始化链路追踪失败", zap.Error(err))
+	}
+
 	// 3. 连接 gRPC Server（TCP Server）
-	grpcAddr := cfg.GRPC.GetAddr()
-	log.Info("正在连接 gRPC Server...", zap.String("addr", grpcAddr))
+	targets := cfg.GRPC.GetTargets()
+	log.Info("正在连接 gRPC Server...", zap.Strings("targets", targets))
+
+	interceptors := []grpc.UnaryClientInterceptor{
+		handler.RequestIDInterceptor(), // 转发请求ID
+	}
+	if cb := cfg.GRPC.CircuitBreaker; cb.Enabled {
+		breaker := grpcclient.NewCircuitBreaker(cb.FailureThreshold, time.Duration(cb.OpenTimeoutMs)*time.Millisecond)
+		interceptors = append(interceptors, breaker.Interceptor()) // 熔断时直接返回服务不可用
+	}
+	interceptors = append(interceptors, handler.SessionCookieInterceptor()) // Session续期后同步Cookie有效期
 
-	conn, err := grpc.Dial(
-		grpcAddr,
+	conn, err := grpcclient.Dial(&cfg.GRPC,
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		// 链路追踪：通过 metadata 传递 W3C Trace Context（就绪探针的健康检查调用不产生 Span）
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler(otelgrpc.WithFilter(filters.Not(filters.HealthCheck())))),
+		grpc.WithChainUnaryInterceptor(interceptors...),
 	)
 	if err != nil {
 		log.Fatal("连接 gRPC Server 失败",
-			zap.String("addr", grpcAddr),
+			zap.Strings("targets", targets),
 			zap.Error(err))
 	}
-	defer conn.Close()
 
-	log.Info("gRPC 连接成功", zap.String("addr", grpcAddr))
+	log.Info("gRPC 连接已创建", zap.Strings("targets", targets))
 
 	// 4. 创建 gRPC Client
 	grpcClient := pb.NewUserServiceClient(conn)
 
-	// 5. 创建 Handler（依赖注入）
-	userHandler := handler.NewUserHandler(grpcClient)
+	// 5. 创建头像存储
+	avatarStorage, err := storage.New(&cfg.Storage)
+	if err != nil {
+		log.Fatal("创建头像存储失败", zap.Error(err))
+	}
+	log.Info("头像存储创建成功",
+		zap.String("type", cfg.Storage.GetType()),
+		zap.Bool("redirect", cfg.Storage.Redirect))
+
+	// 6. 创建 Handler（依赖注入）
+	userHandler := handler.NewUserHandler(grpcClient, avatarStorage, cfg.Storage.Redirect, cfg.Storage.GetPublicMaxAge(), cfg.Storage.GetMaxProcessing())
+	healthHandler := handler.NewHealthHandler(conn)
 	log.Info("Handler 创建成功")
 
-	// 6. 设置路由
-	r := router.SetupRouter(userHandler)
+	// 7. 设置路由
+	publicLimiter := middleware.NewIPRateLimiter(cfg.Server.PublicRateLimit.GetRate(), cfg.Server.PublicRateLimit.GetBurst())
+	r, err := router.SetupRouter(userHandler, healthHandler, publicLimiter, cfg.Server.TrustedProxies, cfg.Tracing.GetServiceName())
+	if err != nil {
+		log.Fatal("设置路由失败", zap.Strings("trusted_proxies", cfg.Server.TrustedProxies), zap.Error(err))
+	}
 	log.Info("路由设置完成")
 
-	// 7. 启动 HTTP Server（在 goroutine 中）
+	// 8. 启动 HTTP Server（在 goroutine 中）
 	addr := cfg.Server.GetHTTPAddr()
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           r,
+		ReadHeaderTimeout: cfg.Server.GetReadHeaderTimeout(),
+		ReadTimeout:       cfg.Server.GetReadTimeout(),
+		WriteTimeout:      cfg.Server.GetWriteTimeout(),
+		IdleTimeout:       cfg.Server.GetIdleTimeout(),
+	}
 	go func() {
 		log.Info("HTTP Server 启动成功",
 			zap.String("addr", addr),
 			zap.String("mode", cfg.Server.Mode))
 
-		if err := r.Run(addr); err != nil {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			log.Fatal("启动 HTTP Server 失败", zap.Error(err))
 		}
 	}()
 
-	// 8. 等待退出信号
+	// 9. 启动管理端口（Prometheus /metrics）
+	adminServer := admin.Start(cfg.Server.GetAdminAddr())
+
+	// 10. 启动头像对账任务（清理孤儿文件、清空悬空引用，与 HTTP 请求共用 gRPC 连接）
+	reconcileCtx, stopReconcile := context.WithCancel(context.Background())
+	reconcileDone := make(chan struct{})
+	if cfg.Storage.Reconcile.Enabled {
+		if cfg.GRPC.InternalToken == "" {
+			log.Fatal("头像对账需要配置 grpc.internal_token")
+		}
+		avatarReconciler := reconciler.NewAvatarReconciler(pb.NewUserAdminServiceClient(conn), avatarStorage, cfg)
+		go func() {
+			defer close(reconcileDone)
+			avatarReconciler.Run(reconcileCtx)
+		}()
+	} else {
+		close(reconcileDone)
+	}
+
+	// 11. 等待退出信号
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
 	log.Info("收到退出信号，开始优雅关闭...")
+
+	// 12. 标记未就绪，等待负载均衡摘除本实例
+	healthHandler.SetShuttingDown()
+	if delay := cfg.Server.GetShutdownDelay(); delay > 0 {
+		log.Info("已标记为未就绪，等待负载均衡摘除", zap.Duration("delay", delay))
+		time.Sleep(delay)
+	}
+
+	// 13. 停止接受新连接，等待处理中的请求（含上传）完成，超时后强制关闭
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), cfg.Server.GetShutdownTimeout())
+	defer cancelShutdown()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Error("等待处理中的请求超时，强制关闭连接", zap.Error(err))
+		_ = server.Close()
+	}
+
+	// 14. 停止头像对账任务；请求全部结束后再关闭 gRPC 连接，避免处理中的请求调用失败
+	stopReconcile()
+	<-reconcileDone
+	if err := conn.Close(); err != nil {
+		log.Error("关闭 gRPC 连接失败", zap.Error(err))
+	}
+	admin.Stop(adminServer)
+
+	// 导出剩余的 Span
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := shutdownTracing(ctx); err != nil {
+		log.Error("关闭链路追踪失败", zap.Error(err))
+	}
 	log.Info("HTTP Server 已关闭")
 }
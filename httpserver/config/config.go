@@ -3,23 +3,124 @@ package config
 import (
 	"fmt"
 	"os"
+	"runtime"
 	"strconv"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
 // Config 全局配置
 type Config struct {
-	Server ServerConfig `yaml:"server"`
-	GRPC   GRPCConfig   `yaml:"grpc"`
-	Log    LogConfig    `yaml:"log"`
+	Server  ServerConfig  `yaml:"server"`
+	GRPC    GRPCConfig    `yaml:"grpc"`
+	Log     LogConfig     `yaml:"log"`
+	Tracing TracingConfig `yaml:"tracing"`
+	Storage StorageConfig `yaml:"storage"`
 }
 
 // ServerConfig HTTP Server 配置
 type ServerConfig struct {
-	Host string `yaml:"host"`
-	Port int    `yaml:"port"`
-	Mode string `yaml:"mode"`
+	Host      string `yaml:"host"`
+	Port      int    `yaml:"port"`
+	AdminPort int    `yaml:"admin_port"` // 管理端口（/metrics），0 表示不启动
+	Mode      string `yaml:"mode"`
+
+	// 超时配置（秒）。时长配置统一以单位为后缀（_ms 毫秒、_sec 秒），除注明外 0 或未配置时使用默认值
+	ReadHeaderTimeoutSec int `yaml:"read_header_timeout_sec"` // 读取请求头超时
+	ReadTimeoutSec       int `yaml:"read_timeout_sec"`        // 读取整个请求（含上传文件）超时
+	WriteTimeoutSec      int `yaml:"write_timeout_sec"`       // 从读完请求头到写完响应的超时
+	IdleTimeoutSec       int `yaml:"idle_timeout_sec"`        // Keep-Alive 空闲连接超时
+	ShutdownDelaySec     int `yaml:"shutdown_delay_sec"`      // 标记未就绪后等待负载均衡摘除的时间，0 表示不等待
+	ShutdownTimeoutSec   int `yaml:"shutdown_timeout_sec"`    // 等待处理中请求完成的最长时间，超时后强制关闭连接
+
+	// 信任的反向代理（IP 或 CIDR），只采信这些地址转发的 X-Forwarded-For；未配置时不信任任何代理，客户端 IP 为连接的对端地址
+	TrustedProxies []string `yaml:"trusted_proxies"`
+	// 公开接口（无需登录，如查看用户头像）按客户端 IP 限流
+	PublicRateLimit RateLimitConfig `yaml:"public_rate_limit"`
+}
+
+// RateLimitConfig 令牌桶限流配置
+type RateLimitConfig struct {
+	Rate  float64 `yaml:"rate"`  // 每秒补充的请求数
+	Burst int     `yaml:"burst"` // 突发请求数（令牌桶容量）
+}
+
+// HTTP Server 默认超时
+const (
+	DefaultReadHeaderTimeout = 5 * time.Second
+	DefaultReadTimeout       = 30 * time.Second
+	DefaultWriteTimeout      = 30 * time.Second
+	DefaultIdleTimeout       = 120 * time.Second
+	DefaultShutdownTimeout   = 30 * time.Second
+)
+
+// 公开接口默认限流
+const (
+	DefaultPublicRate  = 10.0
+	DefaultPublicBurst = 20
+)
+
+// secondsOrDefault 将秒数转换为 Duration，未配置时返回默认值
+func secondsOrDefault(seconds int, def time.Duration) time.Duration {
+	if seconds <= 0 {
+		return def
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// GetReadHeaderTimeout 获取读取请求头超时
+func (s *ServerConfig) GetReadHeaderTimeout() time.Duration {
+	return secondsOrDefault(s.ReadHeaderTimeoutSec, DefaultReadHeaderTimeout)
+}
+
+// GetReadTimeout 获取读取请求超时
+func (s *ServerConfig) GetReadTimeout() time.Duration {
+	return secondsOrDefault(s.ReadTimeoutSec, DefaultReadTimeout)
+}
+
+// GetWriteTimeout 获取写响应超时
+func (s *ServerConfig) GetWriteTimeout() time.Duration {
+	return secondsOrDefault(s.WriteTimeoutSec, DefaultWriteTimeout)
+}
+
+// GetIdleTimeout 获取空闲连接超时
+func (s *ServerConfig) GetIdleTimeout() time.Duration {
+	return secondsOrDefault(s.IdleTimeoutSec, DefaultIdleTimeout)
+}
+
+// GetShutdownDelay 获取标记未就绪后的等待时间
+func (s *ServerConfig) GetShutdownDelay() time.Duration {
+	return time.Duration(s.ShutdownDelaySec) * time.Second
+}
+
+// GetShutdownTimeout 获取优雅关闭超时
+func (s *ServerConfig) GetShutdownTimeout() time.Duration {
+	return secondsOrDefault(s.ShutdownTimeoutSec, DefaultShutdownTimeout)
+}
+
+// GetRate 获取每秒补充的请求数
+func (r *RateLimitConfig) GetRate() float64 {
+	if r.Rate <= 0 {
+		return DefaultPublicRate
+	}
+	return r.Rate
+}
+
+// GetBurst 获取突发请求数
+func (r *RateLimitConfig) GetBurst() int {
+	if r.Burst <= 0 {
+		return DefaultPublicBurst
+	}
+	return r.Burst
+}
+
+// GetAdminAddr 获取管理端口地址，未配置时返回空字符串
+func (s *ServerConfig) GetAdminAddr() string {
+	if s.AdminPort <= 0 {
+		return ""
+	}
+	return s.Host + ":" + strconv.Itoa(s.AdminPort)
 }
 
 // GetHTTPAddr 获取 HTTP Server 地址
@@ -29,8 +130,40 @@ func (s *ServerConfig) GetHTTPAddr() string {
 
 // GRPCConfig gRPC Client 配置
 type GRPCConfig struct {
-	Host string `yaml:"host"`
-	Port int    `yaml:"port"`
+	Host    string   `yaml:"host"`
+	Port    int      `yaml:"port"`
+	Targets []string `yaml:"targets"` // 多个 TCP Server 地址（host:port），轮询负载均衡；配置后忽略 host/port
+
+	DefaultTimeoutMs int            `yaml:"default_timeout_ms"` // 毫秒，RPC 默认超时，0 表示使用 grpcclient 包中的默认值
+	MethodTimeoutsMs map[string]int `yaml:"method_timeouts_ms"` // 毫秒，按方法名（如 GetProfile）覆盖默认超时
+
+	InternalToken string `yaml:"internal_token"` // 调用内部服务（UserAdminService）的鉴权凭证，与 TCP Server 的 server.internal_token 一致
+
+	Retry          GRPCRetryConfig      `yaml:"retry"`
+	Keepalive      GRPCKeepaliveConfig  `yaml:"keepalive"`
+	CircuitBreaker CircuitBreakerConfig `yaml:"circuit_breaker"`
+}
+
+// GRPCRetryConfig 重试配置（只用于幂等方法，重试 UNAVAILABLE 错误）
+type GRPCRetryConfig struct {
+	Methods          []string `yaml:"methods"`            // 允许重试的方法名，未配置时只重试 GetProfile
+	MaxAttempts      int      `yaml:"max_attempts"`       // 最大尝试次数（含首次），0 或 1 表示不重试
+	InitialBackoffMs int      `yaml:"initial_backoff_ms"` // 毫秒，首次重试的退避时间，0 表示使用默认值
+	MaxBackoffMs     int      `yaml:"max_backoff_ms"`     // 毫秒，最大退避时间，0 表示使用默认值
+}
+
+// GRPCKeepaliveConfig 连接保活配置
+type GRPCKeepaliveConfig struct {
+	TimeSec             int  `yaml:"time_sec"`              // 秒，连接空闲多久后发送 ping，0 表示不发送
+	TimeoutSec          int  `yaml:"timeout_sec"`           // 秒，等待 ping 响应的超时，超时后关闭连接，0 表示使用 gRPC 默认值
+	PermitWithoutStream bool `yaml:"permit_without_stream"` // 没有进行中的 RPC 时也发送 ping
+}
+
+// CircuitBreakerConfig 熔断器配置
+type CircuitBreakerConfig struct {
+	Enabled          bool `yaml:"enabled"`
+	FailureThreshold int  `yaml:"failure_threshold"` // 连续失败（不可用/超时）多少次后熔断
+	OpenTimeoutMs    int  `yaml:"open_timeout_ms"`   // 毫秒，熔断后多久放行一个探测请求，0 表示使用默认值
 }
 
 // GetAddr 获取 gRPC Server 地址
@@ -38,6 +171,19 @@ func (g *GRPCConfig) GetAddr() string {
 	return g.Host + ":" + strconv.Itoa(g.Port)
 }
 
+// GetTargets 获取所有 TCP Server 地址（未配置 targets 时为 host:port）
+func (g *GRPCConfig) GetTargets() []string {
+	if len(g.Targets) > 0 {
+		return g.Targets
+	}
+	return []string{g.GetAddr()}
+}
+
+// GetDefaultTimeout 获取 RPC 默认超时
+func (g *GRPCConfig) GetDefaultTimeout() time.Duration {
+	return time.Duration(g.DefaultTimeoutMs) * time.Millisecond
+}
+
 // LogConfig 日志配置
 type LogConfig struct {
 	Level    string `yaml:"level"`
@@ -45,6 +191,138 @@ type LogConfig struct {
 	FilePath string `yaml:"file_path"`
 }
 
+// TracingConfig 链路追踪配置
+type TracingConfig struct {
+	Exporter    string   `yaml:"exporter"`     // none（默认）, otlp, stdout, file
+	ServiceName string   `yaml:"service_name"` // 服务名，未配置时为 httpserver
+	Endpoint    string   `yaml:"endpoint"`     // OTLP Collector 地址（host:port）
+	Insecure    bool     `yaml:"insecure"`     // OTLP 不使用 TLS
+	FilePath    string   `yaml:"file_path"`    // file 导出方式的文件路径
+	SampleRatio *float64 `yaml:"sample_ratio"` // 采样比例（0~1），0 表示不采样根 Span，未配置时全部采样
+}
+
+// DefaultTracingServiceName 默认链路追踪服务名
+const DefaultTracingServiceName = "httpserver"
+
+// GetServiceName 获取链路追踪服务名
+func (t *TracingConfig) GetServiceName() string {
+	if t.ServiceName == "" {
+		return DefaultTracingServiceName
+	}
+	return t.ServiceName
+}
+
+// StorageConfig 头像存储配置
+type StorageConfig struct {
+	Type            string             `yaml:"type"`               // local（默认）, s3
+	Redirect        bool               `yaml:"redirect"`           // 获取头像时 302 跳转到存储的访问地址（S3 预签名 URL），不经过 HTTP Server 转发
+	PublicMaxAgeSec int                `yaml:"public_max_age_sec"` // 秒，公开头像地址（/api/v1/users/{id}/picture）允许共享缓存的时间，0 表示使用默认值
+	MaxProcessing   int                `yaml:"max_processing"`     // 同时解码和生成缩略图的头像数上限，未配置时为 CPU 核数
+	Local           LocalStorageConfig `yaml:"local"`
+	S3              S3StorageConfig    `yaml:"s3"`
+	Reconcile       ReconcileConfig    `yaml:"reconcile"`
+}
+
+// LocalStorageConfig 本地文件存储配置
+type LocalStorageConfig struct {
+	Root string `yaml:"root"` // 存储根目录，未配置时为 ./uploads
+}
+
+// S3StorageConfig S3 兼容对象存储配置（AWS S3、MinIO 等）
+type S3StorageConfig struct {
+	Endpoint         string `yaml:"endpoint"` // host:port，不含协议
+	Region           string `yaml:"region"`   // 未配置时为 us-east-1
+	Bucket           string `yaml:"bucket"`
+	AccessKey        string `yaml:"access_key"`
+	SecretKey        string `yaml:"secret_key"`
+	UseSSL           bool   `yaml:"use_ssl"`
+	PathStyle        bool   `yaml:"path_style"`         // 使用 path-style 地址（MinIO 需要开启）
+	PresignExpirySec int    `yaml:"presign_expiry_sec"` // 秒，预签名 URL 有效期，0 表示使用默认值
+}
+
+// ReconcileConfig 头像对账配置：清理数据库未引用的头像文件，清空指向不存在文件的头像字段
+type ReconcileConfig struct {
+	Enabled        bool `yaml:"enabled"`          // 多实例部署时只需在一个实例上开启
+	IntervalSec    int  `yaml:"interval_sec"`     // 秒，对账间隔，0 表示使用默认值
+	GracePeriodSec int  `yaml:"grace_period_sec"` // 秒，未被引用的文件至少保留多久才删除（避免删除上传中、尚未写入数据库的头像），0 表示使用默认值
+	PageSize       int  `yaml:"page_size"`        // 每次从 TCP Server 读取的用户数
+}
+
+// 存储默认配置
+const (
+	StorageTypeLocal = "local"
+	StorageTypeS3    = "s3"
+
+	DefaultLocalStorageRoot = "./uploads"
+	DefaultS3Region         = "us-east-1"
+	DefaultPresignExpiry    = 10 * time.Minute
+	DefaultPublicMaxAge     = time.Minute
+
+	DefaultReconcileInterval    = time.Hour
+	DefaultReconcileGracePeriod = 24 * time.Hour
+	DefaultReconcilePageSize    = 500
+)
+
+// GetType 获取存储类型
+func (s *StorageConfig) GetType() string {
+	if s.Type == "" {
+		return StorageTypeLocal
+	}
+	return s.Type
+}
+
+// GetPublicMaxAge 获取公开头像的缓存时间
+func (s *StorageConfig) GetPublicMaxAge() time.Duration {
+	return secondsOrDefault(s.PublicMaxAgeSec, DefaultPublicMaxAge)
+}
+
+// GetMaxProcessing 获取同时处理的头像数上限
+func (s *StorageConfig) GetMaxProcessing() int {
+	if s.MaxProcessing <= 0 {
+		return runtime.GOMAXPROCS(0)
+	}
+	return s.MaxProcessing
+}
+
+// GetRoot 获取本地存储根目录
+func (l *LocalStorageConfig) GetRoot() string {
+	if l.Root == "" {
+		return DefaultLocalStorageRoot
+	}
+	return l.Root
+}
+
+// GetRegion 获取 S3 区域
+func (s *S3StorageConfig) GetRegion() string {
+	if s.Region == "" {
+		return DefaultS3Region
+	}
+	return s.Region
+}
+
+// GetPresignExpiry 获取预签名 URL 有效期
+func (s *S3StorageConfig) GetPresignExpiry() time.Duration {
+	return secondsOrDefault(s.PresignExpirySec, DefaultPresignExpiry)
+}
+
+// GetInterval 获取对账间隔
+func (r *ReconcileConfig) GetInterval() time.Duration {
+	return secondsOrDefault(r.IntervalSec, DefaultReconcileInterval)
+}
+
+// GetGracePeriod 获取未引用文件的保留时间
+func (r *ReconcileConfig) GetGracePeriod() time.Duration {
+	return secondsOrDefault(r.GracePeriodSec, DefaultReconcileGracePeriod)
+}
+
+// GetPageSize 获取每次读取的用户数
+func (r *ReconcileConfig) GetPageSize() int {
+	if r.PageSize <= 0 {
+		return DefaultReconcilePageSize
+	}
+	return r.PageSize
+}
+
 var globalConfig *Config
 
 // Load 加载配置文件
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TestServerTimeouts 测试未配置时使用默认超时，配置后按秒转换
+func TestServerTimeouts(t *testing.T) {
+	getters := []struct {
+		name string
+		get  func(*ServerConfig) time.Duration
+		def  time.Duration
+	}{
+		{"read_header_timeout_sec", (*ServerConfig).GetReadHeaderTimeout, DefaultReadHeaderTimeout},
+		{"read_timeout_sec", (*ServerConfig).GetReadTimeout, DefaultReadTimeout},
+		{"write_timeout_sec", (*ServerConfig).GetWriteTimeout, DefaultWriteTimeout},
+		{"idle_timeout_sec", (*ServerConfig).GetIdleTimeout, DefaultIdleTimeout},
+		{"shutdown_timeout_sec", (*ServerConfig).GetShutdownTimeout, DefaultShutdownTimeout},
+		{"shutdown_delay_sec", (*ServerConfig).GetShutdownDelay, 0},
+	}
+
+	for _, g := range getters {
+		if got := g.get(&ServerConfig{}); got != g.def {
+			t.Errorf("%s 未配置时 = %v, 期望默认值 %v", g.name, got, g.def)
+		}
+	}
+
+	negative := &ServerConfig{ReadHeaderTimeoutSec: -1, ReadTimeoutSec: -1, WriteTimeoutSec: -1, IdleTimeoutSec: -1, ShutdownTimeoutSec: -1}
+	for _, g := range getters[:5] {
+		if got := g.get(negative); got != g.def {
+			t.Errorf("%s 为负数时 = %v, 期望默认值 %v", g.name, got, g.def)
+		}
+	}
+
+	configured := &ServerConfig{
+		ReadHeaderTimeoutSec: 1,
+		ReadTimeoutSec:       2,
+		WriteTimeoutSec:      3,
+		IdleTimeoutSec:       4,
+		ShutdownTimeoutSec:   5,
+		ShutdownDelaySec:     6,
+	}
+	for i, g := range getters {
+		want := time.Duration(i+1) * time.Second
+		if got := g.get(configured); got != want {
+			t.Errorf("%s = %v, 期望 %v", g.name, got, want)
+		}
+	}
+}
+
+// TestTracingSampleRatio 测试显式配置 sample_ratio: 0 与未配置可区分
+func TestTracingSampleRatio(t *testing.T) {
+	var unset TracingConfig
+	if err := yaml.Unmarshal([]byte(`exporter: "otlp"`), &unset); err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	if unset.SampleRatio != nil {
+		t.Errorf("未配置时 SampleRatio 应为 nil，实际 %v", *unset.SampleRatio)
+	}
+
+	var zero TracingConfig
+	if err := yaml.Unmarshal([]byte(`sample_ratio: 0`), &zero); err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	if zero.SampleRatio == nil || *zero.SampleRatio != 0 {
+		t.Error("显式配置为 0 时 SampleRatio 应为 0（不采样）")
+	}
+}
+
+// TestConfigFileKnownFields 测试配置文件中的键均与配置结构对应（键名调整后不残留旧键）
+func TestConfigFileKnownFields(t *testing.T) {
+	f, err := os.Open("config.yaml")
+	if err != nil {
+		t.Fatalf("打开配置文件失败: %v", err)
+	}
+	defer f.Close()
+
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true)
+	var cfg Config
+	if err := dec.Decode(&cfg); err != nil {
+		t.Errorf("配置文件包含未知的键: %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	pb "entry-task/proto/user/v2"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// readinessTimeout 就绪检查调用 TCP Server 健康检查的超时时间
+const readinessTimeout = time.Second
+
+// ============================================================================
+// 健康检查 Handler
+// ============================================================================
+
+// HealthHandler 存活/就绪探针
+type HealthHandler struct {
+	conn         *grpc.ClientConn
+	healthClient healthpb.HealthClient
+	shuttingDown atomic.Bool // 开始优雅关闭后就绪探针始终返回 503
+}
+
+// NewHealthHandler 创建 HealthHandler 实例
+func NewHealthHandler(conn *grpc.ClientConn) *HealthHandler {
+	return &HealthHandler{
+		conn:         conn,
+		healthClient: healthpb.NewHealthClient(conn),
+	}
+}
+
+// Liveness 存活探针：进程能处理请求即返回 200
+// GET /healthz
+func (h *HealthHandler) Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// SetShuttingDown 标记正在关闭，之后就绪探针返回 503，负载均衡不再分配新请求
+func (h *HealthHandler) SetShuttingDown() {
+	h.shuttingDown.Store(true)
+}
+
+// Readiness 就绪探针：到 TCP Server 的 gRPC 连接可用且 TCP Server 自身就绪（数据库、Redis 正常）时返回 200，否则返回 503
+// GET /readyz
+func (h *HealthHandler) Readiness(c *gin.Context) {
+	if h.shuttingDown.Load() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "shutting_down"})
+		return
+	}
+
+	state := h.conn.GetState()
+	if state == connectivity.Idle {
+		// 空闲连接不会主动重连，触发一次连接，由下面的健康检查调用等待连接建立
+		h.conn.Connect()
+	}
+
+	checks := gin.H{"grpc_connection": state.String()}
+	ready := state != connectivity.TransientFailure && state != connectivity.Shutdown
+
+	if ready {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), readinessTimeout)
+		defer cancel()
+
+		resp, err := h.healthClient.Check(ctx, &healthpb.HealthCheckRequest{
+			Service: pb.UserService_ServiceDesc.ServiceName,
+		})
+		switch {
+		case err != nil:
+			checks["tcpserver"] = err.Error()
+			ready = false
+		case resp.Status != healthpb.HealthCheckResponse_SERVING:
+			checks["tcpserver"] = resp.Status.String()
+			ready = false
+		default:
+			checks["tcpserver"] = resp.Status.String()
+		}
+	}
+
+	if !ready {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable", "checks": checks})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok", "checks": checks})
+}
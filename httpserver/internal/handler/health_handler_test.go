@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+
+	pb "entry-task/proto/user/v2"
+)
+
+// newHealthConn 启动只提供健康检查服务的内存 gRPC Server，返回到它的连接
+func newHealthConn(t *testing.T, status healthpb.HealthCheckResponse_ServingStatus) *grpc.ClientConn {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	hs := health.NewServer()
+	hs.SetServingStatus(pb.UserService_ServiceDesc.ServiceName, status)
+	healthpb.RegisterHealthServer(server, hs)
+	go func() { _ = server.Serve(lis) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("创建连接失败: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+// readiness 调用就绪探针，返回状态码
+func readiness(h *HealthHandler) int {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	h.Readiness(c)
+	return w.Code
+}
+
+// TestReadiness 测试 TCP Server 就绪时返回 200，未就绪时返回 503
+func TestReadiness(t *testing.T) {
+	if code := readiness(NewHealthHandler(newHealthConn(t, healthpb.HealthCheckResponse_SERVING))); code != http.StatusOK {
+		t.Errorf("TCP Server 就绪时状态码 = %d, 期望 200", code)
+	}
+	if code := readiness(NewHealthHandler(newHealthConn(t, healthpb.HealthCheckResponse_NOT_SERVING))); code != http.StatusServiceUnavailable {
+		t.Errorf("TCP Server 未就绪时状态码 = %d, 期望 503", code)
+	}
+}
+
+// TestReadiness_ShuttingDown 测试开始优雅关闭后就绪探针返回 503，存活探针不受影响
+func TestReadiness_ShuttingDown(t *testing.T) {
+	h := NewHealthHandler(newHealthConn(t, healthpb.HealthCheckResponse_SERVING))
+	if code := readiness(h); code != http.StatusOK {
+		t.Fatalf("关闭前状态码 = %d, 期望 200", code)
+	}
+
+	h.SetShuttingDown()
+	if code := readiness(h); code != http.StatusServiceUnavailable {
+		t.Errorf("开始关闭后状态码 = %d, 期望 503", code)
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	h.Liveness(c)
+	if w.Code != http.StatusOK {
+		t.Errorf("开始关闭后存活探针状态码 = %d, 期望 200", w.Code)
+	}
+}
@@ -1,52 +1,84 @@
 package handler
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"math"
+	"mime/multipart"
 	"net/http"
-	"os"
+	"path"
 	"path/filepath"
-	"strings"
+	"slices"
+	"strconv"
 	"time"
 
+	"entry-task/httpserver/pkg/avatar"
 	"entry-task/httpserver/pkg/response"
-	pb "entry-task/proto/user"
+	"entry-task/httpserver/pkg/storage"
+	"entry-task/proto/errcode"
+	pb "entry-task/proto/user/v2"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 
-	log "entry-task/httpserver/pkg/logger"
+	log "entry-task/pkg/logger"
+	"entry-task/pkg/requestid"
 )
 
 const (
 	// MaxFileSize 文件上传配置
 	MaxFileSize = 5 * 1024 * 1024 // 5MB
 	//AllowedExtensions = ".jpg,.jpeg,.png,.webp"                // 允许的文件类型
-	UploadDir     = "./uploads/avatars"                    // 上传目录
 	DefaultAvatar = "httpserver/static/default_avatar.png" // 默认头像
+
+	// AvatarCacheControl 当前用户头像（/api/v1/profile/picture）的 Cache-Control：
+	// 同一 URL 的内容随登录用户和上传而变化，只允许浏览器缓存，每次使用前用 ETag 验证
+	AvatarCacheControl = "private, no-cache"
+
+	// AuthCookieName 认证Cookie名称
+	AuthCookieName = "auth_token"
+	// RefreshCookieName 刷新凭证Cookie名称（仅token模式下发）
+	RefreshCookieName = "refresh_token"
+	// RefreshCookiePath 刷新凭证Cookie只随认证接口发送，缩小暴露面
+	RefreshCookiePath = "/api/v1/auth"
+	// DefaultCookieMaxAge TCP Server 未返回有效期时的Cookie默认有效期（秒）
+	DefaultCookieMaxAge = 7200
+	// metadataSessionTTL TCP Server 续期Session后在响应 header 中返回的剩余有效期（秒）
+	metadataSessionTTL = "x-session-ttl"
 )
 
-// allowedExtensionsMap 允许的文件扩展名（用于精确匹配）
-var allowedExtensionsMap = map[string]bool{
-	".jpg":  true,
-	".jpeg": true,
-	".png":  true,
-	".webp": true,
-}
+// ginContextKey 在 gRPC 调用的 context 中携带 gin.Context，供客户端拦截器回写Cookie
+type ginContextKey struct{}
 
 // ============================================================================
 // Handler 结构体
 // ============================================================================
 
 type UserHandler struct {
-	grpcClient pb.UserServiceClient
+	grpcClient         pb.UserServiceClient
+	storage            storage.Storage // 头像存储
+	avatarRedirect     bool            // 获取头像时跳转到存储的访问地址
+	publicCacheControl string          // 公开头像（/api/v1/users/{id}/picture）的 Cache-Control
+	processing         chan struct{}   // 头像处理信号量，限制同时解码的图片数（每张最多占用约 64MB）
 }
 
-// NewUserHandler 创建 UserHandler 实例
-func NewUserHandler(grpcClient pb.UserServiceClient) *UserHandler {
+// NewUserHandler 创建 UserHandler 实例，publicMaxAge 为公开头像允许共享缓存的时间，
+// maxProcessing 为同时解码和生成缩略图的头像数上限
+func NewUserHandler(grpcClient pb.UserServiceClient, avatarStorage storage.Storage, avatarRedirect bool, publicMaxAge time.Duration, maxProcessing int) *UserHandler {
 	return &UserHandler{
-		grpcClient: grpcClient,
+		grpcClient:         grpcClient,
+		storage:            avatarStorage,
+		avatarRedirect:     avatarRedirect,
+		publicCacheControl: publicAvatarCacheControl(publicMaxAge),
+		processing:         make(chan struct{}, max(maxProcessing, 1)),
 	}
 }
 
@@ -54,6 +86,12 @@ func NewUserHandler(grpcClient pb.UserServiceClient) *UserHandler {
 // 请求结构体
 // ============================================================================
 
+type RegisterRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+	Nickname string `json:"nickname"`
+}
+
 type LoginRequest struct {
 	Username string `json:"username" binding:"required"`
 	Password string `json:"password" binding:"required"`
@@ -63,10 +101,51 @@ type UpdateNicknameRequest struct {
 	Nickname string `json:"nickname" binding:"required"`
 }
 
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"` // 可选，为空时从Cookie读取
+}
+
+type ChangePasswordRequest struct {
+	OldPassword string `json:"old_password" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+type RevokeAllSessionsQuery struct {
+	IncludeCurrent bool `form:"include_current"`
+}
+
 // ============================================================================
 // Handler 方法
 // ============================================================================
 
+// Register 注册
+func (h *UserHandler) Register(c *gin.Context) {
+	var req RegisterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.CodeBadRequest, "请求参数错误")
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	resp, err := h.grpcClient.Register(ctx, &pb.RegisterRequest{
+		Username: req.Username,
+		Password: req.Password,
+		Nickname: req.Nickname,
+	})
+
+	if err != nil {
+		rpcError(c, err, "注册失败")
+		return
+	}
+
+	response.Success(c, gin.H{
+		"username":   resp.User.Username,
+		"nickname":   resp.User.Nickname,
+		"avatar_url": avatarURL(resp.User.Id),
+	})
+}
+
 // Login 登录
 func (h *UserHandler) Login(c *gin.Context) {
 	var req LoginRequest
@@ -75,9 +154,10 @@ func (h *UserHandler) Login(c *gin.Context) {
 		return
 	}
 
-	//设置超时时间
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 3*time.Second)
-	defer cancel()
+	ctx := c.Request.Context()
+
+	// 透传客户端信息，用于Session设备列表展示
+	ctx = withOutgoingMetadata(ctx, c, "")
 
 	//调用gRPC的API
 	loginResp, err := h.grpcClient.Login(ctx, &pb.LoginRequest{
@@ -86,33 +166,66 @@ func (h *UserHandler) Login(c *gin.Context) {
 	})
 
 	if err != nil {
-		log.Error("登录RPC调用失败", zap.Error(err))
-		response.Error(c, response.CodeRPCError, "登录失败")
+		rpcError(c, err, "登录失败")
 		return
 	}
 
-	if loginResp.Code != 0 {
-		httpCode := mapRPCCode(loginResp.Code)
-		response.Error(c, httpCode, loginResp.Message)
-		return
+	// 设置Cookie（Web浏览器自动使用），有效期与服务端Session一致
+	maxAge := int(loginResp.ExpiresIn)
+	if maxAge <= 0 {
+		maxAge = DefaultCookieMaxAge
+	}
+	setAuthCookie(c, loginResp.Token, maxAge)
+	if loginResp.RefreshToken != "" {
+		setRefreshCookie(c, loginResp.RefreshToken, int(loginResp.RefreshExpiresIn))
 	}
-
-	// 设置Cookie（Web浏览器自动使用）
-	c.SetCookie(
-		"auth_token",    // Cookie名称
-		loginResp.Token, // Token值
-		7200,            // MaxAge: 2小时（秒）
-		"/",             // Path: 全站有效
-		"",              // Domain: 当前域
-		false,           // Secure: 生产环境建议改为true
-		true,            // HttpOnly: 改为false，让前端JS可以读取
-	)
 
 	// 发送响应
 	response.Success(c, gin.H{
 		"username":   loginResp.User.Username,
 		"nickname":   loginResp.User.Nickname,
-		"avatar_url": "/api/v1/profile/picture",
+		"avatar_url": avatarURL(loginResp.User.Id),
+	})
+}
+
+// RefreshToken 使用刷新凭证换发登录凭证（仅token模式）
+func (h *UserHandler) RefreshToken(c *gin.Context) {
+	var req RefreshTokenRequest
+	// 请求体可选
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.Error(c, response.CodeBadRequest, "请求参数错误")
+			return
+		}
+	}
+	if req.RefreshToken == "" {
+		req.RefreshToken, _ = c.Cookie(RefreshCookieName)
+	}
+	if req.RefreshToken == "" {
+		response.Error(c, response.CodeUnauthorized, "未认证")
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	resp, err := h.grpcClient.RefreshToken(ctx, &pb.RefreshTokenRequest{
+		RefreshToken: req.RefreshToken,
+	})
+
+	if err != nil {
+		if status.Code(err) == codes.Unauthenticated {
+			// 刷新凭证失效，清除Cookie让客户端重新登录
+			setRefreshCookie(c, "", -1)
+		}
+		rpcError(c, err, "刷新失败")
+		return
+	}
+
+	setAuthCookie(c, resp.Token, int(resp.ExpiresIn))
+	setRefreshCookie(c, resp.RefreshToken, int(resp.RefreshExpiresIn))
+
+	response.Success(c, gin.H{
+		"expires_in": resp.ExpiresIn,
 	})
 }
 
@@ -124,32 +237,23 @@ func (h *UserHandler) GetProfile(c *gin.Context) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 3*time.Second)
-	defer cancel()
+	ctx := c.Request.Context()
 
-	ctx = metadata.NewOutgoingContext(ctx,
-		metadata.Pairs("authorization", token))
+	ctx = withOutgoingMetadata(ctx, c, token)
 
 	resp, err := h.grpcClient.GetProfile(ctx, &pb.GetProfileRequest{
 		Token: token,
 	})
 
 	if err != nil {
-		log.Error("RPC调用失败", zap.Error(err))
-		response.Error(c, response.CodeRPCError, "获取用户信息失败")
-		return
-	}
-
-	if resp.Code != 0 {
-		httpCode := mapRPCCode(resp.Code)
-		response.Error(c, httpCode, resp.Message)
+		rpcError(c, err, "获取用户信息失败")
 		return
 	}
 
 	response.Success(c, gin.H{
 		"username":   resp.User.Username,
 		"nickname":   resp.User.Nickname,
-		"avatar_url": "/api/v1/profile/picture",
+		"avatar_url": avatarURL(resp.User.Id),
 	})
 }
 
@@ -167,11 +271,9 @@ func (h *UserHandler) UpdateNickname(c *gin.Context) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 3*time.Second)
-	defer cancel()
+	ctx := c.Request.Context()
 
-	ctx = metadata.NewOutgoingContext(ctx,
-		metadata.Pairs("authorization", token))
+	ctx = withOutgoingMetadata(ctx, c, token)
 
 	resp, err := h.grpcClient.UpdateNickname(ctx, &pb.UpdateNicknameRequest{
 		Token:    token,
@@ -179,170 +281,438 @@ func (h *UserHandler) UpdateNickname(c *gin.Context) {
 	})
 
 	if err != nil {
-		log.Error("RPC调用失败", zap.Error(err))
-		response.Error(c, response.CodeRPCError, "更新昵称失败")
-		return
-	}
-
-	if resp.Code != 0 {
-		httpCode := mapRPCCode(resp.Code)
-		response.Error(c, httpCode, resp.Message)
+		rpcError(c, err, "更新昵称失败")
 		return
 	}
 
 	response.Success(c, gin.H{
 		"username":   resp.User.Username,
 		"nickname":   resp.User.Nickname,
-		"avatar_url": "/api/v1/profile/picture",
+		"avatar_url": avatarURL(resp.User.Id),
 	})
 }
 
-// UploadProfilePicture 上传头像
-func (h *UserHandler) UploadProfilePicture(c *gin.Context) {
+// ChangePassword 修改密码（保留当前登录，注销其他设备）
+func (h *UserHandler) ChangePassword(c *gin.Context) {
 	token := extractToken(c)
 	if token == "" {
 		response.Error(c, response.CodeUnauthorized, "未认证")
 		return
 	}
 
-	file, err := c.FormFile("file")
-	if err != nil {
-		response.Error(c, response.CodeBadRequest, "请上传文件")
+	var req ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.CodeBadRequest, "请求参数错误")
 		return
 	}
 
-	if file.Size > MaxFileSize {
-		response.Error(c, response.CodeFileTooLarge, "文件过大")
+	ctx := c.Request.Context()
+
+	ctx = withOutgoingMetadata(ctx, c, token)
+
+	resp, err := h.grpcClient.ChangePassword(ctx, &pb.ChangePasswordRequest{
+		Token:       token,
+		OldPassword: req.OldPassword,
+		NewPassword: req.NewPassword,
+	})
+
+	if err != nil {
+		rpcError(c, err, "修改密码失败")
 		return
 	}
 
-	ext := strings.ToLower(filepath.Ext(file.Filename))
-	if !allowedExtensionsMap[ext] {
-		response.Error(c, response.CodeUnsupportedFileType, "不支持的文件类型")
+	response.Success(c, gin.H{
+		"revoked_sessions": resp.RevokedSessions,
+	})
+}
+
+// UploadProfilePicture 上传头像
+func (h *UserHandler) UploadProfilePicture(c *gin.Context) {
+	token := extractToken(c)
+	if token == "" {
+		response.Error(c, response.CodeUnauthorized, "未认证")
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 3*time.Second)
-	defer cancel()
+	// 先校验Session再读取请求体：未登录的请求不占用图片处理名额，也不触发解码
+	ctx := c.Request.Context()
 
-	ctx = metadata.NewOutgoingContext(ctx,
-		metadata.Pairs("authorization", token))
+	ctx = withOutgoingMetadata(ctx, c, token)
 
 	profileResp, err := h.grpcClient.GetProfile(ctx, &pb.GetProfileRequest{
 		Token: token,
 	})
 
-	if err != nil || profileResp.Code != 0 {
-		response.Error(c, response.CodeUnauthorized, "未认证")
+	if err != nil {
+		rpcError(c, err, "获取用户信息失败")
 		return
 	}
 
-	userID := profileResp.User.Id
-	filename := fmt.Sprintf("%d%s", userID, ext)
-	savePath := filepath.Join(UploadDir, filename)
-
-	if err := os.MkdirAll(UploadDir, 0755); err != nil {
-		log.Error("创建上传目录失败", zap.Error(err))
-		response.Error(c, response.CodeInternalServerError, "服务器错误")
+	file, err := c.FormFile("file")
+	if err != nil {
+		response.Error(c, response.CodeBadRequest, "请上传文件")
 		return
 	}
 
-	if err := c.SaveUploadedFile(file, savePath); err != nil {
-		log.Error("保存文件失败", zap.Error(err))
-		response.Error(c, response.CodeInternalServerError, "保存文件失败")
+	if file.Size > MaxFileSize {
+		response.Error(c, response.CodeFileTooLarge, "文件过大")
 		return
 	}
 
-	avatarURL := fmt.Sprintf("/uploads/avatars/%s", filename)
+	// 不信任扩展名：按文件内容识别格式，完整解码后重新编码，丢弃 EXIF 等元数据
+	data, err := readUploadedFile(file)
+	if err != nil {
+		log.WarnCtx(c.Request.Context(), "读取上传文件失败", zap.Error(err))
+		response.Error(c, response.CodeBadRequest, "读取文件失败")
+		return
+	}
+	if int64(len(data)) > MaxFileSize {
+		response.Error(c, response.CodeFileTooLarge, "文件过大")
+		return
+	}
 
-	ctx2, cancel2 := context.WithTimeout(c.Request.Context(), 3*time.Second)
-	defer cancel2()
+	// 原图和各尺寸缩略图在上传时一次生成
+	variants, err := h.processAvatar(c.Request.Context(), data)
+	if err != nil {
+		log.WarnCtx(c.Request.Context(), "头像图片处理失败",
+			zap.String("filename", file.Filename),
+			zap.Error(err))
+		switch {
+		case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+			response.Error(c, response.CodeServiceUnavailable, "服务繁忙，请稍后重试")
+		case errors.Is(err, avatar.ErrUnsupportedFormat):
+			response.Error(c, response.CodeUnsupportedFileType, "不支持的文件类型")
+		case errors.Is(err, avatar.ErrImageTooLarge):
+			response.Error(c, response.CodeFileTooLarge, "图片尺寸过大")
+		case errors.Is(err, avatar.ErrInvalidImage):
+			response.Error(c, response.CodeInvalidFile, "无效的图片文件")
+		default:
+			response.Error(c, response.CodeInternalServerError, "处理图片失败")
+		}
+		return
+	}
 
-	ctx2 = metadata.NewOutgoingContext(ctx2,
-		metadata.Pairs("authorization", token))
+	userID := profileResp.User.Id
+	previous, hasPrevious := avatar.FromProfilePicture(profileResp.User.AvatarUrl)
+	// key 由内容决定：新文件不会覆盖正在使用的头像，数据库引用新 key 之前也不会被访问
+	key := avatar.Key(userID, variants[0])
+
+	// previous 来自 GetProfile，可能是过期缓存，只用来决定哪些文件可以删除，不用来跳过数据库更新
+	unchanged := hasPrevious && previous == key
+
+	if err := h.putAvatarObjects(c.Request.Context(), key, variants); err != nil {
+		log.ErrorCtx(c.Request.Context(), "保存文件失败", zap.Error(err))
+		// 与当前头像相同的 key 正在使用，不能删除
+		if !unchanged {
+			h.deleteAvatarObjects(c.Request.Context(), avatar.Keys(key))
+		}
+		response.Error(c, response.CodeInternalServerError, "保存文件失败")
+		return
+	}
 
-	updateResp, err := h.grpcClient.UpdateProfilePicture(ctx2, &pb.UpdateProfilePictureRequest{
+	// TCP Server 只保存存储 key，访问地址由存储后端决定；内容与当前头像相同时也要更新（幂等），
+	// 确保数据库确实引用该 key
+	_, err = h.grpcClient.UpdateProfilePicture(ctx, &pb.UpdateProfilePictureRequest{
 		Token:          token,
-		ProfilePicture: avatarURL,
+		ProfilePicture: key,
 	})
 
 	if err != nil {
-		log.Error("RPC调用失败", zap.Error(err))
-		// 尝试删除已上传的文件，失败只记录日志
-		if removeErr := os.Remove(savePath); removeErr != nil {
-			log.Warn("删除文件失败", zap.Error(removeErr), zap.String("path", savePath))
+		// 确定未更新时删除新文件；结果未知（超时、连接中断）时数据库可能已引用新文件，
+		// 保留由对账任务清理。删除失败只记录日志
+		if avatarUpdateRejected(err) && !unchanged {
+			h.deleteAvatarObjects(c.Request.Context(), avatar.Keys(key))
 		}
-		response.Error(c, response.CodeRPCError, "更新头像失败")
+		rpcError(c, err, "更新头像失败")
 		return
 	}
 
-	if updateResp.Code != 0 {
-		httpCode := mapRPCCode(updateResp.Code)
-		// 尝试删除已上传的文件，失败只记录日志
-		if removeErr := os.Remove(savePath); removeErr != nil {
-			log.Warn("删除文件失败", zap.Error(removeErr), zap.String("path", savePath))
-		}
-		response.Error(c, httpCode, updateResp.Message)
-		return
+	// 数据库已指向新头像，删除旧头像（失败只记录日志，由对账任务清理）
+	if hasPrevious && !unchanged {
+		h.deleteAvatarObjects(c.Request.Context(), avatar.Keys(previous))
 	}
 
 	response.Success(c, gin.H{
-		"avatar_url": "/api/v1/profile/picture",
+		"avatar_url": avatarURL(userID),
 	})
 }
 
-// GetProfilePicture 获取头像
+// GetProfilePicture 获取头像，size 参数指定缩略图尺寸（见 avatar.Sizes），不传返回原图
 func (h *UserHandler) GetProfilePicture(c *gin.Context) {
+	size, ok := parseAvatarSize(c)
+	if !ok {
+		return
+	}
+
 	token := extractToken(c)
 	if token == "" {
-		serveDefaultAvatar(c)
+		serveDefaultAvatar(c, AvatarCacheControl)
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 3*time.Second)
-	defer cancel()
+	ctx := c.Request.Context()
 
-	ctx = metadata.NewOutgoingContext(ctx,
-		metadata.Pairs("authorization", token))
+	ctx = withOutgoingMetadata(ctx, c, token)
 
 	resp, err := h.grpcClient.GetProfile(ctx, &pb.GetProfileRequest{
 		Token: token,
 	})
 
-	if err != nil || resp.Code != 0 {
-		serveDefaultAvatar(c)
+	if err != nil {
+		serveDefaultAvatar(c, AvatarCacheControl)
 		return
 	}
 
-	avatarURL := resp.User.AvatarUrl
+	h.serveProfilePicture(c, resp.User.Id, resp.User.AvatarUrl, size, AvatarCacheControl)
+}
 
-	if avatarURL == "" || !strings.HasPrefix(avatarURL, "/uploads/avatars/") {
-		serveDefaultAvatar(c)
+// GetUserPicture 获取指定用户的头像（无需登录），size 参数同 GetProfilePicture
+// 头像未设置或文件不存在时返回默认头像，用户不存在时返回 404
+func (h *UserHandler) GetUserPicture(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil || userID == 0 {
+		response.Error(c, response.CodeInvalidParams, "用户ID无效")
 		return
 	}
 
-	localPath := "." + avatarURL
+	size, ok := parseAvatarSize(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	ctx = withOutgoingMetadata(ctx, c, "")
+
+	resp, err := h.grpcClient.GetPublicProfile(ctx, &pb.GetPublicProfileRequest{
+		UserId: userID,
+	})
 
-	if _, err := os.Stat(localPath); os.IsNotExist(err) {
-		log.Warn("头像文件不存在",
-			zap.String("path", localPath),
-			zap.Uint64("user_id", resp.User.Id))
-		serveDefaultAvatar(c)
+	if err != nil {
+		// 错误响应不设置 Cache-Control，不会被共享缓存
+		rpcError(c, err, "获取头像失败")
 		return
 	}
 
-	c.File(localPath)
+	h.serveProfilePicture(c, resp.User.Id, resp.User.AvatarUrl, size, h.publicCacheControl)
 }
 
-// serveDefaultAvatar 返回默认头像
-func serveDefaultAvatar(c *gin.Context) {
-	// 检查默认头像文件是否存在
-	if _, err := os.Stat(DefaultAvatar); os.IsNotExist(err) {
-		log.Error("默认头像文件不存在", zap.String("path", DefaultAvatar))
-		c.Status(http.StatusNotFound)
+// parseAvatarSize 解析 size 参数，不传时为 0（原图）；不支持的尺寸已写入错误响应，返回 false
+func parseAvatarSize(c *gin.Context) (int, bool) {
+	s := c.Query("size")
+	if s == "" {
+		return 0, true
+	}
+	size, ok := avatar.ParseSize(s)
+	if !ok {
+		response.Error(c, response.CodeInvalidParams, fmt.Sprintf("不支持的头像尺寸，可选值: %v", avatar.Sizes))
+		return 0, false
+	}
+	return size, true
+}
+
+// serveProfilePicture 返回用户头像字段指向的文件，未设置或文件不存在时返回默认头像
+func (h *UserHandler) serveProfilePicture(c *gin.Context, userID uint64, profilePicture string, size int, cacheControl string) {
+	key, ok := avatar.FromProfilePicture(profilePicture)
+	if !ok {
+		serveDefaultAvatar(c, cacheControl)
 		return
 	}
-	c.File(DefaultAvatar)
+
+	if _, err := h.storage.Stat(c.Request.Context(), key); err != nil {
+		log.WarnCtx(c.Request.Context(), "头像文件不存在",
+			zap.String("key", key),
+			zap.Uint64("user_id", userID),
+			zap.Error(err))
+		serveDefaultAvatar(c, cacheControl)
+		return
+	}
+
+	if size != 0 {
+		thumbKey, err := h.ensureThumbnail(c.Request.Context(), key, size)
+		if err != nil {
+			// 缩略图生成失败时退回原图
+			log.WarnCtx(c.Request.Context(), "生成头像缩略图失败",
+				zap.String("key", key),
+				zap.Int("size", size),
+				zap.Error(err))
+		} else {
+			key = thumbKey
+		}
+	}
+
+	h.serveAvatar(c, key, cacheControl)
+}
+
+// avatarUpdateRejected 更新头像的 RPC 是否确定没有修改数据库（请求被拒绝）
+// 超时、连接中断、内部错误等情况下数据库可能已经更新，不能删除新文件
+func avatarUpdateRejected(err error) bool {
+	switch status.Code(err) {
+	case codes.InvalidArgument, codes.Unauthenticated, codes.PermissionDenied,
+		codes.FailedPrecondition, codes.ResourceExhausted:
+		return true
+	}
+	return false
+}
+
+// acquireProcessing 占用一个头像处理名额，名额用完时排队，请求取消或超时后放弃
+func (h *UserHandler) acquireProcessing(ctx context.Context) (func(), error) {
+	select {
+	case h.processing <- struct{}{}:
+		return func() { <-h.processing }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// processAvatar 校验并解码上传的图片，生成原图和各尺寸缩略图
+func (h *UserHandler) processAvatar(ctx context.Context, data []byte) (map[int][]byte, error) {
+	release, err := h.acquireProcessing(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	img, err := avatar.Decode(data, avatar.DefaultLimits)
+	if err != nil {
+		return nil, err
+	}
+	return avatar.Variants(img)
+}
+
+// putAvatarObjects 保存原图和缩略图
+// 先写缩略图，最后写原图：原图存在即表示缩略图已生成
+func (h *UserHandler) putAvatarObjects(ctx context.Context, original string, variants map[int][]byte) error {
+	for _, size := range append(slices.Clone(avatar.Sizes), 0) {
+		key := avatar.SizeKey(original, size)
+		if err := h.storage.Put(ctx, key, variants[size], avatar.ContentType); err != nil {
+			return fmt.Errorf("写入 %s 失败: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// deleteAvatarObjects 删除对象，失败只记录日志
+func (h *UserHandler) deleteAvatarObjects(ctx context.Context, keys []string) {
+	for _, key := range keys {
+		if err := h.storage.Delete(ctx, key); err != nil {
+			log.WarnCtx(ctx, "删除文件失败", zap.Error(err), zap.String("key", key))
+		}
+	}
+}
+
+// ensureThumbnail 返回缩略图 key；缩略图不存在时（如功能上线前上传的头像）由原图生成并保存
+func (h *UserHandler) ensureThumbnail(ctx context.Context, original string, size int) (string, error) {
+	key := avatar.SizeKey(original, size)
+	_, err := h.storage.Stat(ctx, key)
+	if err == nil {
+		return key, nil
+	}
+	if !errors.Is(err, storage.ErrNotFound) {
+		return "", err
+	}
+
+	obj, err := h.storage.Get(ctx, original)
+	if err != nil {
+		return "", err
+	}
+	defer obj.Close()
+	data, err := io.ReadAll(io.LimitReader(obj, MaxFileSize+1))
+	if err != nil {
+		return "", err
+	}
+
+	release, err := h.acquireProcessing(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+
+	img, err := avatar.Decode(data, avatar.DefaultLimits)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := avatar.Encode(&buf, avatar.Thumbnail(img, size)); err != nil {
+		return "", err
+	}
+	if err := h.storage.Put(ctx, key, buf.Bytes(), avatar.ContentType); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+// serveAvatar 返回头像；配置了跳转且存储支持直接访问地址时 302 跳转，否则读取后返回
+func (h *UserHandler) serveAvatar(c *gin.Context, key, cacheControl string) {
+	if h.avatarRedirect {
+		url, err := h.storage.URL(c.Request.Context(), key)
+		if err == nil {
+			// 预签名 URL 每次都不同且会过期，跳转响应不能缓存
+			c.Header("Cache-Control", "no-store")
+			c.Redirect(http.StatusFound, url)
+			return
+		}
+		if !errors.Is(err, storage.ErrURLNotSupported) {
+			log.WarnCtx(c.Request.Context(), "生成头像访问地址失败", zap.String("key", key), zap.Error(err))
+		}
+	}
+
+	serveObject(c, h.storage, key, cacheControl)
+}
+
+// serveObject 读取对象并返回，设置缓存相关 header
+// 客户端带 If-None-Match 且 ETag 未变化时由 http.ServeContent 返回 304
+func serveObject(c *gin.Context, store storage.Storage, key, cacheControl string) {
+	obj, err := store.Get(c.Request.Context(), key)
+	if err != nil {
+		log.ErrorCtx(c.Request.Context(), "读取头像文件失败", zap.String("key", key), zap.Error(err))
+		if errors.Is(err, storage.ErrNotFound) {
+			c.Status(http.StatusNotFound)
+		} else {
+			c.Status(http.StatusInternalServerError)
+		}
+		return
+	}
+	defer obj.Close()
+
+	c.Header("Cache-Control", cacheControl)
+	if obj.ETag != "" {
+		c.Header("ETag", `"`+obj.ETag+`"`)
+	}
+	if obj.ContentType != "" {
+		c.Header("Content-Type", obj.ContentType)
+	}
+	c.Header("X-Content-Type-Options", "nosniff")
+	http.ServeContent(c.Writer, c.Request, path.Base(key), obj.ModTime, obj)
+}
+
+// readUploadedFile 读取上传文件内容，最多读取 MaxFileSize+1 字节用于判断是否超限
+func readUploadedFile(file *multipart.FileHeader) ([]byte, error) {
+	src, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+	return io.ReadAll(io.LimitReader(src, MaxFileSize+1))
+}
+
+// defaultAvatarStorage 默认头像所在目录（不随存储配置变化）
+var defaultAvatarStorage = storage.NewLocalStorage(filepath.Dir(DefaultAvatar))
+
+// serveDefaultAvatar 返回默认头像（不区分尺寸）
+func serveDefaultAvatar(c *gin.Context, cacheControl string) {
+	serveObject(c, defaultAvatarStorage, filepath.Base(DefaultAvatar), cacheControl)
+}
+
+// avatarURL 返回用户头像的访问地址：每个用户固定，上传新头像后地址不变，由 ETag 区分内容
+func avatarURL(userID uint64) string {
+	return "/api/v1/users/" + strconv.FormatUint(userID, 10) + "/picture"
+}
+
+// publicAvatarCacheControl 公开头像的 Cache-Control：内容与请求者无关，允许 CDN 等共享缓存，
+// 上传新头像后最多 maxAge 内仍可能看到旧头像，过期后用 ETag 验证
+func publicAvatarCacheControl(maxAge time.Duration) string {
+	return "public, max-age=" + strconv.Itoa(int(maxAge.Seconds()))
 }
 
 // Logout 登出
@@ -353,46 +723,213 @@ func (h *UserHandler) Logout(c *gin.Context) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 3*time.Second)
-	defer cancel()
+	ctx := c.Request.Context()
 
-	ctx = metadata.NewOutgoingContext(ctx,
-		metadata.Pairs("authorization", token))
+	ctx = withOutgoingMetadata(ctx, c, token)
 
-	resp, err := h.grpcClient.Logout(ctx, &pb.LogoutRequest{
+	_, err := h.grpcClient.Logout(ctx, &pb.LogoutRequest{
 		Token: token,
 	})
 
 	if err != nil {
-		log.Error("RPC调用失败", zap.Error(err))
-		response.Error(c, response.CodeRPCError, "登出失败")
+		rpcError(c, err, "登出失败")
 		return
 	}
 
-	if resp.Code != 0 {
-		httpCode := mapRPCCode(resp.Code)
-		response.Error(c, httpCode, resp.Message)
+	// 清除Cookie
+	setAuthCookie(c, "", -1)
+	setRefreshCookie(c, "", -1)
+
+	response.Success(c, gin.H{})
+}
+
+// ListSessions 列出当前用户的所有登录Session
+func (h *UserHandler) ListSessions(c *gin.Context) {
+	token := extractToken(c)
+	if token == "" {
+		response.Error(c, response.CodeUnauthorized, "未认证")
 		return
 	}
 
-	// 清除Cookie
-	c.SetCookie(
-		"auth_token",
-		"", // 空值
-		-1, // MaxAge=-1 表示立即删除
-		"/",
-		"",
-		false,
-		true,
-	)
+	ctx := c.Request.Context()
+
+	ctx = withOutgoingMetadata(ctx, c, token)
+
+	resp, err := h.grpcClient.ListSessions(ctx, &pb.ListSessionsRequest{
+		Token: token,
+	})
+
+	if err != nil {
+		rpcError(c, err, "获取Session列表失败")
+		return
+	}
+
+	sessions := make([]gin.H, 0, len(resp.Sessions))
+	for _, s := range resp.Sessions {
+		sessions = append(sessions, gin.H{
+			"session_id":   s.SessionId,
+			"created_at":   s.CreatedAt,
+			"last_seen_at": s.LastSeenAt,
+			"client_ip":    s.ClientIp,
+			"user_agent":   s.UserAgent,
+			"current":      s.Current,
+		})
+	}
+
+	response.Success(c, gin.H{
+		"sessions": sessions,
+	})
+}
+
+// RevokeSession 注销指定Session（踢下线某台设备）
+func (h *UserHandler) RevokeSession(c *gin.Context) {
+	token := extractToken(c)
+	if token == "" {
+		response.Error(c, response.CodeUnauthorized, "未认证")
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	ctx = withOutgoingMetadata(ctx, c, token)
+
+	_, err := h.grpcClient.RevokeSession(ctx, &pb.RevokeSessionRequest{
+		Token:     token,
+		SessionId: c.Param("id"),
+	})
+
+	if err != nil {
+		rpcError(c, err, "注销Session失败")
+		return
+	}
 
 	response.Success(c, gin.H{})
 }
 
+// RevokeAllSessions 注销其他所有Session（include_current=true 时连同当前Session一起注销）
+func (h *UserHandler) RevokeAllSessions(c *gin.Context) {
+	token := extractToken(c)
+	if token == "" {
+		response.Error(c, response.CodeUnauthorized, "未认证")
+		return
+	}
+
+	var query RevokeAllSessionsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		response.Error(c, response.CodeBadRequest, "请求参数错误")
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	ctx = withOutgoingMetadata(ctx, c, token)
+
+	resp, err := h.grpcClient.RevokeAllSessions(ctx, &pb.RevokeAllSessionsRequest{
+		Token:          token,
+		IncludeCurrent: query.IncludeCurrent,
+	})
+
+	if err != nil {
+		rpcError(c, err, "注销Session失败")
+		return
+	}
+
+	// 当前Session也被注销时清除Cookie
+	if query.IncludeCurrent {
+		setAuthCookie(c, "", -1)
+		setRefreshCookie(c, "", -1)
+	}
+
+	response.Success(c, gin.H{
+		"revoked_sessions": resp.RevokedSessions,
+	})
+}
+
 // ============================================================================
 // 工具函数
 // ============================================================================
 
+// withOutgoingMetadata 构造调用 TCP Server 的 gRPC metadata
+// 包含认证 Token（如有）以及客户端 IP、User-Agent，供 Session 设备列表使用
+// 这里不设置超时：各 RPC 的超时由 grpcclient 的 Service Config 按方法配置，请求 context 只负责传递客户端取消
+func withOutgoingMetadata(ctx context.Context, c *gin.Context, token string) context.Context {
+	md := metadata.Pairs(
+		"x-client-ip", c.ClientIP(),
+		"x-client-user-agent", c.Request.UserAgent(),
+	)
+	if token != "" {
+		md.Set("authorization", token)
+		// 已登录请求可能触发服务端续期，携带 gin.Context 供 SessionCookieInterceptor 回写Cookie
+		ctx = context.WithValue(ctx, ginContextKey{}, c)
+	}
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+// setAuthCookie 设置认证Cookie，maxAge < 0 表示删除
+func setAuthCookie(c *gin.Context, token string, maxAge int) {
+	c.SetCookie(
+		AuthCookieName, // Cookie名称
+		token,          // Token值
+		maxAge,         // MaxAge（秒）
+		"/",            // Path: 全站有效
+		"",             // Domain: 当前域
+		false,          // Secure: 生产环境建议改为true
+		true,           // HttpOnly: 禁止前端JS读取
+	)
+}
+
+// setRefreshCookie 设置刷新凭证Cookie，maxAge < 0 表示删除
+func setRefreshCookie(c *gin.Context, refreshToken string, maxAge int) {
+	c.SetCookie(RefreshCookieName, refreshToken, maxAge, RefreshCookiePath, "", false, true)
+}
+
+// SessionCookieInterceptor gRPC 客户端拦截器
+// TCP Server 滑动续期 Session 后会在响应 header 中返回新的剩余有效期，这里据此重新下发Cookie，
+// 使浏览器端Cookie与服务端Session同步过期
+func SessionCookieInterceptor() grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply interface{},
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		c, ok := ctx.Value(ginContextKey{}).(*gin.Context)
+		if !ok {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		var header metadata.MD
+		err := invoker(ctx, method, req, reply, cc, append(opts, grpc.Header(&header))...)
+
+		if values := header.Get(metadataSessionTTL); len(values) > 0 {
+			if maxAge, convErr := strconv.Atoi(values[0]); convErr == nil && maxAge > 0 {
+				setAuthCookie(c, extractToken(c), maxAge)
+			}
+		}
+		return err
+	}
+}
+
+// RequestIDInterceptor gRPC 客户端拦截器
+// 将 RequestIDMiddleware 绑定到请求 context 的请求ID写入 metadata，TCP Server 的日志据此与网关日志关联
+func RequestIDInterceptor() grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply interface{},
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		if requestID := log.RequestIDFromContext(ctx); requestID != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, requestid.MetadataKey, requestID)
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
 // extractToken 从请求头或Cookie中提取认证 Token
 // 支持以下格式：
 //   - Authorization: Bearer <token>
@@ -401,31 +938,61 @@ func (h *UserHandler) Logout(c *gin.Context) {
 //
 // 返回去除 "Bearer " 前缀和首尾空格后的 token 字符串
 func extractToken(c *gin.Context) string {
-	token, _ := c.Cookie("auth_token")
+	token, _ := c.Cookie(AuthCookieName)
 	return token
 }
 
-// mapRPCCode 将 RPC 错误码映射为 HTTP 响应错误码
-// 参数:
-//   - rpcCode: TCP Server 返回的 gRPC 错误码
+// rpcError 返回 RPC 调用失败的响应
+// 带 ErrorInfo 的业务错误按错误原因的 (errcode.code) 选项映射错误码，并返回 TCP Server 的错误消息：
+//   - BadRequest 中的字段错误放入 data.field_violations
+//   - RetryInfo 转换为 Retry-After 响应头
 //
-// 返回:
-//   - 对应的 HTTP 错误码（定义在 response.Code 中）
-func mapRPCCode(rpcCode int32) int {
-	switch rpcCode {
-	case 40001:
-		return response.CodeInvalidParams
-	case 40002:
-		return response.CodeInvalidAccountOrPassword
-	case 40003:
-		return response.CodeUnauthorized
-	case 40004:
-		return response.CodeUserNotFound
-	case 40104:
-		return response.CodeInvalidNickname
-	case 42901:
-		return response.CodeBadRequest
+// 其余错误按 gRPC 状态码处理：TCP Server 不可用（含熔断）返回服务不可用，超时返回请求超时，
+// 鉴权拦截器拒绝返回未认证，其余返回 RPC 调用错误
+func rpcError(c *gin.Context, err error, message string) {
+	st := status.Convert(err)
+
+	var info *errdetails.ErrorInfo
+	var violations []gin.H
+	for _, detail := range st.Details() {
+		switch d := detail.(type) {
+		case *errdetails.ErrorInfo:
+			info = d
+		case *errdetails.BadRequest:
+			for _, v := range d.GetFieldViolations() {
+				violations = append(violations, gin.H{"field": v.GetField(), "description": v.GetDescription()})
+			}
+		case *errdetails.RetryInfo:
+			if delay := d.GetRetryDelay().AsDuration(); delay > 0 {
+				c.Header("Retry-After", strconv.Itoa(int(math.Ceil(delay.Seconds()))))
+			}
+		}
+	}
+
+	reason := pb.ErrorReason(pb.ErrorReason_value[info.GetReason()])
+	if code, ok := errcode.FromReason(reason); ok {
+		if code == errcode.Code_INTERNAL_SERVER_ERROR {
+			log.ErrorCtx(c.Request.Context(), "RPC调用失败", zap.Error(err))
+		} else {
+			log.WarnCtx(c.Request.Context(), "RPC业务错误", zap.String("reason", info.GetReason()), zap.Error(err))
+		}
+		if len(violations) > 0 {
+			response.ErrorWithData(c, int(code), st.Message(), gin.H{"field_violations": violations})
+			return
+		}
+		response.Error(c, int(code), st.Message())
+		return
+	}
+
+	log.ErrorCtx(c.Request.Context(), "RPC调用失败", zap.Error(err))
+	switch st.Code() {
+	case codes.Unavailable:
+		response.Error(c, response.CodeServiceUnavailable, response.GetMessage(response.CodeServiceUnavailable))
+	case codes.DeadlineExceeded:
+		response.Error(c, response.CodeRequestTimeout, response.GetMessage(response.CodeRequestTimeout))
+	case codes.Unauthenticated:
+		response.Error(c, response.CodeUnauthorized, response.GetMessage(response.CodeUnauthorized))
 	default:
-		return response.CodeInternalServerError
+		response.Error(c, response.CodeRPCError, message)
 	}
 }
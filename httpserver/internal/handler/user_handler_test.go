@@ -0,0 +1,417 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"entry-task/httpserver/pkg/avatar"
+	"entry-task/httpserver/pkg/response"
+	"entry-task/httpserver/pkg/storage"
+	"entry-task/pkg/logger"
+	"entry-task/pkg/requestid"
+	pb "entry-task/proto/user/v2"
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	if err := logger.Init(&logger.Config{Level: "error", Output: "stdout"}); err != nil {
+		panic("初始化日志失败: " + err.Error())
+	}
+	os.Exit(m.Run())
+}
+
+// fakeProfileClient GetProfile 返回固定的头像，记录 UpdateProfilePicture 的调用
+type fakeProfileClient struct {
+	pb.UserServiceClient
+
+	avatarURL  string   // GetProfile 返回的头像（模拟缓存中的值）
+	profileErr error    // GetProfile 返回的错误（模拟Session无效）
+	updates    []string // UpdateProfilePicture 收到的 key
+}
+
+func (f *fakeProfileClient) GetProfile(context.Context, *pb.GetProfileRequest, ...grpc.CallOption) (*pb.GetProfileResponse, error) {
+	if f.profileErr != nil {
+		return nil, f.profileErr
+	}
+	return &pb.GetProfileResponse{User: &pb.UserProfile{Id: 1, AvatarUrl: f.avatarURL}}, nil
+}
+
+func (f *fakeProfileClient) UpdateProfilePicture(_ context.Context, req *pb.UpdateProfilePictureRequest, _ ...grpc.CallOption) (*pb.UpdateProfilePictureResponse, error) {
+	f.updates = append(f.updates, req.ProfilePicture)
+	return &pb.UpdateProfilePictureResponse{}, nil
+}
+
+// testPNG 生成纯色 PNG
+func testPNG(t *testing.T, c color.Color) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("编码 PNG 失败: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// avatarKey 计算上传 data 后的头像 key
+func avatarKey(t *testing.T, data []byte) string {
+	t.Helper()
+	img, err := avatar.Decode(data, avatar.DefaultLimits)
+	if err != nil {
+		t.Fatalf("解码失败: %v", err)
+	}
+	variants, err := avatar.Variants(img)
+	if err != nil {
+		t.Fatalf("生成缩略图失败: %v", err)
+	}
+	return avatar.Key(1, variants[0])
+}
+
+// upload 以已登录状态上传头像
+func upload(t *testing.T, h *UserHandler, data []byte) int {
+	t.Helper()
+	return uploadWithContext(t, context.Background(), h, data)
+}
+
+// uploadWithContext 以已登录状态上传头像，请求使用 ctx
+func uploadWithContext(t *testing.T, ctx context.Context, h *UserHandler, data []byte) int {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, err := mw.CreateFormFile("file", "avatar.png")
+	if err != nil {
+		t.Fatalf("创建表单失败: %v", err)
+	}
+	_, _ = fw.Write(data)
+	_ = mw.Close()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequestWithContext(ctx, http.MethodPost, "/api/v1/profile/picture", &body)
+	c.Request.Header.Set("Content-Type", mw.FormDataContentType())
+	c.Request.AddCookie(&http.Cookie{Name: AuthCookieName, Value: "token"})
+	h.UploadProfilePicture(c)
+	return w.Code
+}
+
+// countingReader 记录已读取的字节数
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += n
+	return n, err
+}
+
+// TestUploadProfilePicture_InvalidToken 测试Session无效时返回 401，不读取请求体、不占用图片处理名额
+func TestUploadProfilePicture_InvalidToken(t *testing.T) {
+	client := &fakeProfileClient{
+		profileErr: withDetails(t, codes.Unauthenticated, "Token无效", errorInfo(pb.ErrorReason_TOKEN_INVALID)),
+	}
+	h := NewUserHandler(client, storage.NewLocalStorage(t.TempDir()), false, time.Minute, 1)
+	h.processing <- struct{}{} // 占满名额：若进入图片处理会一直等待到请求超时
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, _ := mw.CreateFormFile("file", "avatar.png")
+	_, _ = fw.Write(testPNG(t, color.White))
+	_ = mw.Close()
+	reader := &countingReader{r: &body}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/profile/picture", reader)
+	c.Request.Header.Set("Content-Type", mw.FormDataContentType())
+	c.Request.AddCookie(&http.Cookie{Name: AuthCookieName, Value: "junk"})
+	h.UploadProfilePicture(c)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("状态码 = %d, 期望 401", w.Code)
+	}
+	if reader.n != 0 {
+		t.Errorf("Session无效时不应读取请求体, 已读取 %d 字节", reader.n)
+	}
+	if len(client.updates) != 0 {
+		t.Errorf("不应更新头像: %v", client.updates)
+	}
+}
+
+// TestUploadProfilePicture_StaleProfile 测试 GetProfile 返回的头像与上传内容相同时（可能是过期缓存）仍更新数据库，且不删除该头像文件
+func TestUploadProfilePicture_StaleProfile(t *testing.T) {
+	data := testPNG(t, color.RGBA{R: 255, A: 255})
+	key := avatarKey(t, data)
+
+	store := storage.NewLocalStorage(t.TempDir())
+	client := &fakeProfileClient{avatarURL: key}
+	h := NewUserHandler(client, store, false, time.Minute, 1)
+
+	if code := upload(t, h, data); code != http.StatusOK {
+		t.Fatalf("上传状态码 = %d, 期望 200", code)
+	}
+	if len(client.updates) != 1 || client.updates[0] != key {
+		t.Fatalf("UpdateProfilePicture 调用 = %v, 期望更新为 %s", client.updates, key)
+	}
+	for _, k := range avatar.Keys(key) {
+		if _, err := store.Stat(context.Background(), k); err != nil {
+			t.Errorf("头像文件 %s 不应被删除: %v", k, err)
+		}
+	}
+}
+
+// TestUploadProfilePicture_ReplacesPrevious 测试更换头像后删除旧头像文件
+func TestUploadProfilePicture_ReplacesPrevious(t *testing.T) {
+	store := storage.NewLocalStorage(t.TempDir())
+	client := &fakeProfileClient{}
+	h := NewUserHandler(client, store, false, time.Minute, 1)
+
+	first := testPNG(t, color.RGBA{R: 255, A: 255})
+	if code := upload(t, h, first); code != http.StatusOK {
+		t.Fatalf("上传状态码 = %d, 期望 200", code)
+	}
+	previous := avatarKey(t, first)
+	client.avatarURL = previous
+
+	second := testPNG(t, color.RGBA{B: 255, A: 255})
+	if code := upload(t, h, second); code != http.StatusOK {
+		t.Fatalf("上传状态码 = %d, 期望 200", code)
+	}
+	if len(client.updates) != 2 || client.updates[1] != avatarKey(t, second) {
+		t.Fatalf("UpdateProfilePicture 调用 = %v", client.updates)
+	}
+	if _, err := store.Stat(context.Background(), previous); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("旧头像应被删除，Stat 返回 %v", err)
+	}
+}
+
+// TestUploadProfilePicture_ProcessingLimit 测试处理名额用完时排队，请求超时后放弃且不调用 RPC
+func TestUploadProfilePicture_ProcessingLimit(t *testing.T) {
+	client := &fakeProfileClient{}
+	h := NewUserHandler(client, storage.NewLocalStorage(t.TempDir()), false, time.Minute, 1)
+	h.processing <- struct{}{} // 占满名额
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if code := uploadWithContext(t, ctx, h, testPNG(t, color.White)); code != http.StatusServiceUnavailable {
+		t.Errorf("名额用完时状态码 = %d, 期望 503", code)
+	}
+	if len(client.updates) != 0 {
+		t.Errorf("未处理的图片不应更新头像: %v", client.updates)
+	}
+
+	<-h.processing
+	if code := upload(t, h, testPNG(t, color.White)); code != http.StatusOK {
+		t.Errorf("名额释放后状态码 = %d, 期望 200", code)
+	}
+}
+
+// TestRequestIDInterceptor 测试请求ID通过 metadata 转发给 TCP Server
+func TestRequestIDInterceptor(t *testing.T) {
+	tests := []struct {
+		name      string
+		requestID string
+	}{
+		{"context 携带请求ID", "req-123"},
+		{"context 未携带请求ID", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.requestID != "" {
+				ctx = logger.WithRequestID(ctx, tt.requestID)
+			}
+
+			var got []string
+			invoker := func(ctx context.Context, _ string, _, _ interface{}, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+				md, _ := metadata.FromOutgoingContext(ctx)
+				got = md.Get(requestid.MetadataKey)
+				return nil
+			}
+			if err := RequestIDInterceptor()(ctx, "/user.v2.UserService/GetProfile", nil, nil, nil, invoker); err != nil {
+				t.Fatalf("拦截器返回错误: %v", err)
+			}
+
+			if tt.requestID == "" {
+				if len(got) != 0 {
+					t.Errorf("未携带请求ID时不应写入 metadata, 实际 %v", got)
+				}
+				return
+			}
+			if len(got) != 1 || got[0] != tt.requestID {
+				t.Errorf("metadata 中的请求ID = %v, 期望 [%s]", got, tt.requestID)
+			}
+		})
+	}
+}
+
+// withDetails 构造附带 details 的 gRPC 错误
+func withDetails(t *testing.T, code codes.Code, msg string, details ...protoadapt.MessageV1) error {
+	t.Helper()
+	st, err := status.New(code, msg).WithDetails(details...)
+	if err != nil {
+		t.Fatalf("附加 details 失败: %v", err)
+	}
+	return st.Err()
+}
+
+// errorInfo 构造 ErrorInfo
+func errorInfo(reason pb.ErrorReason) *errdetails.ErrorInfo {
+	return &errdetails.ErrorInfo{Reason: reason.String(), Domain: "user.entry-task"}
+}
+
+// TestRPCError 测试 gRPC 错误转换为 HTTP 响应
+func TestRPCError(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		wantStatus     int
+		wantCode       int
+		wantMessage    string
+		wantRetryAfter string
+		wantViolations []map[string]string
+	}{
+		{
+			name:        "ErrorInfo 映射为业务错误码",
+			err:         withDetails(t, codes.Unauthenticated, "用户名或密码错误", errorInfo(pb.ErrorReason_INVALID_CREDENTIALS)),
+			wantStatus:  http.StatusUnauthorized,
+			wantCode:    response.CodeInvalidAccountOrPassword,
+			wantMessage: "用户名或密码错误",
+		},
+		{
+			name:        "ErrorInfo 映射为内部错误",
+			err:         withDetails(t, codes.Internal, "服务器内部错误", errorInfo(pb.ErrorReason_INTERNAL)),
+			wantStatus:  http.StatusInternalServerError,
+			wantCode:    response.CodeInternalServerError,
+			wantMessage: "服务器内部错误",
+		},
+		{
+			name: "RetryInfo 转换为 Retry-After（向上取整）",
+			err: withDetails(t, codes.ResourceExhausted, "登录失败次数过多",
+				errorInfo(pb.ErrorReason_LOGIN_LOCKED),
+				&errdetails.RetryInfo{RetryDelay: durationpb.New(90500 * time.Millisecond)}),
+			wantStatus:     http.StatusTooManyRequests,
+			wantCode:       response.CodeTooManyRequests,
+			wantMessage:    "登录失败次数过多",
+			wantRetryAfter: "91",
+		},
+		{
+			name: "BadRequest 字段错误放入响应体",
+			err: withDetails(t, codes.InvalidArgument, "参数错误",
+				errorInfo(pb.ErrorReason_INVALID_ARGUMENT),
+				&errdetails.BadRequest{FieldViolations: []*errdetails.BadRequest_FieldViolation{
+					{Field: "username", Description: "长度必须在3到32之间"},
+					{Field: "password", Description: "不能为空"},
+				}}),
+			wantStatus:  http.StatusBadRequest,
+			wantCode:    response.CodeInvalidParams,
+			wantMessage: "参数错误",
+			wantViolations: []map[string]string{
+				{"field": "username", "description": "长度必须在3到32之间"},
+				{"field": "password", "description": "不能为空"},
+			},
+		},
+		{
+			name:        "未知的 reason 按状态码处理",
+			err:         withDetails(t, codes.Internal, "boom", &errdetails.ErrorInfo{Reason: "UNKNOWN"}),
+			wantStatus:  http.StatusInternalServerError,
+			wantCode:    response.CodeRPCError,
+			wantMessage: "获取失败",
+		},
+		{
+			name:        "不可用",
+			err:         status.Error(codes.Unavailable, "connection refused"),
+			wantStatus:  http.StatusServiceUnavailable,
+			wantCode:    response.CodeServiceUnavailable,
+			wantMessage: response.GetMessage(response.CodeServiceUnavailable),
+		},
+		{
+			name:        "超时",
+			err:         status.Error(codes.DeadlineExceeded, "deadline exceeded"),
+			wantStatus:  http.StatusGatewayTimeout,
+			wantCode:    response.CodeRequestTimeout,
+			wantMessage: response.GetMessage(response.CodeRequestTimeout),
+		},
+		{
+			name:        "未认证",
+			err:         status.Error(codes.Unauthenticated, "missing token"),
+			wantStatus:  http.StatusUnauthorized,
+			wantCode:    response.CodeUnauthorized,
+			wantMessage: response.GetMessage(response.CodeUnauthorized),
+		},
+		{
+			name:        "其余状态码",
+			err:         status.Error(codes.Internal, "boom"),
+			wantStatus:  http.StatusInternalServerError,
+			wantCode:    response.CodeRPCError,
+			wantMessage: "获取失败",
+		},
+		{
+			name:        "非 gRPC 错误",
+			err:         errors.New("boom"),
+			wantStatus:  http.StatusInternalServerError,
+			wantCode:    response.CodeRPCError,
+			wantMessage: "获取失败",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+
+			rpcError(c, tt.err, "获取失败")
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("HTTP 状态码 = %d, 期望 %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get("Retry-After"); got != tt.wantRetryAfter {
+				t.Errorf("Retry-After = %q, 期望 %q", got, tt.wantRetryAfter)
+			}
+
+			var body struct {
+				Code    int    `json:"code"`
+				Message string `json:"message"`
+				Data    struct {
+					FieldViolations []map[string]string `json:"field_violations"`
+				} `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("解析响应失败: %v", err)
+			}
+			if body.Code != tt.wantCode || body.Message != tt.wantMessage {
+				t.Errorf("响应 = (%d, %q), 期望 (%d, %q)", body.Code, body.Message, tt.wantCode, tt.wantMessage)
+			}
+			if !reflect.DeepEqual(body.Data.FieldViolations, tt.wantViolations) {
+				t.Errorf("field_violations = %v, 期望 %v", body.Data.FieldViolations, tt.wantViolations)
+			}
+		})
+	}
+}
@@ -1,12 +1,16 @@
 package middleware
 
 import (
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	"go.uber.org/zap"
 
-	log "entry-task/httpserver/pkg/logger"
+	log "entry-task/pkg/logger"
+	"entry-task/pkg/requestid"
 )
 
 // CORSMiddleware CORS 中间件
@@ -29,7 +33,8 @@ func CORSMiddleware() gin.HandlerFunc {
 
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, "+HeaderRequestID)
+		c.Writer.Header().Set("Access-Control-Expose-Headers", HeaderRequestID)
 		c.Writer.Header().Set("Access-Control-Max-Age", "86400")
 
 		if c.Request.Method == "OPTIONS" {
@@ -41,7 +46,22 @@ func CORSMiddleware() gin.HandlerFunc {
 	}
 }
 
-// LoggerMiddleware 日志中间件
+var (
+	// httpRequests HTTP 请求数（route 为路由模板，未匹配路由时为 unmatched）
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "httpserver_http_requests_total",
+		Help: "HTTP 请求数",
+	}, []string{"method", "route", "status"})
+
+	// httpDuration HTTP 请求处理耗时
+	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "httpserver_http_request_duration_seconds",
+		Help:    "HTTP 请求处理耗时（秒）",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route"})
+)
+
+// LoggerMiddleware 日志中间件（同时记录 Prometheus 指标）
 func LoggerMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
@@ -53,7 +73,15 @@ func LoggerMiddleware() gin.HandlerFunc {
 		duration := time.Since(start)
 		statusCode := c.Writer.Status()
 
-		log.Info("HTTP 请求",
+		// 使用路由模板而不是实际路径，避免标签基数过高
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		httpRequests.WithLabelValues(method, route, strconv.Itoa(statusCode)).Inc()
+		httpDuration.WithLabelValues(method, route).Observe(duration.Seconds())
+
+		log.InfoCtx(c.Request.Context(), "HTTP 请求",
 			zap.String("method", method),
 			zap.String("path", path),
 			zap.Int("status", statusCode),
@@ -62,3 +90,20 @@ func LoggerMiddleware() gin.HandlerFunc {
 		)
 	}
 }
+
+// HeaderRequestID 请求ID header（客户端可传入，未传入或不合法时生成）
+const HeaderRequestID = requestid.Header
+
+// RequestIDMiddleware 请求ID中间件
+// 接受客户端传入的 X-Request-ID（不合法时重新生成），绑定到请求 context 并在响应 header 中返回，
+// 之后网关与 TCP Server 中该请求的日志都携带同一个 request_id
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := requestid.OrNew(c.GetHeader(HeaderRequestID))
+
+		c.Header(HeaderRequestID, requestID)
+		c.Request = c.Request.WithContext(log.WithRequestID(c.Request.Context(), requestID))
+
+		c.Next()
+	}
+}
@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"go.uber.org/zap"
+
+	log "entry-task/pkg/logger"
+	"entry-task/pkg/requestid"
+)
+
+// serveRequestID 经过 RequestIDMiddleware 处理请求，返回响应和 handler 从 context 中取到的请求ID
+func serveRequestID(t *testing.T, header string) (*httptest.ResponseRecorder, string) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	var got string
+	r := gin.New()
+	r.Use(RequestIDMiddleware())
+	r.GET("/", func(c *gin.Context) {
+		got = log.RequestIDFromContext(c.Request.Context())
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if header != "" {
+		req.Header.Set(HeaderRequestID, header)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w, got
+}
+
+// TestRequestIDMiddleware 测试传入的请求ID被沿用，缺失或不合法时重新生成
+func TestRequestIDMiddleware(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		keep   bool
+	}{
+		{"合法的请求ID", "req-123", true},
+		{"缺失", "", false},
+		{"超长", strings.Repeat("a", requestid.MaxLen+1), false},
+		{"含换行", "req-1\nlevel=error", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, got := serveRequestID(t, tt.header)
+			if echoed := w.Header().Get(HeaderRequestID); echoed != got {
+				t.Errorf("响应 header = %q, context 中的请求ID = %q, 应一致", echoed, got)
+			}
+			if tt.keep {
+				if got != tt.header {
+					t.Errorf("请求ID = %q, 期望沿用 %q", got, tt.header)
+				}
+				return
+			}
+			if got == tt.header || !requestid.Valid(got) {
+				t.Errorf("请求ID = %q, 期望重新生成合法的请求ID", got)
+			}
+		})
+	}
+}
+
+// histogramCount 返回直方图的样本数
+func histogramCount(t *testing.T, o prometheus.Observer) uint64 {
+	t.Helper()
+	var m dto.Metric
+	if err := o.(prometheus.Metric).Write(&m); err != nil {
+		t.Fatalf("读取直方图失败: %v", err)
+	}
+	return m.GetHistogram().GetSampleCount()
+}
+
+// TestLoggerMiddleware_Metrics 测试请求数按路由模板和状态码计数，未匹配的路由归为 unmatched，并记录耗时
+func TestLoggerMiddleware_Metrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	log.Logger = zap.NewNop()
+
+	r := gin.New()
+	r.Use(LoggerMiddleware())
+	r.GET("/metrics-test/:id", func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+	})
+
+	requests := httpRequests.WithLabelValues(http.MethodGet, "/metrics-test/:id", "201")
+	unmatched := httpRequests.WithLabelValues(http.MethodPost, "unmatched", "404")
+	before, beforeUnmatched := testutil.ToFloat64(requests), testutil.ToFloat64(unmatched)
+	beforeDuration := histogramCount(t, httpDuration.WithLabelValues(http.MethodGet, "/metrics-test/:id"))
+
+	for _, path := range []string{"/metrics-test/1", "/metrics-test/2"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/metrics-test-missing", nil))
+
+	if got := testutil.ToFloat64(requests) - before; got != 2 {
+		t.Errorf("路由模板的请求数增加 %v, 期望 2", got)
+	}
+	if got := testutil.ToFloat64(unmatched) - beforeUnmatched; got != 1 {
+		t.Errorf("未匹配路由的请求数增加 %v, 期望 1", got)
+	}
+	if got := histogramCount(t, httpDuration.WithLabelValues(http.MethodGet, "/metrics-test/:id")) - beforeDuration; got != 2 {
+		t.Errorf("耗时样本数增加 %d, 期望 2", got)
+	}
+}
@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/time/rate"
+
+	"entry-task/httpserver/pkg/response"
+)
+
+const (
+	// ipLimiterIdleTTL 客户端空闲超过该时间后删除其令牌桶（令牌早已补满，重新创建等价）
+	ipLimiterIdleTTL = 10 * time.Minute
+	// maxIPLimiters 最多保存的令牌桶数量，超过后新的客户端共用一个令牌桶，内存不随客户端数量无限增长
+	maxIPLimiters = 100000
+)
+
+// IPRateLimiter 按客户端 IP 限流的令牌桶
+// 只在单个实例内生效，多实例部署时每个客户端的总速率上限为 rate × 实例数
+type IPRateLimiter struct {
+	rate  rate.Limit
+	burst int
+
+	mu         sync.Mutex
+	limiters   map[string]*ipLimiter
+	overflow   *rate.Limiter // 令牌桶数量达到上限后新客户端共用
+	maxEntries int
+	lastSweep  time.Time
+
+	now func() time.Time
+}
+
+// ipLimiter 单个客户端的令牌桶
+type ipLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// NewIPRateLimiter 创建按 IP 限流的令牌桶，每秒补充 r 个令牌，容量为 burst
+func NewIPRateLimiter(r float64, burst int) *IPRateLimiter {
+	return &IPRateLimiter{
+		rate:       rate.Limit(r),
+		burst:      burst,
+		limiters:   make(map[string]*ipLimiter),
+		overflow:   rate.NewLimiter(rate.Limit(r), burst),
+		maxEntries: maxIPLimiters,
+		now:        time.Now,
+	}
+}
+
+// Allow 消耗一个令牌，令牌不足时返回 false 和需要等待的时间
+func (l *IPRateLimiter) Allow(ip string) (bool, time.Duration) {
+	now := l.now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	limiter := l.overflow
+	if entry, ok := l.limiters[ip]; ok {
+		entry.lastSeen = now
+		limiter = entry.limiter
+	} else if len(l.limiters) < l.maxEntries {
+		limiter = rate.NewLimiter(l.rate, l.burst)
+		l.limiters[ip] = &ipLimiter{limiter: limiter, lastSeen: now}
+	}
+
+	// 令牌不足时取消预留，被拒绝的请求不占用之后的令牌
+	reservation := limiter.ReserveN(now, 1)
+	if delay := reservation.DelayFrom(now); delay > 0 {
+		reservation.CancelAt(now)
+		return false, delay
+	}
+	return true, 0
+}
+
+// sweep 每隔 ipLimiterIdleTTL 删除一次空闲的令牌桶，避免 map 随客户端数量无限增长
+func (l *IPRateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < ipLimiterIdleTTL {
+		return
+	}
+	l.lastSweep = now
+	for ip, entry := range l.limiters {
+		if now.Sub(entry.lastSeen) >= ipLimiterIdleTTL {
+			delete(l.limiters, ip)
+		}
+	}
+}
+
+// RateLimitMiddleware 限流中间件，超过限制时返回 429 和 Retry-After
+// 客户端 IP 取自 c.ClientIP()，经过反向代理时需要配置 server.trusted_proxies，否则 X-Forwarded-For 可被伪造
+func RateLimitMiddleware(limiter *IPRateLimiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if ok, retryAfter := limiter.Allow(c.ClientIP()); !ok {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			response.Error(c, response.CodeTooManyRequests, response.GetMessage(response.CodeTooManyRequests))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
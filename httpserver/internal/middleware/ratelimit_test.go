@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestIPRateLimiter 测试按 IP 独立计数、令牌随时间补充
+func TestIPRateLimiter(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	l := NewIPRateLimiter(1, 2)
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := l.Allow("1.1.1.1"); !ok {
+			t.Fatalf("第 %d 个请求应在突发范围内", i+1)
+		}
+	}
+	ok, retryAfter := l.Allow("1.1.1.1")
+	if ok || retryAfter <= 0 || retryAfter > time.Second {
+		t.Fatalf("超过突发后应被拒绝, 实际 ok=%v retryAfter=%s", ok, retryAfter)
+	}
+	if ok, _ := l.Allow("2.2.2.2"); !ok {
+		t.Error("其他 IP 不应受影响")
+	}
+
+	// 被拒绝的请求不消耗令牌：1 秒后补充 1 个
+	now = now.Add(time.Second)
+	if ok, _ := l.Allow("1.1.1.1"); !ok {
+		t.Error("令牌补充后应放行")
+	}
+	if ok, _ := l.Allow("1.1.1.1"); ok {
+		t.Error("补充的令牌已用完，应被拒绝")
+	}
+
+	// 空闲的令牌桶被清理
+	now = now.Add(ipLimiterIdleTTL)
+	l.Allow("3.3.3.3")
+	if _, exists := l.limiters["1.1.1.1"]; exists || len(l.limiters) != 1 {
+		t.Errorf("空闲的令牌桶应被删除, 剩余 %d 个", len(l.limiters))
+	}
+}
+
+// TestIPRateLimiter_MaxEntries 测试令牌桶数量达到上限后新客户端共用一个令牌桶
+func TestIPRateLimiter_MaxEntries(t *testing.T) {
+	l := NewIPRateLimiter(1, 1)
+	l.maxEntries = 2
+
+	for _, ip := range []string{"1.1.1.1", "2.2.2.2", "3.3.3.3"} {
+		if ok, _ := l.Allow(ip); !ok {
+			t.Fatalf("%s 的第一个请求应放行", ip)
+		}
+	}
+	if len(l.limiters) != 2 {
+		t.Errorf("令牌桶数量 = %d, 期望不超过 2", len(l.limiters))
+	}
+	// 3.3.3.3 已用完共用的令牌桶，其他新客户端也被限流
+	if ok, _ := l.Allow("4.4.4.4"); ok {
+		t.Error("超过上限的新客户端应共用令牌桶")
+	}
+	// 已有令牌桶的客户端不受影响
+	if _, exists := l.limiters["1.1.1.1"]; !exists {
+		t.Error("已有的令牌桶不应被替换")
+	}
+}
+
+// TestRateLimitMiddleware 测试超过限制时返回 429 和 Retry-After
+func TestRateLimitMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/", RateLimitMiddleware(NewIPRateLimiter(0.5, 1)), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	do := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = "1.1.1.1:1234"
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := do(); w.Code != http.StatusOK {
+		t.Fatalf("第一个请求状态码 = %d, 期望 200", w.Code)
+	}
+	w := do()
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("超过限制时状态码 = %d, 期望 429", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "2" {
+		t.Errorf("Retry-After = %q, 期望 \"2\"", got)
+	}
+}
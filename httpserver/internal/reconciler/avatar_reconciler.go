@@ -0,0 +1,222 @@
+package reconciler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/metadata"
+
+	"entry-task/httpserver/config"
+	"entry-task/httpserver/pkg/avatar"
+	"entry-task/httpserver/pkg/storage"
+	pb "entry-task/proto/user/v2"
+
+	log "entry-task/pkg/logger"
+)
+
+// ============================================================================
+// 头像对账
+// ============================================================================
+//
+// 上传头像时先写入文件、再更新数据库、最后删除旧文件，任一步失败（或进程退出）都可能留下：
+// - 孤儿文件：数据库中没有用户引用的头像文件（如更新数据库超时、删除旧文件失败）
+// - 悬空引用：数据库中的头像指向不存在的文件（如文件被误删）
+// 对账任务定期分页读取所有用户的头像构建引用集合，清空悬空引用，再遍历存储删除孤儿文件。
+// 读取引用失败时本轮不删除任何文件；修改时间在宽限期内的文件不删除（可能是上传中、
+// 尚未写入数据库的头像，或从库复制延迟导致引用集合中缺少的新头像）。
+// 清空头像是比较后清空，不会覆盖对账期间用户新上传的头像；删除文件和清空头像都是幂等操作。
+
+const (
+	// metadataInternalToken 内部服务的鉴权凭证
+	metadataInternalToken = "x-internal-token"
+	// defaultRPCTimeout 未配置 RPC 默认超时时每次调用的超时
+	defaultRPCTimeout = 5 * time.Second
+)
+
+// Result 一轮对账的结果
+type Result struct {
+	Referenced int // 数据库中引用的头像数（不含已清空的悬空引用）
+	Cleared    int // 清空的悬空引用数
+	Deleted    int // 删除的孤儿文件数
+}
+
+// AvatarReconciler 头像对账后台任务
+type AvatarReconciler struct {
+	client        pb.UserAdminServiceClient
+	storage       storage.Storage
+	internalToken string
+	interval      time.Duration
+	gracePeriod   time.Duration
+	pageSize      int
+	rpcTimeout    time.Duration
+
+	now func() time.Time
+}
+
+// NewAvatarReconciler 创建头像对账任务
+func NewAvatarReconciler(client pb.UserAdminServiceClient, avatarStorage storage.Storage, cfg *config.Config) *AvatarReconciler {
+	reconcile := cfg.Storage.Reconcile
+	r := &AvatarReconciler{
+		client:        client,
+		storage:       avatarStorage,
+		internalToken: cfg.GRPC.InternalToken,
+		interval:      reconcile.GetInterval(),
+		gracePeriod:   reconcile.GetGracePeriod(),
+		pageSize:      reconcile.GetPageSize(),
+		rpcTimeout:    cfg.GRPC.GetDefaultTimeout(),
+		now:           time.Now,
+	}
+	if r.rpcTimeout <= 0 {
+		r.rpcTimeout = defaultRPCTimeout
+	}
+	return r
+}
+
+// Run 启动后立即执行一次对账，之后每隔 interval 执行一次，直到 ctx 被取消
+func (r *AvatarReconciler) Run(ctx context.Context) {
+	log.Info("头像对账任务已启动",
+		zap.Duration("interval", r.interval),
+		zap.Duration("grace_period", r.gracePeriod),
+	)
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		start := time.Now()
+		result, err := r.RunOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Error("头像对账失败", zap.Error(err))
+		} else if err == nil {
+			log.Info("头像对账完成",
+				zap.Int("referenced", result.Referenced),
+				zap.Int("cleared", result.Cleared),
+				zap.Int("deleted", result.Deleted),
+				zap.Duration("duration", time.Since(start)),
+			)
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Info("头像对账任务已停止")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce 执行一轮对账
+func (r *AvatarReconciler) RunOnce(ctx context.Context) (Result, error) {
+	var result Result
+
+	// 1. 读取所有引用并清空悬空引用
+	referenced, cleared, err := r.collectReferences(ctx)
+	result.Referenced, result.Cleared = len(referenced), cleared
+	if err != nil {
+		return result, fmt.Errorf("读取头像引用失败: %w", err)
+	}
+
+	// 2. 删除未被引用且超过宽限期的文件（缩略图随原图判断）
+	deadline := r.now().Add(-r.gracePeriod)
+	err = r.storage.List(ctx, avatar.KeyPrefix, func(info storage.ObjectInfo) error {
+		if referenced[avatar.OriginalKey(info.Key)] || info.ModTime.After(deadline) {
+			return nil
+		}
+		if err := r.storage.Delete(ctx, info.Key); err != nil {
+			log.Warn("删除孤儿头像文件失败", zap.String("key", info.Key), zap.Error(err))
+			return nil
+		}
+		log.Info("删除孤儿头像文件", zap.String("key", info.Key), zap.Time("mod_time", info.ModTime))
+		result.Deleted++
+		return nil
+	})
+	if err != nil {
+		return result, fmt.Errorf("遍历头像文件失败: %w", err)
+	}
+
+	return result, nil
+}
+
+// collectReferences 分页读取所有用户的头像，返回引用的原图 key 集合和清空的悬空引用数
+func (r *AvatarReconciler) collectReferences(ctx context.Context) (map[string]bool, int, error) {
+	ctx = metadata.AppendToOutgoingContext(ctx, metadataInternalToken, r.internalToken)
+
+	referenced := make(map[string]bool)
+	cleared := 0
+	var afterID uint64
+	for {
+		entries, err := r.listPage(ctx, afterID)
+		if err != nil {
+			return referenced, cleared, err
+		}
+		// 读到空页才结束：TCP Server 会限制每页数量，不能以返回数量小于 pageSize 判断
+		if len(entries) == 0 {
+			return referenced, cleared, nil
+		}
+
+		for _, entry := range entries {
+			afterID = entry.UserId
+			key, ok := avatar.FromProfilePicture(entry.ProfilePicture)
+			if !ok {
+				continue // 不是存储中的头像，不处理
+			}
+
+			dangling, err := r.clearIfDangling(ctx, entry, key)
+			if err != nil {
+				log.Warn("检查头像文件失败",
+					zap.Uint64("user_id", entry.UserId),
+					zap.String("key", key),
+					zap.Error(err))
+			}
+			if dangling {
+				cleared++
+				continue // 已清空，原图不存在时遗留的缩略图随之删除
+			}
+			referenced[key] = true
+		}
+	}
+}
+
+// listPage 读取 afterID 之后的一页用户头像
+func (r *AvatarReconciler) listPage(ctx context.Context, afterID uint64) ([]*pb.ProfilePictureEntry, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.rpcTimeout)
+	defer cancel()
+
+	resp, err := r.client.ListProfilePictures(ctx, &pb.ListProfilePicturesRequest{
+		AfterUserId: afterID,
+		Limit:       int32(r.pageSize),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Entries, nil
+}
+
+// clearIfDangling 头像文件不存在时清空用户的头像字段，返回是否清空
+func (r *AvatarReconciler) clearIfDangling(ctx context.Context, entry *pb.ProfilePictureEntry, key string) (bool, error) {
+	_, err := r.storage.Stat(ctx, key)
+	if !errors.Is(err, storage.ErrNotFound) {
+		return false, err
+	}
+
+	rpcCtx, cancel := context.WithTimeout(ctx, r.rpcTimeout)
+	defer cancel()
+
+	// 比较后清空：读取引用之后用户上传了新头像时不修改
+	resp, err := r.client.ClearProfilePicture(rpcCtx, &pb.ClearProfilePictureRequest{
+		UserId:         entry.UserId,
+		ProfilePicture: entry.ProfilePicture,
+	})
+	if err != nil {
+		return false, fmt.Errorf("清空头像失败: %w", err)
+	}
+	if resp.Cleared {
+		log.Info("头像文件不存在，已清空头像",
+			zap.Uint64("user_id", entry.UserId),
+			zap.String("profile_picture", entry.ProfilePicture))
+	}
+	return resp.Cleared, nil
+}
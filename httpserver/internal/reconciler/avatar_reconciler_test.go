@@ -0,0 +1,171 @@
+package reconciler
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"entry-task/httpserver/pkg/avatar"
+	"entry-task/httpserver/pkg/storage"
+	"entry-task/pkg/logger"
+	pb "entry-task/proto/user/v2"
+)
+
+func TestMain(m *testing.M) {
+	if err := logger.Init(&logger.Config{Level: "error", Output: "stdout"}); err != nil {
+		panic("初始化日志失败: " + err.Error())
+	}
+	os.Exit(m.Run())
+}
+
+// fakeAdminClient 内存中的 UserAdminService，每页最多返回 maxPage 条（模拟 TCP Server 限制每页数量）
+type fakeAdminClient struct {
+	pictures map[uint64]string
+	maxPage  int
+	listErr  error
+	tokens   []string
+}
+
+func (f *fakeAdminClient) ListProfilePictures(ctx context.Context, in *pb.ListProfilePicturesRequest, _ ...grpc.CallOption) (*pb.ListProfilePicturesResponse, error) {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	f.tokens = append(f.tokens, md.Get(metadataInternalToken)...)
+	if f.listErr != nil {
+		return nil, f.listErr
+	}
+
+	var ids []uint64
+	for id, p := range f.pictures {
+		if id > in.AfterUserId && p != "" {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	ids = ids[:min(len(ids), int(in.Limit), f.maxPage)]
+
+	resp := &pb.ListProfilePicturesResponse{}
+	for _, id := range ids {
+		resp.Entries = append(resp.Entries, &pb.ProfilePictureEntry{UserId: id, ProfilePicture: f.pictures[id]})
+	}
+	return resp, nil
+}
+
+func (f *fakeAdminClient) ClearProfilePicture(_ context.Context, in *pb.ClearProfilePictureRequest, _ ...grpc.CallOption) (*pb.ClearProfilePictureResponse, error) {
+	if f.pictures[in.UserId] != in.ProfilePicture {
+		return &pb.ClearProfilePictureResponse{}, nil
+	}
+	f.pictures[in.UserId] = ""
+	return &pb.ClearProfilePictureResponse{Cleared: true}, nil
+}
+
+// putAt 写入文件并设置修改时间
+func putAt(t *testing.T, root string, s storage.Storage, key string, modTime time.Time) {
+	t.Helper()
+	if err := s.Put(context.Background(), key, []byte("x"), avatar.ContentType); err != nil {
+		t.Fatalf("Put(%q) 失败: %v", key, err)
+	}
+	if err := os.Chtimes(filepath.Join(root, filepath.FromSlash(key)), modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newTestReconciler(client pb.UserAdminServiceClient, s storage.Storage, now time.Time) *AvatarReconciler {
+	return &AvatarReconciler{
+		client:        client,
+		storage:       s,
+		internalToken: "secret",
+		gracePeriod:   time.Hour,
+		pageSize:      10,
+		rpcTimeout:    time.Second,
+		now:           func() time.Time { return now },
+	}
+}
+
+// TestAvatarReconciler 测试删除孤儿文件、清空悬空引用
+func TestAvatarReconciler(t *testing.T) {
+	root := t.TempDir()
+	s := storage.NewLocalStorage(root)
+	now := time.Now()
+	old := now.Add(-2 * time.Hour)
+
+	for _, key := range avatar.Keys("avatars/1/aaaa.jpg") {
+		putAt(t, root, s, key, old) // 用户1的当前头像
+	}
+	for _, key := range avatar.Keys("avatars/1/bbbb.jpg") {
+		putAt(t, root, s, key, old) // 用户1的旧头像（删除失败留下的孤儿）
+	}
+	putAt(t, root, s, "avatars/2.png", old)          // 旧版本留下的孤儿
+	putAt(t, root, s, "avatars/3.jpg", old)          // 旧版本路径，仍被用户3引用
+	putAt(t, root, s, "avatars/4/cccc.jpg", now)     // 上传中，尚未写入数据库
+	putAt(t, root, s, "other/keep.jpg", old)         // 不在头像前缀下
+	putAt(t, root, s, "avatars/7/dddd_128.jpg", old) // 原图不存在的缩略图
+
+	client := &fakeAdminClient{
+		maxPage: 2,
+		pictures: map[uint64]string{
+			1: "avatars/1/aaaa.jpg",
+			3: "/uploads/avatars/3.jpg",
+			5: "avatars/5/missing.jpg", // 文件被误删
+			6: "https://example.com/a.jpg",
+			7: "avatars/7/dddd.jpg",
+		},
+	}
+	r := newTestReconciler(client, s, now)
+
+	result, err := r.RunOnce(context.Background())
+	if err != nil {
+		t.Fatalf("RunOnce() 失败: %v", err)
+	}
+	if result.Referenced != 2 || result.Cleared != 2 || result.Deleted != 6 {
+		t.Errorf("RunOnce() = %+v, 期望 Referenced=2 Cleared=2 Deleted=6", result)
+	}
+
+	var keys []string
+	s.List(context.Background(), "", func(info storage.ObjectInfo) error {
+		keys = append(keys, info.Key)
+		return nil
+	})
+	want := append(avatar.Keys("avatars/1/aaaa.jpg"), "avatars/3.jpg", "avatars/4/cccc.jpg", "other/keep.jpg")
+	slices.Sort(want)
+	if !slices.Equal(keys, want) {
+		t.Errorf("对账后的文件 = %v, 期望 %v", keys, want)
+	}
+
+	if client.pictures[5] != "" || client.pictures[7] != "" {
+		t.Errorf("悬空引用应被清空: %v", client.pictures)
+	}
+	if client.pictures[1] == "" || client.pictures[6] == "" {
+		t.Errorf("有效引用和非存储中的头像不应被清空: %v", client.pictures)
+	}
+	for _, token := range client.tokens {
+		if token != "secret" {
+			t.Errorf("请求应携带内部服务凭证, 实际 %q", token)
+		}
+	}
+}
+
+// TestAvatarReconciler_ListFailed 测试读取引用失败时不删除文件
+func TestAvatarReconciler_ListFailed(t *testing.T) {
+	root := t.TempDir()
+	s := storage.NewLocalStorage(root)
+	now := time.Now()
+	putAt(t, root, s, "avatars/1/aaaa.jpg", now.Add(-2*time.Hour))
+
+	client := &fakeAdminClient{maxPage: 10, listErr: status.Error(codes.Unavailable, "unavailable")}
+	r := newTestReconciler(client, s, now)
+
+	if _, err := r.RunOnce(context.Background()); status.Code(errors.Unwrap(err)) != codes.Unavailable {
+		t.Fatalf("RunOnce() 错误 = %v, 期望 Unavailable", err)
+	}
+	if _, err := s.Stat(context.Background(), "avatars/1/aaaa.jpg"); err != nil {
+		t.Errorf("读取引用失败时不应删除文件: %v", err)
+	}
+}
@@ -1,21 +1,40 @@
 package router
 
 import (
+	"fmt"
+
 	"entry-task/httpserver/internal/handler"
 	"entry-task/httpserver/internal/middleware"
 
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
-// SetupRouter 设置路由
-func SetupRouter(userHandler *handler.UserHandler) *gin.Engine {
+// SetupRouter 设置路由，publicLimiter 用于无需登录的公开接口
+// trustedProxies 为信任的反向代理（IP 或 CIDR），只采信这些地址转发的 X-Forwarded-For；
+// 为空时不信任任何代理，客户端 IP 取连接的对端地址（gin 默认信任所有代理，客户端可伪造 IP 绕过限流）
+// serviceName 为链路追踪中的服务名
+func SetupRouter(userHandler *handler.UserHandler, healthHandler *handler.HealthHandler, publicLimiter *middleware.IPRateLimiter, trustedProxies []string, serviceName string) (*gin.Engine, error) {
 	// 创建 Gin Engine（不使用默认中间件）
 	r := gin.New()
+	if len(trustedProxies) == 0 {
+		trustedProxies = nil
+	}
+	if err := r.SetTrustedProxies(trustedProxies); err != nil {
+		return nil, fmt.Errorf("信任代理配置无效: %w", err)
+	}
 
 	// 全局中间件
-	r.Use(gin.Recovery())                // Panic 恢复
-	r.Use(middleware.CORSMiddleware())   // CORS
-	r.Use(middleware.LoggerMiddleware()) // 日志
+	r.Use(gin.Recovery()) // Panic 恢复
+
+	// 健康检查（在日志、链路追踪中间件之前注册，探针请求不产生访问日志和 Span）
+	r.GET("/healthz", healthHandler.Liveness)
+	r.GET("/readyz", healthHandler.Readiness)
+
+	r.Use(middleware.RequestIDMiddleware()) // 请求ID（之后的日志都携带 request_id）
+	r.Use(otelgin.Middleware(serviceName))  // 链路追踪（提取/创建 Trace Context）
+	r.Use(middleware.CORSMiddleware())      // CORS
+	r.Use(middleware.LoggerMiddleware())    // 日志
 
 	// API 路由组
 	api := r.Group("/api/v1")
@@ -23,7 +42,9 @@ func SetupRouter(userHandler *handler.UserHandler) *gin.Engine {
 		// 认证相关
 		auth := api.Group("/auth")
 		{
+			auth.POST("/register", userHandler.Register)
 			auth.POST("/login", userHandler.Login)
+			auth.POST("/refresh", userHandler.RefreshToken)
 			auth.POST("/logout", userHandler.Logout)
 		}
 
@@ -32,10 +53,25 @@ func SetupRouter(userHandler *handler.UserHandler) *gin.Engine {
 		{
 			profile.GET("", userHandler.GetProfile)
 			profile.PATCH("/nickname", userHandler.UpdateNickname)
+			profile.PATCH("/password", userHandler.ChangePassword)
 			profile.POST("/picture", userHandler.UploadProfilePicture)
 			profile.GET("/picture", userHandler.GetProfilePicture)
 		}
+
+		// 其他用户的公开信息（无需登录，按客户端 IP 限流）
+		users := api.Group("/users", middleware.RateLimitMiddleware(publicLimiter))
+		{
+			users.GET("/:id/picture", userHandler.GetUserPicture)
+		}
+
+		// 登录Session管理
+		sessions := api.Group("/sessions")
+		{
+			sessions.GET("", userHandler.ListSessions)
+			sessions.DELETE("", userHandler.RevokeAllSessions)
+			sessions.DELETE("/:id", userHandler.RevokeSession)
+		}
 	}
 
-	return r
+	return r, nil
 }
@@ -0,0 +1,82 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"entry-task/httpserver/internal/handler"
+	"entry-task/httpserver/internal/middleware"
+	"entry-task/httpserver/pkg/storage"
+	"entry-task/pkg/logger"
+	pb "entry-task/proto/user/v2"
+)
+
+func TestMain(m *testing.M) {
+	if err := logger.Init(&logger.Config{Level: "error", Output: "stdout"}); err != nil {
+		panic("初始化日志失败: " + err.Error())
+	}
+	os.Exit(m.Run())
+}
+
+// fakeUserClient 只实现 GetPublicProfile，用户都不存在
+type fakeUserClient struct {
+	pb.UserServiceClient
+}
+
+func (fakeUserClient) GetPublicProfile(context.Context, *pb.GetPublicProfileRequest, ...grpc.CallOption) (*pb.GetPublicProfileResponse, error) {
+	return nil, status.Error(codes.NotFound, "用户不存在")
+}
+
+func newTestRouter(t *testing.T, trustedProxies []string) http.Handler {
+	t.Helper()
+	userHandler := handler.NewUserHandler(fakeUserClient{}, storage.NewLocalStorage(t.TempDir()), false, time.Minute, 1)
+	r, err := SetupRouter(userHandler, handler.NewHealthHandler(nil), middleware.NewIPRateLimiter(0.001, 1), trustedProxies, "httpserver-test")
+	if err != nil {
+		t.Fatalf("SetupRouter() 失败: %v", err)
+	}
+	return r
+}
+
+// getPicture 从 remoteAddr 发起请求，X-Forwarded-For 为 forwardedFor
+func getPicture(r http.Handler, remoteAddr, forwardedFor string) int {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users/1/picture", nil)
+	req.RemoteAddr = remoteAddr
+	req.Header.Set("X-Forwarded-For", forwardedFor)
+	r.ServeHTTP(w, req)
+	return w.Code
+}
+
+// TestPublicRateLimit_SpoofedForwardedFor 测试未配置信任代理时伪造 X-Forwarded-For 不能绕过限流
+func TestPublicRateLimit_SpoofedForwardedFor(t *testing.T) {
+	r := newTestRouter(t, nil)
+
+	if code := getPicture(r, "203.0.113.7:1234", "1.1.1.1"); code == http.StatusTooManyRequests {
+		t.Fatalf("第一个请求不应被限流")
+	}
+	if code := getPicture(r, "203.0.113.7:1234", "2.2.2.2"); code != http.StatusTooManyRequests {
+		t.Errorf("更换 X-Forwarded-For 后状态码 = %d, 期望 429", code)
+	}
+}
+
+// TestPublicRateLimit_TrustedProxy 测试来自信任代理的请求按 X-Forwarded-For 中的客户端 IP 限流
+func TestPublicRateLimit_TrustedProxy(t *testing.T) {
+	r := newTestRouter(t, []string{"10.0.0.0/8"})
+
+	for _, client := range []string{"1.1.1.1", "2.2.2.2"} {
+		if code := getPicture(r, "10.0.0.1:1234", client); code == http.StatusTooManyRequests {
+			t.Errorf("客户端 %s 的第一个请求不应被限流", client)
+		}
+	}
+	if code := getPicture(r, "10.0.0.1:1234", "1.1.1.1"); code != http.StatusTooManyRequests {
+		t.Errorf("同一客户端超过限制时状态码 = %d, 期望 429", code)
+	}
+}
@@ -0,0 +1,164 @@
+package avatar
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+
+	"golang.org/x/image/webp"
+)
+
+// ============================================================================
+// 头像图片处理
+// ============================================================================
+//
+// 上传的文件不信任扩展名和 Content-Type：
+// 1. 根据文件头魔数识别格式，只接受 JPEG、PNG、WebP
+// 2. 先只解析图片头检查宽高和像素数，避免解码超大图片（解压炸弹）
+// 3. 完整解码，解码失败的文件（伪装成图片的 HTML、截断文件等）直接拒绝
+// 4. 按 EXIF 方向摆正后重新编码为 JPEG，原文件中的 EXIF/GPS 等元数据和图片数据之后附带的内容全部丢弃
+
+const (
+	// Ext 重新编码后的文件扩展名
+	Ext = ".jpg"
+	// ContentType 重新编码后的 MIME 类型
+	ContentType = "image/jpeg"
+	// Quality 重新编码的 JPEG 质量
+	Quality = 85
+)
+
+// Format 图片格式
+type Format string
+
+const (
+	FormatJPEG Format = "jpeg"
+	FormatPNG  Format = "png"
+	FormatWebP Format = "webp"
+)
+
+var (
+	// ErrUnsupportedFormat 文件头不是支持的图片格式
+	ErrUnsupportedFormat = errors.New("不支持的图片格式")
+	// ErrInvalidImage 图片无法解码
+	ErrInvalidImage = errors.New("无效的图片")
+	// ErrImageTooLarge 图片宽高或像素数超过限制
+	ErrImageTooLarge = errors.New("图片尺寸过大")
+)
+
+// Limits 图片尺寸限制
+type Limits struct {
+	MaxWidth  int // 最大宽度（像素）
+	MaxHeight int // 最大高度（像素）
+	MaxPixels int // 最大像素数（宽 × 高）
+}
+
+// DefaultLimits 默认尺寸限制
+// 解码结果加上 RGBA 画布每像素最多约 8 字节，8MP 时单张图片峰值约 64MB
+var DefaultLimits = Limits{
+	MaxWidth:  4096,
+	MaxHeight: 4096,
+	MaxPixels: 8 * 1000 * 1000,
+}
+
+// check 检查宽高是否在限制内
+func (l Limits) check(width, height int) error {
+	if width <= 0 || height <= 0 {
+		return ErrInvalidImage
+	}
+	if width > l.MaxWidth || height > l.MaxHeight || width*height > l.MaxPixels {
+		return fmt.Errorf("%w: %dx%d", ErrImageTooLarge, width, height)
+	}
+	return nil
+}
+
+// Sniff 根据文件头魔数识别图片格式
+func Sniff(data []byte) (Format, error) {
+	switch {
+	case bytes.HasPrefix(data, []byte("\xff\xd8\xff")):
+		return FormatJPEG, nil
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		return FormatPNG, nil
+	case len(data) >= 12 && bytes.Equal(data[:4], []byte("RIFF")) && bytes.Equal(data[8:12], []byte("WEBP")):
+		return FormatWebP, nil
+	default:
+		return "", ErrUnsupportedFormat
+	}
+}
+
+// decoder 图片格式对应的解码函数（不使用 image.Decode，避免接受其他包注册的格式）
+type decoder struct {
+	decodeConfig func(io.Reader) (image.Config, error)
+	decode       func(io.Reader) (image.Image, error)
+}
+
+var decoders = map[Format]decoder{
+	FormatJPEG: {jpeg.DecodeConfig, jpeg.Decode},
+	FormatPNG:  {png.DecodeConfig, png.Decode},
+	FormatWebP: {webp.DecodeConfig, webp.Decode},
+}
+
+// Decode 校验并解码图片，返回按 EXIF 方向摆正、透明区域填充白色后的图片
+// 不透明且无需旋转的图片（大多数 JPEG）直接返回解码结果，不复制像素
+func Decode(data []byte, limits Limits) (image.Image, error) {
+	format, err := Sniff(data)
+	if err != nil {
+		return nil, err
+	}
+	dec := decoders[format]
+
+	cfg, err := dec.decodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	if err := limits.check(cfg.Width, cfg.Height); err != nil {
+		return nil, err
+	}
+
+	img, err := dec.decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	// 图片头与实际数据不一致时以实际解码结果为准再检查一次
+	b := img.Bounds()
+	if err := limits.check(b.Dx(), b.Dy()); err != nil {
+		return nil, err
+	}
+
+	orientation := 1
+	if format == FormatJPEG {
+		orientation = jpegOrientation(data)
+	}
+	if orientation == 1 && isOpaque(img) {
+		return img, nil
+	}
+	// JPEG 不支持透明通道，转换时铺白色背景
+	return orient(img, orientation), nil
+}
+
+// isOpaque 判断图片是否完全不透明
+func isOpaque(img image.Image) bool {
+	o, ok := img.(interface{ Opaque() bool })
+	return ok && o.Opaque()
+}
+
+// Encode 将图片编码为 JPEG
+func Encode(w io.Writer, img image.Image) error {
+	return jpeg.Encode(w, img, &jpeg.Options{Quality: Quality})
+}
+
+// Normalize 校验上传的图片并重新编码为 JPEG
+func Normalize(data []byte, limits Limits) ([]byte, error) {
+	img, err := Decode(data, limits)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("编码图片失败: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,215 @@
+package avatar
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// encodePNG 生成纯色 PNG
+func encodePNG(t *testing.T, w, h int, c color.Color) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("编码 PNG 失败: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// encodeJPEG 生成纯色 JPEG
+func encodeJPEG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatalf("编码 JPEG 失败: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// withExif 在 JPEG 的 SOI 之后插入只包含方向标签的 EXIF 段，payload 附加在 TIFF 数据之后
+func withExif(jpg []byte, orientation uint16, payload string) []byte {
+	var tiff bytes.Buffer
+	tiff.WriteString("MM\x00\x2a")
+	binary.Write(&tiff, binary.BigEndian, uint32(8)) // IFD0 偏移
+	binary.Write(&tiff, binary.BigEndian, uint16(1)) // 条目数
+	binary.Write(&tiff, binary.BigEndian, uint16(orientationTag))
+	binary.Write(&tiff, binary.BigEndian, uint16(3)) // SHORT
+	binary.Write(&tiff, binary.BigEndian, uint32(1)) // 个数
+	binary.Write(&tiff, binary.BigEndian, orientation)
+	tiff.Write([]byte{0, 0})
+	binary.Write(&tiff, binary.BigEndian, uint32(0)) // 没有下一个 IFD
+	tiff.WriteString(payload)
+
+	segment := append([]byte("Exif\x00\x00"), tiff.Bytes()...)
+	var out bytes.Buffer
+	out.Write(jpg[:2])
+	out.Write([]byte{0xff, 0xe1})
+	binary.Write(&out, binary.BigEndian, uint16(len(segment)+2))
+	out.Write(segment)
+	out.Write(jpg[2:])
+	return out.Bytes()
+}
+
+// TestSniff 测试按文件头识别格式
+func TestSniff(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want Format
+		err  error
+	}{
+		{"JPEG", []byte("\xff\xd8\xff\xe0"), FormatJPEG, nil},
+		{"PNG", []byte("\x89PNG\r\n\x1a\n...."), FormatPNG, nil},
+		{"WebP", []byte("RIFF\x00\x00\x00\x00WEBPVP8 "), FormatWebP, nil},
+		{"HTML", []byte("<html><script>alert(1)</script></html>"), "", ErrUnsupportedFormat},
+		{"GIF", []byte("GIF89a"), "", ErrUnsupportedFormat},
+		{"RIFF 但不是 WebP", []byte("RIFF\x00\x00\x00\x00WAVEfmt "), "", ErrUnsupportedFormat},
+		{"空文件", nil, "", ErrUnsupportedFormat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Sniff(tt.data)
+			if got != tt.want || !errors.Is(err, tt.err) {
+				t.Errorf("Sniff() = %q, %v, 期望 %q, %v", got, err, tt.want, tt.err)
+			}
+		})
+	}
+}
+
+// TestNormalize_Rejects 测试拒绝非图片、损坏图片和超限图片
+func TestNormalize_Rejects(t *testing.T) {
+	limits := Limits{MaxWidth: 100, MaxHeight: 100, MaxPixels: 50 * 50}
+	valid := encodePNG(t, 10, 10, color.Black)
+
+	tests := []struct {
+		name string
+		data []byte
+		err  error
+	}{
+		{"改扩展名的 HTML", []byte("<!DOCTYPE html><html></html>"), ErrUnsupportedFormat},
+		{"只有 PNG 文件头", append([]byte("\x89PNG\r\n\x1a\n"), "<script>alert(1)</script>"...), ErrInvalidImage},
+		{"截断的 PNG", valid[:len(valid)/2], ErrInvalidImage},
+		{"宽度超限", encodePNG(t, 101, 1, color.Black), ErrImageTooLarge},
+		{"像素数超限", encodePNG(t, 60, 60, color.Black), ErrImageTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Normalize(tt.data, limits); !errors.Is(err, tt.err) {
+				t.Errorf("Normalize() 错误 = %v, 期望 %v", err, tt.err)
+			}
+		})
+	}
+}
+
+// TestNormalize_PNG 测试 PNG 重新编码为 JPEG，透明区域填充白色
+func TestNormalize_PNG(t *testing.T) {
+	out, err := Normalize(encodePNG(t, 20, 10, color.Transparent), DefaultLimits)
+	if err != nil {
+		t.Fatalf("Normalize() 失败: %v", err)
+	}
+	if format, _ := Sniff(out); format != FormatJPEG {
+		t.Fatalf("输出格式 = %q, 期望 JPEG", format)
+	}
+
+	img, err := jpeg.Decode(bytes.NewReader(out))
+	if err != nil {
+		t.Fatalf("解码输出失败: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 20 || b.Dy() != 10 {
+		t.Errorf("输出尺寸 = %dx%d, 期望 20x10", b.Dx(), b.Dy())
+	}
+	if r, g, b, _ := img.At(5, 5).RGBA(); r>>8 < 250 || g>>8 < 250 || b>>8 < 250 {
+		t.Errorf("透明区域应填充白色, 实际 (%d, %d, %d)", r>>8, g>>8, b>>8)
+	}
+}
+
+// TestNormalize_StripsExif 测试去除 EXIF 并按方向摆正
+func TestNormalize_StripsExif(t *testing.T) {
+	const gps = "GPSLatitude=39.9042"
+	data := withExif(encodeJPEG(t, 40, 20), 6, gps)
+	if jpegOrientation(data) != 6 {
+		t.Fatalf("测试数据的 EXIF 方向应为 6")
+	}
+
+	out, err := Normalize(data, DefaultLimits)
+	if err != nil {
+		t.Fatalf("Normalize() 失败: %v", err)
+	}
+	if bytes.Contains(out, []byte("Exif")) || bytes.Contains(out, []byte(gps)) {
+		t.Error("输出中不应包含 EXIF 数据")
+	}
+
+	cfg, err := jpeg.DecodeConfig(bytes.NewReader(out))
+	if err != nil {
+		t.Fatalf("解码输出失败: %v", err)
+	}
+	if cfg.Width != 20 || cfg.Height != 40 {
+		t.Errorf("方向 6 应顺时针旋转 90°, 输出尺寸 = %dx%d, 期望 20x40", cfg.Width, cfg.Height)
+	}
+}
+
+// TestDecode_NoCopy 测试不透明且无需旋转的 JPEG 直接返回解码结果，需要旋转时只生成摆正后的画布
+func TestDecode_NoCopy(t *testing.T) {
+	img, err := Decode(encodeJPEG(t, 40, 20), DefaultLimits)
+	if err != nil {
+		t.Fatalf("Decode() 失败: %v", err)
+	}
+	if _, ok := img.(*image.YCbCr); !ok {
+		t.Errorf("无需旋转的 JPEG 应直接返回解码结果, 实际类型 %T", img)
+	}
+
+	img, err = Decode(withExif(encodeJPEG(t, 40, 20), 8, ""), DefaultLimits)
+	if err != nil {
+		t.Fatalf("Decode() 失败: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 20 || b.Dy() != 40 {
+		t.Errorf("方向 8 输出尺寸 = %dx%d, 期望 20x40", b.Dx(), b.Dy())
+	}
+}
+
+// TestOrient 测试各 EXIF 方向的像素变换
+func TestOrient(t *testing.T) {
+	// 2x1 图片：左红右蓝
+	red := color.RGBA{255, 0, 0, 255}
+	blue := color.RGBA{0, 0, 255, 255}
+	src := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	src.Set(0, 0, red)
+	src.Set(1, 0, blue)
+
+	tests := []struct {
+		orientation int
+		w, h        int
+		redAt       image.Point
+	}{
+		{1, 2, 1, image.Pt(0, 0)},
+		{2, 2, 1, image.Pt(1, 0)},
+		{3, 2, 1, image.Pt(1, 0)},
+		{4, 2, 1, image.Pt(0, 0)},
+		{5, 1, 2, image.Pt(0, 0)},
+		{6, 1, 2, image.Pt(0, 0)},
+		{7, 1, 2, image.Pt(0, 1)},
+		{8, 1, 2, image.Pt(0, 1)},
+	}
+	for _, tt := range tests {
+		dst := orient(src, tt.orientation)
+		if b := dst.Bounds(); b.Dx() != tt.w || b.Dy() != tt.h {
+			t.Errorf("方向 %d: 尺寸 = %dx%d, 期望 %dx%d", tt.orientation, b.Dx(), b.Dy(), tt.w, tt.h)
+			continue
+		}
+		if got := dst.RGBAAt(tt.redAt.X, tt.redAt.Y); got != red {
+			t.Errorf("方向 %d: %v 处应为红色, 实际 %v", tt.orientation, tt.redAt, got)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package avatar
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// ============================================================================
+// 存储 key
+// ============================================================================
+//
+// 原图：avatars/{userID}/{内容哈希}.jpg，缩略图：avatars/{userID}/{内容哈希}_{size}.jpg
+// key 由内容决定，上传新头像不会覆盖正在使用的文件；数据库指向新 key 之后才删除旧文件，
+// 数据库中不再引用的文件由对账任务清理
+
+const (
+	// KeyPrefix 头像在存储中的 key 前缀
+	KeyPrefix = "avatars/"
+	// legacyUploadURLPrefix 旧版本保存的头像字段为本地路径（/uploads/avatars/{userID}.jpg）
+	legacyUploadURLPrefix = "/uploads/"
+	// hashLen key 中内容哈希的长度（十六进制字符数）
+	hashLen = 32
+)
+
+// Key 返回原图的存储 key，data 为重新编码后的原图
+func Key(userID uint64, data []byte) string {
+	sum := sha256.Sum256(data)
+	return fmt.Sprintf("%s%d/%s%s", KeyPrefix, userID, hex.EncodeToString(sum[:])[:hashLen], Ext)
+}
+
+// SizeKey 返回缩略图的存储 key，size 为 0 时为原图，否则为 {name}_{size}{ext}
+func SizeKey(original string, size int) string {
+	if size == 0 {
+		return original
+	}
+	ext := path.Ext(original)
+	return fmt.Sprintf("%s_%d%s", strings.TrimSuffix(original, ext), size, ext)
+}
+
+// Keys 返回原图及所有缩略图的存储 key
+func Keys(original string) []string {
+	keys := []string{original}
+	for _, size := range Sizes {
+		keys = append(keys, SizeKey(original, size))
+	}
+	return keys
+}
+
+// OriginalKey 返回缩略图对应的原图 key，不是缩略图时原样返回
+func OriginalKey(key string) string {
+	ext := path.Ext(key)
+	name := strings.TrimSuffix(key, ext)
+	i := strings.LastIndexByte(name, '_')
+	if i < 0 {
+		return key
+	}
+	if _, ok := ParseSize(name[i+1:]); !ok {
+		return key
+	}
+	return name[:i] + ext
+}
+
+// FromProfilePicture 将用户信息中的头像字段转换为原图的存储 key
+// 兼容旧数据中的本地路径（/uploads/avatars/1.jpg → avatars/1.jpg，本地存储根目录默认为 ./uploads）
+func FromProfilePicture(profilePicture string) (string, bool) {
+	key := strings.TrimPrefix(profilePicture, legacyUploadURLPrefix)
+	if !strings.HasPrefix(key, KeyPrefix) || len(key) == len(KeyPrefix) {
+		return "", false
+	}
+	return key, true
+}
@@ -0,0 +1,68 @@
+package avatar
+
+import (
+	"regexp"
+	"slices"
+	"testing"
+)
+
+// TestKey 测试 key 由用户ID和内容决定
+func TestKey(t *testing.T) {
+	key := Key(1, []byte("a"))
+	if !regexp.MustCompile(`^avatars/1/[0-9a-f]{32}\.jpg$`).MatchString(key) {
+		t.Fatalf("Key() = %s, 格式不正确", key)
+	}
+	if Key(1, []byte("a")) != key {
+		t.Error("相同内容应得到相同的 key")
+	}
+	if Key(1, []byte("b")) == key || Key(2, []byte("a")) == key {
+		t.Error("内容或用户不同时 key 应不同")
+	}
+}
+
+// TestSizeKey 测试缩略图 key 与原图 key 互相转换
+func TestSizeKey(t *testing.T) {
+	original := "avatars/1/0123abcd.jpg"
+	want := []string{original, "avatars/1/0123abcd_64.jpg", "avatars/1/0123abcd_128.jpg", "avatars/1/0123abcd_512.jpg"}
+	if keys := Keys(original); !slices.Equal(keys, want) {
+		t.Fatalf("Keys() = %v, 期望 %v", keys, want)
+	}
+	for _, key := range want {
+		if got := OriginalKey(key); got != original {
+			t.Errorf("OriginalKey(%q) = %q, 期望 %q", key, got, original)
+		}
+	}
+
+	// 旧版本的 key 和不是缩略图尺寸的后缀
+	for key, want := range map[string]string{
+		"avatars/1.jpg":       "avatars/1.jpg",
+		"avatars/1_128.jpg":   "avatars/1.jpg",
+		"avatars/1.png":       "avatars/1.png",
+		"avatars/a_b.jpg":     "avatars/a_b.jpg",
+		"avatars/1/x_100.jpg": "avatars/1/x_100.jpg",
+	} {
+		if got := OriginalKey(key); got != want {
+			t.Errorf("OriginalKey(%q) = %q, 期望 %q", key, got, want)
+		}
+	}
+}
+
+// TestFromProfilePicture 测试头像字段转换为 key
+func TestFromProfilePicture(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		ok   bool
+	}{
+		{"avatars/1/0123abcd.jpg", "avatars/1/0123abcd.jpg", true},
+		{"/uploads/avatars/1.jpg", "avatars/1.jpg", true},
+		{"", "", false},
+		{"avatars/", "", false},
+		{"https://example.com/a.jpg", "", false},
+	}
+	for _, tt := range tests {
+		if got, ok := FromProfilePicture(tt.in); got != tt.want || ok != tt.ok {
+			t.Errorf("FromProfilePicture(%q) = %q, %v, 期望 %q, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package avatar
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// orientationTag EXIF 方向标签
+const orientationTag = 0x0112
+
+// jpegOrientation 读取 JPEG 的 EXIF 方向（1-8），没有或无法解析时返回 1（正常方向）
+// 重新编码会丢弃 EXIF，方向需要在丢弃前应用到像素上
+func jpegOrientation(data []byte) int {
+	// 跳过 SOI，逐个读取段，直到图像数据（SOS）开始
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xff {
+			return 1
+		}
+		marker := data[i+1]
+		if marker == 0xda || marker == 0xd9 { // SOS / EOI
+			return 1
+		}
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		if length < 2 || i+2+length > len(data) {
+			return 1
+		}
+		segment := data[i+4 : i+2+length]
+		if marker == 0xe1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return exifOrientation(segment[6:])
+		}
+		i += 2 + length
+	}
+	return 1
+}
+
+// exifOrientation 从 TIFF 结构的 IFD0 中读取方向标签
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 1
+	}
+	count := int(order.Uint16(tiff[ifd:]))
+	for n := 0; n < count; n++ {
+		entry := ifd + 2 + n*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) == orientationTag {
+			if v := int(order.Uint16(tiff[entry+8:])); v >= 1 && v <= 8 {
+				return v
+			}
+			return 1
+		}
+	}
+	return 1
+}
+
+// orient 转换为 RGBA 并按 EXIF 方向变换，透明区域填充白色
+// 像素直接写入摆正后的位置，除结果外只分配一行大小的缓冲区
+func orient(src image.Image, orientation int) *image.RGBA {
+	if orientation < 1 || orientation > 8 {
+		orientation = 1
+	}
+
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	dw, dh := w, h
+	if orientation >= 5 { // 5-8 需要交换宽高
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	white := image.NewUniform(color.White)
+
+	if orientation == 1 {
+		draw.Draw(dst, dst.Rect, white, image.Point{}, draw.Src)
+		draw.Draw(dst, dst.Rect, src, b.Min, draw.Over)
+		return dst
+	}
+
+	// 逐行转换为 RGBA，再把每个像素写入变换后的位置
+	row := image.NewRGBA(image.Rect(0, 0, w, 1))
+	for y := 0; y < h; y++ {
+		draw.Draw(row, row.Rect, white, image.Point{}, draw.Src)
+		draw.Draw(row, row.Rect, src, image.Pt(b.Min.X, b.Min.Y+y), draw.Over)
+
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // 水平翻转
+				dx, dy = w-1-x, y
+			case 3: // 旋转 180°
+				dx, dy = w-1-x, h-1-y
+			case 4: // 垂直翻转
+				dx, dy = x, h-1-y
+			case 5: // 沿主对角线翻转
+				dx, dy = y, x
+			case 6: // 顺时针旋转 90°
+				dx, dy = h-1-y, x
+			case 7: // 沿副对角线翻转
+				dx, dy = h-1-y, w-1-x
+			case 8: // 逆时针旋转 90°
+				dx, dy = y, w-1-x
+			}
+			d := dst.PixOffset(dx, dy)
+			copy(dst.Pix[d:d+4], row.Pix[x*4:x*4+4])
+		}
+	}
+	return dst
+}
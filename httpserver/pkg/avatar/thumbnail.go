@@ -0,0 +1,63 @@
+package avatar
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"strconv"
+
+	"golang.org/x/image/draw"
+)
+
+// Sizes 预生成的缩略图边长（像素），上传时与原图一起生成
+var Sizes = []int{64, 128, 512}
+
+// ParseSize 解析请求中的缩略图尺寸，只接受 Sizes 中的值
+func ParseSize(s string) (int, bool) {
+	size, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, false
+	}
+	for _, v := range Sizes {
+		if v == size {
+			return size, true
+		}
+	}
+	return 0, false
+}
+
+// Thumbnail 居中裁剪为正方形并缩放到 size×size；原图短边小于 size 时只裁剪不放大
+func Thumbnail(img image.Image, size int) image.Image {
+	b := img.Bounds()
+	side := min(b.Dx(), b.Dy())
+	crop := image.Rect(0, 0, side, side).Add(image.Pt(
+		b.Min.X+(b.Dx()-side)/2,
+		b.Min.Y+(b.Dy()-side)/2,
+	))
+
+	if side < size {
+		size = side
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, crop, draw.Src, nil)
+	return dst
+}
+
+// Variants 编码原图和 Sizes 中的所有缩略图，键为边长，原图的键为 0
+func Variants(img image.Image) (map[int][]byte, error) {
+	variants := make(map[int][]byte, len(Sizes)+1)
+	var buf bytes.Buffer
+	if err := Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("编码图片失败: %w", err)
+	}
+	variants[0] = buf.Bytes()
+
+	for _, size := range Sizes {
+		var buf bytes.Buffer
+		if err := Encode(&buf, Thumbnail(img, size)); err != nil {
+			return nil, fmt.Errorf("编码 %d 缩略图失败: %w", size, err)
+		}
+		variants[size] = buf.Bytes()
+	}
+	return variants, nil
+}
@@ -0,0 +1,77 @@
+package avatar
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+)
+
+// TestParseSize 测试缩略图尺寸解析
+func TestParseSize(t *testing.T) {
+	for _, s := range []string{"64", "128", "512"} {
+		if _, ok := ParseSize(s); !ok {
+			t.Errorf("ParseSize(%q) 应成功", s)
+		}
+	}
+	for _, s := range []string{"", "0", "100", "-64", "abc", "1e3"} {
+		if size, ok := ParseSize(s); ok {
+			t.Errorf("ParseSize(%q) = %d, 应失败", s, size)
+		}
+	}
+}
+
+// TestThumbnail 测试居中裁剪和缩放
+func TestThumbnail(t *testing.T) {
+	// 300x100：中间 100x100 为红色，两侧为蓝色
+	red := color.RGBA{255, 0, 0, 255}
+	src := image.NewRGBA(image.Rect(0, 0, 300, 100))
+	for y := 0; y < 100; y++ {
+		for x := 0; x < 300; x++ {
+			if x >= 100 && x < 200 {
+				src.Set(x, y, red)
+			} else {
+				src.Set(x, y, color.RGBA{0, 0, 255, 255})
+			}
+		}
+	}
+
+	thumb := Thumbnail(src, 64)
+	if b := thumb.Bounds(); b.Dx() != 64 || b.Dy() != 64 {
+		t.Fatalf("尺寸 = %dx%d, 期望 64x64", b.Dx(), b.Dy())
+	}
+	for _, p := range []image.Point{{0, 0}, {63, 63}, {32, 32}} {
+		if r, _, b, _ := thumb.At(p.X, p.Y).RGBA(); r>>8 != 255 || b>>8 != 0 {
+			t.Errorf("%v 处应为裁剪后的红色区域", p)
+		}
+	}
+
+	// 短边小于目标尺寸时不放大
+	if b := Thumbnail(src, 512).Bounds(); b.Dx() != 100 || b.Dy() != 100 {
+		t.Errorf("不应放大, 尺寸 = %dx%d, 期望 100x100", b.Dx(), b.Dy())
+	}
+}
+
+// TestVariants 测试生成原图和所有缩略图
+func TestVariants(t *testing.T) {
+	variants, err := Variants(image.NewRGBA(image.Rect(0, 0, 600, 400)))
+	if err != nil {
+		t.Fatalf("Variants() 失败: %v", err)
+	}
+	if len(variants) != len(Sizes)+1 {
+		t.Fatalf("数量 = %d, 期望 %d", len(variants), len(Sizes)+1)
+	}
+
+	want := map[int]int{0: 600, 64: 64, 128: 128, 512: 400}
+	for size, width := range want {
+		cfg, err := jpeg.DecodeConfig(bytes.NewReader(variants[size]))
+		if err != nil {
+			t.Errorf("尺寸 %d 解码失败: %v", size, err)
+			continue
+		}
+		if cfg.Width != width {
+			t.Errorf("尺寸 %d 宽度 = %d, 期望 %d", size, cfg.Width, width)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package grpcclient
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	log "entry-task/pkg/logger"
+)
+
+// ============================================================================
+// 熔断器
+// ============================================================================
+//
+// 关闭：正常放行，连续失败达到阈值后打开
+// 打开：直接返回 UNAVAILABLE，不再等待超时；经过 openTimeout 后进入半开
+// 半开：只放行一个探测请求，成功则关闭，失败则重新打开
+
+const (
+	// DefaultFailureThreshold 默认熔断阈值（连续失败次数）
+	DefaultFailureThreshold = 5
+
+	// DefaultOpenTimeout 默认熔断持续时间
+	DefaultOpenTimeout = 5 * time.Second
+)
+
+// State 熔断器状态
+type State int
+
+const (
+	StateClosed State = iota
+	StateOpen
+	StateHalfOpen
+)
+
+// String 状态名
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half_open"
+	default:
+		return "unknown"
+	}
+}
+
+// breakerState 熔断器状态（0 关闭，1 打开，2 半开）
+var breakerState = promauto.NewGauge(prometheus.GaugeOpts{
+	Name: "httpserver_grpc_circuit_breaker_state",
+	Help: "TCP Server gRPC 熔断器状态（0 关闭，1 打开，2 半开）",
+})
+
+// CircuitBreaker 熔断器
+type CircuitBreaker struct {
+	threshold   int
+	openTimeout time.Duration
+	now         func() time.Time // 便于测试
+
+	mu       sync.Mutex
+	state    State
+	failures int       // 关闭状态下的连续失败次数
+	openedAt time.Time // 打开的时间
+	probing  bool      // 半开状态下是否已有探测请求在进行
+}
+
+// NewCircuitBreaker 创建熔断器，参数未配置时使用默认值
+func NewCircuitBreaker(threshold int, openTimeout time.Duration) *CircuitBreaker {
+	if threshold <= 0 {
+		threshold = DefaultFailureThreshold
+	}
+	if openTimeout <= 0 {
+		openTimeout = DefaultOpenTimeout
+	}
+	return &CircuitBreaker{threshold: threshold, openTimeout: openTimeout, now: time.Now}
+}
+
+// State 获取当前状态
+func (b *CircuitBreaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// Allow 是否放行请求
+func (b *CircuitBreaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateOpen:
+		if b.now().Sub(b.openedAt) < b.openTimeout {
+			return false
+		}
+		b.setState(StateHalfOpen)
+		b.probing = true
+		return true
+	case StateHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+// Record 记录请求结果，failed 表示下游不可用（连接失败、超时）
+func (b *CircuitBreaker) Record(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateHalfOpen:
+		b.probing = false
+		if failed {
+			b.open()
+		} else {
+			b.failures = 0
+			b.setState(StateClosed)
+		}
+	case StateClosed:
+		if !failed {
+			b.failures = 0
+			return
+		}
+		b.failures++
+		if b.failures >= b.threshold {
+			b.open()
+		}
+	}
+}
+
+// Ignore 放弃本次请求的结果（不计入成功或失败），半开状态下允许下一个探测请求
+func (b *CircuitBreaker) Ignore() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == StateHalfOpen {
+		b.probing = false
+	}
+}
+
+// open 打开熔断器（需持有锁）
+func (b *CircuitBreaker) open() {
+	b.failures = 0
+	b.openedAt = b.now()
+	b.setState(StateOpen)
+}
+
+// setState 切换状态并记录日志和指标（需持有锁）
+func (b *CircuitBreaker) setState(state State) {
+	if b.state == state {
+		return
+	}
+	log.Warn("gRPC 熔断器状态变化",
+		zap.String("from", b.state.String()),
+		zap.String("to", state.String()),
+	)
+	b.state = state
+	breakerState.Set(float64(state))
+}
+
+// isFailure 是否视为下游不可用（业务错误和调用方取消不计入）
+// RESOURCE_EXHAUSTED 是登录锁定等业务限流，不计入
+func isFailure(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	default:
+		return false
+	}
+}
+
+// Interceptor 熔断拦截器，熔断时直接返回 UNAVAILABLE
+// 健康检查调用不经过熔断器，就绪探针始终反映真实的连接状态
+func (b *CircuitBreaker) Interceptor() grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply interface{},
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		if strings.HasPrefix(method, "/grpc.health.v1.") {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		if !b.Allow() {
+			return status.Error(codes.Unavailable, "TCP Server 熔断中")
+		}
+
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		// 调用方自己取消或超时（如客户端断开）时 ctx 已结束，不能说明下游不可用
+		if err != nil && ctx.Err() != nil && status.Code(err) != codes.Unavailable {
+			b.Ignore()
+			return err
+		}
+		b.Record(isFailure(err))
+		return err
+	}
+}
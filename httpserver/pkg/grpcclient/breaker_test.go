@@ -0,0 +1,135 @@
+package grpcclient
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"entry-task/pkg/logger"
+)
+
+// TestMain 在所有测试运行前初始化日志
+func TestMain(m *testing.M) {
+	if err := logger.Init(&logger.Config{Level: "fatal", Output: "stdout"}); err != nil {
+		panic("初始化日志失败: " + err.Error())
+	}
+	os.Exit(m.Run())
+}
+
+// newTestBreaker 创建使用可控时钟的熔断器
+func newTestBreaker(threshold int, openTimeout time.Duration) (*CircuitBreaker, *time.Time) {
+	now := time.Now()
+	b := NewCircuitBreaker(threshold, openTimeout)
+	b.now = func() time.Time { return now }
+	return b, &now
+}
+
+// TestCircuitBreaker 测试关闭 → 打开 → 半开 → 关闭的状态转换
+func TestCircuitBreaker(t *testing.T) {
+	b, now := newTestBreaker(3, time.Second)
+
+	// 成功会重置连续失败计数
+	b.Record(true)
+	b.Record(true)
+	b.Record(false)
+	b.Record(true)
+	b.Record(true)
+	if b.State() != StateClosed {
+		t.Fatalf("未达到连续失败阈值时应保持关闭, 实际 %v", b.State())
+	}
+
+	b.Record(true)
+	if b.State() != StateOpen {
+		t.Fatalf("连续失败达到阈值后应打开, 实际 %v", b.State())
+	}
+	if b.Allow() {
+		t.Error("打开状态应拒绝请求")
+	}
+
+	// 超过熔断时间后只放行一个探测请求
+	*now = now.Add(time.Second)
+	if !b.Allow() {
+		t.Fatal("熔断时间过后应放行探测请求")
+	}
+	if b.State() != StateHalfOpen || b.Allow() {
+		t.Error("半开状态只应放行一个探测请求")
+	}
+
+	// 探测失败重新打开
+	b.Record(true)
+	if b.State() != StateOpen || b.Allow() {
+		t.Fatalf("探测失败后应重新打开, 实际 %v", b.State())
+	}
+
+	// 探测成功关闭
+	*now = now.Add(time.Second)
+	if !b.Allow() {
+		t.Fatal("熔断时间过后应放行探测请求")
+	}
+	b.Record(false)
+	if b.State() != StateClosed || !b.Allow() {
+		t.Errorf("探测成功后应关闭, 实际 %v", b.State())
+	}
+}
+
+// TestCircuitBreaker_Interceptor 测试拦截器按错误类型计数，熔断时直接返回 UNAVAILABLE
+func TestCircuitBreaker_Interceptor(t *testing.T) {
+	b, _ := newTestBreaker(2, time.Minute)
+	interceptor := b.Interceptor()
+
+	calls := 0
+	var invokeErr error
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		calls++
+		return invokeErr
+	}
+	call := func(ctx context.Context, method string) error {
+		return interceptor(ctx, method, nil, nil, nil, invoker)
+	}
+	method := "/user.v2.UserService/GetProfile"
+
+	// 业务错误（含登录锁定）不计入失败
+	for _, code := range []codes.Code{codes.InvalidArgument, codes.ResourceExhausted} {
+		invokeErr = status.Error(code, "business error")
+		for i := 0; i < 3; i++ {
+			_ = call(context.Background(), method)
+		}
+	}
+	if b.State() != StateClosed {
+		t.Fatalf("业务错误不应触发熔断, 实际 %v", b.State())
+	}
+
+	// 调用方取消不计入失败
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	invokeErr = status.Error(codes.DeadlineExceeded, "context canceled")
+	_ = call(ctx, method)
+	_ = call(ctx, method)
+	if b.State() != StateClosed {
+		t.Fatalf("调用方取消不应触发熔断, 实际 %v", b.State())
+	}
+
+	// 下游不可用
+	invokeErr = status.Error(codes.Unavailable, "connection refused")
+	_ = call(context.Background(), method)
+	_ = call(context.Background(), method)
+	if b.State() != StateOpen {
+		t.Fatalf("连续不可用后应熔断, 实际 %v", b.State())
+	}
+
+	calls = 0
+	if err := call(context.Background(), method); status.Code(err) != codes.Unavailable || calls != 0 {
+		t.Errorf("熔断时应直接返回 UNAVAILABLE, err=%v calls=%d", err, calls)
+	}
+
+	// 健康检查不经过熔断器
+	invokeErr = nil
+	if err := call(context.Background(), "/grpc.health.v1.Health/Check"); err != nil || calls != 1 {
+		t.Errorf("健康检查应放行, err=%v calls=%d", err, calls)
+	}
+}
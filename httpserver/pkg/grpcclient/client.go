@@ -0,0 +1,192 @@
+package grpcclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/resolver/manual"
+
+	"entry-task/httpserver/config"
+	pb "entry-task/proto/user/v2"
+)
+
+// ============================================================================
+// TCP Server gRPC 客户端
+// ============================================================================
+//
+// 多个 TCP Server 地址通过静态解析器交给 gRPC 的 round_robin 负载均衡；
+// 超时和重试通过 Service Config 按方法配置，熔断器以拦截器形式加在最外层。
+
+const (
+	// DefaultTimeout 未配置时的 RPC 默认超时
+	DefaultTimeout = 3 * time.Second
+
+	// DefaultRetryMethod 未配置重试方法时只重试的幂等方法
+	DefaultRetryMethod = "GetProfile"
+
+	// resolverScheme 静态地址解析器的 scheme
+	resolverScheme = "tcpserver"
+)
+
+// Dial 按配置创建到 TCP Server 的连接（不阻塞等待连接建立）
+// opts 为额外的选项（如拦截器、StatsHandler）
+func Dial(cfg *config.GRPCConfig, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
+	serviceConfig, err := buildServiceConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// 静态解析器：地址列表来自配置
+	r := manual.NewBuilderWithScheme(resolverScheme)
+	addrs := make([]resolver.Address, 0, len(cfg.GetTargets()))
+	for _, target := range cfg.GetTargets() {
+		addrs = append(addrs, resolver.Address{Addr: target})
+	}
+	r.InitialState(resolver.State{Addresses: addrs})
+
+	dialOpts := []grpc.DialOption{
+		grpc.WithResolvers(r),
+		grpc.WithDefaultServiceConfig(serviceConfig),
+	}
+	if cfg.Keepalive.TimeSec > 0 {
+		dialOpts = append(dialOpts, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                time.Duration(cfg.Keepalive.TimeSec) * time.Second,
+			Timeout:             time.Duration(cfg.Keepalive.TimeoutSec) * time.Second,
+			PermitWithoutStream: cfg.Keepalive.PermitWithoutStream,
+		}))
+	}
+	dialOpts = append(dialOpts, opts...)
+
+	conn, err := grpc.NewClient(resolverScheme+":///"+pb.UserService_ServiceDesc.ServiceName, dialOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("创建 gRPC 连接失败: %w", err)
+	}
+	return conn, nil
+}
+
+// ============================================================================
+// Service Config
+// ============================================================================
+
+// serviceConfig gRPC Service Config（JSON）
+type serviceConfig struct {
+	LoadBalancingConfig []map[string]struct{} `json:"loadBalancingConfig"`
+	MethodConfig        []methodConfig        `json:"methodConfig"`
+}
+
+// methodConfig 按方法的超时和重试配置
+type methodConfig struct {
+	Name        []methodName `json:"name"`
+	Timeout     string       `json:"timeout,omitempty"`
+	RetryPolicy *retryPolicy `json:"retryPolicy,omitempty"`
+}
+
+// methodName 方法名（Method 为空时匹配整个服务）
+type methodName struct {
+	Service string `json:"service"`
+	Method  string `json:"method,omitempty"`
+}
+
+// retryPolicy 重试策略
+type retryPolicy struct {
+	MaxAttempts          int      `json:"maxAttempts"`
+	InitialBackoff       string   `json:"initialBackoff"`
+	MaxBackoff           string   `json:"maxBackoff"`
+	BackoffMultiplier    float64  `json:"backoffMultiplier"`
+	RetryableStatusCodes []string `json:"retryableStatusCodes"`
+}
+
+// buildServiceConfig 生成 Service Config：round_robin 负载均衡、默认超时、按方法的超时和重试
+func buildServiceConfig(cfg *config.GRPCConfig) (string, error) {
+	service := pb.UserService_ServiceDesc.ServiceName
+
+	defaultTimeout := cfg.GetDefaultTimeout()
+	if defaultTimeout <= 0 {
+		defaultTimeout = DefaultTimeout
+	}
+
+	retryMethods := cfg.Retry.Methods
+	if len(retryMethods) == 0 {
+		retryMethods = []string{DefaultRetryMethod}
+	}
+	var retry *retryPolicy
+	if cfg.Retry.MaxAttempts > 1 {
+		retry = &retryPolicy{
+			MaxAttempts:          cfg.Retry.MaxAttempts,
+			InitialBackoff:       durationString(time.Duration(cfg.Retry.InitialBackoffMs)*time.Millisecond, 50*time.Millisecond),
+			MaxBackoff:           durationString(time.Duration(cfg.Retry.MaxBackoffMs)*time.Millisecond, 500*time.Millisecond),
+			BackoffMultiplier:    2,
+			RetryableStatusCodes: []string{"UNAVAILABLE"},
+		}
+	}
+
+	// 需要单独配置的方法：有自定义超时或允许重试
+	methods := map[string]*methodConfig{}
+	method := func(name string) *methodConfig {
+		if m, ok := methods[name]; ok {
+			return m
+		}
+		m := &methodConfig{
+			Name:    []methodName{{Service: service, Method: name}},
+			Timeout: durationString(defaultTimeout, DefaultTimeout),
+		}
+		methods[name] = m
+		return m
+	}
+	for name, ms := range cfg.MethodTimeoutsMs {
+		if !hasMethod(name) {
+			return "", fmt.Errorf("method_timeouts_ms 中的方法不存在: %s", name)
+		}
+		method(name).Timeout = durationString(time.Duration(ms)*time.Millisecond, defaultTimeout)
+	}
+	if retry != nil {
+		for _, name := range retryMethods {
+			if !hasMethod(name) {
+				return "", fmt.Errorf("retry.methods 中的方法不存在: %s", name)
+			}
+			method(name).RetryPolicy = retry
+		}
+	}
+
+	sc := serviceConfig{
+		LoadBalancingConfig: []map[string]struct{}{{"round_robin": {}}},
+		MethodConfig: []methodConfig{{
+			Name:    []methodName{{Service: service}},
+			Timeout: durationString(defaultTimeout, DefaultTimeout),
+		}},
+	}
+	// 按服务定义中的方法顺序输出，保证生成结果稳定
+	for _, desc := range pb.UserService_ServiceDesc.Methods {
+		if m, ok := methods[desc.MethodName]; ok {
+			sc.MethodConfig = append(sc.MethodConfig, *m)
+		}
+	}
+
+	data, err := json.Marshal(sc)
+	if err != nil {
+		return "", fmt.Errorf("生成 Service Config 失败: %w", err)
+	}
+	return string(data), nil
+}
+
+// hasMethod UserService 是否有该方法
+func hasMethod(name string) bool {
+	for _, desc := range pb.UserService_ServiceDesc.Methods {
+		if desc.MethodName == name {
+			return true
+		}
+	}
+	return false
+}
+
+// durationString 转换为 Service Config 的时长格式（如 "1.5s"），d 未配置时使用 def
+func durationString(d, def time.Duration) string {
+	if d <= 0 {
+		d = def
+	}
+	return fmt.Sprintf("%gs", d.Seconds())
+}
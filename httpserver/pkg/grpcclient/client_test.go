@@ -0,0 +1,84 @@
+package grpcclient
+
+import (
+	"encoding/json"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"entry-task/httpserver/config"
+)
+
+// TestBuildServiceConfig 测试生成的 Service Config
+func TestBuildServiceConfig(t *testing.T) {
+	cfg := &config.GRPCConfig{
+		DefaultTimeoutMs: 2000,
+		MethodTimeoutsMs: map[string]int{"UpdateProfilePicture": 5000},
+		Retry: config.GRPCRetryConfig{
+			MaxAttempts:      3,
+			InitialBackoffMs: 100,
+		},
+	}
+	data, err := buildServiceConfig(cfg)
+	if err != nil {
+		t.Fatalf("生成失败: %v", err)
+	}
+
+	var sc serviceConfig
+	if err := json.Unmarshal([]byte(data), &sc); err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	if _, ok := sc.LoadBalancingConfig[0]["round_robin"]; !ok {
+		t.Errorf("负载均衡期望 round_robin: %s", data)
+	}
+
+	methods := map[string]methodConfig{}
+	for _, m := range sc.MethodConfig {
+		methods[m.Name[0].Method] = m
+	}
+	if m := methods[""]; m.Timeout != "2s" || m.RetryPolicy != nil {
+		t.Errorf("默认配置不正确: %+v", m)
+	}
+	if m := methods["UpdateProfilePicture"]; m.Timeout != "5s" || m.RetryPolicy != nil {
+		t.Errorf("UpdateProfilePicture 配置不正确: %+v", m)
+	}
+	// 未配置重试方法时只重试 GetProfile
+	m, ok := methods["GetProfile"]
+	if !ok || m.RetryPolicy == nil || m.RetryPolicy.MaxAttempts != 3 || m.RetryPolicy.InitialBackoff != "0.1s" {
+		t.Errorf("GetProfile 重试配置不正确: %+v", m)
+	}
+	if m.Timeout != "2s" {
+		t.Errorf("GetProfile 应使用默认超时, 实际 %s", m.Timeout)
+	}
+	if len(methods) != 3 {
+		t.Errorf("只有配置过的方法需要单独的配置: %s", data)
+	}
+}
+
+// TestBuildServiceConfig_UnknownMethod 测试配置了不存在的方法
+func TestBuildServiceConfig_UnknownMethod(t *testing.T) {
+	cfg := &config.GRPCConfig{MethodTimeoutsMs: map[string]int{"GetProfil": 1000}}
+	if _, err := buildServiceConfig(cfg); err == nil {
+		t.Error("不存在的方法应返回错误")
+	}
+
+	cfg = &config.GRPCConfig{Retry: config.GRPCRetryConfig{MaxAttempts: 2, Methods: []string{"Nope"}}}
+	if _, err := buildServiceConfig(cfg); err == nil {
+		t.Error("不存在的重试方法应返回错误")
+	}
+}
+
+// TestDial 测试多个地址创建连接（不会立即建立连接）
+func TestDial(t *testing.T) {
+	cfg := &config.GRPCConfig{
+		Targets:   []string{"127.0.0.1:1", "127.0.0.1:2"},
+		Retry:     config.GRPCRetryConfig{MaxAttempts: 2},
+		Keepalive: config.GRPCKeepaliveConfig{TimeSec: 30, TimeoutSec: 10},
+	}
+	conn, err := Dial(cfg, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("创建连接失败: %v", err)
+	}
+	defer conn.Close()
+}
@@ -1,90 +1,55 @@
 package response
 
+import "entry-task/proto/errcode"
+
 // 业务错误码定义
+// 编号、HTTP 状态码和默认消息统一定义在 proto/errcode/errcode.proto，这里只是别名
 const (
 	// 成功
-	CodeSuccess = 0
+	CodeSuccess = int(errcode.Code_SUCCESS)
 
-	// 客户端错误 (400-499)
-	CodeBadRequest    = 40000 // 请求参数错误
-	CodeInvalidParams = 40001 // 参数验证失败
-	CodeInvalidFormat = 40002 // 格式错误
+	// 客户端错误 (400xx)
+	CodeBadRequest          = int(errcode.Code_BAD_REQUEST)           // 请求参数错误
+	CodeInvalidParams       = int(errcode.Code_INVALID_PARAMS)        // 参数验证失败
+	CodeInvalidFormat       = int(errcode.Code_INVALID_FORMAT)        // 格式错误
+	CodeInvalidFile         = int(errcode.Code_INVALID_FILE)          // 无效文件
+	CodeFileTooLarge        = int(errcode.Code_FILE_TOO_LARGE)        // 文件过大
+	CodeUnsupportedFileType = int(errcode.Code_UNSUPPORTED_FILE_TYPE) // 不支持的文件类型
 
 	// 认证错误 (401xx)
-	CodeUnauthorized             = 40100 // 未认证
-	CodeInvalidToken             = 40101 // Token无效
-	CodeTokenExpired             = 40102 // Token过期
-	CodeInvalidAccountOrPassword = 40103 // 用户名或密码错误
-	CodeInvalidNickname          = 40104 // 无效昵称
-	CodeInvalidFile              = 40005 // 无效文件
-	CodeFileTooLarge             = 40006 // 文件过大
-	CodeUnsupportedFileType      = 40007 // 不支持的文件类型
+	CodeUnauthorized             = int(errcode.Code_UNAUTHORIZED)                // 未认证
+	CodeInvalidToken             = int(errcode.Code_INVALID_TOKEN)               // Token无效
+	CodeTokenExpired             = int(errcode.Code_TOKEN_EXPIRED)               // Token过期
+	CodeInvalidAccountOrPassword = int(errcode.Code_INVALID_ACCOUNT_OR_PASSWORD) // 用户名或密码错误
+	CodeInvalidNickname          = int(errcode.Code_INVALID_NICKNAME)            // 无效昵称（HTTP 400）
 
 	// 权限错误 (403xx)
-	CodeForbidden    = 40300 // 无权限
-	CodeAccessDenied = 40301 // 访问被拒绝
+	CodeForbidden    = int(errcode.Code_FORBIDDEN)     // 无权限
+	CodeAccessDenied = int(errcode.Code_ACCESS_DENIED) // 访问被拒绝
 
 	// 资源错误 (404xx)
-	CodeNotFound     = 40400 // 资源不存在
-	CodeUserNotFound = 40401 // 用户不存在
+	CodeNotFound        = int(errcode.Code_NOT_FOUND)         // 资源不存在
+	CodeUserNotFound    = int(errcode.Code_USER_NOT_FOUND)    // 用户不存在
+	CodeSessionNotFound = int(errcode.Code_SESSION_NOT_FOUND) // Session不存在
 
 	// 业务错误 (409xx)
-	CodeConflict       = 40900 // 资源冲突
-	CodeUserExists     = 40901 // 用户已存在
-	CodeUsernameExists = 40902 // 用户名已存在
-
-	// 服务端错误 (500-599)
-	CodeInternalServerError = 50000 // 服务器内部错误
-	CodeDatabaseError       = 50001 // 数据库错误
-	CodeRPCError            = 50002 // RPC调用错误
-	CodeRedisError          = 50003 // Redis错误
-	CodeServiceUnavailable  = 50004 // 服务不可用
+	CodeConflict       = int(errcode.Code_CONFLICT)        // 资源冲突
+	CodeUserExists     = int(errcode.Code_USER_EXISTS)     // 用户已存在
+	CodeUsernameExists = int(errcode.Code_USERNAME_EXISTS) // 用户名已存在
+
+	// 限流错误 (429xx)
+	CodeTooManyRequests = int(errcode.Code_TOO_MANY_REQUESTS) // 请求过于频繁
+
+	// 服务端错误 (500xx)
+	CodeInternalServerError = int(errcode.Code_INTERNAL_SERVER_ERROR) // 服务器内部错误
+	CodeDatabaseError       = int(errcode.Code_DATABASE_ERROR)        // 数据库错误
+	CodeRPCError            = int(errcode.Code_RPC_ERROR)             // RPC调用错误
+	CodeRedisError          = int(errcode.Code_REDIS_ERROR)           // Redis错误
+	CodeServiceUnavailable  = int(errcode.Code_SERVICE_UNAVAILABLE)   // 服务不可用
+	CodeRequestTimeout      = int(errcode.Code_REQUEST_TIMEOUT)       // 请求超时
 )
 
-// 错误信息映射
-var CodeMessage = map[int]string{
-	CodeSuccess: "OK",
-
-	// 客户端错误
-	CodeBadRequest:    "请求参数错误",
-	CodeInvalidParams: "参数验证失败",
-	CodeInvalidFormat: "格式错误",
-
-	// 认证错误
-	CodeUnauthorized:             "未认证",
-	CodeInvalidToken:             "Token无效",
-	CodeTokenExpired:             "Token已过期",
-	CodeInvalidAccountOrPassword: "用户名或密码错误",
-	CodeInvalidNickname:          "无效的昵称",
-	CodeInvalidFile:              "无效文件",
-	CodeFileTooLarge:             "文件过大",
-	CodeUnsupportedFileType:      "不支持的文件类型",
-
-	// 权限错误
-	CodeForbidden:    "无权限",
-	CodeAccessDenied: "访问被拒绝",
-
-	// 资源错误
-	CodeNotFound:     "资源不存在",
-	CodeUserNotFound: "用户不存在",
-
-	// 业务错误
-	CodeConflict:       "资源冲突",
-	CodeUserExists:     "用户已存在",
-	CodeUsernameExists: "用户名已存在",
-
-	// 服务端错误
-	CodeInternalServerError: "服务器内部错误",
-	CodeDatabaseError:       "数据库错误",
-	CodeRPCError:            "RPC调用错误",
-	CodeRedisError:          "Redis错误",
-	CodeServiceUnavailable:  "服务不可用",
-}
-
 // GetMessage 获取错误码对应的消息
 func GetMessage(code int) string {
-	if msg, ok := CodeMessage[code]; ok {
-		return msg
-	}
-	return "未知错误"
+	return errcode.Code(code).Message()
 }
@@ -3,6 +3,8 @@ package response
 import (
 	"net/http"
 
+	"entry-task/proto/errcode"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -105,22 +107,7 @@ func InternalServerError(c *gin.Context, message string) {
 	})
 }
 
-// getHTTPStatus 根据业务错误码获取HTTP状态码
+// getHTTPStatus 根据业务错误码获取HTTP状态码（定义在 proto/errcode/errcode.proto）
 func getHTTPStatus(code int) int {
-	switch {
-	case code == CodeSuccess:
-		return http.StatusOK
-	case code >= CodeBadRequest && code < CodeUnauthorized:
-		return http.StatusBadRequest
-	case code >= CodeUnauthorized && code < CodeForbidden:
-		return http.StatusUnauthorized
-	case code >= CodeForbidden && code < CodeNotFound:
-		return http.StatusForbidden
-	case code >= CodeNotFound && code < CodeInternalServerError:
-		return http.StatusNotFound
-	case code >= CodeInternalServerError:
-		return http.StatusInternalServerError
-	default:
-		return http.StatusOK
-	}
+	return errcode.Code(code).HTTPStatus()
 }
@@ -0,0 +1,186 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// tempFilePrefix Put 写入时临时文件的文件名前缀，List 时跳过
+const tempFilePrefix = ".upload-"
+
+// LocalStorage 本地文件存储，key 对应根目录下的相对路径
+type LocalStorage struct {
+	root string
+}
+
+// NewLocalStorage 创建本地文件存储
+func NewLocalStorage(root string) *LocalStorage {
+	return &LocalStorage{root: root}
+}
+
+// path 将 key 转换为本地路径，拒绝跳出根目录的 key
+func (s *LocalStorage) path(key string) (string, error) {
+	if !fs.ValidPath(key) || key == "." {
+		return "", fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
+
+// Put 先写入同目录下的临时文件再重命名
+func (s *LocalStorage) Put(_ context.Context, key string, data []byte, _ string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return fmt.Errorf("创建目录失败: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(p), tempFilePrefix+"*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // 重命名成功后删除会失败，忽略
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), p)
+}
+
+// Get 打开文件
+func (s *LocalStorage) Get(_ context.Context, key string) (*Object, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	stat, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if stat.IsDir() {
+		f.Close()
+		return nil, ErrNotFound
+	}
+	return &Object{ObjectInfo: fileInfo(key, stat), ReadSeekCloser: f}, nil
+}
+
+// Stat 读取文件信息
+func (s *LocalStorage) Stat(_ context.Context, key string) (*ObjectInfo, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	stat, err := os.Stat(p)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	if stat.IsDir() {
+		return nil, ErrNotFound
+	}
+	info := fileInfo(key, stat)
+	return &info, nil
+}
+
+// Delete 删除文件
+func (s *LocalStorage) Delete(_ context.Context, key string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// List 遍历 prefix 所在目录下的文件（跳过写入中的临时文件）
+func (s *LocalStorage) List(ctx context.Context, prefix string, fn func(ObjectInfo) error) error {
+	// 从 prefix 中最后一个 / 之前的目录开始遍历，目录不存在时没有对象
+	dir := s.root
+	if i := strings.LastIndexByte(prefix, '/'); i > 0 {
+		p, err := s.path(prefix[:i])
+		if err != nil {
+			return err
+		}
+		dir = p
+	}
+
+	return filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// 目录不存在，或遍历过程中被删除
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if d.IsDir() || strings.HasPrefix(d.Name(), tempFilePrefix) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(s.root, p)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		stat, err := d.Info()
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil // 遍历过程中被删除
+		}
+		if err != nil {
+			return err
+		}
+		return fn(fileInfo(key, stat))
+	})
+}
+
+// URL 本地文件没有可以直接访问的地址
+func (s *LocalStorage) URL(context.Context, string) (string, error) {
+	return "", ErrURLNotSupported
+}
+
+// fileInfo 由文件信息生成对象元信息
+// ETag 由修改时间和大小生成（文件通过重命名整体替换，内容变化时两者都会变化）
+func fileInfo(key string, stat fs.FileInfo) ObjectInfo {
+	return ObjectInfo{
+		Key:         key,
+		Size:        stat.Size(),
+		ModTime:     stat.ModTime(),
+		ETag:        fmt.Sprintf("%x-%x", stat.ModTime().UnixNano(), stat.Size()),
+		ContentType: mime.TypeByExtension(path.Ext(key)),
+	}
+}
+
+// notFound 将文件不存在错误转换为 ErrNotFound
+func notFound(err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return ErrNotFound
+	}
+	return err
+}
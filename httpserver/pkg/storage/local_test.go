@@ -0,0 +1,138 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// TestLocalStorage 测试本地存储的读写删除
+func TestLocalStorage(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	s := NewLocalStorage(root)
+
+	if err := s.Put(ctx, "avatars/1.jpg", []byte("v1"), "image/jpeg"); err != nil {
+		t.Fatalf("Put() 失败: %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(root, "avatars", "1.jpg")); err != nil || string(data) != "v1" {
+		t.Fatalf("文件内容 = %q, %v, 期望 v1", data, err)
+	}
+	info1, err := s.Stat(ctx, "avatars/1.jpg")
+	if err != nil {
+		t.Fatalf("Stat() 失败: %v", err)
+	}
+	if info1.Size != 2 || info1.ContentType != "image/jpeg" || info1.ETag == "" {
+		t.Errorf("Stat() = %+v", info1)
+	}
+
+	// 覆盖写入后 ETag 变化
+	if err := s.Put(ctx, "avatars/1.jpg", []byte("v2-new"), "image/jpeg"); err != nil {
+		t.Fatalf("Put() 失败: %v", err)
+	}
+	obj, err := s.Get(ctx, "avatars/1.jpg")
+	if err != nil {
+		t.Fatalf("Get() 失败: %v", err)
+	}
+	data, _ := io.ReadAll(obj)
+	obj.Close()
+	if string(data) != "v2-new" {
+		t.Errorf("Get() 内容 = %q, 期望 v2-new", data)
+	}
+	if obj.ETag == info1.ETag {
+		t.Error("内容变化后 ETag 应变化")
+	}
+
+	// 目录中不应残留临时文件
+	entries, _ := os.ReadDir(filepath.Join(root, "avatars"))
+	if len(entries) != 1 {
+		t.Errorf("目录中应只有一个文件, 实际 %d 个", len(entries))
+	}
+
+	if err := s.Delete(ctx, "avatars/1.jpg"); err != nil {
+		t.Fatalf("Delete() 失败: %v", err)
+	}
+	if _, err := s.Get(ctx, "avatars/1.jpg"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("删除后 Get() 错误 = %v, 期望 ErrNotFound", err)
+	}
+	if _, err := s.Stat(ctx, "avatars"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("目录 Stat() 错误 = %v, 期望 ErrNotFound", err)
+	}
+	if err := s.Delete(ctx, "avatars/1.jpg"); err != nil {
+		t.Errorf("删除不存在的对象不应返回错误: %v", err)
+	}
+	if _, err := s.URL(ctx, "avatars/1.jpg"); !errors.Is(err, ErrURLNotSupported) {
+		t.Errorf("URL() 错误 = %v, 期望 ErrURLNotSupported", err)
+	}
+}
+
+// TestLocalStorage_InvalidKey 测试拒绝跳出根目录的 key
+func TestLocalStorage_InvalidKey(t *testing.T) {
+	ctx := context.Background()
+	s := NewLocalStorage(t.TempDir())
+
+	for _, key := range []string{"", ".", "../secret", "avatars/../../secret", "/etc/passwd", "avatars//1.jpg"} {
+		if err := s.Put(ctx, key, []byte("x"), ""); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Put(%q) 错误 = %v, 期望 ErrInvalidKey", key, err)
+		}
+		if _, err := s.Get(ctx, key); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Get(%q) 错误 = %v, 期望 ErrInvalidKey", key, err)
+		}
+	}
+}
+
+// listKeys 列出 prefix 下所有对象的 key
+func listKeys(t *testing.T, s Storage, prefix string) []string {
+	t.Helper()
+	var keys []string
+	if err := s.List(context.Background(), prefix, func(info ObjectInfo) error {
+		keys = append(keys, info.Key)
+		return nil
+	}); err != nil {
+		t.Fatalf("List(%q) 失败: %v", prefix, err)
+	}
+	return keys
+}
+
+// TestLocalStorage_List 测试按前缀遍历文件，跳过写入中的临时文件
+func TestLocalStorage_List(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	s := NewLocalStorage(root)
+
+	if keys := listKeys(t, s, "avatars/"); len(keys) != 0 {
+		t.Errorf("目录不存在时 List() = %v, 期望为空", keys)
+	}
+
+	for _, key := range []string{"avatars/2/b.jpg", "avatars/1/a.jpg", "avatars/1/a_64.jpg", "avatars/10.jpg", "other/x.jpg"} {
+		if err := s.Put(ctx, key, []byte("x"), ""); err != nil {
+			t.Fatalf("Put(%q) 失败: %v", key, err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, "avatars", "1", tempFilePrefix+"123"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"avatars/1/a.jpg", "avatars/1/a_64.jpg", "avatars/10.jpg", "avatars/2/b.jpg"}
+	if keys := listKeys(t, s, "avatars/"); !slices.Equal(keys, want) {
+		t.Errorf("List(avatars/) = %v, 期望 %v", keys, want)
+	}
+	if keys := listKeys(t, s, "avatars/1"); !slices.Equal(keys, want[:3]) {
+		t.Errorf("List(avatars/1) = %v, 期望 %v", keys, want[:3])
+	}
+
+	// fn 返回错误时停止遍历
+	stop := errors.New("stop")
+	n := 0
+	err := s.List(ctx, "avatars/", func(ObjectInfo) error {
+		n++
+		return stop
+	})
+	if !errors.Is(err, stop) || n != 1 {
+		t.Errorf("List() = %v, 调用 %d 次, 期望返回 fn 的错误且只调用 1 次", err, n)
+	}
+}
@@ -0,0 +1,138 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+
+	"entry-task/httpserver/config"
+)
+
+// S3Storage S3 兼容对象存储，key 即对象名
+type S3Storage struct {
+	client        *minio.Client
+	bucket        string
+	presignExpiry time.Duration
+}
+
+// NewS3Storage 创建 S3 兼容对象存储（不检查 bucket 是否存在，由部署时创建）
+func NewS3Storage(cfg *config.S3StorageConfig) (*S3Storage, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" {
+		return nil, errors.New("S3 存储需要配置 endpoint 和 bucket")
+	}
+
+	lookup := minio.BucketLookupAuto
+	if cfg.PathStyle {
+		lookup = minio.BucketLookupPath
+	}
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:        credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure:       cfg.UseSSL,
+		Region:       cfg.GetRegion(), // 指定区域，避免每次请求前查询 bucket 所在区域
+		BucketLookup: lookup,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("创建 S3 客户端失败: %w", err)
+	}
+
+	return &S3Storage{
+		client:        client,
+		bucket:        cfg.Bucket,
+		presignExpiry: cfg.GetPresignExpiry(),
+	}, nil
+}
+
+// Put 上传对象
+func (s *S3Storage) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	if key == "" {
+		return ErrInvalidKey
+	}
+	_, err := s.client.PutObject(ctx, s.bucket, key, bytes.NewReader(data), int64(len(data)),
+		minio.PutObjectOptions{ContentType: contentType})
+	return err
+}
+
+// Get 下载对象，返回的 Object 支持 Seek（按 Range 请求）
+func (s *S3Storage) Get(ctx context.Context, key string) (*Object, error) {
+	obj, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, s3Error(err)
+	}
+	// GetObject 不发送请求，Stat 时才真正请求并得知对象是否存在
+	stat, err := obj.Stat()
+	if err != nil {
+		obj.Close()
+		return nil, s3Error(err)
+	}
+	return &Object{ObjectInfo: objectInfo(key, stat), ReadSeekCloser: obj}, nil
+}
+
+// Stat 读取对象元信息
+func (s *S3Storage) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	stat, err := s.client.StatObject(ctx, s.bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		return nil, s3Error(err)
+	}
+	info := objectInfo(key, stat)
+	return &info, nil
+}
+
+// Delete 删除对象（S3 删除不存在的对象也返回成功）
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	return s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{})
+}
+
+// List 分页列出 prefix 下的所有对象（ListObjectsV2）
+func (s *S3Storage) List(ctx context.Context, prefix string, fn func(ObjectInfo) error) error {
+	// 提前结束遍历时取消后台的分页请求
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	for obj := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if obj.Err != nil {
+			return obj.Err
+		}
+		if err := fn(ObjectInfo{
+			Key:     obj.Key,
+			Size:    obj.Size,
+			ModTime: obj.LastModified,
+			ETag:    obj.ETag,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// URL 返回预签名下载地址（本地计算签名，不发送请求）
+func (s *S3Storage) URL(ctx context.Context, key string) (string, error) {
+	u, err := s.client.PresignedGetObject(ctx, s.bucket, key, s.presignExpiry, nil)
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
+// objectInfo 转换对象元信息
+func objectInfo(key string, stat minio.ObjectInfo) ObjectInfo {
+	return ObjectInfo{
+		Key:         key,
+		Size:        stat.Size,
+		ModTime:     stat.LastModified,
+		ETag:        stat.ETag,
+		ContentType: stat.ContentType,
+	}
+}
+
+// s3Error 将对象不存在错误转换为 ErrNotFound
+func s3Error(err error) error {
+	if minio.ToErrorResponse(err).Code == minio.NoSuchKey {
+		return ErrNotFound
+	}
+	return err
+}
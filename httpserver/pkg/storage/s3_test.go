@@ -0,0 +1,274 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"entry-task/httpserver/config"
+)
+
+// fakeS3 内存中的 S3 兼容服务（只实现单个 bucket 的 PUT/GET/HEAD/DELETE 和不分页的 ListObjectsV2，不校验签名）
+type fakeS3 struct {
+	bucket string
+
+	mu      sync.Mutex
+	objects map[string]fakeObject
+}
+
+type fakeObject struct {
+	data        []byte
+	contentType string
+	modTime     time.Time
+}
+
+func newFakeS3(t *testing.T, bucket string) (*fakeS3, *httptest.Server) {
+	f := &fakeS3{bucket: bucket, objects: map[string]fakeObject{}}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	return f, srv
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if bucket == f.bucket && key == "" && r.Method == http.MethodGet && r.URL.Query().Get("list-type") == "2" {
+		f.list(w, r.URL.Query().Get("prefix"))
+		return
+	}
+	if bucket != f.bucket || key == "" {
+		http.Error(w, "unexpected path "+r.URL.Path, http.StatusBadRequest)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodPut:
+		data, _ := io.ReadAll(r.Body)
+		if strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+			data = decodeAWSChunked(data)
+		}
+		f.objects[key] = fakeObject{data: data, contentType: r.Header.Get("Content-Type"), modTime: time.Now().UTC()}
+		w.Header().Set("ETag", `"`+etag(data)+`"`)
+	case http.MethodGet, http.MethodHead:
+		obj, ok := f.objects[key]
+		if !ok {
+			w.Header().Set("Content-Type", "application/xml")
+			w.WriteHeader(http.StatusNotFound)
+			if r.Method == http.MethodGet {
+				io.WriteString(w, `<?xml version="1.0" encoding="UTF-8"?><Error><Code>NoSuchKey</Code><Message>The specified key does not exist.</Message></Error>`)
+			}
+			return
+		}
+		w.Header().Set("ETag", `"`+etag(obj.data)+`"`)
+		w.Header().Set("Content-Type", obj.contentType)
+		http.ServeContent(w, r, key, obj.modTime, bytes.NewReader(obj.data))
+	case http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// list 返回 ListObjectsV2 结果（全部对象在一页中返回）
+func (f *fakeS3) list(w http.ResponseWriter, prefix string) {
+	type content struct {
+		Key          string
+		LastModified string
+		ETag         string
+		Size         int
+	}
+	result := struct {
+		XMLName     xml.Name `xml:"ListBucketResult"`
+		Name        string
+		Prefix      string
+		KeyCount    int
+		IsTruncated bool
+		Contents    []content
+	}{Name: f.bucket, Prefix: prefix}
+
+	f.mu.Lock()
+	for key, obj := range f.objects {
+		if strings.HasPrefix(key, prefix) {
+			result.Contents = append(result.Contents, content{
+				Key:          key,
+				LastModified: obj.modTime.Format(time.RFC3339Nano),
+				ETag:         `"` + etag(obj.data) + `"`,
+				Size:         len(obj.data),
+			})
+		}
+	}
+	f.mu.Unlock()
+	slices.SortFunc(result.Contents, func(a, b content) int { return strings.Compare(a.Key, b.Key) })
+	result.KeyCount = len(result.Contents)
+
+	w.Header().Set("Content-Type", "application/xml")
+	io.WriteString(w, xml.Header)
+	xml.NewEncoder(w).Encode(result)
+}
+
+// decodeAWSChunked 解码流式签名上传的 aws-chunked 请求体（{hex 长度};chunk-signature=...\r\n{数据}\r\n ...）
+func decodeAWSChunked(body []byte) []byte {
+	var out []byte
+	for {
+		line, rest, ok := bytes.Cut(body, []byte("\r\n"))
+		if !ok {
+			return out
+		}
+		sizeHex, _, _ := bytes.Cut(line, []byte(";"))
+		size, err := strconv.ParseInt(string(sizeHex), 16, 64)
+		if err != nil || size == 0 || int64(len(rest)) < size {
+			return out
+		}
+		out = append(out, rest[:size]...)
+		body = bytes.TrimPrefix(rest[size:], []byte("\r\n"))
+	}
+}
+
+func etag(data []byte) string {
+	sum := md5.Sum(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func newTestS3Storage(t *testing.T) (*fakeS3, *S3Storage) {
+	f, srv := newFakeS3(t, "entry-task")
+	s, err := NewS3Storage(&config.S3StorageConfig{
+		Endpoint:  strings.TrimPrefix(srv.URL, "http://"),
+		Bucket:    "entry-task",
+		AccessKey: "minioadmin",
+		SecretKey: "minioadmin",
+		PathStyle: true,
+	})
+	if err != nil {
+		t.Fatalf("NewS3Storage() 失败: %v", err)
+	}
+	return f, s
+}
+
+// TestS3Storage 测试 S3 存储的读写删除
+func TestS3Storage(t *testing.T) {
+	ctx := context.Background()
+	f, s := newTestS3Storage(t)
+
+	if err := s.Put(ctx, "avatars/1.jpg", []byte("jpeg-data"), "image/jpeg"); err != nil {
+		t.Fatalf("Put() 失败: %v", err)
+	}
+	if obj := f.objects["avatars/1.jpg"]; string(obj.data) != "jpeg-data" || obj.contentType != "image/jpeg" {
+		t.Fatalf("服务端对象 = %q (%s)", obj.data, obj.contentType)
+	}
+
+	info, err := s.Stat(ctx, "avatars/1.jpg")
+	if err != nil {
+		t.Fatalf("Stat() 失败: %v", err)
+	}
+	if info.Key != "avatars/1.jpg" || info.Size != 9 || info.ETag != etag([]byte("jpeg-data")) || info.ContentType != "image/jpeg" {
+		t.Errorf("Stat() = %+v", info)
+	}
+
+	obj, err := s.Get(ctx, "avatars/1.jpg")
+	if err != nil {
+		t.Fatalf("Get() 失败: %v", err)
+	}
+	defer obj.Close()
+	// http.ServeContent 会先 Seek 到末尾取长度再回到开头
+	if _, err := obj.Seek(5, io.SeekStart); err != nil {
+		t.Fatalf("Seek() 失败: %v", err)
+	}
+	if data, _ := io.ReadAll(obj); string(data) != "data" {
+		t.Errorf("Seek 后读取 = %q, 期望 data", data)
+	}
+
+	if err := s.Delete(ctx, "avatars/1.jpg"); err != nil {
+		t.Fatalf("Delete() 失败: %v", err)
+	}
+	if _, err := s.Stat(ctx, "avatars/1.jpg"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("删除后 Stat() 错误 = %v, 期望 ErrNotFound", err)
+	}
+	if _, err := s.Get(ctx, "avatars/1.jpg"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("删除后 Get() 错误 = %v, 期望 ErrNotFound", err)
+	}
+}
+
+// TestS3Storage_URL 测试预签名 URL 可以直接下载对象
+func TestS3Storage_URL(t *testing.T) {
+	ctx := context.Background()
+	_, s := newTestS3Storage(t)
+
+	if err := s.Put(ctx, "avatars/2_128.jpg", []byte("thumb"), "image/jpeg"); err != nil {
+		t.Fatalf("Put() 失败: %v", err)
+	}
+	u, err := s.URL(ctx, "avatars/2_128.jpg")
+	if err != nil {
+		t.Fatalf("URL() 失败: %v", err)
+	}
+	if !strings.Contains(u, "/entry-task/avatars/2_128.jpg?") || !strings.Contains(u, "X-Amz-Signature=") || !strings.Contains(u, "X-Amz-Expires=600") {
+		t.Errorf("URL() = %s, 期望 path-style 预签名地址", u)
+	}
+
+	resp, err := http.Get(u)
+	if err != nil {
+		t.Fatalf("下载失败: %v", err)
+	}
+	defer resp.Body.Close()
+	if data, _ := io.ReadAll(resp.Body); resp.StatusCode != http.StatusOK || string(data) != "thumb" {
+		t.Errorf("下载结果 = %d %q", resp.StatusCode, data)
+	}
+}
+
+// TestS3Storage_List 测试按前缀列出对象
+func TestS3Storage_List(t *testing.T) {
+	ctx := context.Background()
+	_, s := newTestS3Storage(t)
+
+	for _, key := range []string{"avatars/2/b.jpg", "avatars/1/a.jpg", "avatars/1/a_64.jpg", "other/x.jpg"} {
+		if err := s.Put(ctx, key, []byte(key), "image/jpeg"); err != nil {
+			t.Fatalf("Put(%q) 失败: %v", key, err)
+		}
+	}
+
+	want := []string{"avatars/1/a.jpg", "avatars/1/a_64.jpg", "avatars/2/b.jpg"}
+	if keys := listKeys(t, s, "avatars/"); !slices.Equal(keys, want) {
+		t.Errorf("List(avatars/) = %v, 期望 %v", keys, want)
+	}
+
+	var infos []ObjectInfo
+	if err := s.List(ctx, "avatars/2/", func(info ObjectInfo) error {
+		infos = append(infos, info)
+		return nil
+	}); err != nil {
+		t.Fatalf("List() 失败: %v", err)
+	}
+	data := []byte("avatars/2/b.jpg")
+	if len(infos) != 1 || infos[0].Size != int64(len(data)) || infos[0].ETag != etag(data) || infos[0].ModTime.IsZero() {
+		t.Errorf("List(avatars/2/) = %+v", infos)
+	}
+}
+
+// TestNew 测试根据配置创建存储
+func TestNew(t *testing.T) {
+	if s, err := New(&config.StorageConfig{}); err != nil {
+		t.Errorf("默认应为本地存储: %v", err)
+	} else if _, ok := s.(*LocalStorage); !ok {
+		t.Errorf("默认应为本地存储, 实际 %T", s)
+	}
+	if _, err := New(&config.StorageConfig{Type: "s3"}); err == nil {
+		t.Error("S3 存储未配置 endpoint 和 bucket 时应返回错误")
+	}
+	if _, err := New(&config.StorageConfig{Type: "ftp"}); err == nil {
+		t.Error("不支持的存储类型应返回错误")
+	}
+}
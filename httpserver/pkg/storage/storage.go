@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"entry-task/httpserver/config"
+)
+
+// ============================================================================
+// 对象存储
+// ============================================================================
+//
+// 头像等文件按 key（如 avatars/1.jpg）存取，HTTP Server 不依赖本机磁盘：
+// - local：保存在本地目录，适合单实例部署和开发环境
+// - s3：保存在 S3 兼容对象存储（AWS S3、MinIO 等），多个 HTTP Server 实例共享
+
+var (
+	// ErrNotFound 对象不存在
+	ErrNotFound = errors.New("对象不存在")
+	// ErrInvalidKey key 不合法（为空、绝对路径、包含 .. 等）
+	ErrInvalidKey = errors.New("无效的对象 key")
+	// ErrURLNotSupported 存储不支持直接访问地址，需要由 HTTP Server 读取后返回
+	ErrURLNotSupported = errors.New("存储不支持直接访问地址")
+)
+
+// ObjectInfo 对象元信息
+type ObjectInfo struct {
+	Key         string
+	Size        int64
+	ModTime     time.Time
+	ETag        string // 不含引号
+	ContentType string
+}
+
+// Object 读取到的对象，使用后需要关闭
+type Object struct {
+	ObjectInfo
+	io.ReadSeekCloser
+}
+
+// Storage 对象存储接口
+type Storage interface {
+	// Put 写入对象，已存在时整体替换（读取方不会读到写了一半的内容）
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+
+	// Get 读取对象，不存在时返回 ErrNotFound
+	Get(ctx context.Context, key string) (*Object, error)
+
+	// Stat 读取对象元信息，不存在时返回 ErrNotFound
+	Stat(ctx context.Context, key string) (*ObjectInfo, error)
+
+	// Delete 删除对象，不存在时不返回错误
+	Delete(ctx context.Context, key string) error
+
+	// List 按 key 顺序遍历 key 以 prefix 开头的所有对象，fn 返回错误时停止遍历并返回该错误
+	List(ctx context.Context, prefix string, fn func(ObjectInfo) error) error
+
+	// URL 返回可以直接访问对象的临时地址（如 S3 预签名 URL），不支持时返回 ErrURLNotSupported
+	URL(ctx context.Context, key string) (string, error)
+}
+
+// New 根据配置创建存储
+func New(cfg *config.StorageConfig) (Storage, error) {
+	switch cfg.GetType() {
+	case config.StorageTypeLocal:
+		return NewLocalStorage(cfg.Local.GetRoot()), nil
+	case config.StorageTypeS3:
+		return NewS3Storage(&cfg.S3)
+	default:
+		return nil, fmt.Errorf("不支持的存储类型: %s", cfg.Type)
+	}
+}
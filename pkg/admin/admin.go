@@ -0,0 +1,64 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/zap"
+
+	log "entry-task/pkg/logger"
+)
+
+// ============================================================================
+// 管理端口（Prometheus /metrics）
+// ============================================================================
+
+//
+// HTTP Server 与 TCP Server 共用，各自的进程级指标（如连接池）在启动前注册到默认 Registry
+
+// shutdownTimeout 关闭管理端口的超时时间
+const shutdownTimeout = 5 * time.Second
+
+// Handler 管理端口的路由
+func Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	return mux
+}
+
+// Start 在管理端口上启动 /metrics，addr 为空时不启动（返回 nil）
+func Start(addr string) *http.Server {
+	if addr == "" {
+		return nil
+	}
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           Handler(),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		log.Info("管理端口启动成功", zap.String("addr", addr))
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error("管理端口启动失败", zap.String("addr", addr), zap.Error(err))
+		}
+	}()
+	return server
+}
+
+// Stop 关闭管理端口，server 为 nil 时直接返回
+func Stop(server *http.Server) {
+	if server == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		log.Error("关闭管理端口失败", zap.Error(err))
+	}
+}
@@ -0,0 +1,35 @@
+package admin
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// TestHandler 测试 /metrics 输出注册到默认 Registry 的指标
+func TestHandler(t *testing.T) {
+	promauto.NewCounter(prometheus.CounterOpts{Name: "admin_test_total", Help: "测试指标"}).Inc()
+
+	w := httptest.NewRecorder()
+	Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("状态码 = %d, 期望 200", w.Code)
+	}
+	body, _ := io.ReadAll(w.Body)
+	if !strings.Contains(string(body), "admin_test_total 1") {
+		t.Errorf("/metrics 缺少已注册的指标:\n%s", body)
+	}
+}
+
+// TestStart_Disabled 测试未配置管理端口时不启动
+func TestStart_Disabled(t *testing.T) {
+	if server := Start(""); server != nil {
+		t.Fatal("地址为空时不应启动管理端口")
+	}
+	Stop(nil)
+}
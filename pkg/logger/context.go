@@ -0,0 +1,102 @@
+package logger
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// RequestIDField 请求ID在日志中的字段名
+const RequestIDField = "request_id"
+
+// ctxKey context 中日志信息的 key
+type ctxKey struct{}
+
+// ctxLog 绑定到 context 的日志信息
+type ctxLog struct {
+	requestID string
+	fields    []zap.Field
+}
+
+// WithRequestID 将请求ID绑定到 ctx，之后 *Ctx 系列函数输出的日志都会携带该ID
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	l := fromContext(ctx)
+	fields := make([]zap.Field, 0, len(l.fields)+1)
+	fields = append(fields, zap.String(RequestIDField, requestID))
+	for _, f := range l.fields {
+		if f.Key != RequestIDField {
+			fields = append(fields, f)
+		}
+	}
+	return context.WithValue(ctx, ctxKey{}, &ctxLog{requestID: requestID, fields: fields})
+}
+
+// WithFields 将字段绑定到 ctx（如 user_id），之后 *Ctx 系列函数输出的日志都会携带这些字段
+func WithFields(ctx context.Context, fields ...zap.Field) context.Context {
+	l := fromContext(ctx)
+	merged := make([]zap.Field, 0, len(l.fields)+len(fields))
+	merged = append(merged, l.fields...)
+	merged = append(merged, fields...)
+	return context.WithValue(ctx, ctxKey{}, &ctxLog{requestID: l.requestID, fields: merged})
+}
+
+// RequestIDFromContext 获取 ctx 中的请求ID，没有时返回空字符串
+func RequestIDFromContext(ctx context.Context) string {
+	return fromContext(ctx).requestID
+}
+
+// FromContext 获取携带 ctx 中字段的 Logger（ctx 中没有字段时返回全局 Logger）
+func FromContext(ctx context.Context) *zap.Logger {
+	// 全局 Logger 为包装函数跳过了一层调用栈，直接使用时需要还原
+	logger := Logger.WithOptions(zap.AddCallerSkip(-1))
+	if l := fromContext(ctx); len(l.fields) > 0 {
+		logger = logger.With(l.fields...)
+	}
+	return logger
+}
+
+// fromContext 获取 ctx 中的日志信息，没有时返回空值
+func fromContext(ctx context.Context) *ctxLog {
+	if ctx != nil {
+		if l, ok := ctx.Value(ctxKey{}).(*ctxLog); ok {
+			return l
+		}
+	}
+	return &ctxLog{}
+}
+
+// withContextFields 在日志字段前加上 ctx 中绑定的字段
+func withContextFields(ctx context.Context, fields []zap.Field) []zap.Field {
+	l := fromContext(ctx)
+	if len(l.fields) == 0 {
+		return fields
+	}
+	all := make([]zap.Field, 0, len(l.fields)+len(fields))
+	all = append(all, l.fields...)
+	return append(all, fields...)
+}
+
+// InfoCtx 记录 Info 级别日志（携带 ctx 中的请求ID等字段）
+func InfoCtx(ctx context.Context, msg string, fields ...zap.Field) {
+	Logger.Info(msg, withContextFields(ctx, fields)...)
+}
+
+// WarnCtx 记录 Warn 级别日志（携带 ctx 中的请求ID等字段）
+func WarnCtx(ctx context.Context, msg string, fields ...zap.Field) {
+	Logger.Warn(msg, withContextFields(ctx, fields)...)
+}
+
+// ErrorCtx 记录 Error 级别日志（携带 ctx 中的请求ID等字段）
+func ErrorCtx(ctx context.Context, msg string, fields ...zap.Field) {
+	Logger.Error(msg, withContextFields(ctx, fields)...)
+}
+
+// DebugCtx 记录 Debug 级别日志（携带 ctx 中的请求ID等字段）
+func DebugCtx(ctx context.Context, msg string, fields ...zap.Field) {
+	// Debug 日志通常关闭，避免无谓地拼接字段
+	if !Logger.Core().Enabled(zapcore.DebugLevel) {
+		return
+	}
+	Logger.Debug(msg, withContextFields(ctx, fields)...)
+}
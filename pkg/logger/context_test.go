@@ -0,0 +1,86 @@
+package logger
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// observe 将全局 Logger 替换为可观察的 Logger，测试结束后还原
+func observe(t *testing.T, level zapcore.Level) *observer.ObservedLogs {
+	core, logs := observer.New(level)
+	old := Logger
+	Logger = zap.New(core)
+	t.Cleanup(func() { Logger = old })
+	return logs
+}
+
+// TestWithRequestID 测试 *Ctx 系列函数输出 ctx 中的请求ID和字段
+func TestWithRequestID(t *testing.T) {
+	logs := observe(t, zapcore.DebugLevel)
+
+	ctx := WithRequestID(context.Background(), "req-1")
+	ctx = WithFields(ctx, zap.Uint64("user_id", 42))
+	if got := RequestIDFromContext(ctx); got != "req-1" {
+		t.Errorf("请求ID期望 req-1, 实际 %s", got)
+	}
+
+	InfoCtx(ctx, "hello", zap.String("k", "v"))
+	DebugCtx(ctx, "debug")
+
+	entries := logs.All()
+	if len(entries) != 2 {
+		t.Fatalf("期望 2 条日志, 实际 %d", len(entries))
+	}
+	fields := entries[0].ContextMap()
+	if fields[RequestIDField] != "req-1" || fields["user_id"] != uint64(42) || fields["k"] != "v" {
+		t.Errorf("日志字段不正确: %v", fields)
+	}
+}
+
+// TestWithRequestID_Replace 测试重复绑定请求ID时只保留最新的
+func TestWithRequestID_Replace(t *testing.T) {
+	logs := observe(t, zapcore.InfoLevel)
+
+	ctx := WithRequestID(context.Background(), "old")
+	ctx = WithFields(ctx, zap.String("k", "v"))
+	ctx = WithRequestID(ctx, "new")
+	InfoCtx(ctx, "hello")
+
+	entry := logs.All()[0]
+	n := 0
+	for _, f := range entry.Context {
+		if f.Key == RequestIDField {
+			n++
+		}
+	}
+	if n != 1 || entry.ContextMap()[RequestIDField] != "new" || entry.ContextMap()["k"] != "v" {
+		t.Errorf("日志字段不正确: %v", entry.Context)
+	}
+}
+
+// TestCtx_NoFields 测试 ctx 中没有绑定字段时与全局函数一致
+func TestCtx_NoFields(t *testing.T) {
+	logs := observe(t, zapcore.InfoLevel)
+
+	if RequestIDFromContext(context.Background()) != "" {
+		t.Error("空 ctx 不应有请求ID")
+	}
+	WarnCtx(context.Background(), "plain")
+	DebugCtx(context.Background(), "filtered")
+	FromContext(WithRequestID(context.Background(), "req-2")).Info("direct")
+
+	entries := logs.All()
+	if len(entries) != 2 {
+		t.Fatalf("期望 2 条日志（Debug 被过滤）, 实际 %d", len(entries))
+	}
+	if len(entries[0].Context) != 0 {
+		t.Errorf("不应有额外字段: %v", entries[0].Context)
+	}
+	if entries[1].ContextMap()[RequestIDField] != "req-2" {
+		t.Errorf("FromContext 返回的 Logger 应携带请求ID: %v", entries[1].Context)
+	}
+}
@@ -0,0 +1,50 @@
+package requestid
+
+import "github.com/google/uuid"
+
+// ============================================================================
+// 请求ID
+// ============================================================================
+//
+// HTTP Server 接受客户端传入的 X-Request-ID（不合法时生成），通过 gRPC metadata 转发给 TCP Server，
+// 两个服务中同一请求的日志都携带同一个 request_id。
+
+const (
+	// Header HTTP 请求/响应中的请求ID header
+	Header = "X-Request-ID"
+
+	// MetadataKey gRPC metadata 中的请求ID
+	MetadataKey = "x-request-id"
+
+	// MaxLen 外部传入的请求ID最大长度
+	MaxLen = 128
+)
+
+// New 生成请求ID
+func New() string {
+	return uuid.NewString()
+}
+
+// Valid 请求ID只允许字母、数字和 -_.: 且长度不超过 MaxLen（避免日志注入）
+func Valid(id string) bool {
+	if id == "" || len(id) > MaxLen {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// OrNew 合法时返回 id，否则生成新的请求ID
+func OrNew(id string) string {
+	if Valid(id) {
+		return id
+	}
+	return New()
+}
@@ -0,0 +1,43 @@
+package requestid
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestValid 测试请求ID校验
+func TestValid(t *testing.T) {
+	tests := []struct {
+		id   string
+		want bool
+	}{
+		{"req-1", true},
+		{"9f0c2a4e-1b2c-4d5e-8f90-a1b2c3d4e5f6", true},
+		{"svc:req_1.2", true},
+		{strings.Repeat("a", MaxLen), true},
+		{"", false},
+		{strings.Repeat("a", MaxLen+1), false},
+		{"req-1\nlevel=error", false},
+		{"req 1", false},
+		{"请求", false},
+	}
+	for _, tt := range tests {
+		if got := Valid(tt.id); got != tt.want {
+			t.Errorf("Valid(%q) = %v, 期望 %v", tt.id, got, tt.want)
+		}
+	}
+}
+
+// TestOrNew 测试不合法的请求ID被替换
+func TestOrNew(t *testing.T) {
+	if got := OrNew("req-1"); got != "req-1" {
+		t.Errorf("合法的请求ID应保留, 实际 %q", got)
+	}
+	got := OrNew("bad id")
+	if got == "bad id" || !Valid(got) {
+		t.Errorf("不合法的请求ID应重新生成, 实际 %q", got)
+	}
+	if OrNew("") == OrNew("") {
+		t.Error("每次生成的请求ID应不同")
+	}
+}
@@ -0,0 +1,157 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// ============================================================================
+// 链路追踪（OpenTelemetry）
+// ============================================================================
+//
+// 跨进程通过 gRPC metadata 传递 W3C Trace Context（traceparent），
+// HTTP Server、TCP Server、Redis 和 SQL 的 Span 属于同一条链路。
+// HTTP Server 和 TCP Server 共用本包，服务名由各自的配置决定。
+
+// 导出方式
+const (
+	ExporterNone   = "none"   // 不导出（默认），仍然传递上游的 Trace Context
+	ExporterOTLP   = "otlp"   // OTLP/gRPC 导出到 Collector
+	ExporterStdout = "stdout" // 输出到标准输出（调试用）
+	ExporterFile   = "file"   // 以 JSON 写入文件（测试用）
+)
+
+// Config 链路追踪配置
+type Config struct {
+	ServiceName string      // 服务名（resource 的 service.name）
+	Exporter    string      // none, otlp, stdout, file
+	Endpoint    string      // OTLP Collector 地址（host:port），未配置时使用 localhost:4317
+	Insecure    bool        // OTLP 不使用 TLS
+	FilePath    string      // file 导出方式的文件路径
+	SampleRatio *float64    // 根 Span 采样比例（0~1），0 表示不采样，nil 表示全部采样；有上游 Span 时跟随上游的采样决定
+	Logger      *zap.Logger // 记录初始化结果和导出错误，未配置时不输出
+}
+
+// ShutdownFunc 导出剩余 Span 并关闭导出器
+type ShutdownFunc func(ctx context.Context) error
+
+// Init 初始化全局 TracerProvider 和 W3C Trace Context 传播器
+func Init(cfg *Config) (ShutdownFunc, error) {
+	logger := cfg.Logger
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		logger.Warn("链路追踪错误", zap.Error(err))
+	}))
+
+	exporter, closeExporter, err := newExporter(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if exporter == nil {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(cfg.ServiceName),
+	))
+	if err != nil {
+		_ = closeExporter()
+		return nil, fmt.Errorf("创建链路追踪资源失败: %w", err)
+	}
+
+	ratio := 1.0
+	if cfg.SampleRatio != nil {
+		ratio = min(max(*cfg.SampleRatio, 0), 1)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+	)
+	otel.SetTracerProvider(tp)
+
+	logger.Info("链路追踪初始化成功",
+		zap.String("exporter", cfg.Exporter),
+		zap.String("service", cfg.ServiceName),
+		zap.Float64("sample_ratio", ratio),
+	)
+
+	return func(ctx context.Context) error {
+		return errors.Join(tp.Shutdown(ctx), closeExporter())
+	}, nil
+}
+
+// newExporter 按配置创建导出器，不导出时返回 nil
+// 第二个返回值用于关闭导出器打开的资源（文件）
+func newExporter(cfg *Config) (sdktrace.SpanExporter, func() error, error) {
+	noClose := func() error { return nil }
+
+	switch cfg.Exporter {
+	case "", ExporterNone:
+		return nil, noClose, nil
+
+	case ExporterOTLP:
+		opts := []otlptracegrpc.Option{}
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlptracegrpc.WithEndpoint(cfg.Endpoint))
+		}
+		if cfg.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		// 不等待连接建立，Collector 不可用时只丢弃 Span
+		exporter, err := otlptracegrpc.New(context.Background(), opts...)
+		if err != nil {
+			return nil, nil, fmt.Errorf("创建 OTLP 导出器失败: %w", err)
+		}
+		return exporter, noClose, nil
+
+	case ExporterStdout:
+		exporter, err := stdouttrace.New(stdouttrace.WithPrettyPrint())
+		if err != nil {
+			return nil, nil, fmt.Errorf("创建 stdout 导出器失败: %w", err)
+		}
+		return exporter, noClose, nil
+
+	case ExporterFile:
+		file, err := os.OpenFile(cfg.FilePath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			return nil, nil, fmt.Errorf("打开链路追踪文件失败: %w", err)
+		}
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(file))
+		if err != nil {
+			_ = file.Close()
+			return nil, nil, fmt.Errorf("创建文件导出器失败: %w", err)
+		}
+		return exporter, file.Close, nil
+
+	default:
+		return nil, nil, fmt.Errorf("不支持的链路追踪导出方式: %s", cfg.Exporter)
+	}
+}
+
+// HasParent ctx 中是否有正在进行的 Span
+// Redis、SQL 等底层调用只在请求链路中创建 Span，避免后台轮询产生大量孤立的根 Span
+func HasParent(ctx context.Context) bool {
+	return trace.SpanContextFromContext(ctx).IsValid()
+}
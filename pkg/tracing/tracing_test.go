@@ -0,0 +1,67 @@
+package tracing
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TestInit_FileExporter 测试 file 导出方式在关闭时写出 Span
+func TestInit_FileExporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traces.json")
+	shutdown, err := Init(&Config{ServiceName: "tracing-test", Exporter: ExporterFile, FilePath: path})
+	if err != nil {
+		t.Fatalf("初始化失败: %v", err)
+	}
+
+	ctx, span := otel.Tracer("test").Start(context.Background(), "test-span")
+	if !HasParent(ctx) {
+		t.Error("Start 之后 ctx 中应有 Span")
+	}
+	span.End()
+
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("关闭失败: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("读取文件失败: %v", err)
+	}
+	if !strings.Contains(string(data), "test-span") || !strings.Contains(string(data), "tracing-test") {
+		t.Errorf("文件中缺少 Span 或服务名: %s", data)
+	}
+}
+
+// TestInit_Propagator 测试通过 W3C traceparent 传递 Trace Context
+func TestInit_Propagator(t *testing.T) {
+	shutdown, err := Init(&Config{Exporter: ExporterNone})
+	if err != nil {
+		t.Fatalf("初始化失败: %v", err)
+	}
+	defer shutdown(context.Background())
+
+	carrier := propagation.MapCarrier{
+		"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	}
+	ctx := otel.GetTextMapPropagator().Extract(context.Background(), carrier)
+	sc := trace.SpanContextFromContext(ctx)
+	if !HasParent(ctx) || sc.TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("提取的 Trace Context 不正确: %v", sc)
+	}
+	if HasParent(context.Background()) {
+		t.Error("空 ctx 不应有 Span")
+	}
+}
+
+// TestInit_UnknownExporter 测试不支持的导出方式
+func TestInit_UnknownExporter(t *testing.T) {
+	if _, err := Init(&Config{Exporter: "zipkin"}); err == nil {
+		t.Error("不支持的导出方式应返回错误")
+	}
+}
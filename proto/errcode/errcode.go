@@ -0,0 +1,78 @@
+package errcode
+
+import (
+	"net/http"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// UnknownMessage 未定义的错误码的消息
+const UnknownMessage = "未知错误"
+
+// httpStatuses、messages 首次使用时从 errcode.proto 的枚举选项中读取
+// （不能在 init 中读取：本文件的 init 先于 errcode.pb.go 中的描述符初始化执行）
+var (
+	loadOnce     sync.Once
+	httpStatuses = map[Code]int{}
+	messages     = map[Code]string{}
+)
+
+// load 读取错误码的枚举选项
+func load() {
+	values := Code(0).Descriptor().Values()
+	for i := 0; i < values.Len(); i++ {
+		v := values.Get(i)
+		code := Code(v.Number())
+		httpStatuses[code] = int(proto.GetExtension(v.Options(), E_HttpStatus).(int32))
+		messages[code] = proto.GetExtension(v.Options(), E_Message).(string)
+	}
+}
+
+// HTTPStatus 返回错误码对应的 HTTP 状态码，未定义的错误码返回 500
+func (c Code) HTTPStatus() int {
+	loadOnce.Do(load)
+	if status, ok := httpStatuses[c]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// Message 返回错误码的默认消息
+func (c Code) Message() string {
+	loadOnce.Do(load)
+	if msg, ok := messages[c]; ok {
+		return msg
+	}
+	return UnknownMessage
+}
+
+// FromReason 返回错误原因枚举值（如 user.v2.ErrorReason）通过 (errcode.code) 选项指定的错误码
+// 未指定时 ok 为 false
+func FromReason(reason protoreflect.Enum) (code Code, ok bool) {
+	opts, ok := reasonOptions(reason)
+	if !ok || !proto.HasExtension(opts, E_Code) {
+		return Code_SUCCESS, false
+	}
+	return proto.GetExtension(opts, E_Code).(Code), true
+}
+
+// V1CodeFromReason 返回错误原因在 v1 响应体中的错误码（(errcode.v1_code) 选项）
+// 未指定时 ok 为 false
+func V1CodeFromReason(reason protoreflect.Enum) (code int32, ok bool) {
+	opts, ok := reasonOptions(reason)
+	if !ok || !proto.HasExtension(opts, E_V1Code) {
+		return 0, false
+	}
+	return proto.GetExtension(opts, E_V1Code).(int32), true
+}
+
+// reasonOptions 返回枚举值的选项，未定义的枚举值 ok 为 false
+func reasonOptions(reason protoreflect.Enum) (proto.Message, bool) {
+	v := reason.Descriptor().Values().ByNumber(reason.Number())
+	if v == nil {
+		return nil, false
+	}
+	return v.Options(), true
+}
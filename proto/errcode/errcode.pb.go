@@ -0,0 +1,294 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v6.33.1
+// source: proto/errcode/errcode.proto
+
+package errcode
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	descriptorpb "google.golang.org/protobuf/types/descriptorpb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// 错误码
+type Code int32
+
+const (
+	Code_SUCCESS Code = 0
+	// 客户端错误 (400xx)
+	Code_BAD_REQUEST           Code = 40000
+	Code_INVALID_PARAMS        Code = 40001
+	Code_INVALID_FORMAT        Code = 40002
+	Code_INVALID_FILE          Code = 40005
+	Code_FILE_TOO_LARGE        Code = 40006
+	Code_UNSUPPORTED_FILE_TYPE Code = 40007
+	// 认证错误 (401xx)
+	Code_UNAUTHORIZED                Code = 40100
+	Code_INVALID_TOKEN               Code = 40101
+	Code_TOKEN_EXPIRED               Code = 40102
+	Code_INVALID_ACCOUNT_OR_PASSWORD Code = 40103
+	Code_INVALID_NICKNAME            Code = 40104 // 历史编号，实际为参数错误
+	// 权限错误 (403xx)
+	Code_FORBIDDEN     Code = 40300
+	Code_ACCESS_DENIED Code = 40301
+	// 资源错误 (404xx)
+	Code_NOT_FOUND         Code = 40400
+	Code_USER_NOT_FOUND    Code = 40401
+	Code_SESSION_NOT_FOUND Code = 40402
+	// 业务冲突 (409xx)
+	Code_CONFLICT        Code = 40900
+	Code_USER_EXISTS     Code = 40901
+	Code_USERNAME_EXISTS Code = 40902
+	// 限流错误 (429xx)
+	Code_TOO_MANY_REQUESTS Code = 42900
+	// 服务端错误 (500xx)
+	Code_INTERNAL_SERVER_ERROR Code = 50000
+	Code_DATABASE_ERROR        Code = 50001
+	Code_RPC_ERROR             Code = 50002
+	Code_REDIS_ERROR           Code = 50003
+	Code_SERVICE_UNAVAILABLE   Code = 50004
+	Code_REQUEST_TIMEOUT       Code = 50005
+)
+
+// Enum value maps for Code.
+var (
+	Code_name = map[int32]string{
+		0:     "SUCCESS",
+		40000: "BAD_REQUEST",
+		40001: "INVALID_PARAMS",
+		40002: "INVALID_FORMAT",
+		40005: "INVALID_FILE",
+		40006: "FILE_TOO_LARGE",
+		40007: "UNSUPPORTED_FILE_TYPE",
+		40100: "UNAUTHORIZED",
+		40101: "INVALID_TOKEN",
+		40102: "TOKEN_EXPIRED",
+		40103: "INVALID_ACCOUNT_OR_PASSWORD",
+		40104: "INVALID_NICKNAME",
+		40300: "FORBIDDEN",
+		40301: "ACCESS_DENIED",
+		40400: "NOT_FOUND",
+		40401: "USER_NOT_FOUND",
+		40402: "SESSION_NOT_FOUND",
+		40900: "CONFLICT",
+		40901: "USER_EXISTS",
+		40902: "USERNAME_EXISTS",
+		42900: "TOO_MANY_REQUESTS",
+		50000: "INTERNAL_SERVER_ERROR",
+		50001: "DATABASE_ERROR",
+		50002: "RPC_ERROR",
+		50003: "REDIS_ERROR",
+		50004: "SERVICE_UNAVAILABLE",
+		50005: "REQUEST_TIMEOUT",
+	}
+	Code_value = map[string]int32{
+		"SUCCESS":                     0,
+		"BAD_REQUEST":                 40000,
+		"INVALID_PARAMS":              40001,
+		"INVALID_FORMAT":              40002,
+		"INVALID_FILE":                40005,
+		"FILE_TOO_LARGE":              40006,
+		"UNSUPPORTED_FILE_TYPE":       40007,
+		"UNAUTHORIZED":                40100,
+		"INVALID_TOKEN":               40101,
+		"TOKEN_EXPIRED":               40102,
+		"INVALID_ACCOUNT_OR_PASSWORD": 40103,
+		"INVALID_NICKNAME":            40104,
+		"FORBIDDEN":                   40300,
+		"ACCESS_DENIED":               40301,
+		"NOT_FOUND":                   40400,
+		"USER_NOT_FOUND":              40401,
+		"SESSION_NOT_FOUND":           40402,
+		"CONFLICT":                    40900,
+		"USER_EXISTS":                 40901,
+		"USERNAME_EXISTS":             40902,
+		"TOO_MANY_REQUESTS":           42900,
+		"INTERNAL_SERVER_ERROR":       50000,
+		"DATABASE_ERROR":              50001,
+		"RPC_ERROR":                   50002,
+		"REDIS_ERROR":                 50003,
+		"SERVICE_UNAVAILABLE":         50004,
+		"REQUEST_TIMEOUT":             50005,
+	}
+)
+
+func (x Code) Enum() *Code {
+	p := new(Code)
+	*p = x
+	return p
+}
+
+func (x Code) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Code) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_errcode_errcode_proto_enumTypes[0].Descriptor()
+}
+
+func (Code) Type() protoreflect.EnumType {
+	return &file_proto_errcode_errcode_proto_enumTypes[0]
+}
+
+func (x Code) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Code.Descriptor instead.
+func (Code) EnumDescriptor() ([]byte, []int) {
+	return file_proto_errcode_errcode_proto_rawDescGZIP(), []int{0}
+}
+
+var file_proto_errcode_errcode_proto_extTypes = []protoimpl.ExtensionInfo{
+	{
+		ExtendedType:  (*descriptorpb.EnumValueOptions)(nil),
+		ExtensionType: (*int32)(nil),
+		Field:         50001,
+		Name:          "errcode.http_status",
+		Tag:           "varint,50001,opt,name=http_status",
+		Filename:      "proto/errcode/errcode.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.EnumValueOptions)(nil),
+		ExtensionType: (*string)(nil),
+		Field:         50002,
+		Name:          "errcode.message",
+		Tag:           "bytes,50002,opt,name=message",
+		Filename:      "proto/errcode/errcode.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.EnumValueOptions)(nil),
+		ExtensionType: (*Code)(nil),
+		Field:         50003,
+		Name:          "errcode.code",
+		Tag:           "varint,50003,opt,name=code,enum=errcode.Code",
+		Filename:      "proto/errcode/errcode.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.EnumValueOptions)(nil),
+		ExtensionType: (*int32)(nil),
+		Field:         50004,
+		Name:          "errcode.v1_code",
+		Tag:           "varint,50004,opt,name=v1_code",
+		Filename:      "proto/errcode/errcode.proto",
+	},
+}
+
+// Extension fields to descriptorpb.EnumValueOptions.
+var (
+	// optional int32 http_status = 50001;
+	E_HttpStatus = &file_proto_errcode_errcode_proto_extTypes[0] // Code 对应的 HTTP 状态码
+	// optional string message = 50002;
+	E_Message = &file_proto_errcode_errcode_proto_extTypes[1] // Code 的默认消息
+	// optional errcode.Code code = 50003;
+	E_Code = &file_proto_errcode_errcode_proto_extTypes[2] // 错误原因（如 user.v2.ErrorReason）对应的错误码
+	// optional int32 v1_code = 50004;
+	E_V1Code = &file_proto_errcode_errcode_proto_extTypes[3] // 错误原因在 user.UserService（v1）响应体中的错误码，仅为兼容保留
+)
+
+var File_proto_errcode_errcode_proto protoreflect.FileDescriptor
+
+const file_proto_errcode_errcode_proto_rawDesc = "" +
+	"\n" +
+	"\x1bproto/errcode/errcode.proto\x12\aerrcode\x1a google/protobuf/descriptor.proto*\xaa\n" +
+	"\n" +
+	"\x04Code\x12\x18\n" +
+	"\aSUCCESS\x10\x00\x1a\v\x88\xb5\x18\xc8\x01\x92\xb5\x18\x02OK\x12.\n" +
+	"\vBAD_REQUEST\x10\xc0\xb8\x02\x1a\x1b\x88\xb5\x18\x90\x03\x92\xb5\x18\x12请求参数错误\x121\n" +
+	"\x0eINVALID_PARAMS\x10\xc1\xb8\x02\x1a\x1b\x88\xb5\x18\x90\x03\x92\xb5\x18\x12参数验证失败\x12+\n" +
+	"\x0eINVALID_FORMAT\x10¸\x02\x1a\x15\x88\xb5\x18\x90\x03\x92\xb5\x18\f格式错误\x12)\n" +
+	"\fINVALID_FILE\x10Ÿ\x02\x1a\x15\x88\xb5\x18\x90\x03\x92\xb5\x18\f无效文件\x12+\n" +
+	"\x0eFILE_TOO_LARGE\x10Ƹ\x02\x1a\x15\x88\xb5\x18\x90\x03\x92\xb5\x18\f文件过大\x12>\n" +
+	"\x15UNSUPPORTED_FILE_TYPE\x10Ǹ\x02\x1a!\x88\xb5\x18\x90\x03\x92\xb5\x18\x18不支持的文件类型\x12&\n" +
+	"\fUNAUTHORIZED\x10\xa4\xb9\x02\x1a\x12\x88\xb5\x18\x91\x03\x92\xb5\x18\t未认证\x12)\n" +
+	"\rINVALID_TOKEN\x10\xa5\xb9\x02\x1a\x14\x88\xb5\x18\x91\x03\x92\xb5\x18\vToken无效\x12,\n" +
+	"\rTOKEN_EXPIRED\x10\xa6\xb9\x02\x1a\x17\x88\xb5\x18\x91\x03\x92\xb5\x18\x0eToken已过期\x12D\n" +
+	"\x1bINVALID_ACCOUNT_OR_PASSWORD\x10\xa7\xb9\x02\x1a!\x88\xb5\x18\x91\x03\x92\xb5\x18\x18用户名或密码错误\x120\n" +
+	"\x10INVALID_NICKNAME\x10\xa8\xb9\x02\x1a\x18\x88\xb5\x18\x90\x03\x92\xb5\x18\x0f无效的昵称\x12#\n" +
+	"\tFORBIDDEN\x10\xec\xba\x02\x1a\x12\x88\xb5\x18\x93\x03\x92\xb5\x18\t无权限\x12-\n" +
+	"\rACCESS_DENIED\x10\xed\xba\x02\x1a\x18\x88\xb5\x18\x93\x03\x92\xb5\x18\x0f访问被拒绝\x12)\n" +
+	"\tNOT_FOUND\x10л\x02\x1a\x18\x88\xb5\x18\x94\x03\x92\xb5\x18\x0f资源不存在\x12.\n" +
+	"\x0eUSER_NOT_FOUND\x10ѻ\x02\x1a\x18\x88\xb5\x18\x94\x03\x92\xb5\x18\x0f用户不存在\x122\n" +
+	"\x11SESSION_NOT_FOUND\x10һ\x02\x1a\x19\x88\xb5\x18\x94\x03\x92\xb5\x18\x10Session不存在\x12%\n" +
+	"\bCONFLICT\x10Ŀ\x02\x1a\x15\x88\xb5\x18\x99\x03\x92\xb5\x18\f资源冲突\x12+\n" +
+	"\vUSER_EXISTS\x10ſ\x02\x1a\x18\x88\xb5\x18\x99\x03\x92\xb5\x18\x0f用户已存在\x122\n" +
+	"\x0fUSERNAME_EXISTS\x10ƿ\x02\x1a\x1b\x88\xb5\x18\x99\x03\x92\xb5\x18\x12用户名已存在\x12F\n" +
+	"\x11TOO_MANY_REQUESTS\x10\x94\xcf\x02\x1a-\x88\xb5\x18\xad\x03\x92\xb5\x18$请求过于频繁，请稍后再试\x12;\n" +
+	"\x15INTERNAL_SERVER_ERROR\x10І\x03\x1a\x1e\x88\xb5\x18\xf4\x03\x92\xb5\x18\x15服务器内部错误\x12.\n" +
+	"\x0eDATABASE_ERROR\x10ц\x03\x1a\x18\x88\xb5\x18\xf4\x03\x92\xb5\x18\x0f数据库错误\x12)\n" +
+	"\tRPC_ERROR\x10҆\x03\x1a\x18\x88\xb5\x18\xf4\x03\x92\xb5\x18\x0fRPC调用错误\x12'\n" +
+	"\vREDIS_ERROR\x10ӆ\x03\x1a\x14\x88\xb5\x18\xf4\x03\x92\xb5\x18\vRedis错误\x123\n" +
+	"\x13SERVICE_UNAVAILABLE\x10Ԇ\x03\x1a\x18\x88\xb5\x18\xf7\x03\x92\xb5\x18\x0f服务不可用\x12>\n" +
+	"\x0fREQUEST_TIMEOUT\x10Ն\x03\x1a'\x88\xb5\x18\xf8\x03\x92\xb5\x18\x1e请求超时，请稍后重试:D\n" +
+	"\vhttp_status\x12!.google.protobuf.EnumValueOptions\x18ц\x03 \x01(\x05R\n" +
+	"httpStatus:=\n" +
+	"\amessage\x12!.google.protobuf.EnumValueOptions\x18҆\x03 \x01(\tR\amessage:F\n" +
+	"\x04code\x12!.google.protobuf.EnumValueOptions\x18ӆ\x03 \x01(\x0e2\r.errcode.CodeR\x04code:<\n" +
+	"\av1_code\x12!.google.protobuf.EnumValueOptions\x18Ԇ\x03 \x01(\x05R\x06v1CodeB\x1aZ\x18entry-task/proto/errcodeb\x06proto3"
+
+var (
+	file_proto_errcode_errcode_proto_rawDescOnce sync.Once
+	file_proto_errcode_errcode_proto_rawDescData []byte
+)
+
+func file_proto_errcode_errcode_proto_rawDescGZIP() []byte {
+	file_proto_errcode_errcode_proto_rawDescOnce.Do(func() {
+		file_proto_errcode_errcode_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_proto_errcode_errcode_proto_rawDesc), len(file_proto_errcode_errcode_proto_rawDesc)))
+	})
+	return file_proto_errcode_errcode_proto_rawDescData
+}
+
+var file_proto_errcode_errcode_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_proto_errcode_errcode_proto_goTypes = []any{
+	(Code)(0),                             // 0: errcode.Code
+	(*descriptorpb.EnumValueOptions)(nil), // 1: google.protobuf.EnumValueOptions
+}
+var file_proto_errcode_errcode_proto_depIdxs = []int32{
+	1, // 0: errcode.http_status:extendee -> google.protobuf.EnumValueOptions
+	1, // 1: errcode.message:extendee -> google.protobuf.EnumValueOptions
+	1, // 2: errcode.code:extendee -> google.protobuf.EnumValueOptions
+	1, // 3: errcode.v1_code:extendee -> google.protobuf.EnumValueOptions
+	0, // 4: errcode.code:type_name -> errcode.Code
+	5, // [5:5] is the sub-list for method output_type
+	5, // [5:5] is the sub-list for method input_type
+	4, // [4:5] is the sub-list for extension type_name
+	0, // [0:4] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_proto_errcode_errcode_proto_init() }
+func file_proto_errcode_errcode_proto_init() {
+	if File_proto_errcode_errcode_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_errcode_errcode_proto_rawDesc), len(file_proto_errcode_errcode_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   0,
+			NumExtensions: 4,
+			NumServices:   0,
+		},
+		GoTypes:           file_proto_errcode_errcode_proto_goTypes,
+		DependencyIndexes: file_proto_errcode_errcode_proto_depIdxs,
+		EnumInfos:         file_proto_errcode_errcode_proto_enumTypes,
+		ExtensionInfos:    file_proto_errcode_errcode_proto_extTypes,
+	}.Build()
+	File_proto_errcode_errcode_proto = out.File
+	file_proto_errcode_errcode_proto_goTypes = nil
+	file_proto_errcode_errcode_proto_depIdxs = nil
+}
@@ -0,0 +1,115 @@
+package errcode_test
+
+import (
+	"testing"
+
+	"entry-task/proto/errcode"
+	userv2 "entry-task/proto/user/v2"
+)
+
+// 已发布的错误码对照表
+// 修改 errcode.proto 中已有的编号、HTTP 状态码或删除错误码都会导致测试失败；新增错误码需同时加入此表
+var publishedCodes = []struct {
+	code       errcode.Code
+	value      int
+	httpStatus int
+}{
+	{errcode.Code_SUCCESS, 0, 200},
+	{errcode.Code_BAD_REQUEST, 40000, 400},
+	{errcode.Code_INVALID_PARAMS, 40001, 400},
+	{errcode.Code_INVALID_FORMAT, 40002, 400},
+	{errcode.Code_INVALID_FILE, 40005, 400},
+	{errcode.Code_FILE_TOO_LARGE, 40006, 400},
+	{errcode.Code_UNSUPPORTED_FILE_TYPE, 40007, 400},
+	{errcode.Code_UNAUTHORIZED, 40100, 401},
+	{errcode.Code_INVALID_TOKEN, 40101, 401},
+	{errcode.Code_TOKEN_EXPIRED, 40102, 401},
+	{errcode.Code_INVALID_ACCOUNT_OR_PASSWORD, 40103, 401},
+	{errcode.Code_INVALID_NICKNAME, 40104, 400},
+	{errcode.Code_FORBIDDEN, 40300, 403},
+	{errcode.Code_ACCESS_DENIED, 40301, 403},
+	{errcode.Code_NOT_FOUND, 40400, 404},
+	{errcode.Code_USER_NOT_FOUND, 40401, 404},
+	{errcode.Code_SESSION_NOT_FOUND, 40402, 404},
+	{errcode.Code_CONFLICT, 40900, 409},
+	{errcode.Code_USER_EXISTS, 40901, 409},
+	{errcode.Code_USERNAME_EXISTS, 40902, 409},
+	{errcode.Code_TOO_MANY_REQUESTS, 42900, 429},
+	{errcode.Code_INTERNAL_SERVER_ERROR, 50000, 500},
+	{errcode.Code_DATABASE_ERROR, 50001, 500},
+	{errcode.Code_RPC_ERROR, 50002, 500},
+	{errcode.Code_REDIS_ERROR, 50003, 500},
+	{errcode.Code_SERVICE_UNAVAILABLE, 50004, 503},
+	{errcode.Code_REQUEST_TIMEOUT, 50005, 504},
+}
+
+// TestPublishedCodes 测试错误码编号和 HTTP 状态码与已发布的对照表一致
+func TestPublishedCodes(t *testing.T) {
+	values := errcode.Code(0).Descriptor().Values()
+	if values.Len() != len(publishedCodes) {
+		t.Errorf("errcode.proto 中有 %d 个错误码, 对照表中有 %d 个", values.Len(), len(publishedCodes))
+	}
+
+	for _, tt := range publishedCodes {
+		if int(tt.code) != tt.value {
+			t.Errorf("%s 编号被修改: 期望 %d, 实际 %d", tt.code, tt.value, int(tt.code))
+		}
+		if tt.code.HTTPStatus() != tt.httpStatus {
+			t.Errorf("%s HTTP 状态码被修改: 期望 %d, 实际 %d", tt.code, tt.httpStatus, tt.code.HTTPStatus())
+		}
+		if tt.code.Message() == "" || tt.code.Message() == errcode.UnknownMessage {
+			t.Errorf("%s 缺少默认消息", tt.code)
+		}
+	}
+
+	if errcode.Code(12345).HTTPStatus() != 500 || errcode.Code(12345).Message() != errcode.UnknownMessage {
+		t.Error("未定义的错误码应返回 500 和未知错误")
+	}
+}
+
+// 错误原因对应的错误码（v1 的错误码为已发布的响应体 code，不得修改）
+var publishedReasons = []struct {
+	reason userv2.ErrorReason
+	code   errcode.Code
+	v1Code int32
+}{
+	{userv2.ErrorReason_INVALID_ARGUMENT, errcode.Code_INVALID_PARAMS, 40001},
+	{userv2.ErrorReason_INVALID_CREDENTIALS, errcode.Code_INVALID_ACCOUNT_OR_PASSWORD, 40002},
+	{userv2.ErrorReason_OLD_PASSWORD_WRONG, errcode.Code_INVALID_ACCOUNT_OR_PASSWORD, 40002},
+	{userv2.ErrorReason_LOGIN_LOCKED, errcode.Code_TOO_MANY_REQUESTS, 42901},
+	{userv2.ErrorReason_USERNAME_EXISTS, errcode.Code_USERNAME_EXISTS, 40902},
+	{userv2.ErrorReason_TOKEN_INVALID, errcode.Code_UNAUTHORIZED, 40003},
+	{userv2.ErrorReason_REFRESH_TOKEN_INVALID, errcode.Code_UNAUTHORIZED, 40003},
+	{userv2.ErrorReason_USER_NOT_FOUND, errcode.Code_USER_NOT_FOUND, 40004},
+	{userv2.ErrorReason_SESSION_NOT_FOUND, errcode.Code_SESSION_NOT_FOUND, 40005},
+	{userv2.ErrorReason_REQUEST_CANCELED, errcode.Code_REQUEST_TIMEOUT, 49901},
+	{userv2.ErrorReason_REQUEST_TIMEOUT, errcode.Code_REQUEST_TIMEOUT, 50401},
+	{userv2.ErrorReason_INTERNAL, errcode.Code_INTERNAL_SERVER_ERROR, 50001},
+	{userv2.ErrorReason_INVALID_NICKNAME, errcode.Code_INVALID_NICKNAME, 40001},
+}
+
+// TestReasonCodes 测试每个错误原因都指定了错误码，且与对照表一致
+func TestReasonCodes(t *testing.T) {
+	values := userv2.ErrorReason(0).Descriptor().Values()
+	if values.Len()-1 != len(publishedReasons) {
+		t.Errorf("user.v2.ErrorReason 中有 %d 个错误原因, 对照表中有 %d 个", values.Len()-1, len(publishedReasons))
+	}
+
+	for _, tt := range publishedReasons {
+		code, ok := errcode.FromReason(tt.reason)
+		if !ok || code != tt.code {
+			t.Errorf("%s: 期望错误码 %s, 实际 %s (ok=%v)", tt.reason, tt.code, code, ok)
+		}
+		v1Code, ok := errcode.V1CodeFromReason(tt.reason)
+		if !ok || v1Code != tt.v1Code {
+			t.Errorf("%s: 期望 v1 错误码 %d, 实际 %d (ok=%v)", tt.reason, tt.v1Code, v1Code, ok)
+		}
+	}
+
+	if _, ok := errcode.FromReason(userv2.ErrorReason_ERROR_REASON_UNSPECIFIED); ok {
+		t.Error("ERROR_REASON_UNSPECIFIED 不应有错误码")
+	}
+	if _, ok := errcode.FromReason(userv2.ErrorReason(999)); ok {
+		t.Error("未定义的错误原因不应有错误码")
+	}
+}
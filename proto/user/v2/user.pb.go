@@ -0,0 +1,2020 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v6.33.1
+// source: proto/user/v2/user.proto
+
+package userv2
+
+import (
+	_ "entry-task/proto/errcode"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// 错误原因，枚举名作为 google.rpc.ErrorInfo 的 reason
+// errcode.code 为 HTTP 网关返回的错误码，errcode.v1_code 为 v1 响应体中的错误码
+type ErrorReason int32
+
+const (
+	ErrorReason_ERROR_REASON_UNSPECIFIED ErrorReason = 0
+	ErrorReason_INVALID_ARGUMENT         ErrorReason = 1  // 参数错误（附带 BadRequest）
+	ErrorReason_INVALID_CREDENTIALS      ErrorReason = 2  // 用户名或密码错误
+	ErrorReason_OLD_PASSWORD_WRONG       ErrorReason = 3  // 原密码错误
+	ErrorReason_LOGIN_LOCKED             ErrorReason = 4  // 登录失败次数过多（附带 RetryInfo）
+	ErrorReason_USERNAME_EXISTS          ErrorReason = 5  // 用户名已存在
+	ErrorReason_TOKEN_INVALID            ErrorReason = 6  // Token无效或已过期
+	ErrorReason_REFRESH_TOKEN_INVALID    ErrorReason = 7  // 刷新凭证无效或已过期
+	ErrorReason_USER_NOT_FOUND           ErrorReason = 8  // 用户不存在
+	ErrorReason_SESSION_NOT_FOUND        ErrorReason = 9  // Session不存在
+	ErrorReason_REQUEST_CANCELED         ErrorReason = 10 // 请求已被调用方取消
+	ErrorReason_REQUEST_TIMEOUT          ErrorReason = 11 // 请求处理超时（含数据库查询超时）
+	ErrorReason_INTERNAL                 ErrorReason = 12 // 内部错误
+	ErrorReason_INVALID_NICKNAME         ErrorReason = 13 // 昵称不合法（附带 BadRequest）
+)
+
+// Enum value maps for ErrorReason.
+var (
+	ErrorReason_name = map[int32]string{
+		0:  "ERROR_REASON_UNSPECIFIED",
+		1:  "INVALID_ARGUMENT",
+		2:  "INVALID_CREDENTIALS",
+		3:  "OLD_PASSWORD_WRONG",
+		4:  "LOGIN_LOCKED",
+		5:  "USERNAME_EXISTS",
+		6:  "TOKEN_INVALID",
+		7:  "REFRESH_TOKEN_INVALID",
+		8:  "USER_NOT_FOUND",
+		9:  "SESSION_NOT_FOUND",
+		10: "REQUEST_CANCELED",
+		11: "REQUEST_TIMEOUT",
+		12: "INTERNAL",
+		13: "INVALID_NICKNAME",
+	}
+	ErrorReason_value = map[string]int32{
+		"ERROR_REASON_UNSPECIFIED": 0,
+		"INVALID_ARGUMENT":         1,
+		"INVALID_CREDENTIALS":      2,
+		"OLD_PASSWORD_WRONG":       3,
+		"LOGIN_LOCKED":             4,
+		"USERNAME_EXISTS":          5,
+		"TOKEN_INVALID":            6,
+		"REFRESH_TOKEN_INVALID":    7,
+		"USER_NOT_FOUND":           8,
+		"SESSION_NOT_FOUND":        9,
+		"REQUEST_CANCELED":         10,
+		"REQUEST_TIMEOUT":          11,
+		"INTERNAL":                 12,
+		"INVALID_NICKNAME":         13,
+	}
+)
+
+func (x ErrorReason) Enum() *ErrorReason {
+	p := new(ErrorReason)
+	*p = x
+	return p
+}
+
+func (x ErrorReason) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ErrorReason) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_user_v2_user_proto_enumTypes[0].Descriptor()
+}
+
+func (ErrorReason) Type() protoreflect.EnumType {
+	return &file_proto_user_v2_user_proto_enumTypes[0]
+}
+
+func (x ErrorReason) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ErrorReason.Descriptor instead.
+func (ErrorReason) EnumDescriptor() ([]byte, []int) {
+	return file_proto_user_v2_user_proto_rawDescGZIP(), []int{0}
+}
+
+// 注册请求
+type RegisterRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Username      string                 `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	Password      string                 `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`
+	Nickname      string                 `protobuf:"bytes,3,opt,name=nickname,proto3" json:"nickname,omitempty"` // 昵称（可选，为空时默认使用用户名）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RegisterRequest) Reset() {
+	*x = RegisterRequest{}
+	mi := &file_proto_user_v2_user_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegisterRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterRequest) ProtoMessage() {}
+
+func (x *RegisterRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_v2_user_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterRequest.ProtoReflect.Descriptor instead.
+func (*RegisterRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_v2_user_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *RegisterRequest) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *RegisterRequest) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+func (x *RegisterRequest) GetNickname() string {
+	if x != nil {
+		return x.Nickname
+	}
+	return ""
+}
+
+// 注册响应
+type RegisterResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	User          *UserProfile           `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RegisterResponse) Reset() {
+	*x = RegisterResponse{}
+	mi := &file_proto_user_v2_user_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegisterResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterResponse) ProtoMessage() {}
+
+func (x *RegisterResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_v2_user_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterResponse.ProtoReflect.Descriptor instead.
+func (*RegisterResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_v2_user_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *RegisterResponse) GetUser() *UserProfile {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+// 登录请求
+type LoginRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Username      string                 `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	Password      string                 `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LoginRequest) Reset() {
+	*x = LoginRequest{}
+	mi := &file_proto_user_v2_user_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LoginRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LoginRequest) ProtoMessage() {}
+
+func (x *LoginRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_v2_user_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LoginRequest.ProtoReflect.Descriptor instead.
+func (*LoginRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_v2_user_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *LoginRequest) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *LoginRequest) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+// 登录响应
+type LoginResponse struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Token            string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"` // Session Token
+	User             *UserProfile           `protobuf:"bytes,2,opt,name=user,proto3" json:"user,omitempty"`
+	ExpiresIn        int64                  `protobuf:"varint,3,opt,name=expires_in,json=expiresIn,proto3" json:"expires_in,omitempty"`                        // Session有效期（秒），用于设置Cookie
+	RefreshToken     string                 `protobuf:"bytes,4,opt,name=refresh_token,json=refreshToken,proto3" json:"refresh_token,omitempty"`                // 刷新凭证（仅 token 模式）
+	RefreshExpiresIn int64                  `protobuf:"varint,5,opt,name=refresh_expires_in,json=refreshExpiresIn,proto3" json:"refresh_expires_in,omitempty"` // 刷新凭证有效期（秒）
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *LoginResponse) Reset() {
+	*x = LoginResponse{}
+	mi := &file_proto_user_v2_user_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LoginResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LoginResponse) ProtoMessage() {}
+
+func (x *LoginResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_v2_user_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LoginResponse.ProtoReflect.Descriptor instead.
+func (*LoginResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_v2_user_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *LoginResponse) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *LoginResponse) GetUser() *UserProfile {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+func (x *LoginResponse) GetExpiresIn() int64 {
+	if x != nil {
+		return x.ExpiresIn
+	}
+	return 0
+}
+
+func (x *LoginResponse) GetRefreshToken() string {
+	if x != nil {
+		return x.RefreshToken
+	}
+	return ""
+}
+
+func (x *LoginResponse) GetRefreshExpiresIn() int64 {
+	if x != nil {
+		return x.RefreshExpiresIn
+	}
+	return 0
+}
+
+// 刷新凭证请求
+type RefreshTokenRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RefreshToken  string                 `protobuf:"bytes,1,opt,name=refresh_token,json=refreshToken,proto3" json:"refresh_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RefreshTokenRequest) Reset() {
+	*x = RefreshTokenRequest{}
+	mi := &file_proto_user_v2_user_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RefreshTokenRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RefreshTokenRequest) ProtoMessage() {}
+
+func (x *RefreshTokenRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_v2_user_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RefreshTokenRequest.ProtoReflect.Descriptor instead.
+func (*RefreshTokenRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_v2_user_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *RefreshTokenRequest) GetRefreshToken() string {
+	if x != nil {
+		return x.RefreshToken
+	}
+	return ""
+}
+
+// 刷新凭证响应（刷新凭证同时轮换）
+type RefreshTokenResponse struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Token            string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	ExpiresIn        int64                  `protobuf:"varint,2,opt,name=expires_in,json=expiresIn,proto3" json:"expires_in,omitempty"`
+	RefreshToken     string                 `protobuf:"bytes,3,opt,name=refresh_token,json=refreshToken,proto3" json:"refresh_token,omitempty"`
+	RefreshExpiresIn int64                  `protobuf:"varint,4,opt,name=refresh_expires_in,json=refreshExpiresIn,proto3" json:"refresh_expires_in,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *RefreshTokenResponse) Reset() {
+	*x = RefreshTokenResponse{}
+	mi := &file_proto_user_v2_user_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RefreshTokenResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RefreshTokenResponse) ProtoMessage() {}
+
+func (x *RefreshTokenResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_v2_user_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RefreshTokenResponse.ProtoReflect.Descriptor instead.
+func (*RefreshTokenResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_v2_user_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *RefreshTokenResponse) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *RefreshTokenResponse) GetExpiresIn() int64 {
+	if x != nil {
+		return x.ExpiresIn
+	}
+	return 0
+}
+
+func (x *RefreshTokenResponse) GetRefreshToken() string {
+	if x != nil {
+		return x.RefreshToken
+	}
+	return ""
+}
+
+func (x *RefreshTokenResponse) GetRefreshExpiresIn() int64 {
+	if x != nil {
+		return x.RefreshExpiresIn
+	}
+	return 0
+}
+
+// 登出请求
+type LogoutRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LogoutRequest) Reset() {
+	*x = LogoutRequest{}
+	mi := &file_proto_user_v2_user_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LogoutRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogoutRequest) ProtoMessage() {}
+
+func (x *LogoutRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_v2_user_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogoutRequest.ProtoReflect.Descriptor instead.
+func (*LogoutRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_v2_user_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *LogoutRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+// 登出响应
+type LogoutResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LogoutResponse) Reset() {
+	*x = LogoutResponse{}
+	mi := &file_proto_user_v2_user_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LogoutResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogoutResponse) ProtoMessage() {}
+
+func (x *LogoutResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_v2_user_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogoutResponse.ProtoReflect.Descriptor instead.
+func (*LogoutResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_v2_user_proto_rawDescGZIP(), []int{7}
+}
+
+// 获取Profile请求
+type GetProfileRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetProfileRequest) Reset() {
+	*x = GetProfileRequest{}
+	mi := &file_proto_user_v2_user_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetProfileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetProfileRequest) ProtoMessage() {}
+
+func (x *GetProfileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_v2_user_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetProfileRequest.ProtoReflect.Descriptor instead.
+func (*GetProfileRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_v2_user_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GetProfileRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+// 获取Profile响应
+type GetProfileResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	User          *UserProfile           `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetProfileResponse) Reset() {
+	*x = GetProfileResponse{}
+	mi := &file_proto_user_v2_user_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetProfileResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetProfileResponse) ProtoMessage() {}
+
+func (x *GetProfileResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_v2_user_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetProfileResponse.ProtoReflect.Descriptor instead.
+func (*GetProfileResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_v2_user_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *GetProfileResponse) GetUser() *UserProfile {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+// 获取公开信息请求
+type GetPublicProfileRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        uint64                 `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPublicProfileRequest) Reset() {
+	*x = GetPublicProfileRequest{}
+	mi := &file_proto_user_v2_user_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPublicProfileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPublicProfileRequest) ProtoMessage() {}
+
+func (x *GetPublicProfileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_v2_user_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPublicProfileRequest.ProtoReflect.Descriptor instead.
+func (*GetPublicProfileRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_v2_user_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *GetPublicProfileRequest) GetUserId() uint64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+// 获取公开信息响应
+type GetPublicProfileResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	User          *PublicProfile         `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPublicProfileResponse) Reset() {
+	*x = GetPublicProfileResponse{}
+	mi := &file_proto_user_v2_user_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPublicProfileResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPublicProfileResponse) ProtoMessage() {}
+
+func (x *GetPublicProfileResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_v2_user_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPublicProfileResponse.ProtoReflect.Descriptor instead.
+func (*GetPublicProfileResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_v2_user_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *GetPublicProfileResponse) GetUser() *PublicProfile {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+// 更新昵称请求
+type UpdateNicknameRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	Nickname      string                 `protobuf:"bytes,2,opt,name=nickname,proto3" json:"nickname,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateNicknameRequest) Reset() {
+	*x = UpdateNicknameRequest{}
+	mi := &file_proto_user_v2_user_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateNicknameRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateNicknameRequest) ProtoMessage() {}
+
+func (x *UpdateNicknameRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_v2_user_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateNicknameRequest.ProtoReflect.Descriptor instead.
+func (*UpdateNicknameRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_v2_user_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *UpdateNicknameRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *UpdateNicknameRequest) GetNickname() string {
+	if x != nil {
+		return x.Nickname
+	}
+	return ""
+}
+
+// 更新昵称响应
+type UpdateNicknameResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	User          *UserProfile           `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateNicknameResponse) Reset() {
+	*x = UpdateNicknameResponse{}
+	mi := &file_proto_user_v2_user_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateNicknameResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateNicknameResponse) ProtoMessage() {}
+
+func (x *UpdateNicknameResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_v2_user_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateNicknameResponse.ProtoReflect.Descriptor instead.
+func (*UpdateNicknameResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_v2_user_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *UpdateNicknameResponse) GetUser() *UserProfile {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+// 更新头像请求
+type UpdateProfilePictureRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Token          string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	ProfilePicture string                 `protobuf:"bytes,2,opt,name=profile_picture,json=profilePicture,proto3" json:"profile_picture,omitempty"` // 头像存储 key（如 avatars/1/{内容哈希}.jpg），访问地址由 HTTP Server 的存储配置决定
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *UpdateProfilePictureRequest) Reset() {
+	*x = UpdateProfilePictureRequest{}
+	mi := &file_proto_user_v2_user_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateProfilePictureRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateProfilePictureRequest) ProtoMessage() {}
+
+func (x *UpdateProfilePictureRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_v2_user_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateProfilePictureRequest.ProtoReflect.Descriptor instead.
+func (*UpdateProfilePictureRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_v2_user_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *UpdateProfilePictureRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *UpdateProfilePictureRequest) GetProfilePicture() string {
+	if x != nil {
+		return x.ProfilePicture
+	}
+	return ""
+}
+
+// 更新头像响应
+type UpdateProfilePictureResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	User          *UserProfile           `protobuf:"bytes,1,opt,name=user,proto3" json:"user,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateProfilePictureResponse) Reset() {
+	*x = UpdateProfilePictureResponse{}
+	mi := &file_proto_user_v2_user_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateProfilePictureResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateProfilePictureResponse) ProtoMessage() {}
+
+func (x *UpdateProfilePictureResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_v2_user_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateProfilePictureResponse.ProtoReflect.Descriptor instead.
+func (*UpdateProfilePictureResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_v2_user_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *UpdateProfilePictureResponse) GetUser() *UserProfile {
+	if x != nil {
+		return x.User
+	}
+	return nil
+}
+
+// 修改密码请求
+type ChangePasswordRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	OldPassword   string                 `protobuf:"bytes,2,opt,name=old_password,json=oldPassword,proto3" json:"old_password,omitempty"`
+	NewPassword   string                 `protobuf:"bytes,3,opt,name=new_password,json=newPassword,proto3" json:"new_password,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChangePasswordRequest) Reset() {
+	*x = ChangePasswordRequest{}
+	mi := &file_proto_user_v2_user_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChangePasswordRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChangePasswordRequest) ProtoMessage() {}
+
+func (x *ChangePasswordRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_v2_user_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChangePasswordRequest.ProtoReflect.Descriptor instead.
+func (*ChangePasswordRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_v2_user_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ChangePasswordRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *ChangePasswordRequest) GetOldPassword() string {
+	if x != nil {
+		return x.OldPassword
+	}
+	return ""
+}
+
+func (x *ChangePasswordRequest) GetNewPassword() string {
+	if x != nil {
+		return x.NewPassword
+	}
+	return ""
+}
+
+// 修改密码响应
+type ChangePasswordResponse struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	RevokedSessions int32                  `protobuf:"varint,1,opt,name=revoked_sessions,json=revokedSessions,proto3" json:"revoked_sessions,omitempty"` // 被注销的其他Session数量
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ChangePasswordResponse) Reset() {
+	*x = ChangePasswordResponse{}
+	mi := &file_proto_user_v2_user_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChangePasswordResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChangePasswordResponse) ProtoMessage() {}
+
+func (x *ChangePasswordResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_v2_user_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChangePasswordResponse.ProtoReflect.Descriptor instead.
+func (*ChangePasswordResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_v2_user_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ChangePasswordResponse) GetRevokedSessions() int32 {
+	if x != nil {
+		return x.RevokedSessions
+	}
+	return 0
+}
+
+// 列出Session请求
+type ListSessionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSessionsRequest) Reset() {
+	*x = ListSessionsRequest{}
+	mi := &file_proto_user_v2_user_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSessionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSessionsRequest) ProtoMessage() {}
+
+func (x *ListSessionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_v2_user_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSessionsRequest.ProtoReflect.Descriptor instead.
+func (*ListSessionsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_v2_user_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ListSessionsRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+// 列出Session响应
+type ListSessionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sessions      []*SessionInfo         `protobuf:"bytes,1,rep,name=sessions,proto3" json:"sessions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSessionsResponse) Reset() {
+	*x = ListSessionsResponse{}
+	mi := &file_proto_user_v2_user_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSessionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSessionsResponse) ProtoMessage() {}
+
+func (x *ListSessionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_v2_user_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSessionsResponse.ProtoReflect.Descriptor instead.
+func (*ListSessionsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_v2_user_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *ListSessionsResponse) GetSessions() []*SessionInfo {
+	if x != nil {
+		return x.Sessions
+	}
+	return nil
+}
+
+// 注销指定Session请求
+type RevokeSessionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	SessionId     string                 `protobuf:"bytes,2,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeSessionRequest) Reset() {
+	*x = RevokeSessionRequest{}
+	mi := &file_proto_user_v2_user_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeSessionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeSessionRequest) ProtoMessage() {}
+
+func (x *RevokeSessionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_v2_user_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeSessionRequest.ProtoReflect.Descriptor instead.
+func (*RevokeSessionRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_v2_user_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *RevokeSessionRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *RevokeSessionRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+// 注销指定Session响应
+type RevokeSessionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeSessionResponse) Reset() {
+	*x = RevokeSessionResponse{}
+	mi := &file_proto_user_v2_user_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeSessionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeSessionResponse) ProtoMessage() {}
+
+func (x *RevokeSessionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_v2_user_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeSessionResponse.ProtoReflect.Descriptor instead.
+func (*RevokeSessionResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_v2_user_proto_rawDescGZIP(), []int{21}
+}
+
+// 注销所有Session请求
+type RevokeAllSessionsRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Token          string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	IncludeCurrent bool                   `protobuf:"varint,2,opt,name=include_current,json=includeCurrent,proto3" json:"include_current,omitempty"` // 是否同时注销当前Session
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *RevokeAllSessionsRequest) Reset() {
+	*x = RevokeAllSessionsRequest{}
+	mi := &file_proto_user_v2_user_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeAllSessionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeAllSessionsRequest) ProtoMessage() {}
+
+func (x *RevokeAllSessionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_v2_user_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeAllSessionsRequest.ProtoReflect.Descriptor instead.
+func (*RevokeAllSessionsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_v2_user_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *RevokeAllSessionsRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *RevokeAllSessionsRequest) GetIncludeCurrent() bool {
+	if x != nil {
+		return x.IncludeCurrent
+	}
+	return false
+}
+
+// 注销所有Session响应
+type RevokeAllSessionsResponse struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	RevokedSessions int32                  `protobuf:"varint,1,opt,name=revoked_sessions,json=revokedSessions,proto3" json:"revoked_sessions,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *RevokeAllSessionsResponse) Reset() {
+	*x = RevokeAllSessionsResponse{}
+	mi := &file_proto_user_v2_user_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeAllSessionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeAllSessionsResponse) ProtoMessage() {}
+
+func (x *RevokeAllSessionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_v2_user_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeAllSessionsResponse.ProtoReflect.Descriptor instead.
+func (*RevokeAllSessionsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_v2_user_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *RevokeAllSessionsResponse) GetRevokedSessions() int32 {
+	if x != nil {
+		return x.RevokedSessions
+	}
+	return 0
+}
+
+// Session信息
+type SessionInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	CreatedAt     int64                  `protobuf:"varint,2,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`      // 创建时间（Unix秒）
+	LastSeenAt    int64                  `protobuf:"varint,3,opt,name=last_seen_at,json=lastSeenAt,proto3" json:"last_seen_at,omitempty"` // 最近活跃时间（Unix秒）
+	ClientIp      string                 `protobuf:"bytes,4,opt,name=client_ip,json=clientIp,proto3" json:"client_ip,omitempty"`
+	UserAgent     string                 `protobuf:"bytes,5,opt,name=user_agent,json=userAgent,proto3" json:"user_agent,omitempty"`
+	Current       bool                   `protobuf:"varint,6,opt,name=current,proto3" json:"current,omitempty"` // 是否为发起请求的当前Session
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SessionInfo) Reset() {
+	*x = SessionInfo{}
+	mi := &file_proto_user_v2_user_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SessionInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SessionInfo) ProtoMessage() {}
+
+func (x *SessionInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_v2_user_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SessionInfo.ProtoReflect.Descriptor instead.
+func (*SessionInfo) Descriptor() ([]byte, []int) {
+	return file_proto_user_v2_user_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *SessionInfo) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *SessionInfo) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *SessionInfo) GetLastSeenAt() int64 {
+	if x != nil {
+		return x.LastSeenAt
+	}
+	return 0
+}
+
+func (x *SessionInfo) GetClientIp() string {
+	if x != nil {
+		return x.ClientIp
+	}
+	return ""
+}
+
+func (x *SessionInfo) GetUserAgent() string {
+	if x != nil {
+		return x.UserAgent
+	}
+	return ""
+}
+
+func (x *SessionInfo) GetCurrent() bool {
+	if x != nil {
+		return x.Current
+	}
+	return false
+}
+
+// 列出头像请求
+type ListProfilePicturesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AfterUserId   uint64                 `protobuf:"varint,1,opt,name=after_user_id,json=afterUserId,proto3" json:"after_user_id,omitempty"` // 从该用户ID之后开始（不含），首页为 0
+	Limit         int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`                                  // 每页数量，0 为默认值 100，最大 1000
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListProfilePicturesRequest) Reset() {
+	*x = ListProfilePicturesRequest{}
+	mi := &file_proto_user_v2_user_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListProfilePicturesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListProfilePicturesRequest) ProtoMessage() {}
+
+func (x *ListProfilePicturesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_v2_user_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListProfilePicturesRequest.ProtoReflect.Descriptor instead.
+func (*ListProfilePicturesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_v2_user_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *ListProfilePicturesRequest) GetAfterUserId() uint64 {
+	if x != nil {
+		return x.AfterUserId
+	}
+	return 0
+}
+
+func (x *ListProfilePicturesRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+// 列出头像响应，返回数量小于 limit 时表示已到最后一页
+type ListProfilePicturesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Entries       []*ProfilePictureEntry `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListProfilePicturesResponse) Reset() {
+	*x = ListProfilePicturesResponse{}
+	mi := &file_proto_user_v2_user_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListProfilePicturesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListProfilePicturesResponse) ProtoMessage() {}
+
+func (x *ListProfilePicturesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_v2_user_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListProfilePicturesResponse.ProtoReflect.Descriptor instead.
+func (*ListProfilePicturesResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_v2_user_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *ListProfilePicturesResponse) GetEntries() []*ProfilePictureEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+// 用户头像
+type ProfilePictureEntry struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	UserId         uint64                 `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	ProfilePicture string                 `protobuf:"bytes,2,opt,name=profile_picture,json=profilePicture,proto3" json:"profile_picture,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ProfilePictureEntry) Reset() {
+	*x = ProfilePictureEntry{}
+	mi := &file_proto_user_v2_user_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProfilePictureEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProfilePictureEntry) ProtoMessage() {}
+
+func (x *ProfilePictureEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_v2_user_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProfilePictureEntry.ProtoReflect.Descriptor instead.
+func (*ProfilePictureEntry) Descriptor() ([]byte, []int) {
+	return file_proto_user_v2_user_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *ProfilePictureEntry) GetUserId() uint64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *ProfilePictureEntry) GetProfilePicture() string {
+	if x != nil {
+		return x.ProfilePicture
+	}
+	return ""
+}
+
+// 清空头像请求
+type ClearProfilePictureRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	UserId         uint64                 `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	ProfilePicture string                 `protobuf:"bytes,2,opt,name=profile_picture,json=profilePicture,proto3" json:"profile_picture,omitempty"` // 期望的当前头像
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ClearProfilePictureRequest) Reset() {
+	*x = ClearProfilePictureRequest{}
+	mi := &file_proto_user_v2_user_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClearProfilePictureRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClearProfilePictureRequest) ProtoMessage() {}
+
+func (x *ClearProfilePictureRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_v2_user_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClearProfilePictureRequest.ProtoReflect.Descriptor instead.
+func (*ClearProfilePictureRequest) Descriptor() ([]byte, []int) {
+	return file_proto_user_v2_user_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *ClearProfilePictureRequest) GetUserId() uint64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *ClearProfilePictureRequest) GetProfilePicture() string {
+	if x != nil {
+		return x.ProfilePicture
+	}
+	return ""
+}
+
+// 清空头像响应
+type ClearProfilePictureResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Cleared       bool                   `protobuf:"varint,1,opt,name=cleared,proto3" json:"cleared,omitempty"` // 头像已变化（或用户不存在）时为 false
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ClearProfilePictureResponse) Reset() {
+	*x = ClearProfilePictureResponse{}
+	mi := &file_proto_user_v2_user_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClearProfilePictureResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClearProfilePictureResponse) ProtoMessage() {}
+
+func (x *ClearProfilePictureResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_v2_user_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClearProfilePictureResponse.ProtoReflect.Descriptor instead.
+func (*ClearProfilePictureResponse) Descriptor() ([]byte, []int) {
+	return file_proto_user_v2_user_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *ClearProfilePictureResponse) GetCleared() bool {
+	if x != nil {
+		return x.Cleared
+	}
+	return false
+}
+
+// 用户Profile
+type UserProfile struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Username      string                 `protobuf:"bytes,2,opt,name=username,proto3" json:"username,omitempty"`
+	Nickname      string                 `protobuf:"bytes,3,opt,name=nickname,proto3" json:"nickname,omitempty"`
+	AvatarUrl     string                 `protobuf:"bytes,4,opt,name=avatar_url,json=avatarUrl,proto3" json:"avatar_url,omitempty"` // 头像存储 key（旧数据为 /uploads/avatars/ 开头的本地路径）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UserProfile) Reset() {
+	*x = UserProfile{}
+	mi := &file_proto_user_v2_user_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UserProfile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserProfile) ProtoMessage() {}
+
+func (x *UserProfile) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_v2_user_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserProfile.ProtoReflect.Descriptor instead.
+func (*UserProfile) Descriptor() ([]byte, []int) {
+	return file_proto_user_v2_user_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *UserProfile) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *UserProfile) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *UserProfile) GetNickname() string {
+	if x != nil {
+		return x.Nickname
+	}
+	return ""
+}
+
+func (x *UserProfile) GetAvatarUrl() string {
+	if x != nil {
+		return x.AvatarUrl
+	}
+	return ""
+}
+
+// 用户公开信息（任何人可见）
+type PublicProfile struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Nickname      string                 `protobuf:"bytes,2,opt,name=nickname,proto3" json:"nickname,omitempty"`
+	AvatarUrl     string                 `protobuf:"bytes,3,opt,name=avatar_url,json=avatarUrl,proto3" json:"avatar_url,omitempty"` // 头像存储 key，同 UserProfile.avatar_url
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PublicProfile) Reset() {
+	*x = PublicProfile{}
+	mi := &file_proto_user_v2_user_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PublicProfile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PublicProfile) ProtoMessage() {}
+
+func (x *PublicProfile) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_user_v2_user_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PublicProfile.ProtoReflect.Descriptor instead.
+func (*PublicProfile) Descriptor() ([]byte, []int) {
+	return file_proto_user_v2_user_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *PublicProfile) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *PublicProfile) GetNickname() string {
+	if x != nil {
+		return x.Nickname
+	}
+	return ""
+}
+
+func (x *PublicProfile) GetAvatarUrl() string {
+	if x != nil {
+		return x.AvatarUrl
+	}
+	return ""
+}
+
+var File_proto_user_v2_user_proto protoreflect.FileDescriptor
+
+const file_proto_user_v2_user_proto_rawDesc = "" +
+	"\n" +
+	"\x18proto/user/v2/user.proto\x12\auser.v2\x1a\x1bproto/errcode/errcode.proto\"e\n" +
+	"\x0fRegisterRequest\x12\x1a\n" +
+	"\busername\x18\x01 \x01(\tR\busername\x12\x1a\n" +
+	"\bpassword\x18\x02 \x01(\tR\bpassword\x12\x1a\n" +
+	"\bnickname\x18\x03 \x01(\tR\bnickname\"<\n" +
+	"\x10RegisterResponse\x12(\n" +
+	"\x04user\x18\x01 \x01(\v2\x14.user.v2.UserProfileR\x04user\"F\n" +
+	"\fLoginRequest\x12\x1a\n" +
+	"\busername\x18\x01 \x01(\tR\busername\x12\x1a\n" +
+	"\bpassword\x18\x02 \x01(\tR\bpassword\"\xc1\x01\n" +
+	"\rLoginResponse\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12(\n" +
+	"\x04user\x18\x02 \x01(\v2\x14.user.v2.UserProfileR\x04user\x12\x1d\n" +
+	"\n" +
+	"expires_in\x18\x03 \x01(\x03R\texpiresIn\x12#\n" +
+	"\rrefresh_token\x18\x04 \x01(\tR\frefreshToken\x12,\n" +
+	"\x12refresh_expires_in\x18\x05 \x01(\x03R\x10refreshExpiresIn\":\n" +
+	"\x13RefreshTokenRequest\x12#\n" +
+	"\rrefresh_token\x18\x01 \x01(\tR\frefreshToken\"\x9e\x01\n" +
+	"\x14RefreshTokenResponse\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12\x1d\n" +
+	"\n" +
+	"expires_in\x18\x02 \x01(\x03R\texpiresIn\x12#\n" +
+	"\rrefresh_token\x18\x03 \x01(\tR\frefreshToken\x12,\n" +
+	"\x12refresh_expires_in\x18\x04 \x01(\x03R\x10refreshExpiresIn\"%\n" +
+	"\rLogoutRequest\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\"\x10\n" +
+	"\x0eLogoutResponse\")\n" +
+	"\x11GetProfileRequest\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\">\n" +
+	"\x12GetProfileResponse\x12(\n" +
+	"\x04user\x18\x01 \x01(\v2\x14.user.v2.UserProfileR\x04user\"2\n" +
+	"\x17GetPublicProfileRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x04R\x06userId\"F\n" +
+	"\x18GetPublicProfileResponse\x12*\n" +
+	"\x04user\x18\x01 \x01(\v2\x16.user.v2.PublicProfileR\x04user\"I\n" +
+	"\x15UpdateNicknameRequest\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12\x1a\n" +
+	"\bnickname\x18\x02 \x01(\tR\bnickname\"B\n" +
+	"\x16UpdateNicknameResponse\x12(\n" +
+	"\x04user\x18\x01 \x01(\v2\x14.user.v2.UserProfileR\x04user\"\\\n" +
+	"\x1bUpdateProfilePictureRequest\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12'\n" +
+	"\x0fprofile_picture\x18\x02 \x01(\tR\x0eprofilePicture\"H\n" +
+	"\x1cUpdateProfilePictureResponse\x12(\n" +
+	"\x04user\x18\x01 \x01(\v2\x14.user.v2.UserProfileR\x04user\"s\n" +
+	"\x15ChangePasswordRequest\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12!\n" +
+	"\fold_password\x18\x02 \x01(\tR\voldPassword\x12!\n" +
+	"\fnew_password\x18\x03 \x01(\tR\vnewPassword\"C\n" +
+	"\x16ChangePasswordResponse\x12)\n" +
+	"\x10revoked_sessions\x18\x01 \x01(\x05R\x0frevokedSessions\"+\n" +
+	"\x13ListSessionsRequest\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\"H\n" +
+	"\x14ListSessionsResponse\x120\n" +
+	"\bsessions\x18\x01 \x03(\v2\x14.user.v2.SessionInfoR\bsessions\"K\n" +
+	"\x14RevokeSessionRequest\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x02 \x01(\tR\tsessionId\"\x17\n" +
+	"\x15RevokeSessionResponse\"Y\n" +
+	"\x18RevokeAllSessionsRequest\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12'\n" +
+	"\x0finclude_current\x18\x02 \x01(\bR\x0eincludeCurrent\"F\n" +
+	"\x19RevokeAllSessionsResponse\x12)\n" +
+	"\x10revoked_sessions\x18\x01 \x01(\x05R\x0frevokedSessions\"\xc3\x01\n" +
+	"\vSessionInfo\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x02 \x01(\x03R\tcreatedAt\x12 \n" +
+	"\flast_seen_at\x18\x03 \x01(\x03R\n" +
+	"lastSeenAt\x12\x1b\n" +
+	"\tclient_ip\x18\x04 \x01(\tR\bclientIp\x12\x1d\n" +
+	"\n" +
+	"user_agent\x18\x05 \x01(\tR\tuserAgent\x12\x18\n" +
+	"\acurrent\x18\x06 \x01(\bR\acurrent\"V\n" +
+	"\x1aListProfilePicturesRequest\x12\"\n" +
+	"\rafter_user_id\x18\x01 \x01(\x04R\vafterUserId\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\"U\n" +
+	"\x1bListProfilePicturesResponse\x126\n" +
+	"\aentries\x18\x01 \x03(\v2\x1c.user.v2.ProfilePictureEntryR\aentries\"W\n" +
+	"\x13ProfilePictureEntry\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x04R\x06userId\x12'\n" +
+	"\x0fprofile_picture\x18\x02 \x01(\tR\x0eprofilePicture\"^\n" +
+	"\x1aClearProfilePictureRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x04R\x06userId\x12'\n" +
+	"\x0fprofile_picture\x18\x02 \x01(\tR\x0eprofilePicture\"7\n" +
+	"\x1bClearProfilePictureResponse\x12\x18\n" +
+	"\acleared\x18\x01 \x01(\bR\acleared\"t\n" +
+	"\vUserProfile\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x1a\n" +
+	"\busername\x18\x02 \x01(\tR\busername\x12\x1a\n" +
+	"\bnickname\x18\x03 \x01(\tR\bnickname\x12\x1d\n" +
+	"\n" +
+	"avatar_url\x18\x04 \x01(\tR\tavatarUrl\"Z\n" +
+	"\rPublicProfile\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x1a\n" +
+	"\bnickname\x18\x02 \x01(\tR\bnickname\x12\x1d\n" +
+	"\n" +
+	"avatar_url\x18\x03 \x01(\tR\tavatarUrl*\xf7\x03\n" +
+	"\vErrorReason\x12\x1c\n" +
+	"\x18ERROR_REASON_UNSPECIFIED\x10\x00\x12\"\n" +
+	"\x10INVALID_ARGUMENT\x10\x01\x1a\f\x98\xb5\x18\xc1\xb8\x02\xa0\xb5\x18\xc1\xb8\x02\x12%\n" +
+	"\x13INVALID_CREDENTIALS\x10\x02\x1a\f\x98\xb5\x18\xa7\xb9\x02\xa0\xb5\x18¸\x02\x12$\n" +
+	"\x12OLD_PASSWORD_WRONG\x10\x03\x1a\f\x98\xb5\x18\xa7\xb9\x02\xa0\xb5\x18¸\x02\x12\x1e\n" +
+	"\fLOGIN_LOCKED\x10\x04\x1a\f\x98\xb5\x18\x94\xcf\x02\xa0\xb5\x18\x95\xcf\x02\x12!\n" +
+	"\x0fUSERNAME_EXISTS\x10\x05\x1a\f\x98\xb5\x18ƿ\x02\xa0\xb5\x18ƿ\x02\x12\x1f\n" +
+	"\rTOKEN_INVALID\x10\x06\x1a\f\x98\xb5\x18\xa4\xb9\x02\xa0\xb5\x18ø\x02\x12'\n" +
+	"\x15REFRESH_TOKEN_INVALID\x10\a\x1a\f\x98\xb5\x18\xa4\xb9\x02\xa0\xb5\x18ø\x02\x12 \n" +
+	"\x0eUSER_NOT_FOUND\x10\b\x1a\f\x98\xb5\x18ѻ\x02\xa0\xb5\x18ĸ\x02\x12#\n" +
+	"\x11SESSION_NOT_FOUND\x10\t\x1a\f\x98\xb5\x18һ\x02\xa0\xb5\x18Ÿ\x02\x12\"\n" +
+	"\x10REQUEST_CANCELED\x10\n" +
+	"\x1a\f\x98\xb5\x18Ն\x03\xa0\xb5\x18\xed\x85\x03\x12!\n" +
+	"\x0fREQUEST_TIMEOUT\x10\v\x1a\f\x98\xb5\x18Ն\x03\xa0\xb5\x18\xe1\x89\x03\x12\x1a\n" +
+	"\bINTERNAL\x10\f\x1a\f\x98\xb5\x18І\x03\xa0\xb5\x18ц\x03\x12\"\n" +
+	"\x10INVALID_NICKNAME\x10\r\x1a\f\x98\xb5\x18\xa8\xb9\x02\xa0\xb5\x18\xc1\xb8\x022\xb2\a\n" +
+	"\vUserService\x12?\n" +
+	"\bRegister\x12\x18.user.v2.RegisterRequest\x1a\x19.user.v2.RegisterResponse\x126\n" +
+	"\x05Login\x12\x15.user.v2.LoginRequest\x1a\x16.user.v2.LoginResponse\x129\n" +
+	"\x06Logout\x12\x16.user.v2.LogoutRequest\x1a\x17.user.v2.LogoutResponse\x12K\n" +
+	"\fRefreshToken\x12\x1c.user.v2.RefreshTokenRequest\x1a\x1d.user.v2.RefreshTokenResponse\x12E\n" +
+	"\n" +
+	"GetProfile\x12\x1a.user.v2.GetProfileRequest\x1a\x1b.user.v2.GetProfileResponse\x12W\n" +
+	"\x10GetPublicProfile\x12 .user.v2.GetPublicProfileRequest\x1a!.user.v2.GetPublicProfileResponse\x12Q\n" +
+	"\x0eUpdateNickname\x12\x1e.user.v2.UpdateNicknameRequest\x1a\x1f.user.v2.UpdateNicknameResponse\x12c\n" +
+	"\x14UpdateProfilePicture\x12$.user.v2.UpdateProfilePictureRequest\x1a%.user.v2.UpdateProfilePictureResponse\x12Q\n" +
+	"\x0eChangePassword\x12\x1e.user.v2.ChangePasswordRequest\x1a\x1f.user.v2.ChangePasswordResponse\x12K\n" +
+	"\fListSessions\x12\x1c.user.v2.ListSessionsRequest\x1a\x1d.user.v2.ListSessionsResponse\x12N\n" +
+	"\rRevokeSession\x12\x1d.user.v2.RevokeSessionRequest\x1a\x1e.user.v2.RevokeSessionResponse\x12Z\n" +
+	"\x11RevokeAllSessions\x12!.user.v2.RevokeAllSessionsRequest\x1a\".user.v2.RevokeAllSessionsResponse2\xd6\x01\n" +
+	"\x10UserAdminService\x12`\n" +
+	"\x13ListProfilePictures\x12#.user.v2.ListProfilePicturesRequest\x1a$.user.v2.ListProfilePicturesResponse\x12`\n" +
+	"\x13ClearProfilePicture\x12#.user.v2.ClearProfilePictureRequest\x1a$.user.v2.ClearProfilePictureResponseB!Z\x1fentry-task/proto/user/v2;userv2b\x06proto3"
+
+var (
+	file_proto_user_v2_user_proto_rawDescOnce sync.Once
+	file_proto_user_v2_user_proto_rawDescData []byte
+)
+
+func file_proto_user_v2_user_proto_rawDescGZIP() []byte {
+	file_proto_user_v2_user_proto_rawDescOnce.Do(func() {
+		file_proto_user_v2_user_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_proto_user_v2_user_proto_rawDesc), len(file_proto_user_v2_user_proto_rawDesc)))
+	})
+	return file_proto_user_v2_user_proto_rawDescData
+}
+
+var file_proto_user_v2_user_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_proto_user_v2_user_proto_msgTypes = make([]protoimpl.MessageInfo, 32)
+var file_proto_user_v2_user_proto_goTypes = []any{
+	(ErrorReason)(0),                     // 0: user.v2.ErrorReason
+	(*RegisterRequest)(nil),              // 1: user.v2.RegisterRequest
+	(*RegisterResponse)(nil),             // 2: user.v2.RegisterResponse
+	(*LoginRequest)(nil),                 // 3: user.v2.LoginRequest
+	(*LoginResponse)(nil),                // 4: user.v2.LoginResponse
+	(*RefreshTokenRequest)(nil),          // 5: user.v2.RefreshTokenRequest
+	(*RefreshTokenResponse)(nil),         // 6: user.v2.RefreshTokenResponse
+	(*LogoutRequest)(nil),                // 7: user.v2.LogoutRequest
+	(*LogoutResponse)(nil),               // 8: user.v2.LogoutResponse
+	(*GetProfileRequest)(nil),            // 9: user.v2.GetProfileRequest
+	(*GetProfileResponse)(nil),           // 10: user.v2.GetProfileResponse
+	(*GetPublicProfileRequest)(nil),      // 11: user.v2.GetPublicProfileRequest
+	(*GetPublicProfileResponse)(nil),     // 12: user.v2.GetPublicProfileResponse
+	(*UpdateNicknameRequest)(nil),        // 13: user.v2.UpdateNicknameRequest
+	(*UpdateNicknameResponse)(nil),       // 14: user.v2.UpdateNicknameResponse
+	(*UpdateProfilePictureRequest)(nil),  // 15: user.v2.UpdateProfilePictureRequest
+	(*UpdateProfilePictureResponse)(nil), // 16: user.v2.UpdateProfilePictureResponse
+	(*ChangePasswordRequest)(nil),        // 17: user.v2.ChangePasswordRequest
+	(*ChangePasswordResponse)(nil),       // 18: user.v2.ChangePasswordResponse
+	(*ListSessionsRequest)(nil),          // 19: user.v2.ListSessionsRequest
+	(*ListSessionsResponse)(nil),         // 20: user.v2.ListSessionsResponse
+	(*RevokeSessionRequest)(nil),         // 21: user.v2.RevokeSessionRequest
+	(*RevokeSessionResponse)(nil),        // 22: user.v2.RevokeSessionResponse
+	(*RevokeAllSessionsRequest)(nil),     // 23: user.v2.RevokeAllSessionsRequest
+	(*RevokeAllSessionsResponse)(nil),    // 24: user.v2.RevokeAllSessionsResponse
+	(*SessionInfo)(nil),                  // 25: user.v2.SessionInfo
+	(*ListProfilePicturesRequest)(nil),   // 26: user.v2.ListProfilePicturesRequest
+	(*ListProfilePicturesResponse)(nil),  // 27: user.v2.ListProfilePicturesResponse
+	(*ProfilePictureEntry)(nil),          // 28: user.v2.ProfilePictureEntry
+	(*ClearProfilePictureRequest)(nil),   // 29: user.v2.ClearProfilePictureRequest
+	(*ClearProfilePictureResponse)(nil),  // 30: user.v2.ClearProfilePictureResponse
+	(*UserProfile)(nil),                  // 31: user.v2.UserProfile
+	(*PublicProfile)(nil),                // 32: user.v2.PublicProfile
+}
+var file_proto_user_v2_user_proto_depIdxs = []int32{
+	31, // 0: user.v2.RegisterResponse.user:type_name -> user.v2.UserProfile
+	31, // 1: user.v2.LoginResponse.user:type_name -> user.v2.UserProfile
+	31, // 2: user.v2.GetProfileResponse.user:type_name -> user.v2.UserProfile
+	32, // 3: user.v2.GetPublicProfileResponse.user:type_name -> user.v2.PublicProfile
+	31, // 4: user.v2.UpdateNicknameResponse.user:type_name -> user.v2.UserProfile
+	31, // 5: user.v2.UpdateProfilePictureResponse.user:type_name -> user.v2.UserProfile
+	25, // 6: user.v2.ListSessionsResponse.sessions:type_name -> user.v2.SessionInfo
+	28, // 7: user.v2.ListProfilePicturesResponse.entries:type_name -> user.v2.ProfilePictureEntry
+	1,  // 8: user.v2.UserService.Register:input_type -> user.v2.RegisterRequest
+	3,  // 9: user.v2.UserService.Login:input_type -> user.v2.LoginRequest
+	7,  // 10: user.v2.UserService.Logout:input_type -> user.v2.LogoutRequest
+	5,  // 11: user.v2.UserService.RefreshToken:input_type -> user.v2.RefreshTokenRequest
+	9,  // 12: user.v2.UserService.GetProfile:input_type -> user.v2.GetProfileRequest
+	11, // 13: user.v2.UserService.GetPublicProfile:input_type -> user.v2.GetPublicProfileRequest
+	13, // 14: user.v2.UserService.UpdateNickname:input_type -> user.v2.UpdateNicknameRequest
+	15, // 15: user.v2.UserService.UpdateProfilePicture:input_type -> user.v2.UpdateProfilePictureRequest
+	17, // 16: user.v2.UserService.ChangePassword:input_type -> user.v2.ChangePasswordRequest
+	19, // 17: user.v2.UserService.ListSessions:input_type -> user.v2.ListSessionsRequest
+	21, // 18: user.v2.UserService.RevokeSession:input_type -> user.v2.RevokeSessionRequest
+	23, // 19: user.v2.UserService.RevokeAllSessions:input_type -> user.v2.RevokeAllSessionsRequest
+	26, // 20: user.v2.UserAdminService.ListProfilePictures:input_type -> user.v2.ListProfilePicturesRequest
+	29, // 21: user.v2.UserAdminService.ClearProfilePicture:input_type -> user.v2.ClearProfilePictureRequest
+	2,  // 22: user.v2.UserService.Register:output_type -> user.v2.RegisterResponse
+	4,  // 23: user.v2.UserService.Login:output_type -> user.v2.LoginResponse
+	8,  // 24: user.v2.UserService.Logout:output_type -> user.v2.LogoutResponse
+	6,  // 25: user.v2.UserService.RefreshToken:output_type -> user.v2.RefreshTokenResponse
+	10, // 26: user.v2.UserService.GetProfile:output_type -> user.v2.GetProfileResponse
+	12, // 27: user.v2.UserService.GetPublicProfile:output_type -> user.v2.GetPublicProfileResponse
+	14, // 28: user.v2.UserService.UpdateNickname:output_type -> user.v2.UpdateNicknameResponse
+	16, // 29: user.v2.UserService.UpdateProfilePicture:output_type -> user.v2.UpdateProfilePictureResponse
+	18, // 30: user.v2.UserService.ChangePassword:output_type -> user.v2.ChangePasswordResponse
+	20, // 31: user.v2.UserService.ListSessions:output_type -> user.v2.ListSessionsResponse
+	22, // 32: user.v2.UserService.RevokeSession:output_type -> user.v2.RevokeSessionResponse
+	24, // 33: user.v2.UserService.RevokeAllSessions:output_type -> user.v2.RevokeAllSessionsResponse
+	27, // 34: user.v2.UserAdminService.ListProfilePictures:output_type -> user.v2.ListProfilePicturesResponse
+	30, // 35: user.v2.UserAdminService.ClearProfilePicture:output_type -> user.v2.ClearProfilePictureResponse
+	22, // [22:36] is the sub-list for method output_type
+	8,  // [8:22] is the sub-list for method input_type
+	8,  // [8:8] is the sub-list for extension type_name
+	8,  // [8:8] is the sub-list for extension extendee
+	0,  // [0:8] is the sub-list for field type_name
+}
+
+func init() { file_proto_user_v2_user_proto_init() }
+func file_proto_user_v2_user_proto_init() {
+	if File_proto_user_v2_user_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_user_v2_user_proto_rawDesc), len(file_proto_user_v2_user_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   32,
+			NumExtensions: 0,
+			NumServices:   2,
+		},
+		GoTypes:           file_proto_user_v2_user_proto_goTypes,
+		DependencyIndexes: file_proto_user_v2_user_proto_depIdxs,
+		EnumInfos:         file_proto_user_v2_user_proto_enumTypes,
+		MessageInfos:      file_proto_user_v2_user_proto_msgTypes,
+	}.Build()
+	File_proto_user_v2_user_proto = out.File
+	file_proto_user_v2_user_proto_goTypes = nil
+	file_proto_user_v2_user_proto_depIdxs = nil
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+
+	"entry-task/tcpserver/pkg/db"
+	"entry-task/tcpserver/pkg/redis"
+)
+
+// ============================================================================
+// 管理端口指标（/metrics 由 pkg/admin 提供）
+// ============================================================================
+
+// registerPoolMetrics 注册数据库（主库及各从库）和Redis连接池指标
+func registerPoolMetrics(cluster *db.Cluster, client redis.Client) error {
+	for name, pool := range cluster.Pools() {
+		if err := prometheus.Register(collectors.NewDBStatsCollector(pool, name)); err != nil {
+			return err
+		}
+	}
+	return prometheus.Register(redis.NewPoolCollector(client))
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+
+	"google.golang.org/grpc/health"
+
+	pb "entry-task/proto/user"
+	pbv2 "entry-task/proto/user/v2"
+	"entry-task/tcpserver/config"
+	"entry-task/tcpserver/internal/service"
+	"entry-task/tcpserver/pkg/db"
+	healthcheck "entry-task/tcpserver/pkg/health"
+	"entry-task/tcpserver/pkg/redis"
+)
+
+// ============================================================================
+// 健康检查
+// ============================================================================
+
+// newHealthChecker 创建依赖健康检查器：主库 Ping、Redis Ping、雪花ID生成器可用
+// 从库不可用时读请求回退到主库，不影响就绪状态
+func newHealthChecker(server *health.Server, cfg *config.Config, cluster *db.Cluster, client redis.Client, idGen service.IDGenerator) *healthcheck.Checker {
+	checks := []healthcheck.Check{
+		{Name: "database", Check: func(ctx context.Context) error {
+			return cluster.Primary().PingContext(ctx)
+		}},
+		{Name: "redis", Check: client.Ping},
+	}
+	if r, ok := idGen.(interface{ Ready() error }); ok {
+		checks = append(checks, healthcheck.Check{Name: "snowflake", Check: func(context.Context) error {
+			return r.Ready()
+		}})
+	}
+
+	return healthcheck.NewChecker(server,
+		[]string{pb.UserService_ServiceDesc.ServiceName, pbv2.UserService_ServiceDesc.ServiceName},
+		checks,
+		cfg.Server.GetHealthCheckInterval(),
+		cfg.Server.GetHealthCheckTimeout(),
+	)
+}
@@ -1,28 +1,51 @@
 package main
 
 import (
+	"context"
+	"entry-task/pkg/admin"
+	"entry-task/pkg/tracing"
 	pb "entry-task/proto/user"
+	pbv2 "entry-task/proto/user/v2"
 	"entry-task/tcpserver/config"
 	"entry-task/tcpserver/internal/middleware"
+	"entry-task/tcpserver/internal/repository"
 	"entry-task/tcpserver/internal/rpchandler"
+	"entry-task/tcpserver/internal/service"
 	"entry-task/tcpserver/pkg/container"
+	"entry-task/tcpserver/pkg/db"
 	"entry-task/tcpserver/pkg/redis"
 	"flag"
 	"net"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc/filters"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
 
-	log "entry-task/tcpserver/pkg/logger"
+	log "entry-task/pkg/logger"
 )
 
+// keepaliveMinTime 客户端 keepalive ping 的最小间隔，需小于网关 grpc.keepalive.time
+const keepaliveMinTime = 10 * time.Second
+
 var (
 	configPath = flag.String("config", "./tcpserver/config/config.yaml", "配置文件路径")
 )
 
+// 用法：
+//
+//	tcpserver [-config path]                          启动 TCP Server
+//	tcpserver [-config path] migrate up               执行所有未执行的数据库迁移
+//	tcpserver [-config path] migrate down [N]         回滚最近 N 个迁移（默认 1）
+//	tcpserver [-config path] migrate status           查看迁移状态
+
 func main() {
 	// 解析命令行参数
 	flag.Parse()
@@ -44,9 +67,30 @@ func main() {
 	}
 	defer log.Sync()
 
+	// 数据库迁移子命令
+	if flag.Arg(0) == "migrate" {
+		code := runMigrate(cfg, flag.Args()[1:])
+		log.Sync()
+		os.Exit(code)
+	}
+
 	log.Info("TCP Server 启动中...")
 	log.Info("配置加载成功", zap.String("config_path", *configPath))
 
+	// 初始化链路追踪（需在创建数据库和 Redis 客户端之前）
+	shutdownTracing, err := tracing.Init(&tracing.Config{
+		ServiceName: cfg.Tracing.GetServiceName(),
+		Exporter:    cfg.Tracing.Exporter,
+		Endpoint:    cfg.Tracing.Endpoint,
+		Insecure:    cfg.Tracing.Insecure,
+		FilePath:    cfg.Tracing.FilePath,
+		SampleRatio: cfg.Tracing.SampleRatio,
+		Logger:      log.Logger,
+	})
+	if err != nil {
+		log.Fatal("初始化链路追踪失败", zap.Error(err))
+	}
+
 	// 3. 初始化依赖注入容器
 	if err := container.Init(); err != nil {
 		log.Fatal("初始化容器失败", zap.Error(err))
@@ -60,7 +104,14 @@ func main() {
 		log.Fatal("注册配置失败", zap.Error(err))
 	}
 
-	// 5. 从容器获取 RedisManager（用于鉴权拦截器）
+	// 5. 自动执行数据库迁移
+	if cfg.Database.AutoMigrate {
+		if err := container.Invoke(migrateUp); err != nil {
+			log.Fatal("执行数据库迁移失败", zap.Error(err))
+		}
+	}
+
+	// 6. 从容器获取 RedisManager（用于鉴权拦截器）
 	var redisManager redis.Manager
 	if err := container.Invoke(func(rm redis.Manager) {
 		redisManager = rm
@@ -69,40 +120,86 @@ func main() {
 	}
 	log.Info("RedisManager 初始化成功")
 
-	// 6. 创建 gRPC Server，注册拦截器链
+	// 7. 创建 gRPC Server，注册拦截器链
 	grpcServer := grpc.NewServer(
+		// 允许 HTTP 网关在空闲连接上发送 keepalive ping（默认策略会以 too_many_pings 断开连接）
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             keepaliveMinTime,
+			PermitWithoutStream: true,
+		}),
+		// 链路追踪：从 metadata 中提取上游 Trace Context（健康检查不产生 Span）
+		grpc.StatsHandler(otelgrpc.NewServerHandler(otelgrpc.WithFilter(filters.Not(filters.HealthCheck())))),
 		grpc.ChainUnaryInterceptor(
-			middleware.RecoveryInterceptor(),         // 第1层：Panic 恢复（最外层）
-			middleware.LoggingInterceptor(),          // 第2层：日志记录
-			middleware.AuthInterceptor(redisManager), // 第3层：鉴权验证
-			middleware.MetricsInterceptor(),          // 第4层：性能监控（最内层）
+			middleware.RequestIDInterceptor(),                            // 第1层：请求ID（最外层，之后所有日志都携带）
+			middleware.RecoveryInterceptor(),                             // 第2层：Panic 恢复
+			middleware.MetricsInterceptor(),                              // 第3层：性能监控（鉴权失败的请求也计入指标）
+			middleware.LoggingInterceptor(),                              // 第4层：日志记录
+			middleware.InternalAuthInterceptor(cfg.Server.InternalToken), // 第5层：内部服务鉴权（只作用于 UserAdminService）
+			middleware.AuthInterceptor(redisManager),                     // 第6层：鉴权验证（最内层，跳过内部服务）
 		),
 	)
 	log.Info("gRPC Server 创建成功，拦截器链已注册")
 
-	// 7. 从容器获取 Handler
+	// 8. 从容器获取 Handler
 	var handler *rpchandler.UserServiceHandler
-	if err := container.Invoke(func(h *rpchandler.UserServiceHandler) {
-		handler = h
+	var handlerV2 *rpchandler.UserServiceV2Handler
+	var adminHandler *rpchandler.UserAdminServiceHandler
+	if err := container.Invoke(func(h *rpchandler.UserServiceHandler, h2 *rpchandler.UserServiceV2Handler, ha *rpchandler.UserAdminServiceHandler) {
+		handler, handlerV2, adminHandler = h, h2, ha
 	}); err != nil {
 		log.Fatal("获取 Handler 失败", zap.Error(err))
 	}
 
-	// 8. 注册 gRPC 服务
+	// 9. 注册 gRPC 服务（v1 与 v2 同时提供，已有客户端不受影响）
 	pb.RegisterUserServiceServer(grpcServer, handler)
+	pbv2.RegisterUserServiceServer(grpcServer, handlerV2)
+	services := []string{pb.UserService_ServiceDesc.ServiceName, pbv2.UserService_ServiceDesc.ServiceName}
+	// 内部管理服务只在配置了鉴权凭证时提供
+	if cfg.Server.InternalToken != "" {
+		pbv2.RegisterUserAdminServiceServer(grpcServer, adminHandler)
+		services = append(services, pbv2.UserAdminService_ServiceDesc.ServiceName)
+	}
 	log.Info("gRPC 服务注册成功",
-		zap.String("service", "UserService"),
-		zap.Int("methods", 5),
+		zap.Strings("services", services),
+		zap.Int("methods", len(pb.UserService_ServiceDesc.Methods)),
 	)
 
-	// 9. 监听端口
+	// 10. 注册健康检查服务（状态随数据库、Redis、雪花ID检查结果变化）
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+	healthCtx, stopHealth := context.WithCancel(context.Background())
+	if err := container.Invoke(func(cluster *db.Cluster, client redis.Client, idGen service.IDGenerator) {
+		go newHealthChecker(healthServer, cfg, cluster, client, idGen).Run(healthCtx)
+	}); err != nil {
+		log.Fatal("启动健康检查失败", zap.Error(err))
+	}
+
+	// 11. 启动缓存失效 Worker（延迟双删的第二次删除）
+	workerCtx, stopWorker := context.WithCancel(context.Background())
+	workerDone := make(chan struct{})
+	if err := container.Invoke(func(w *repository.CacheInvalidationWorker) {
+		go func() {
+			defer close(workerDone)
+			w.Run(workerCtx)
+		}()
+	}); err != nil {
+		log.Fatal("启动缓存失效 Worker 失败", zap.Error(err))
+	}
+
+	// 12. 启动管理端口（Prometheus /metrics）
+	if err := container.Invoke(registerPoolMetrics); err != nil {
+		log.Fatal("注册连接池指标失败", zap.Error(err))
+	}
+	adminServer := admin.Start(cfg.Server.GetAdminAddr())
+
+	// 13. 监听端口
 	addr := cfg.Server.GetTCPAddr()
 	lis, err := net.Listen("tcp", addr)
 	if err != nil {
 		log.Fatal("监听失败", zap.String("addr", addr), zap.Error(err))
 	}
 
-	// 10. 启动 gRPC Server（在 goroutine 中）
+	// 14. 启动 gRPC Server（在 goroutine 中）
 	go func() {
 		log.Info("TCP Server 启动成功",
 			zap.String("addr", addr),
@@ -113,14 +210,38 @@ func main() {
 		}
 	}()
 
-	// 11. 等待退出信号
+	// 15. 等待退出信号
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
 	log.Info("收到退出信号，开始优雅关闭...")
 
-	// 12. 优雅关闭 gRPC Server
+	// 16. 先将健康状态置为 NOT_SERVING，使负载均衡不再分配新请求
+	stopHealth()
+	healthServer.Shutdown()
+
+	// 17. 优雅关闭 gRPC Server，之后停止 Worker（未处理的失效记录保留在数据库中，重启后继续处理）
 	grpcServer.GracefulStop()
+	admin.Stop(adminServer)
+	stopWorker()
+	<-workerDone
+
+	// 停止 Redis 后台任务（吊销列表同步等）
+	redisManager.Close()
+
+	// 关闭数据库连接（停止从库健康检查）
+	if err := container.Invoke(func(cluster *db.Cluster) error {
+		return cluster.Close()
+	}); err != nil {
+		log.Error("关闭数据库连接失败", zap.Error(err))
+	}
+
+	// 导出剩余的 Span
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := shutdownTracing(ctx); err != nil {
+		log.Error("关闭链路追踪失败", zap.Error(err))
+	}
 	log.Info("TCP Server 已关闭")
 }
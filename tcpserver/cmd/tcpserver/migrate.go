@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+
+	"entry-task/tcpserver/config"
+	"entry-task/tcpserver/pkg/db"
+	"entry-task/tcpserver/pkg/migrate"
+
+	log "entry-task/pkg/logger"
+)
+
+// migrateUp 启动时自动执行未执行的数据库迁移
+func migrateUp(database *sqlx.DB) error {
+	migrator, err := migrate.New(database)
+	if err != nil {
+		return err
+	}
+
+	executed, err := migrator.Up(context.Background())
+	if err != nil {
+		return err
+	}
+	log.Info("数据库迁移完成", zap.Int("executed", len(executed)))
+	return nil
+}
+
+// runMigrate 执行 migrate 子命令，返回进程退出码
+func runMigrate(cfg *config.Config, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "用法: tcpserver [-config path] migrate <up|down [N]|status>")
+		return 2
+	}
+
+	database, err := db.InitDB(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "连接数据库失败: %v\n", err)
+		return 1
+	}
+	defer database.Close()
+
+	migrator, err := migrate.New(database)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "加载迁移文件失败: %v\n", err)
+		return 1
+	}
+
+	ctx := context.Background()
+	switch args[0] {
+	case "up":
+		executed, err := migrator.Up(ctx)
+		for _, m := range executed {
+			fmt.Printf("已执行 %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "执行迁移失败: %v\n", err)
+			return 1
+		}
+		if len(executed) == 0 {
+			fmt.Println("数据库已是最新版本")
+		}
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps <= 0 {
+				fmt.Fprintf(os.Stderr, "回滚数量无效: %s\n", args[1])
+				return 2
+			}
+		}
+		reverted, err := migrator.Down(ctx, steps)
+		for _, m := range reverted {
+			fmt.Printf("已回滚 %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "回滚迁移失败: %v\n", err)
+			return 1
+		}
+
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "查询迁移状态失败: %v\n", err)
+			return 1
+		}
+		for _, s := range statuses {
+			state := "未执行"
+			if s.Applied {
+				state = "已执行 " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%-40s %s\n", s.Version, s.Name, state)
+		}
+
+	default:
+		fmt.Fprintf(os.Stderr, "未知的 migrate 命令: %s\n", args[0])
+		return 2
+	}
+
+	return 0
+}
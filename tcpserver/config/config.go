@@ -2,6 +2,8 @@ package config
 
 import (
 	"fmt"
+	"net"
+	"net/url"
 	"os"
 	"strconv"
 	"time"
@@ -15,15 +17,43 @@ type Config struct {
 	Database  DatabaseConfig  `yaml:"database"`
 	Redis     RedisConfig     `yaml:"redis"`
 	Snowflake SnowflakeConfig `yaml:"snowflake"`
+	Session   SessionConfig   `yaml:"session"`
+	UserCache UserCacheConfig `yaml:"user_cache"`
 	Log       LogConfig       `yaml:"log"`
+	Tracing   TracingConfig   `yaml:"tracing"`
 }
 
 // ServerConfig 服务器配置
 type ServerConfig struct {
-	Host    string `yaml:"host"`
-	Port    int    `yaml:"port"`
-	TCPPort int    `yaml:"tcp_port"` // TCP Server (gRPC) 端口
-	Mode    string `yaml:"mode"`
+	Host      string `yaml:"host"`
+	Port      int    `yaml:"port"`
+	TCPPort   int    `yaml:"tcp_port"`   // TCP Server (gRPC) 端口
+	AdminPort int    `yaml:"admin_port"` // 管理端口（/metrics），0 表示不启动
+	Mode      string `yaml:"mode"`
+
+	// 时长配置统一以单位为后缀（_ms 毫秒、_sec 秒），0 或未配置时使用默认值
+	HealthCheckIntervalMs int `yaml:"health_check_interval_ms"` // 毫秒，依赖健康检查间隔
+	HealthCheckTimeoutMs  int `yaml:"health_check_timeout_ms"`  // 毫秒，单次健康检查超时
+
+	InternalToken string `yaml:"internal_token"` // 内部服务（UserAdminService）鉴权凭证，为空时不提供内部服务
+}
+
+// GetHealthCheckInterval 获取依赖健康检查间隔
+func (s *ServerConfig) GetHealthCheckInterval() time.Duration {
+	return time.Duration(s.HealthCheckIntervalMs) * time.Millisecond
+}
+
+// GetHealthCheckTimeout 获取单次健康检查超时
+func (s *ServerConfig) GetHealthCheckTimeout() time.Duration {
+	return time.Duration(s.HealthCheckTimeoutMs) * time.Millisecond
+}
+
+// GetAdminAddr 获取管理端口地址，未配置时返回空字符串
+func (s *ServerConfig) GetAdminAddr() string {
+	if s.AdminPort <= 0 {
+		return ""
+	}
+	return s.Host + ":" + strconv.Itoa(s.AdminPort)
 }
 
 // GetTCPAddr 获取 TCP Server 地址
@@ -33,22 +63,123 @@ func (s *ServerConfig) GetTCPAddr() string {
 
 // DatabaseConfig 数据库配置
 type DatabaseConfig struct {
-	Driver          string `yaml:"driver"` // 数据库驱动: mysql, postgres
-	Host            string `yaml:"host"`
-	Port            int    `yaml:"port"`
-	Username        string `yaml:"username"`
-	Password        string `yaml:"password"`
-	Database        string `yaml:"database"`
-	Charset         string `yaml:"charset"`
-	ParseTime       bool   `yaml:"parse_time"`
-	Loc             string `yaml:"loc"`
-	MaxOpenConns    int    `yaml:"max_open_conns"`
-	MaxIdleConns    int    `yaml:"max_idle_conns"`
-	ConnMaxLifetime int    `yaml:"conn_max_lifetime"` // 秒
+	Driver             string `yaml:"driver"` // 数据库驱动: mysql（8.0+）, postgres（9.5+，别名 pgsql），缓存失效 Worker 依赖 SKIP LOCKED
+	Host               string `yaml:"host"`
+	Port               int    `yaml:"port"`
+	Username           string `yaml:"username"`
+	Password           string `yaml:"password"`
+	Database           string `yaml:"database"`
+	Charset            string `yaml:"charset"`
+	ParseTime          bool   `yaml:"parse_time"`
+	Loc                string `yaml:"loc"`
+	MaxOpenConns       int    `yaml:"max_open_conns"`
+	MaxIdleConns       int    `yaml:"max_idle_conns"`
+	ConnMaxLifetimeSec int    `yaml:"conn_max_lifetime_sec"` // 秒，连接最长复用时间，0 表示不限制
+	SSLMode            string `yaml:"ssl_mode"`              // PostgreSQL sslmode: disable, require, verify-full 等，默认 disable
+	AutoMigrate        bool   `yaml:"auto_migrate"`          // 启动时自动执行未执行的数据库迁移
+	ReadTimeoutMs      int    `yaml:"read_timeout_ms"`       // 毫秒，单条查询超时，0 表示使用 repository 包中的默认值
+	WriteTimeoutMs     int    `yaml:"write_timeout_ms"`      // 毫秒，单条写入/更新事务超时，0 表示使用默认值
+	BatchTimeoutMs     int    `yaml:"batch_timeout_ms"`      // 毫秒，批量写入事务超时，0 表示使用默认值
+
+	// 读写分离（未配置从库时读写都走主库）
+	Replicas               []ReplicaConfig `yaml:"replicas"`                  // 只读从库
+	ReplicaCheckIntervalMs int             `yaml:"replica_check_interval_ms"` // 毫秒，从库健康检查间隔，0 表示使用默认值
+	PrimaryStickyMs        int             `yaml:"primary_sticky_ms"`         // 毫秒，用户写入后其读请求固定走主库的时间（应大于主从延迟），0 表示使用默认值
+}
+
+// ReplicaConfig 只读从库配置（其余连接参数与主库相同）
+type ReplicaConfig struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	Username string `yaml:"username"` // 未配置时使用主库用户名
+	Password string `yaml:"password"` // 未配置时使用主库密码
+}
+
+// 数据库驱动名（sqlx 注册名）
+const (
+	DriverMySQL    = "mysql"
+	DriverPostgres = "postgres"
+)
+
+// GetDriverName 获取 sqlx 驱动名（pgsql 视为 postgres，未配置时为 mysql）
+func (d *DatabaseConfig) GetDriverName() string {
+	switch d.Driver {
+	case "", DriverMySQL:
+		return DriverMySQL
+	case DriverPostgres, "pgsql":
+		return DriverPostgres
+	default:
+		return d.Driver
+	}
+}
+
+// GetReadTimeout 获取查询超时时间
+func (d *DatabaseConfig) GetReadTimeout() time.Duration {
+	return time.Duration(d.ReadTimeoutMs) * time.Millisecond
+}
+
+// GetWriteTimeout 获取写操作超时时间
+func (d *DatabaseConfig) GetWriteTimeout() time.Duration {
+	return time.Duration(d.WriteTimeoutMs) * time.Millisecond
+}
+
+// GetBatchTimeout 获取批量写入超时时间
+func (d *DatabaseConfig) GetBatchTimeout() time.Duration {
+	return time.Duration(d.BatchTimeoutMs) * time.Millisecond
+}
+
+// GetReplicaCheckInterval 获取从库健康检查间隔
+func (d *DatabaseConfig) GetReplicaCheckInterval() time.Duration {
+	return time.Duration(d.ReplicaCheckIntervalMs) * time.Millisecond
+}
+
+// GetPrimarySticky 获取写后读主库的时间窗口
+func (d *DatabaseConfig) GetPrimarySticky() time.Duration {
+	return time.Duration(d.PrimaryStickyMs) * time.Millisecond
+}
+
+// ForReplica 生成从库的连接配置（继承主库的驱动、库名和连接池等参数）
+func (d *DatabaseConfig) ForReplica(r ReplicaConfig) DatabaseConfig {
+	replica := *d
+	replica.Replicas = nil
+	replica.Host = r.Host
+	replica.Port = r.Port
+	if r.Username != "" {
+		replica.Username = r.Username
+	}
+	if r.Password != "" {
+		replica.Password = r.Password
+	}
+	return replica
 }
 
-// GetDSN 获取数据库连接字符串
+// GetDSN 获取数据库连接字符串（按驱动生成）
 func (d *DatabaseConfig) GetDSN() string {
+	if d.GetDriverName() == DriverPostgres {
+		return d.getPostgresDSN()
+	}
+	return d.getMySQLDSN()
+}
+
+// getPostgresDSN PostgreSQL 连接字符串（URL格式，用户名密码自动转义）
+func (d *DatabaseConfig) getPostgresDSN() string {
+	sslMode := d.SSLMode
+	if sslMode == "" {
+		sslMode = "disable"
+	}
+
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(d.Username, d.Password),
+		Host:     net.JoinHostPort(d.Host, strconv.Itoa(d.Port)),
+		Path:     "/" + d.Database,
+		RawQuery: url.Values{"sslmode": {sslMode}}.Encode(),
+	}
+	return dsn.String()
+}
+
+// getMySQLDSN MySQL 连接字符串
+func (d *DatabaseConfig) getMySQLDSN() string {
 	return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=%s&parseTime=%t&loc=%s",
 		d.Username,
 		d.Password,
@@ -63,16 +194,16 @@ func (d *DatabaseConfig) GetDSN() string {
 
 // RedisConfig Redis配置
 type RedisConfig struct {
-	Host         string `yaml:"host"`
-	Port         int    `yaml:"port"`
-	Password     string `yaml:"password"`
-	DB           int    `yaml:"db"`
-	PoolSize     int    `yaml:"pool_size"`
-	MinIdleConns int    `yaml:"min_idle_conns"`
-	MaxRetries   int    `yaml:"max_retries"`
-	DialTimeout  int    `yaml:"dial_timeout"`  // 秒
-	ReadTimeout  int    `yaml:"read_timeout"`  // 秒
-	WriteTimeout int    `yaml:"write_timeout"` // 秒
+	Host            string `yaml:"host"`
+	Port            int    `yaml:"port"`
+	Password        string `yaml:"password"`
+	DB              int    `yaml:"db"`
+	PoolSize        int    `yaml:"pool_size"`
+	MinIdleConns    int    `yaml:"min_idle_conns"`
+	MaxRetries      int    `yaml:"max_retries"`
+	DialTimeoutSec  int    `yaml:"dial_timeout_sec"`  // 秒，0 表示使用 go-redis 默认值
+	ReadTimeoutSec  int    `yaml:"read_timeout_sec"`  // 秒，0 表示使用 go-redis 默认值
+	WriteTimeoutSec int    `yaml:"write_timeout_sec"` // 秒，0 表示使用 go-redis 默认值
 }
 
 // GetAddr 获取Redis地址
@@ -82,17 +213,17 @@ func (r *RedisConfig) GetAddr() string {
 
 // GetDialTimeout 获取连接超时时间
 func (r *RedisConfig) GetDialTimeout() time.Duration {
-	return time.Duration(r.DialTimeout) * time.Second
+	return time.Duration(r.DialTimeoutSec) * time.Second
 }
 
 // GetReadTimeout 获取读超时时间
 func (r *RedisConfig) GetReadTimeout() time.Duration {
-	return time.Duration(r.ReadTimeout) * time.Second
+	return time.Duration(r.ReadTimeoutSec) * time.Second
 }
 
 // GetWriteTimeout 获取写超时时间
 func (r *RedisConfig) GetWriteTimeout() time.Duration {
-	return time.Duration(r.WriteTimeout) * time.Second
+	return time.Duration(r.WriteTimeoutSec) * time.Second
 }
 
 // SnowflakeConfig 雪花ID配置
@@ -100,6 +231,132 @@ type SnowflakeConfig struct {
 	MachineID int64 `yaml:"machine_id"`
 }
 
+// SessionConfig Session配置（0 或未配置的时长使用 redis 包中的默认值）
+type SessionConfig struct {
+	Mode               string      `yaml:"mode"`                 // redis（默认，Redis Session Token）, token（签名Access Token + 刷新凭证）
+	IdleTimeoutSec     int         `yaml:"idle_timeout_sec"`     // 秒，无活动超过该时间后过期（token模式下为刷新凭证的空闲过期时间）
+	MaxLifetimeSec     int         `yaml:"max_lifetime_sec"`     // 秒，登录后的绝对最长有效期
+	RefreshIntervalSec int         `yaml:"refresh_interval_sec"` // 秒，同一Session两次续期的最小间隔
+	Token              TokenConfig `yaml:"token"`                // token模式配置
+}
+
+// Session模式
+const (
+	SessionModeRedis = "redis"
+	SessionModeToken = "token"
+)
+
+// TokenConfig 签名Access Token配置
+type TokenConfig struct {
+	Algorithm                 string           `yaml:"algorithm"`                    // 签名算法: hmac, ed25519
+	AccessTTLSec              int              `yaml:"access_ttl_sec"`               // 秒，Access Token有效期
+	ActiveKeyID               string           `yaml:"active_key_id"`                // 当前用于签发的密钥ID
+	Keys                      []TokenKeyConfig `yaml:"keys"`                         // 所有可用于验签的密钥（轮换时新旧密钥并存）
+	RevocationSyncIntervalSec int              `yaml:"revocation_sync_interval_sec"` // 秒，吊销列表同步间隔
+}
+
+// TokenKeyConfig 签名密钥配置
+type TokenKeyConfig struct {
+	ID        string `yaml:"id"`
+	Secret    string `yaml:"secret"`     // base64编码：HMAC密钥（至少32字节）或 Ed25519 seed（32字节），仅用于本地测试
+	SecretEnv string `yaml:"secret_env"` // 保存密钥（base64）的环境变量名，配置后优先于 secret，避免密钥提交到配置文件
+}
+
+// GetSecret 获取base64编码的密钥，配置了 secret_env 时从环境变量读取
+func (k *TokenKeyConfig) GetSecret() (string, error) {
+	if k.SecretEnv == "" {
+		return k.Secret, nil
+	}
+	secret := os.Getenv(k.SecretEnv)
+	if secret == "" {
+		return "", fmt.Errorf("环境变量 %s 未设置", k.SecretEnv)
+	}
+	return secret, nil
+}
+
+// GetAccessTTL 获取Access Token有效期
+func (t *TokenConfig) GetAccessTTL() time.Duration {
+	return time.Duration(t.AccessTTLSec) * time.Second
+}
+
+// GetRevocationSyncInterval 获取吊销列表同步间隔
+func (t *TokenConfig) GetRevocationSyncInterval() time.Duration {
+	return time.Duration(t.RevocationSyncIntervalSec) * time.Second
+}
+
+// GetIdleTimeout 获取空闲过期时间
+func (s *SessionConfig) GetIdleTimeout() time.Duration {
+	return time.Duration(s.IdleTimeoutSec) * time.Second
+}
+
+// GetMaxLifetime 获取绝对最长有效期
+func (s *SessionConfig) GetMaxLifetime() time.Duration {
+	return time.Duration(s.MaxLifetimeSec) * time.Second
+}
+
+// GetRefreshInterval 获取续期最小间隔
+func (s *SessionConfig) GetRefreshInterval() time.Duration {
+	return time.Duration(s.RefreshIntervalSec) * time.Second
+}
+
+// UserCacheConfig 用户缓存配置
+type UserCacheConfig struct {
+	Local        LocalCacheConfig   `yaml:"local"`         // 进程内缓存（L1），位于Redis缓存之前
+	EarlyRefresh EarlyRefreshConfig `yaml:"early_refresh"` // 概率提前刷新
+	Invalidation InvalidationConfig `yaml:"invalidation"`  // 缓存失效（延迟双删）Worker
+}
+
+// InvalidationConfig 缓存失效 Worker 配置（0 或未配置时使用 repository 包中的默认值）
+type InvalidationConfig struct {
+	DelayMs        int `yaml:"delay_ms"`         // 毫秒，更新后第二次删除缓存的延迟
+	PollIntervalMs int `yaml:"poll_interval_ms"` // 毫秒，轮询失效记录的间隔
+	BatchSize      int `yaml:"batch_size"`       // 每次处理的记录数
+	MaxAttempts    int `yaml:"max_attempts"`     // 最大重试次数，超过后放弃（缓存最迟在TTL后过期）
+}
+
+// GetDelay 获取第二次删除缓存的延迟
+func (i *InvalidationConfig) GetDelay() time.Duration {
+	return time.Duration(i.DelayMs) * time.Millisecond
+}
+
+// GetPollInterval 获取轮询间隔
+func (i *InvalidationConfig) GetPollInterval() time.Duration {
+	return time.Duration(i.PollIntervalMs) * time.Millisecond
+}
+
+// EarlyRefreshConfig 缓存概率提前刷新配置（XFetch）
+// 缓存临近过期时，按概率由单个请求在后台提前回源，避免热点键过期瞬间的并发回源
+type EarlyRefreshConfig struct {
+	Enabled bool    `yaml:"enabled"`
+	Beta    float64 `yaml:"beta"` // 越大越倾向于提前刷新，未配置时为 1.0
+}
+
+// GetBeta 获取提前刷新系数
+func (e *EarlyRefreshConfig) GetBeta() float64 {
+	if e.Beta <= 0 {
+		return 1.0
+	}
+	return e.Beta
+}
+
+// LocalCacheConfig 进程内用户缓存配置（0 或未配置时使用 redis 包中的默认值）
+type LocalCacheConfig struct {
+	Enabled          bool `yaml:"enabled"`
+	Capacity         int  `yaml:"capacity"`           // 最大缓存用户数
+	TTLSec           int  `yaml:"ttl_sec"`            // 秒，本地缓存有效期（失效广播丢失时的最大不一致时间）
+	StatsIntervalSec int  `yaml:"stats_interval_sec"` // 秒，统计信息输出间隔，-1 表示不输出
+}
+
+// GetTTL 获取本地缓存有效期
+func (l *LocalCacheConfig) GetTTL() time.Duration {
+	return time.Duration(l.TTLSec) * time.Second
+}
+
+// GetStatsInterval 获取统计信息输出间隔
+func (l *LocalCacheConfig) GetStatsInterval() time.Duration {
+	return time.Duration(l.StatsIntervalSec) * time.Second
+}
+
 // LogConfig 日志配置
 type LogConfig struct {
 	Level    string `yaml:"level"`
@@ -107,6 +364,27 @@ type LogConfig struct {
 	FilePath string `yaml:"file_path"`
 }
 
+// TracingConfig 链路追踪配置
+type TracingConfig struct {
+	Exporter    string   `yaml:"exporter"`     // none（默认）, otlp, stdout, file
+	ServiceName string   `yaml:"service_name"` // 服务名，未配置时为 tcpserver
+	Endpoint    string   `yaml:"endpoint"`     // OTLP Collector 地址（host:port）
+	Insecure    bool     `yaml:"insecure"`     // OTLP 不使用 TLS
+	FilePath    string   `yaml:"file_path"`    // file 导出方式的文件路径
+	SampleRatio *float64 `yaml:"sample_ratio"` // 采样比例（0~1），0 表示不采样根 Span，未配置时全部采样
+}
+
+// DefaultTracingServiceName 默认链路追踪服务名
+const DefaultTracingServiceName = "tcpserver"
+
+// GetServiceName 获取链路追踪服务名
+func (t *TracingConfig) GetServiceName() string {
+	if t.ServiceName == "" {
+		return DefaultTracingServiceName
+	}
+	return t.ServiceName
+}
+
 var globalConfig *Config
 
 // Load 加载配置文件
@@ -1,19 +1,40 @@
 package dto
 
+// ============================================================================
+// 注册相关 DTO
+// ============================================================================
+
+// RegisterDTO 注册请求
+type RegisterDTO struct {
+	Username string
+	Password string // 明文密码
+	Nickname string // 可选，为空时默认使用用户名
+}
+
 // ============================================================================
 // 登录相关 DTO
 // ============================================================================
 
 // LoginDTO 登录请求
 type LoginDTO struct {
-	Username string
-	Password string // 明文密码
+	Username  string
+	Password  string // 明文密码
+	ClientIP  string // 客户端IP（由HTTP网关透传）
+	UserAgent string // 客户端User-Agent（由HTTP网关透传）
 }
 
 // LoginResultDTO 登录结果
 type LoginResultDTO struct {
-	Token   string
-	Profile *UserProfileDTO
+	Token            string
+	ExpiresIn        int64  // Session有效期（秒）
+	RefreshToken     string // 刷新凭证（仅token模式）
+	RefreshExpiresIn int64  // 刷新凭证有效期（秒）
+	Profile          *UserProfileDTO
+}
+
+// RefreshTokenDTO 刷新凭证请求
+type RefreshTokenDTO struct {
+	RefreshToken string
 }
 
 // LogoutDTO 登出请求
@@ -35,3 +56,36 @@ type TokenResultDTO struct {
 	UserID uint64
 	Valid  bool
 }
+
+// ============================================================================
+// Session 管理 DTO
+// ============================================================================
+
+// SessionDTO Session信息（不包含token）
+type SessionDTO struct {
+	ID         string
+	CreatedAt  int64
+	LastSeenAt int64
+	ClientIP   string
+	UserAgent  string
+	Current    bool // 是否为当前请求使用的Session
+}
+
+// ListSessionsDTO 列出Session请求
+type ListSessionsDTO struct {
+	UserID uint64
+	Token  string // 当前Session，用于标记 Current
+}
+
+// RevokeSessionDTO 注销指定Session请求
+type RevokeSessionDTO struct {
+	UserID    uint64
+	SessionID string
+}
+
+// RevokeAllSessionsDTO 注销所有Session请求
+type RevokeAllSessionsDTO struct {
+	UserID         uint64
+	Token          string // 当前Session
+	IncludeCurrent bool   // 是否同时注销当前Session
+}
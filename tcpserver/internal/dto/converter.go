@@ -10,6 +10,15 @@ import (
 // Proto → DTO (gRPC 请求 → Service 层)
 // ============================================================================
 
+// FromProtoRegisterRequest Proto注册请求 → DTO
+func FromProtoRegisterRequest(req *pb.RegisterRequest) *RegisterDTO {
+	return &RegisterDTO{
+		Username: req.Username,
+		Password: req.Password,
+		Nickname: req.Nickname,
+	}
+}
+
 // FromProtoLoginRequest Proto登录请求 → DTO
 func FromProtoLoginRequest(req *pb.LoginRequest) *LoginDTO {
 	return &LoginDTO{
@@ -18,6 +27,13 @@ func FromProtoLoginRequest(req *pb.LoginRequest) *LoginDTO {
 	}
 }
 
+// FromProtoRefreshTokenRequest Proto刷新凭证请求 → DTO
+func FromProtoRefreshTokenRequest(req *pb.RefreshTokenRequest) *RefreshTokenDTO {
+	return &RefreshTokenDTO{
+		RefreshToken: req.RefreshToken,
+	}
+}
+
 // FromProtoLogoutRequest Proto登出请求 → DTO
 func FromProtoLogoutRequest(req *pb.LogoutRequest) *LogoutDTO {
 	return &LogoutDTO{
@@ -48,6 +64,41 @@ func FromProtoUpdateProfilePictureRequest(req *pb.UpdateProfilePictureRequest, u
 	}
 }
 
+// FromProtoChangePasswordRequest Proto修改密码请求 → DTO
+func FromProtoChangePasswordRequest(req *pb.ChangePasswordRequest, userID uint64) *ChangePasswordDTO {
+	return &ChangePasswordDTO{
+		UserID:      userID,
+		Token:       req.Token,
+		OldPassword: req.OldPassword,
+		NewPassword: req.NewPassword,
+	}
+}
+
+// FromProtoListSessionsRequest Proto列出Session请求 → DTO
+func FromProtoListSessionsRequest(req *pb.ListSessionsRequest, userID uint64) *ListSessionsDTO {
+	return &ListSessionsDTO{
+		UserID: userID,
+		Token:  req.Token,
+	}
+}
+
+// FromProtoRevokeSessionRequest Proto注销指定Session请求 → DTO
+func FromProtoRevokeSessionRequest(req *pb.RevokeSessionRequest, userID uint64) *RevokeSessionDTO {
+	return &RevokeSessionDTO{
+		UserID:    userID,
+		SessionID: req.SessionId,
+	}
+}
+
+// FromProtoRevokeAllSessionsRequest Proto注销所有Session请求 → DTO
+func FromProtoRevokeAllSessionsRequest(req *pb.RevokeAllSessionsRequest, userID uint64) *RevokeAllSessionsDTO {
+	return &RevokeAllSessionsDTO{
+		UserID:         userID,
+		Token:          req.Token,
+		IncludeCurrent: req.IncludeCurrent,
+	}
+}
+
 // ============================================================================
 // DTO → Proto (Service 层 → gRPC 响应)
 // ============================================================================
@@ -65,13 +116,37 @@ func (p *UserProfileDTO) ToProto() *pb.UserProfile {
 	}
 }
 
+// ToProtoRegisterResponse UserProfileDTO → Proto RegisterResponse
+func (p *UserProfileDTO) ToProtoRegisterResponse(code int32, message string) *pb.RegisterResponse {
+	return &pb.RegisterResponse{
+		Code:    code,
+		Message: message,
+		User:    p.ToProto(),
+	}
+}
+
 // ToProtoResponse LoginResultDTO → Proto LoginResponse
 func (r *LoginResultDTO) ToProtoResponse(code int32, message string) *pb.LoginResponse {
 	return &pb.LoginResponse{
-		Code:    code,
-		Message: message,
-		Token:   r.Token,
-		User:    r.Profile.ToProto(),
+		Code:             code,
+		Message:          message,
+		Token:            r.Token,
+		User:             r.Profile.ToProto(),
+		ExpiresIn:        r.ExpiresIn,
+		RefreshToken:     r.RefreshToken,
+		RefreshExpiresIn: r.RefreshExpiresIn,
+	}
+}
+
+// ToProtoRefreshTokenResponse LoginResultDTO → Proto RefreshTokenResponse（不包含用户信息）
+func (r *LoginResultDTO) ToProtoRefreshTokenResponse(code int32, message string) *pb.RefreshTokenResponse {
+	return &pb.RefreshTokenResponse{
+		Code:             code,
+		Message:          message,
+		Token:            r.Token,
+		ExpiresIn:        r.ExpiresIn,
+		RefreshToken:     r.RefreshToken,
+		RefreshExpiresIn: r.RefreshExpiresIn,
 	}
 }
 
@@ -110,6 +185,40 @@ func (p *UserProfileDTO) ToProtoUpdateProfilePictureResponse(code int32, message
 	}
 }
 
+// ToProtoChangePasswordResponse → Proto ChangePasswordResponse
+func ToProtoChangePasswordResponse(code int32, message string, revokedSessions int) *pb.ChangePasswordResponse {
+	return &pb.ChangePasswordResponse{
+		Code:            code,
+		Message:         message,
+		RevokedSessions: int32(revokedSessions),
+	}
+}
+
+// ToProto SessionDTO → Proto SessionInfo
+func (d *SessionDTO) ToProto() *pb.SessionInfo {
+	return &pb.SessionInfo{
+		SessionId:  d.ID,
+		CreatedAt:  d.CreatedAt,
+		LastSeenAt: d.LastSeenAt,
+		ClientIp:   d.ClientIP,
+		UserAgent:  d.UserAgent,
+		Current:    d.Current,
+	}
+}
+
+// ToProtoListSessionsResponse []SessionDTO → Proto ListSessionsResponse
+func ToProtoListSessionsResponse(code int32, message string, sessions []*SessionDTO) *pb.ListSessionsResponse {
+	items := make([]*pb.SessionInfo, 0, len(sessions))
+	for _, s := range sessions {
+		items = append(items, s.ToProto())
+	}
+	return &pb.ListSessionsResponse{
+		Code:     code,
+		Message:  message,
+		Sessions: items,
+	}
+}
+
 // ============================================================================
 // Model → DTO (Repository 层 → Service 层)
 // ============================================================================
@@ -143,6 +252,21 @@ func FromCachedUser(cached *redis.CachedUser) *UserProfileDTO {
 	}
 }
 
+// FromSessionInfo redis.SessionInfo → SessionDTO
+func FromSessionInfo(info *redis.SessionInfo) *SessionDTO {
+	if info == nil {
+		return nil
+	}
+	return &SessionDTO{
+		ID:         info.ID,
+		CreatedAt:  info.CreatedAt,
+		LastSeenAt: info.LastSeenAt,
+		ClientIP:   info.ClientIP,
+		UserAgent:  info.UserAgent,
+		Current:    info.Current,
+	}
+}
+
 // ============================================================================
 // DTO → Model (Service 层 → Repository 层)
 // ============================================================================
@@ -0,0 +1,199 @@
+package dto
+
+import (
+	pbv2 "entry-task/proto/user/v2"
+)
+
+// ============================================================================
+// Proto v2 → DTO (gRPC 请求 → Service 层)
+// ============================================================================
+
+// FromProtoV2RegisterRequest Proto注册请求 → DTO
+func FromProtoV2RegisterRequest(req *pbv2.RegisterRequest) *RegisterDTO {
+	return &RegisterDTO{
+		Username: req.Username,
+		Password: req.Password,
+		Nickname: req.Nickname,
+	}
+}
+
+// FromProtoV2LoginRequest Proto登录请求 → DTO
+func FromProtoV2LoginRequest(req *pbv2.LoginRequest) *LoginDTO {
+	return &LoginDTO{
+		Username: req.Username,
+		Password: req.Password,
+	}
+}
+
+// FromProtoV2RefreshTokenRequest Proto刷新凭证请求 → DTO
+func FromProtoV2RefreshTokenRequest(req *pbv2.RefreshTokenRequest) *RefreshTokenDTO {
+	return &RefreshTokenDTO{
+		RefreshToken: req.RefreshToken,
+	}
+}
+
+// FromProtoV2LogoutRequest Proto登出请求 → DTO
+func FromProtoV2LogoutRequest(req *pbv2.LogoutRequest) *LogoutDTO {
+	return &LogoutDTO{
+		Token: req.Token,
+	}
+}
+
+// FromProtoV2UpdateNicknameRequest Proto更新昵称请求 → DTO
+func FromProtoV2UpdateNicknameRequest(req *pbv2.UpdateNicknameRequest, userID uint64) *UpdateNicknameDTO {
+	return &UpdateNicknameDTO{
+		UserID:   userID,
+		Nickname: req.Nickname,
+	}
+}
+
+// FromProtoV2UpdateProfilePictureRequest Proto更新头像请求 → DTO
+func FromProtoV2UpdateProfilePictureRequest(req *pbv2.UpdateProfilePictureRequest, userID uint64) *UpdateProfilePictureDTO {
+	return &UpdateProfilePictureDTO{
+		UserID:         userID,
+		ProfilePicture: req.ProfilePicture,
+	}
+}
+
+// FromProtoV2GetPublicProfileRequest Proto获取公开信息请求 → DTO
+func FromProtoV2GetPublicProfileRequest(req *pbv2.GetPublicProfileRequest) *GetPublicProfileDTO {
+	return &GetPublicProfileDTO{
+		UserID: req.UserId,
+	}
+}
+
+// FromProtoV2ClearProfilePictureRequest Proto清空头像请求 → DTO
+func FromProtoV2ClearProfilePictureRequest(req *pbv2.ClearProfilePictureRequest) *ClearProfilePictureDTO {
+	return &ClearProfilePictureDTO{
+		UserID:         req.UserId,
+		ProfilePicture: req.ProfilePicture,
+	}
+}
+
+// FromProtoV2ListProfilePicturesRequest Proto列出头像请求 → DTO
+func FromProtoV2ListProfilePicturesRequest(req *pbv2.ListProfilePicturesRequest) *ListProfilePicturesDTO {
+	return &ListProfilePicturesDTO{
+		AfterUserID: req.AfterUserId,
+		Limit:       int(req.Limit),
+	}
+}
+
+// FromProtoV2ChangePasswordRequest Proto修改密码请求 → DTO
+func FromProtoV2ChangePasswordRequest(req *pbv2.ChangePasswordRequest, userID uint64) *ChangePasswordDTO {
+	return &ChangePasswordDTO{
+		UserID:      userID,
+		Token:       req.Token,
+		OldPassword: req.OldPassword,
+		NewPassword: req.NewPassword,
+	}
+}
+
+// FromProtoV2ListSessionsRequest Proto列出Session请求 → DTO
+func FromProtoV2ListSessionsRequest(req *pbv2.ListSessionsRequest, userID uint64) *ListSessionsDTO {
+	return &ListSessionsDTO{
+		UserID: userID,
+		Token:  req.Token,
+	}
+}
+
+// FromProtoV2RevokeSessionRequest Proto注销指定Session请求 → DTO
+func FromProtoV2RevokeSessionRequest(req *pbv2.RevokeSessionRequest, userID uint64) *RevokeSessionDTO {
+	return &RevokeSessionDTO{
+		UserID:    userID,
+		SessionID: req.SessionId,
+	}
+}
+
+// FromProtoV2RevokeAllSessionsRequest Proto注销所有Session请求 → DTO
+func FromProtoV2RevokeAllSessionsRequest(req *pbv2.RevokeAllSessionsRequest, userID uint64) *RevokeAllSessionsDTO {
+	return &RevokeAllSessionsDTO{
+		UserID:         userID,
+		Token:          req.Token,
+		IncludeCurrent: req.IncludeCurrent,
+	}
+}
+
+// ============================================================================
+// DTO → Proto v2 (Service 层 → gRPC 响应)
+// ============================================================================
+
+// ToProtoV2 UserProfileDTO → Proto v2 UserProfile
+func (p *UserProfileDTO) ToProtoV2() *pbv2.UserProfile {
+	if p == nil {
+		return nil
+	}
+	return &pbv2.UserProfile{
+		Id:        p.ID,
+		Username:  p.Username,
+		Nickname:  p.Nickname,
+		AvatarUrl: p.ProfilePicture,
+	}
+}
+
+// ToProtoV2PublicProfile UserProfileDTO → Proto v2 PublicProfile（不含用户名）
+func (p *UserProfileDTO) ToProtoV2PublicProfile() *pbv2.PublicProfile {
+	if p == nil {
+		return nil
+	}
+	return &pbv2.PublicProfile{
+		Id:        p.ID,
+		Nickname:  p.Nickname,
+		AvatarUrl: p.ProfilePicture,
+	}
+}
+
+// ToProtoV2LoginResponse LoginResultDTO → Proto v2 LoginResponse
+func (r *LoginResultDTO) ToProtoV2LoginResponse() *pbv2.LoginResponse {
+	return &pbv2.LoginResponse{
+		Token:            r.Token,
+		User:             r.Profile.ToProtoV2(),
+		ExpiresIn:        r.ExpiresIn,
+		RefreshToken:     r.RefreshToken,
+		RefreshExpiresIn: r.RefreshExpiresIn,
+	}
+}
+
+// ToProtoV2RefreshTokenResponse LoginResultDTO → Proto v2 RefreshTokenResponse（不包含用户信息）
+func (r *LoginResultDTO) ToProtoV2RefreshTokenResponse() *pbv2.RefreshTokenResponse {
+	return &pbv2.RefreshTokenResponse{
+		Token:            r.Token,
+		ExpiresIn:        r.ExpiresIn,
+		RefreshToken:     r.RefreshToken,
+		RefreshExpiresIn: r.RefreshExpiresIn,
+	}
+}
+
+// ToProtoV2 SessionDTO → Proto v2 SessionInfo
+func (d *SessionDTO) ToProtoV2() *pbv2.SessionInfo {
+	return &pbv2.SessionInfo{
+		SessionId:  d.ID,
+		CreatedAt:  d.CreatedAt,
+		LastSeenAt: d.LastSeenAt,
+		ClientIp:   d.ClientIP,
+		UserAgent:  d.UserAgent,
+		Current:    d.Current,
+	}
+}
+
+// ToProtoV2ListSessionsResponse []SessionDTO → Proto v2 ListSessionsResponse
+func ToProtoV2ListSessionsResponse(sessions []*SessionDTO) *pbv2.ListSessionsResponse {
+	items := make([]*pbv2.SessionInfo, 0, len(sessions))
+	for _, s := range sessions {
+		items = append(items, s.ToProtoV2())
+	}
+	return &pbv2.ListSessionsResponse{
+		Sessions: items,
+	}
+}
+
+// ToProtoV2ListProfilePicturesResponse ProfilePictureDTO 列表 → Proto v2 ListProfilePicturesResponse
+func ToProtoV2ListProfilePicturesResponse(pictures []*ProfilePictureDTO) *pbv2.ListProfilePicturesResponse {
+	entries := make([]*pbv2.ProfilePictureEntry, 0, len(pictures))
+	for _, p := range pictures {
+		entries = append(entries, &pbv2.ProfilePictureEntry{
+			UserId:         p.UserID,
+			ProfilePicture: p.ProfilePicture,
+		})
+	}
+	return &pbv2.ListProfilePicturesResponse{Entries: entries}
+}
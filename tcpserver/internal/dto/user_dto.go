@@ -29,6 +29,11 @@ type UserProfileDTO struct {
 // 操作 DTO
 // ============================================================================
 
+// GetPublicProfileDTO 获取指定用户的公开信息
+type GetPublicProfileDTO struct {
+	UserID uint64
+}
+
 // UpdateNicknameDTO 更新昵称
 type UpdateNicknameDTO struct {
 	UserID   uint64
@@ -41,6 +46,32 @@ type UpdateProfilePictureDTO struct {
 	ProfilePicture string
 }
 
+// ClearProfilePictureDTO 比较后清空头像（头像对账使用）
+type ClearProfilePictureDTO struct {
+	UserID         uint64
+	ProfilePicture string // 期望的当前头像
+}
+
+// ListProfilePicturesDTO 分页列出已设置头像的用户（头像对账使用）
+type ListProfilePicturesDTO struct {
+	AfterUserID uint64
+	Limit       int
+}
+
+// ProfilePictureDTO 用户ID与头像
+type ProfilePictureDTO struct {
+	UserID         uint64
+	ProfilePicture string
+}
+
+// ChangePasswordDTO 修改密码
+type ChangePasswordDTO struct {
+	UserID      uint64
+	Token       string // 当前Session，修改密码后保留
+	OldPassword string
+	NewPassword string
+}
+
 // ============================================================================
 // 方法
 // ============================================================================
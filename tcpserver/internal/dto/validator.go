@@ -11,23 +11,60 @@ var (
 	usernameRegex = regexp.MustCompile(`^[a-zA-Z0-9_]{3,50}$`)
 )
 
+// 密码长度（字节）
+const (
+	MinPasswordLen = 6
+	MaxPasswordLen = 72 // bcrypt 只接受不超过72字节的密码，更长时返回 bcrypt.ErrPasswordTooLong
+)
+
 // ============================================================================
 // 验证错误
 // ============================================================================
 
 var (
-	ErrUsernameEmpty    = errors.New("用户名不能为空")
-	ErrUsernameInvalid  = errors.New("用户名格式不正确（3-50个字符，仅限字母、数字、下划线）")
-	ErrPasswordEmpty    = errors.New("密码不能为空")
-	ErrPasswordTooShort = errors.New("密码长度不能少于6位")
-	ErrPasswordTooLong  = errors.New("密码长度不能超过100位")
-	ErrNicknameEmpty    = errors.New("昵称不能为空")
-	ErrNicknameTooLong  = errors.New("昵称长度不能超过50个字符")
-	ErrTokenEmpty       = errors.New("Token不能为空")
-	ErrPictureURLEmpty  = errors.New("头像URL不能为空")
-	ErrUserIDInvalid    = errors.New("用户ID无效")
+	ErrUsernameEmpty     = errors.New("用户名不能为空")
+	ErrUsernameInvalid   = errors.New("用户名格式不正确（3-50个字符，仅限字母、数字、下划线）")
+	ErrPasswordEmpty     = errors.New("密码不能为空")
+	ErrPasswordTooShort  = errors.New("密码长度不能少于6位")
+	ErrPasswordTooLong   = errors.New("密码长度不能超过72字节")
+	ErrNicknameEmpty     = errors.New("昵称不能为空")
+	ErrNicknameTooLong   = errors.New("昵称长度不能超过50个字符")
+	ErrTokenEmpty        = errors.New("Token不能为空")
+	ErrPictureURLEmpty   = errors.New("头像URL不能为空")
+	ErrUserIDInvalid     = errors.New("用户ID无效")
+	ErrPasswordUnchanged = errors.New("新密码不能与原密码相同")
+	ErrSessionIDEmpty    = errors.New("Session ID不能为空")
+	ErrRefreshTokenEmpty = errors.New("刷新凭证不能为空")
 )
 
+// ============================================================================
+// RegisterDTO 验证
+// ============================================================================
+
+// Validate 验证注册DTO
+func (d *RegisterDTO) Validate() error {
+	if d.Username == "" {
+		return ErrUsernameEmpty
+	}
+	if !usernameRegex.MatchString(d.Username) {
+		return ErrUsernameInvalid
+	}
+	if d.Password == "" {
+		return ErrPasswordEmpty
+	}
+	if len(d.Password) < MinPasswordLen {
+		return ErrPasswordTooShort
+	}
+	if len(d.Password) > MaxPasswordLen {
+		return ErrPasswordTooLong
+	}
+	// 昵称可选，填写时与更新昵称规则一致
+	if utf8.RuneCountInString(d.Nickname) > 50 {
+		return ErrNicknameTooLong
+	}
+	return nil
+}
+
 // ============================================================================
 // LoginDTO 验证
 // ============================================================================
@@ -43,10 +80,10 @@ func (d *LoginDTO) Validate() error {
 	if d.Password == "" {
 		return ErrPasswordEmpty
 	}
-	if len(d.Password) < 6 {
+	if len(d.Password) < MinPasswordLen {
 		return ErrPasswordTooShort
 	}
-	if len(d.Password) > 100 {
+	if len(d.Password) > MaxPasswordLen {
 		return ErrPasswordTooLong
 	}
 	return nil
@@ -87,6 +124,71 @@ func (d *UpdateProfilePictureDTO) Validate() error {
 	return nil
 }
 
+// ============================================================================
+// GetPublicProfileDTO 验证
+// ============================================================================
+
+// Validate 验证获取公开信息DTO
+func (d *GetPublicProfileDTO) Validate() error {
+	if d.UserID == 0 {
+		return ErrUserIDInvalid
+	}
+	return nil
+}
+
+// ============================================================================
+// ClearProfilePictureDTO / ListProfilePicturesDTO 验证
+// ============================================================================
+
+// 列出头像的分页大小
+const (
+	DefaultProfilePicturePageSize = 100
+	MaxProfilePicturePageSize     = 1000
+)
+
+// Validate 验证清空头像DTO
+func (d *ClearProfilePictureDTO) Validate() error {
+	if d.UserID == 0 {
+		return ErrUserIDInvalid
+	}
+	if d.ProfilePicture == "" {
+		return ErrPictureURLEmpty
+	}
+	return nil
+}
+
+// GetLimit 获取分页大小（未指定时为默认值，超过上限时取上限）
+func (d *ListProfilePicturesDTO) GetLimit() int {
+	if d.Limit <= 0 {
+		return DefaultProfilePicturePageSize
+	}
+	return min(d.Limit, MaxProfilePicturePageSize)
+}
+
+// ============================================================================
+// ChangePasswordDTO 验证
+// ============================================================================
+
+// Validate 验证修改密码DTO
+func (d *ChangePasswordDTO) Validate() error {
+	if d.UserID == 0 {
+		return ErrUserIDInvalid
+	}
+	if d.OldPassword == "" || d.NewPassword == "" {
+		return ErrPasswordEmpty
+	}
+	if len(d.NewPassword) < MinPasswordLen {
+		return ErrPasswordTooShort
+	}
+	if len(d.NewPassword) > MaxPasswordLen {
+		return ErrPasswordTooLong
+	}
+	if d.OldPassword == d.NewPassword {
+		return ErrPasswordUnchanged
+	}
+	return nil
+}
+
 // ============================================================================
 // ValidateTokenDTO 验证
 // ============================================================================
@@ -99,6 +201,18 @@ func (d *ValidateTokenDTO) Validate() error {
 	return nil
 }
 
+// ============================================================================
+// RefreshTokenDTO 验证
+// ============================================================================
+
+// Validate 验证刷新凭证DTO
+func (d *RefreshTokenDTO) Validate() error {
+	if d.RefreshToken == "" {
+		return ErrRefreshTokenEmpty
+	}
+	return nil
+}
+
 // ============================================================================
 // LogoutDTO 验证
 // ============================================================================
@@ -110,3 +224,37 @@ func (d *LogoutDTO) Validate() error {
 	}
 	return nil
 }
+
+// ============================================================================
+// Session 管理 DTO 验证
+// ============================================================================
+
+// Validate 验证列出Session DTO
+func (d *ListSessionsDTO) Validate() error {
+	if d.UserID == 0 {
+		return ErrUserIDInvalid
+	}
+	return nil
+}
+
+// Validate 验证注销指定Session DTO
+func (d *RevokeSessionDTO) Validate() error {
+	if d.UserID == 0 {
+		return ErrUserIDInvalid
+	}
+	if d.SessionID == "" {
+		return ErrSessionIDEmpty
+	}
+	return nil
+}
+
+// Validate 验证注销所有Session DTO
+func (d *RevokeAllSessionsDTO) Validate() error {
+	if d.UserID == 0 {
+		return ErrUserIDInvalid
+	}
+	if !d.IncludeCurrent && d.Token == "" {
+		return ErrTokenEmpty
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package dto
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// TestMaxPasswordLen 测试密码上限与 bcrypt 的限制一致
+func TestMaxPasswordLen(t *testing.T) {
+	_, err := bcrypt.GenerateFromPassword([]byte(strings.Repeat("a", MaxPasswordLen)), bcrypt.MinCost)
+	assert.NoError(t, err)
+	_, err = bcrypt.GenerateFromPassword([]byte(strings.Repeat("a", MaxPasswordLen+1)), bcrypt.MinCost)
+	assert.ErrorIs(t, err, bcrypt.ErrPasswordTooLong)
+}
+
+// TestRegisterDTO_Validate_Password 测试注册密码长度校验（按字节计算）
+func TestRegisterDTO_Validate_Password(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		want     error
+	}{
+		{"过短", "12345", ErrPasswordTooShort},
+		{"最短", "123456", nil},
+		{"72字节", strings.Repeat("a", 72), nil},
+		{"73字节", strings.Repeat("a", 73), ErrPasswordTooLong},
+		{"100字节", strings.Repeat("a", 100), ErrPasswordTooLong},
+		{"24个汉字（72字节）", strings.Repeat("密", 24), nil},
+		{"25个汉字（75字节）", strings.Repeat("密", 25), ErrPasswordTooLong},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &RegisterDTO{Username: "alice", Password: tt.password}
+			assert.Equal(t, tt.want, d.Validate())
+		})
+	}
+}
+
+// TestLoginDTO_Validate_Password 测试登录密码超过72字节时直接返回校验错误
+func TestLoginDTO_Validate_Password(t *testing.T) {
+	assert.NoError(t, (&LoginDTO{Username: "alice", Password: strings.Repeat("a", 72)}).Validate())
+	assert.Equal(t, ErrPasswordTooLong, (&LoginDTO{Username: "alice", Password: strings.Repeat("a", 73)}).Validate())
+}
+
+// TestChangePasswordDTO_Validate_Password 测试新密码超过72字节时返回校验错误而不是在 bcrypt 中失败
+func TestChangePasswordDTO_Validate_Password(t *testing.T) {
+	tests := []struct {
+		name        string
+		newPassword string
+		want        error
+	}{
+		{"72字节", strings.Repeat("a", 72), nil},
+		{"73字节", strings.Repeat("a", 73), ErrPasswordTooLong},
+		{"100字节", strings.Repeat("a", 100), ErrPasswordTooLong},
+		{"过短", "12345", ErrPasswordTooShort},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &ChangePasswordDTO{UserID: 1, OldPassword: "old-password", NewPassword: tt.newPassword}
+			assert.Equal(t, tt.want, d.Validate())
+		})
+	}
+}
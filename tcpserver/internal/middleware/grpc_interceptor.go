@@ -2,22 +2,58 @@ package middleware
 
 import (
 	"context"
+	"crypto/subtle"
 	"entry-task/tcpserver/pkg/redis"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 
-	log "entry-task/tcpserver/pkg/logger"
+	log "entry-task/pkg/logger"
+	"entry-task/pkg/requestid"
 )
 
+// MetadataSessionTTL Session续期后通过响应 header 返回的剩余有效期（秒），HTTP网关据此重新下发Cookie
+const MetadataSessionTTL = "x-session-ttl"
+
+// MetadataRequestID HTTP网关转发的请求ID，同时通过响应 header 返回
+const MetadataRequestID = requestid.MetadataKey
+
+// MetadataInternalToken 内部服务（UserAdminService）的鉴权凭证
+const MetadataInternalToken = "x-internal-token"
+
+// internalServicePrefix 内部服务的方法前缀，不使用用户 Token 鉴权（由 InternalAuthInterceptor 校验）
+const internalServicePrefix = "/user.v2.UserAdminService/"
+
+// tracer 拦截器 Span 的 Tracer（RPC 本身的 Span 由 otelgrpc StatsHandler 创建）
+var tracer = otel.Tracer("entry-task/tcpserver/internal/middleware")
+
 // ============================================================================
 // 1. 日志拦截器
 // ============================================================================
 
+// clientErrorCodes 由调用方导致的 gRPC 状态码
+var clientErrorCodes = map[codes.Code]bool{
+	codes.Canceled:           true,
+	codes.InvalidArgument:    true,
+	codes.NotFound:           true,
+	codes.AlreadyExists:      true,
+	codes.PermissionDenied:   true,
+	codes.ResourceExhausted:  true,
+	codes.FailedPrecondition: true,
+	codes.Unauthenticated:    true,
+}
+
 // LoggingInterceptor 记录所有 RPC 请求的日志
 func LoggingInterceptor() grpc.UnaryServerInterceptor {
 	return func(
@@ -29,7 +65,7 @@ func LoggingInterceptor() grpc.UnaryServerInterceptor {
 		start := time.Now()
 
 		// 记录请求开始
-		log.Info("gRPC 请求开始",
+		log.InfoCtx(ctx, "gRPC 请求开始",
 			zap.String("method", info.FullMethod),
 		)
 
@@ -39,13 +75,18 @@ func LoggingInterceptor() grpc.UnaryServerInterceptor {
 		// 记录请求结束
 		duration := time.Since(start)
 		if err != nil {
-			log.Error("gRPC 请求失败",
+			// 调用方导致的错误（参数错误、未认证等，v2 接口以 gRPC 状态返回）只记录警告
+			logFunc := log.ErrorCtx
+			if clientErrorCodes[status.Code(err)] {
+				logFunc = log.WarnCtx
+			}
+			logFunc(ctx, "gRPC 请求失败",
 				zap.String("method", info.FullMethod),
 				zap.Duration("duration", duration),
 				zap.Error(err),
 			)
 		} else {
-			log.Info("gRPC 请求成功",
+			log.InfoCtx(ctx, "gRPC 请求成功",
 				zap.String("method", info.FullMethod),
 				zap.Duration("duration", duration),
 			)
@@ -70,7 +111,7 @@ func RecoveryInterceptor() grpc.UnaryServerInterceptor {
 		// 使用 defer + recover 捕获 panic
 		defer func() {
 			if r := recover(); r != nil {
-				log.Error("gRPC Panic 恢复",
+				log.ErrorCtx(ctx, "gRPC Panic 恢复",
 					zap.String("method", info.FullMethod),
 					zap.Any("panic", r),
 				)
@@ -99,54 +140,86 @@ func AuthInterceptor(redisManager redis.Manager) grpc.UnaryServerInterceptor {
 
 		// ===== 第1步：检查白名单（不需要鉴权的方法）=====
 		publicMethods := map[string]bool{
-			"/user.UserService/Login": true, // 登录接口公开
+			"/user.UserService/Login":               true, // 登录接口公开
+			"/user.UserService/Register":            true, // 注册接口公开
+			"/user.UserService/RefreshToken":        true, // 刷新凭证接口公开（凭刷新凭证本身鉴权）
+			"/user.v2.UserService/Login":            true,
+			"/user.v2.UserService/Register":         true,
+			"/user.v2.UserService/RefreshToken":     true,
+			"/user.v2.UserService/GetPublicProfile": true, // 公开信息（查看其他用户的头像）
+			"/grpc.health.v1.Health/Check":          true, // 健康检查（探针无需登录）
+			"/grpc.health.v1.Health/List":           true,
 		}
 
-		if publicMethods[info.FullMethod] {
+		if publicMethods[info.FullMethod] || strings.HasPrefix(info.FullMethod, internalServicePrefix) {
 			// 白名单方法，直接放行
-			log.Debug("公开方法，跳过鉴权", zap.String("method", info.FullMethod))
+			log.DebugCtx(ctx, "公开方法，跳过鉴权", zap.String("method", info.FullMethod))
 			return handler(ctx, req)
 		}
 
 		// ===== 第2步：提取 metadata =====
 		md, ok := metadata.FromIncomingContext(ctx)
 		if !ok {
-			log.Warn("缺少 metadata", zap.String("method", info.FullMethod))
+			log.WarnCtx(ctx, "缺少 metadata", zap.String("method", info.FullMethod))
 			return nil, status.Error(codes.Unauthenticated, "缺少认证信息")
 		}
 
 		// ===== 第3步：提取 Token =====
 		tokens := md.Get("authorization")
 		if len(tokens) == 0 {
-			log.Warn("缺少 Token", zap.String("method", info.FullMethod))
+			log.WarnCtx(ctx, "缺少 Token", zap.String("method", info.FullMethod))
 			return nil, status.Error(codes.Unauthenticated, "缺少 Token")
 		}
 
 		token := tokens[0]
 		if token == "" {
-			log.Warn("Token 为空", zap.String("method", info.FullMethod))
+			log.WarnCtx(ctx, "Token 为空", zap.String("method", info.FullMethod))
 			return nil, status.Error(codes.Unauthenticated, "Token 为空")
 		}
 
 		// ===== 第4步：验证 Token（调用 Redis Session）=====
-		userID, err := redisManager.GetSession().ValidateSession(ctx, token)
+		// 鉴权 Span 在调用 Handler 前结束，Handler 的 Span 仍挂在 RPC Span 下
+		authCtx, span := tracer.Start(ctx, "AuthInterceptor")
+
+		userID, err := redisManager.GetSession().ValidateSession(authCtx, token)
 		if err != nil {
-			log.Warn("Token 验证失败",
+			log.WarnCtx(ctx, "Token 验证失败",
 				zap.String("method", info.FullMethod),
 				zap.String("token", token),
 				zap.Error(err),
 			)
+			span.SetStatus(otelcodes.Error, "Token 无效或已过期")
+			span.End()
 			return nil, status.Error(codes.Unauthenticated, "Token 无效或已过期")
 		}
+		span.SetAttributes(attribute.Int64("user.id", int64(userID)))
+
+		// ===== 第5步：滑动续期（内部节流，失败不影响请求）=====
+		ttl, err := redisManager.GetSession().RefreshSession(authCtx, token)
+		if err != nil {
+			log.WarnCtx(ctx, "Session续期失败",
+				zap.String("method", info.FullMethod),
+				zap.Error(err),
+			)
+		} else if ttl > 0 {
+			// 通知网关按新的有效期重新下发Cookie
+			header := metadata.Pairs(MetadataSessionTTL, strconv.FormatInt(int64(ttl.Seconds()), 10))
+			if err := grpc.SetHeader(ctx, header); err != nil {
+				log.WarnCtx(ctx, "设置Session续期header失败", zap.Error(err))
+			}
+		}
 
-		// ===== 第5步：Token 有效，放入 context =====
+		span.End()
+
+		// ===== 第6步：Token 有效，放入 context（之后的日志都携带 user_id）=====
 		ctx = context.WithValue(ctx, "user_id", userID)
-		log.Debug("Token 验证通过",
+		ctx = log.WithFields(ctx, zap.Uint64("user_id", userID))
+		log.DebugCtx(ctx, "Token 验证通过",
 			zap.String("method", info.FullMethod),
 			zap.Uint64("user_id", userID),
 		)
 
-		// ===== 第6步：放行，调用 Handler =====
+		// ===== 第7步：放行，调用 Handler =====
 		return handler(ctx, req)
 	}
 }
@@ -155,7 +228,27 @@ func AuthInterceptor(redisManager redis.Manager) grpc.UnaryServerInterceptor {
 // 4. 性能监控拦截器
 // ============================================================================
 
-// MetricsInterceptor 性能指标收集
+var (
+	// rpcRequests RPC 请求数（code 为响应中的业务错误码，返回 gRPC 错误时为 gRPC 状态码）
+	rpcRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "tcpserver_rpc_requests_total",
+		Help: "RPC 请求数",
+	}, []string{"method", "code"})
+
+	// rpcDuration RPC 处理耗时
+	rpcDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "tcpserver_rpc_duration_seconds",
+		Help:    "RPC 处理耗时（秒）",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method"})
+)
+
+// codeResponse 带业务错误码的响应（所有 pb 响应都实现了 GetCode）
+type codeResponse interface {
+	GetCode() int32
+}
+
+// MetricsInterceptor 性能指标收集（Prometheus）
 func MetricsInterceptor() grpc.UnaryServerInterceptor {
 	return func(
 		ctx context.Context,
@@ -170,15 +263,80 @@ func MetricsInterceptor() grpc.UnaryServerInterceptor {
 
 		// 记录性能指标
 		duration := time.Since(start)
-		log.Debug("RPC 性能指标",
+		code := status.Code(err).String()
+		if r, ok := resp.(codeResponse); ok && err == nil {
+			code = strconv.Itoa(int(r.GetCode()))
+		}
+		rpcRequests.WithLabelValues(info.FullMethod, code).Inc()
+		rpcDuration.WithLabelValues(info.FullMethod).Observe(duration.Seconds())
+
+		log.DebugCtx(ctx, "RPC 性能指标",
 			zap.String("method", info.FullMethod),
 			zap.Duration("duration", duration),
-			zap.Bool("success", err == nil),
+			zap.String("code", code),
 		)
 
-		// 这里可以集成 Prometheus 等监控系统
-		// metrics.RecordRPCDuration(info.FullMethod, duration)
-
 		return resp, err
 	}
 }
+
+// ============================================================================
+// 5. 请求ID拦截器
+// ============================================================================
+
+// RequestIDInterceptor 从 metadata 中读取HTTP网关转发的请求ID（没有时生成），
+// 绑定到 ctx 供后续日志使用，并通过响应 header 返回
+func RequestIDInterceptor() grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		var requestID string
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if ids := md.Get(MetadataRequestID); len(ids) > 0 {
+				requestID = ids[0]
+			}
+		}
+		requestID = requestid.OrNew(requestID)
+
+		if err := grpc.SetHeader(ctx, metadata.Pairs(MetadataRequestID, requestID)); err != nil {
+			log.Warn("设置请求ID header失败", zap.Error(err))
+		}
+
+		return handler(log.WithRequestID(ctx, requestID), req)
+	}
+}
+
+// ============================================================================
+// 6. 内部服务鉴权拦截器
+// ============================================================================
+
+// InternalAuthInterceptor 校验内部服务调用方携带的 x-internal-token，只作用于内部服务的方法
+// token 为空时拒绝所有内部服务调用
+func InternalAuthInterceptor(token string) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		if !strings.HasPrefix(info.FullMethod, internalServicePrefix) {
+			return handler(ctx, req)
+		}
+
+		if token == "" {
+			return nil, status.Error(codes.PermissionDenied, "未启用内部服务")
+		}
+
+		md, _ := metadata.FromIncomingContext(ctx)
+		values := md.Get(MetadataInternalToken)
+		if len(values) == 0 || subtle.ConstantTimeCompare([]byte(values[0]), []byte(token)) != 1 {
+			log.WarnCtx(ctx, "内部服务凭证无效", zap.String("method", info.FullMethod))
+			return nil, status.Error(codes.Unauthenticated, "内部服务凭证无效")
+		}
+
+		return handler(ctx, req)
+	}
+}
@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	log "entry-task/pkg/logger"
+	"entry-task/pkg/requestid"
+)
+
+// fakeServerStream 记录 grpc.SetHeader 写入的响应 header
+type fakeServerStream struct {
+	header metadata.MD
+}
+
+func (s *fakeServerStream) Method() string { return "/user.v2.UserService/GetProfile" }
+
+func (s *fakeServerStream) SetHeader(md metadata.MD) error {
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+
+func (s *fakeServerStream) SendHeader(md metadata.MD) error { return s.SetHeader(md) }
+
+func (s *fakeServerStream) SetTrailer(metadata.MD) error { return nil }
+
+// TestRequestIDInterceptor 测试网关转发的请求ID被沿用，缺失或不合法时重新生成，并通过响应 header 返回
+func TestRequestIDInterceptor(t *testing.T) {
+	log.Logger = zap.NewNop()
+
+	tests := []struct {
+		name      string
+		requestID string
+		keep      bool
+	}{
+		{"合法的请求ID", "req-123", true},
+		{"缺失", "", false},
+		{"超长", strings.Repeat("a", requestid.MaxLen+1), false},
+		{"含非法字符", "req 1\nlevel=error", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.requestID != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(MetadataRequestID, tt.requestID))
+			}
+			stream := &fakeServerStream{}
+			ctx = grpc.NewContextWithServerTransportStream(ctx, stream)
+
+			var got string
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				got = log.RequestIDFromContext(ctx)
+				return nil, nil
+			}
+			_, err := RequestIDInterceptor()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: stream.Method()}, handler)
+			require.NoError(t, err)
+
+			if tt.keep {
+				assert.Equal(t, tt.requestID, got)
+			} else {
+				assert.NotEqual(t, tt.requestID, got)
+				assert.True(t, requestid.Valid(got), "生成的请求ID应合法: %q", got)
+			}
+			assert.Equal(t, []string{got}, stream.header.Get(MetadataRequestID))
+		})
+	}
+}
+
+// codeResp 带业务错误码的响应
+type codeResp struct {
+	code int32
+}
+
+func (r *codeResp) GetCode() int32 { return r.code }
+
+// histogramCount 返回直方图的样本数
+func histogramCount(t *testing.T, o prometheus.Observer) uint64 {
+	t.Helper()
+	var m dto.Metric
+	require.NoError(t, o.(prometheus.Metric).Write(&m))
+	return m.GetHistogram().GetSampleCount()
+}
+
+// TestMetricsInterceptor 测试请求数按方法和业务错误码（返回 gRPC 错误时为状态码）计数，并记录耗时
+func TestMetricsInterceptor(t *testing.T) {
+	log.Logger = zap.NewNop()
+
+	const method = "/user.v2.UserService/MetricsTest"
+	info := &grpc.UnaryServerInfo{FullMethod: method}
+	ok := rpcRequests.WithLabelValues(method, "0")
+	bizErr := rpcRequests.WithLabelValues(method, "40001")
+	rpcErr := rpcRequests.WithLabelValues(method, codes.Unavailable.String())
+	beforeOK, beforeBiz, beforeRPC := testutil.ToFloat64(ok), testutil.ToFloat64(bizErr), testutil.ToFloat64(rpcErr)
+	beforeDuration := histogramCount(t, rpcDuration.WithLabelValues(method))
+
+	handlers := []grpc.UnaryHandler{
+		func(context.Context, interface{}) (interface{}, error) { return &codeResp{}, nil },
+		func(context.Context, interface{}) (interface{}, error) { return &codeResp{code: 40001}, nil },
+		func(context.Context, interface{}) (interface{}, error) {
+			return nil, status.Error(codes.Unavailable, "unavailable")
+		},
+	}
+	for _, h := range handlers {
+		_, _ = MetricsInterceptor()(context.Background(), nil, info, h)
+	}
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(ok)-beforeOK)
+	assert.Equal(t, 1.0, testutil.ToFloat64(bizErr)-beforeBiz)
+	assert.Equal(t, 1.0, testutil.ToFloat64(rpcErr)-beforeRPC)
+	assert.Equal(t, uint64(3), histogramCount(t, rpcDuration.WithLabelValues(method))-beforeDuration)
+}
@@ -11,3 +11,9 @@ type User struct {
 	CreatedAt      time.Time `db:"created_at"`
 	UpdatedAt      time.Time `db:"updated_at"`
 }
+
+// UserProfilePicture 用户ID与头像字段（头像对账时分页扫描使用）
+type UserProfilePicture struct {
+	ID             uint64 `db:"id"`
+	ProfilePicture string `db:"profile_picture"`
+}
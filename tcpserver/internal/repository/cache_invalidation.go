@@ -0,0 +1,254 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+
+	"entry-task/tcpserver/config"
+	"entry-task/tcpserver/pkg/redis"
+
+	log "entry-task/pkg/logger"
+)
+
+// ============================================================================
+// 缓存失效发件箱（Outbox）
+// ============================================================================
+//
+// 更新用户资料时，在同一个数据库事务中写入一条缓存失效记录；
+// 后台 Worker 轮询到期记录并删除缓存（延迟双删的第二次删除），失败按指数退避重试。
+// 进程在延迟窗口内退出时记录仍在数据库中，由任意实例在重启后继续处理。
+// Worker 用 FOR UPDATE SKIP LOCKED 领取记录（需要 MySQL 8.0+ 或 PostgreSQL 9.5+），
+// 多个实例不会重复处理同一条记录；领取后实例退出的记录在租约到期后重新处理。
+
+const (
+	// DefaultInvalidationDelay 默认延迟删除时间（双删的第二次删除）
+	DefaultInvalidationDelay = 500 * time.Millisecond
+
+	// DefaultInvalidationPollInterval 默认轮询间隔
+	DefaultInvalidationPollInterval = 200 * time.Millisecond
+
+	// DefaultInvalidationBatchSize 默认每次处理的记录数
+	DefaultInvalidationBatchSize = 100
+
+	// DefaultInvalidationMaxAttempts 默认最大重试次数
+	DefaultInvalidationMaxAttempts = 10
+
+	// invalidationClaimTTL 领取记录的租约时间，超过后未处理完的记录可被再次领取
+	invalidationClaimTTL = time.Minute
+
+	// invalidationMaxBackoff 重试退避上限
+	invalidationMaxBackoff = 30 * time.Second
+
+	// invalidationDelayJitter 延迟抖动上限
+	invalidationDelayJitter = 200 * time.Millisecond
+
+	// invalidationErrorMaxLen last_error 字段最大长度
+	invalidationErrorMaxLen = 512
+)
+
+// cacheInvalidation 缓存失效记录（对应 cache_invalidations 表）
+type cacheInvalidation struct {
+	ID       uint64 `db:"id"`
+	UserID   uint64 `db:"user_id"`
+	Attempts int    `db:"attempts"`
+}
+
+// enqueueCacheInvalidation 在事务中写入缓存失效记录，delay 后由 Worker 处理
+func enqueueCacheInvalidation(ctx context.Context, tx *sqlx.Tx, userID uint64, delay time.Duration) error {
+	delay += time.Duration(rand.Int63n(int64(invalidationDelayJitter))) // 延迟抖动
+	nextAttemptAt := time.Now().Add(delay).UnixMilli()
+
+	query := tx.Rebind(`INSERT INTO cache_invalidations (user_id, next_attempt_at) VALUES (?, ?)`)
+	if _, err := tx.ExecContext(ctx, query, userID, nextAttemptAt); err != nil {
+		return fmt.Errorf("failed to enqueue cache invalidation: %w", err)
+	}
+	return nil
+}
+
+// CacheInvalidationWorker 缓存失效后台 Worker
+type CacheInvalidationWorker struct {
+	db           *sqlx.DB
+	redisManager redis.Manager
+	pollInterval time.Duration
+	batchSize    int
+	maxAttempts  int
+	writeTimeout time.Duration
+}
+
+// NewCacheInvalidationWorker 创建缓存失效 Worker
+func NewCacheInvalidationWorker(db *sqlx.DB, redisManager redis.Manager, cfg *config.Config) *CacheInvalidationWorker {
+	invalidation := cfg.UserCache.Invalidation
+
+	w := &CacheInvalidationWorker{
+		db:           db,
+		redisManager: redisManager,
+		pollInterval: invalidation.GetPollInterval(),
+		batchSize:    invalidation.BatchSize,
+		maxAttempts:  invalidation.MaxAttempts,
+		writeTimeout: cfg.Database.GetWriteTimeout(),
+	}
+	if w.pollInterval <= 0 {
+		w.pollInterval = DefaultInvalidationPollInterval
+	}
+	if w.batchSize <= 0 {
+		w.batchSize = DefaultInvalidationBatchSize
+	}
+	if w.maxAttempts <= 0 {
+		w.maxAttempts = DefaultInvalidationMaxAttempts
+	}
+	if w.writeTimeout <= 0 {
+		w.writeTimeout = DefaultWriteTimeout
+	}
+	return w
+}
+
+// Run 轮询并处理到期的缓存失效记录，直到 ctx 被取消
+func (w *CacheInvalidationWorker) Run(ctx context.Context) {
+	log.Info("缓存失效 Worker 已启动",
+		zap.Duration("poll_interval", w.pollInterval),
+		zap.Int("batch_size", w.batchSize),
+	)
+
+	ticker := time.NewTicker(w.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("缓存失效 Worker 已停止")
+			return
+		case <-ticker.C:
+			// 一批处理满时说明还有积压，继续处理
+			for ctx.Err() == nil {
+				if w.processBatch(ctx) < w.batchSize {
+					break
+				}
+			}
+		}
+	}
+}
+
+// processBatch 处理一批到期记录，返回本批记录数
+func (w *CacheInvalidationWorker) processBatch(ctx context.Context) int {
+	items, err := w.claim(ctx)
+	if err != nil {
+		log.Error("领取缓存失效记录失败", zap.Error(err))
+		return 0
+	}
+
+	for _, item := range items {
+		w.process(ctx, item)
+	}
+	return len(items)
+}
+
+// claim 领取一批到期记录：锁定后把 next_attempt_at 推迟 invalidationClaimTTL 再提交，
+// 锁定期间其他实例跳过这些行（SKIP LOCKED），提交后因未到期也不会再查到
+func (w *CacheInvalidationWorker) claim(ctx context.Context) ([]cacheInvalidation, error) {
+	ctx, cancel := context.WithTimeout(ctx, w.writeTimeout)
+	defer cancel()
+
+	tx, err := w.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Error("事务回滚失败", zap.Error(err))
+		}
+	}()
+
+	now := time.Now()
+	var items []cacheInvalidation
+	query := tx.Rebind(`SELECT id, user_id, attempts FROM cache_invalidations
+              WHERE next_attempt_at <= ? ORDER BY next_attempt_at LIMIT ? FOR UPDATE SKIP LOCKED`)
+	if err := tx.SelectContext(ctx, &items, query, now.UnixMilli(), w.batchSize); err != nil {
+		return nil, fmt.Errorf("failed to select cache invalidations: %w", err)
+	}
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]uint64, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+	}
+	query, args, err := sqlx.In(`UPDATE cache_invalidations SET next_attempt_at = ? WHERE id IN (?)`,
+		now.Add(invalidationClaimTTL).UnixMilli(), ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build claim query: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, tx.Rebind(query), args...); err != nil {
+		return nil, fmt.Errorf("failed to claim cache invalidations: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return items, nil
+}
+
+// process 删除缓存，成功后删除记录，失败时按指数退避重新调度
+func (w *CacheInvalidationWorker) process(ctx context.Context, item cacheInvalidation) {
+	err := w.redisManager.GetUserCache().DeleteUser(ctx, item.UserID)
+	if err == nil {
+		w.remove(ctx, item)
+		return
+	}
+
+	attempts := item.Attempts + 1
+	if attempts >= w.maxAttempts {
+		// 放弃重试，缓存最迟在 UserCacheTTL 后过期
+		log.Error("缓存失效重试次数耗尽，放弃处理",
+			zap.Uint64("user_id", item.UserID),
+			zap.Int("attempts", attempts),
+			zap.Error(err),
+		)
+		w.remove(ctx, item)
+		return
+	}
+
+	backoff := w.pollInterval << attempts
+	if backoff <= 0 || backoff > invalidationMaxBackoff {
+		backoff = invalidationMaxBackoff
+	}
+
+	lastError := err.Error()
+	if len(lastError) > invalidationErrorMaxLen {
+		lastError = lastError[:invalidationErrorMaxLen]
+	}
+
+	updateCtx, cancel := context.WithTimeout(ctx, w.writeTimeout)
+	defer cancel()
+
+	query := w.db.Rebind(`UPDATE cache_invalidations SET attempts = ?, next_attempt_at = ?, last_error = ? WHERE id = ?`)
+	if _, dbErr := w.db.ExecContext(updateCtx, query, attempts, time.Now().Add(backoff).UnixMilli(), lastError, item.ID); dbErr != nil {
+		log.Error("更新缓存失效记录失败", zap.Uint64("id", item.ID), zap.Error(dbErr))
+		return
+	}
+
+	log.Warn("删除用户缓存失败，稍后重试",
+		zap.Uint64("user_id", item.UserID),
+		zap.Int("attempts", attempts),
+		zap.Duration("backoff", backoff),
+		zap.Error(err),
+	)
+}
+
+// remove 删除已处理的记录
+func (w *CacheInvalidationWorker) remove(ctx context.Context, item cacheInvalidation) {
+	ctx, cancel := context.WithTimeout(ctx, w.writeTimeout)
+	defer cancel()
+
+	query := w.db.Rebind(`DELETE FROM cache_invalidations WHERE id = ?`)
+	if _, err := w.db.ExecContext(ctx, query, item.ID); err != nil {
+		// 记录会被再次处理，删除缓存是幂等的
+		log.Error("删除缓存失效记录失败", zap.Uint64("id", item.ID), zap.Error(err))
+	}
+}
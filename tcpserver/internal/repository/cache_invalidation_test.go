@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"entry-task/tcpserver/config"
+)
+
+// newMockWorker 创建使用 sqlmock 的缓存失效 Worker
+func newMockWorker(t *testing.T) (*CacheInvalidationWorker, sqlmock.Sqlmock, *memUserCache) {
+	t.Helper()
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = mockDB.Close() })
+
+	cache := newMemUserCache()
+	worker := NewCacheInvalidationWorker(sqlx.NewDb(mockDB, config.DriverMySQL), &fakeManager{cache: cache}, &config.Config{})
+	return worker, mock, cache
+}
+
+// TestCacheInvalidationWorker_Claim 测试 Worker 用 SKIP LOCKED 锁定到期记录并延长租约后再处理
+func TestCacheInvalidationWorker_Claim(t *testing.T) {
+	worker, mock, cache := newMockWorker(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id, user_id, attempts FROM cache_invalidations\s+WHERE next_attempt_at <= \? ORDER BY next_attempt_at LIMIT \? FOR UPDATE SKIP LOCKED`).
+		WithArgs(sqlmock.AnyArg(), DefaultInvalidationBatchSize).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "attempts"}).AddRow(1, 1001, 0).AddRow(2, 1002, 0))
+	mock.ExpectExec(`UPDATE cache_invalidations SET next_attempt_at = \? WHERE id IN \(\?, \?\)`).
+		WithArgs(sqlmock.AnyArg(), 1, 2).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+	// 领取的事务提交后才删除缓存和记录
+	mock.ExpectExec(`DELETE FROM cache_invalidations WHERE id = \?`).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM cache_invalidations WHERE id = \?`).WithArgs(2).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.Equal(t, 2, worker.processBatch(context.Background()))
+	assert.Equal(t, 1, cache.deletes[1001])
+	assert.Equal(t, 1, cache.deletes[1002])
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestCacheInvalidationWorker_ClaimEmpty 测试没有可领取的记录时回滚事务
+func TestCacheInvalidationWorker_ClaimEmpty(t *testing.T) {
+	worker, mock, _ := newMockWorker(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FOR UPDATE SKIP LOCKED`).WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "attempts"}))
+	mock.ExpectRollback()
+
+	assert.Equal(t, 0, worker.processBatch(context.Background()))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestCacheInvalidationWorker_ClaimError 测试领取失败时不处理任何记录
+func TestCacheInvalidationWorker_ClaimError(t *testing.T) {
+	worker, mock, cache := newMockWorker(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FOR UPDATE SKIP LOCKED`).WillReturnError(errors.New("lock wait timeout"))
+	mock.ExpectRollback()
+
+	assert.Equal(t, 0, worker.processBatch(context.Background()))
+	assert.Empty(t, cache.deletes)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
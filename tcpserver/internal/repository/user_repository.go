@@ -3,20 +3,49 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"entry-task/tcpserver/config"
 	"entry-task/tcpserver/internal/model"
+	"entry-task/tcpserver/pkg/db"
 	"entry-task/tcpserver/pkg/redis"
+	"errors"
 	"fmt"
-	"math/rand"
+	"strconv"
+	"sync/atomic"
 	"time"
 
 	"github.com/jmoiron/sqlx"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
 
-	log "entry-task/tcpserver/pkg/logger"
+	log "entry-task/pkg/logger"
 )
 
 const (
-	doubleDeleteDelayTime = time.Millisecond * 500
+	// cacheWriteTimeout 回源后写缓存的超时时间
+	cacheWriteTimeout = 2 * time.Second
+
+	// DefaultReadTimeout 默认单条查询超时时间
+	DefaultReadTimeout = time.Second
+
+	// DefaultWriteTimeout 默认单条写入/更新事务超时时间
+	DefaultWriteTimeout = 3 * time.Second
+
+	// DefaultBatchTimeout 默认批量写入事务超时时间
+	DefaultBatchTimeout = time.Minute
+)
+
+// tracer 仓储层 Span 的 Tracer
+var tracer = otel.Tracer("entry-task/tcpserver/internal/repository")
+
+var (
+	// ErrUserNotFound 用户不存在
+	ErrUserNotFound = errors.New("user not found")
+
+	// ErrUsernameDuplicate 用户名已存在（唯一索引冲突）
+	ErrUsernameDuplicate = errors.New("username already exists")
 )
 
 // UserRepository 用户仓储接口
@@ -27,6 +56,9 @@ type UserRepository interface {
 	// GetByID 根据ID查询用户
 	GetByID(ctx context.Context, id uint64) (*redis.CachedUser, error)
 
+	// GetByIDFromDB 根据ID直接查询数据库（包含password_hash，不经过缓存）
+	GetByIDFromDB(ctx context.Context, id uint64) (*model.User, error)
+
 	// Create 创建用户
 	Create(ctx context.Context, user *model.User) error
 
@@ -36,36 +68,100 @@ type UserRepository interface {
 	// UpdateProfilePicture 更新用户头像
 	UpdateProfilePicture(ctx context.Context, id uint64, profilePicture string) error
 
+	// ClearProfilePicture 当前头像等于 expected 时清空头像，返回是否清空
+	ClearProfilePicture(ctx context.Context, id uint64, expected string) (bool, error)
+
+	// ListProfilePictures 按ID升序分页列出已设置头像的用户（ID 大于 afterID）
+	ListProfilePictures(ctx context.Context, afterID uint64, limit int) ([]model.UserProfilePicture, error)
+
+	// UpdatePassword 更新用户密码哈希
+	UpdatePassword(ctx context.Context, id uint64, passwordHash string) error
+
 	// BatchCreate 批量创建用户（用于生成测试数据）
 	BatchCreate(ctx context.Context, users []*model.User) error
 }
 
 // userRepository 用户仓储实现
 type userRepository struct {
-	db           *sqlx.DB
+	db           *sqlx.DB    // 主库（写操作、事务、读己之写）
+	cluster      *db.Cluster // 主从集群，普通读操作走从库
 	redisManager redis.Manager
+
+	// loadGroup 合并同一用户ID的并发回源请求（缓存击穿保护）
+	loadGroup singleflight.Group
+
+	// earlyRefreshBeta 概率提前刷新系数，0 表示关闭
+	earlyRefreshBeta float64
+
+	// loadCost 最近一次回源耗时（纳秒），作为提前刷新的 delta
+	loadCost atomic.Int64
+
+	// invalidationDelay 更新后第二次删除缓存的延迟（由缓存失效 Worker 执行）
+	invalidationDelay time.Duration
+
+	// 各类数据库操作的超时时间（与调用方 ctx 的截止时间取较早者）
+	readTimeout  time.Duration
+	writeTimeout time.Duration
+	batchTimeout time.Duration
 }
 
 // NewUserRepository 创建用户仓储实例
-func NewUserRepository(db *sqlx.DB, redisManager redis.Manager) UserRepository {
-	return &userRepository{
-		db:           db,
-		redisManager: redisManager,
+func NewUserRepository(cluster *db.Cluster, redisManager redis.Manager, cfg *config.Config) UserRepository {
+	r := &userRepository{
+		db:                cluster.Primary(),
+		cluster:           cluster,
+		redisManager:      redisManager,
+		invalidationDelay: cfg.UserCache.Invalidation.GetDelay(),
+		readTimeout:       cfg.Database.GetReadTimeout(),
+		writeTimeout:      cfg.Database.GetWriteTimeout(),
+		batchTimeout:      cfg.Database.GetBatchTimeout(),
+	}
+	if r.invalidationDelay <= 0 {
+		r.invalidationDelay = DefaultInvalidationDelay
+	}
+	if r.readTimeout <= 0 {
+		r.readTimeout = DefaultReadTimeout
+	}
+	if r.writeTimeout <= 0 {
+		r.writeTimeout = DefaultWriteTimeout
 	}
+	if r.batchTimeout <= 0 {
+		r.batchTimeout = DefaultBatchTimeout
+	}
+	if earlyRefresh := cfg.UserCache.EarlyRefresh; earlyRefresh.Enabled {
+		r.earlyRefreshBeta = earlyRefresh.GetBeta()
+	}
+	return r
 }
 
 // GetByUsername 根据用户名查询用户
 func (r *userRepository) GetByUsername(ctx context.Context, username string) (*model.User, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.readTimeout)
+	defer cancel()
+
+	// 刚注册的用户名走主库
+	reader := r.reader(ctx, stickyUsernameKey(username))
+	user, err := r.getByUsername(ctx, reader, username)
+
+	// 从库读到的用户最近修改过（如密码），改为从主库读取
+	if err == nil && reader != r.db && r.reader(ctx, stickyUserKey(user.ID)) == r.db {
+		user, err = r.getByUsername(ctx, r.db, username)
+	}
+	return user, err
+}
+
+// getByUsername 在指定的库上根据用户名查询用户
+func (r *userRepository) getByUsername(ctx context.Context, reader *sqlx.DB, username string) (*model.User, error) {
 	var user model.User
-	query := `SELECT id, username, password_hash, nickname, profile_picture, created_at, updated_at 
-              FROM users WHERE username = ?`
+	query := reader.Rebind(`SELECT id, username, password_hash, nickname, profile_picture, created_at, updated_at 
+              FROM users WHERE username = ?`)
 
-	err := r.db.Get(&user, query, username)
+	err := reader.GetContext(ctx, &user, query, username)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("user not found: %s", username)
+			return nil, fmt.Errorf("%w: %s", ErrUserNotFound, username)
 		}
-		return nil, fmt.Errorf("failed to get user by username: %w", err)
+		return nil, fmt.Errorf("failed to get user by username: %w", wrapCtxErr(ctx, err))
 	}
 
 	return &user, nil
@@ -73,74 +169,140 @@ func (r *userRepository) GetByUsername(ctx context.Context, username string) (*m
 
 // GetByID 根据ID查询用户（优先从缓存获取，自动处理负缓存）
 func (r *userRepository) GetByID(ctx context.Context, id uint64) (*redis.CachedUser, error) {
+	ctx, span := tracer.Start(ctx, "userRepository.GetByID",
+		trace.WithAttributes(attribute.Int64("user.id", int64(id))))
+	defer span.End()
+
 	// 1. 先查缓存
 	cachedUser, err := r.redisManager.GetUserCache().GetUser(ctx, id)
 	if err != nil {
 		// Redis 错误（不是 redis.Nil），记录日志但继续查数据库（降级策略）
-		log.Error("查询Redis缓存失败", zap.Error(err), zap.Uint64("user_id", id))
+		log.ErrorCtx(ctx, "查询Redis缓存失败", zap.Error(err), zap.Uint64("user_id", id))
 		// 继续执行，尝试从数据库查询
 	}
 
-	// 2. 缓存命中
+	// 2. 缓存命中（临近过期时按概率在后台提前刷新，不阻塞本次请求）
+	span.SetAttributes(attribute.Bool("cache.hit", cachedUser != nil))
 	if cachedUser != nil {
-		log.Debug("用户缓存命中", zap.Uint64("user_id", id))
+		log.DebugCtx(ctx, "用户缓存命中", zap.Uint64("user_id", id))
+		if r.earlyRefreshBeta > 0 &&
+			cachedUser.ShouldRefreshEarly(time.Now(), time.Duration(r.loadCost.Load()), r.earlyRefreshBeta) {
+			log.DebugCtx(ctx, "用户缓存临近过期，提前刷新", zap.Uint64("user_id", id))
+			go func() {
+				_, _ = r.loadUser(context.Background(), id)
+			}()
+		}
 		return cachedUser, nil
 	}
 
-	// 3. 缓存未命中，查数据库（使用 model.User，带 db tag）
+	// 3. 缓存未命中，回源数据库（同一用户的并发请求只查询一次）
+	return r.loadUser(ctx, id)
+}
+
+// loadUser 合并同一用户ID的并发回源请求
+// 回源本身不受单个调用方取消的影响，调用方取消或超时时立即返回 ctx.Err()；
+// 返回的 CachedUser 为副本，调用方之间互不影响
+func (r *userRepository) loadUser(ctx context.Context, id uint64) (*redis.CachedUser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// 回源的 Span 挂在发起回源的调用方链路下
+	traceCtx := context.WithoutCancel(ctx)
+	ch := r.loadGroup.DoChan(strconv.FormatUint(id, 10), func() (interface{}, error) {
+		return r.loadUserFromDB(traceCtx, id)
+	})
+
+	var res singleflight.Result
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res = <-ch:
+	}
+	if res.Err != nil {
+		return nil, res.Err
+	}
+	if res.Shared {
+		log.DebugCtx(ctx, "合并并发回源请求", zap.Uint64("user_id", id))
+	}
+
+	loaded := res.Val.(*redis.CachedUser)
+	if loaded == nil {
+		return nil, nil // 用户不存在
+	}
+	user := *loaded
+	return &user, nil
+}
+
+// loadUserFromDB 从数据库加载用户并写入缓存
+// 在 singleflight 中执行，缓存同步写入，保证后续请求能命中缓存；
+// parent 不可取消，只用于传递链路追踪信息
+func (r *userRepository) loadUserFromDB(parent context.Context, id uint64) (*redis.CachedUser, error) {
+	// 写缓存不受单个调用方取消的影响（结果由所有等待者共享）
+	ctx, cancel := context.WithTimeout(parent, cacheWriteTimeout)
+	defer cancel()
+
+	// 1. 查数据库（使用 model.User，带 db tag）
+	queryCtx, queryCancel := context.WithTimeout(parent, r.readTimeout)
+	defer queryCancel()
+
+	// 回源结果会写入缓存，用户最近修改过时必须读主库，否则旧数据会被回填到缓存
+	reader := r.reader(queryCtx, stickyUserKey(id))
+
+	start := time.Now()
 	var dbUser model.User
-	query := `SELECT id, username, nickname, profile_picture FROM users WHERE id = ?`
-	err = r.db.Get(&dbUser, query, id)
+	query := reader.Rebind(`SELECT id, username, nickname, profile_picture FROM users WHERE id = ?`)
+	err := reader.GetContext(queryCtx, &dbUser, query, id)
+	r.loadCost.Store(int64(time.Since(start)))
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			// 4. 用户不存在，设置负缓存
-			log.Debug("用户不存在，设置负缓存", zap.Uint64("user_id", id))
+			// 2. 用户不存在，设置负缓存
+			log.DebugCtx(ctx, "用户不存在，设置负缓存", zap.Uint64("user_id", id))
 			if cacheErr := r.redisManager.GetUserCache().SetNullCache(ctx, id); cacheErr != nil {
-				log.Error("设置负缓存失败", zap.Error(cacheErr), zap.Uint64("user_id", id))
+				log.ErrorCtx(ctx, "设置负缓存失败", zap.Error(cacheErr), zap.Uint64("user_id", id))
 				// 不返回 cacheErr，继续返回用户不存在的信息
 			}
-			return nil, nil // 用户不存在
+			return nil, nil
 		}
 		// 数据库查询错误
-		log.Error("数据库查询失败", zap.Error(err), zap.Uint64("user_id", id))
-		return nil, err
+		log.ErrorCtx(ctx, "数据库查询失败", zap.Error(err), zap.Uint64("user_id", id))
+		return nil, wrapCtxErr(queryCtx, err)
 	}
 
-	// 5. 用户存在，转换为 CachedUser
-	cachedUser = &redis.CachedUser{
+	// 3. 用户存在，写入缓存（失败不影响返回结果）
+	if err := r.redisManager.GetUserCache().SetUser(ctx, &dbUser); err != nil {
+		log.ErrorCtx(ctx, "设置用户缓存失败", zap.Error(err), zap.Uint64("user_id", id))
+	} else {
+		log.DebugCtx(ctx, "设置用户缓存成功", zap.Uint64("user_id", id))
+	}
+
+	log.DebugCtx(ctx, "从数据库加载用户成功", zap.Uint64("user_id", id))
+	return &redis.CachedUser{
 		ID:             dbUser.ID,
 		Username:       dbUser.Username,
 		Nickname:       dbUser.Nickname,
 		ProfilePicture: dbUser.ProfilePicture,
-	}
+	}, nil
+}
 
-	// 6. 异步设置缓存（不阻塞返回）
-	go func() {
-		setCtx := context.Background()
-		if err := r.redisManager.GetUserCache().SetUser(setCtx, &dbUser); err != nil {
-			log.Error("设置用户缓存失败", zap.Error(err), zap.Uint64("user_id", id))
-		} else {
-			log.Debug("设置用户缓存成功", zap.Uint64("user_id", id))
-		}
-	}()
+// GetByIDFromDB 从数据库查询用户
+func (r *userRepository) GetByIDFromDB(ctx context.Context, id uint64) (*model.User, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.readTimeout)
+	defer cancel()
 
-	log.Debug("从数据库加载用户成功", zap.Uint64("user_id", id))
-	return cachedUser, nil
-}
+	reader := r.reader(ctx, stickyUserKey(id))
 
-// getByIDFromDB 从数据库查询用户（内部方法）
-func (r *userRepository) getByIDFromDB(id uint64) (*model.User, error) {
 	var user model.User
-	query := `SELECT id, username, password_hash, nickname, profile_picture, created_at, updated_at 
-              FROM users WHERE id = ?`
+	query := reader.Rebind(`SELECT id, username, password_hash, nickname, profile_picture, created_at, updated_at 
+              FROM users WHERE id = ?`)
 
-	err := r.db.Get(&user, query, id)
+	err := reader.GetContext(ctx, &user, query, id)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil // 用户不存在，返回nil（会触发负缓存）
 		}
-		return nil, fmt.Errorf("failed to get user by id: %w", err)
+		return nil, fmt.Errorf("failed to get user by id: %w", wrapCtxErr(ctx, err))
 	}
 
 	return &user, nil
@@ -148,12 +310,20 @@ func (r *userRepository) getByIDFromDB(id uint64) (*model.User, error) {
 
 // Create 创建用户
 func (r *userRepository) Create(ctx context.Context, user *model.User) error {
-	query := `INSERT INTO users (id, username, password_hash, nickname, profile_picture) 
-              VALUES (?, ?, ?, ?, ?)`
+	r.markPrimarySticky(ctx, stickyUserKey(user.ID), stickyUsernameKey(user.Username))
+
+	ctx, cancel := context.WithTimeout(ctx, r.writeTimeout)
+	defer cancel()
 
-	_, err := r.db.Exec(query, user.ID, user.Username, user.PasswordHash, user.Nickname, user.ProfilePicture)
+	query := r.db.Rebind(`INSERT INTO users (id, username, password_hash, nickname, profile_picture) 
+              VALUES (?, ?, ?, ?, ?)`)
+
+	_, err := r.db.ExecContext(ctx, query, user.ID, user.Username, user.PasswordHash, user.Nickname, user.ProfilePicture)
 	if err != nil {
-		return fmt.Errorf("failed to create user: %w", err)
+		if db.IsDuplicateKey(err) {
+			return fmt.Errorf("%w: %s", ErrUsernameDuplicate, user.Username)
+		}
+		return fmt.Errorf("failed to create user: %w", wrapCtxErr(ctx, err))
 	}
 
 	return nil
@@ -161,95 +331,156 @@ func (r *userRepository) Create(ctx context.Context, user *model.User) error {
 
 // UpdateNickname 更新用户昵称
 func (r *userRepository) UpdateNickname(ctx context.Context, id uint64, nickname string) error {
-	// 1. 删除缓存（降级策略：失败不影响主流程）
-	if err := r.redisManager.GetUserCache().DeleteUser(ctx, id); err != nil {
-		log.Error("删除用户缓存失败（第一次）",
-			zap.Error(err),
-			zap.Uint64("user_id", id),
-			zap.String("nickname", nickname))
-		// 不返回错误，继续执行数据库更新
+	query := r.db.Rebind(`UPDATE users SET nickname = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`)
+	if err := r.updateWithInvalidation(ctx, id, query, nickname, id); err != nil {
+		return fmt.Errorf("failed to update nickname: %w", err)
 	}
 
-	// 2. 更新数据库
-	query := `UPDATE users SET nickname = ? WHERE id = ?`
-	result, err := r.db.Exec(query, nickname, id)
-	if err != nil {
-		return fmt.Errorf("failed to update nickname: %w", err)
+	log.InfoCtx(ctx, "更新用户昵称成功",
+		zap.Uint64("user_id", id),
+		zap.String("nickname", nickname),
+	)
+
+	return nil
+}
+
+// UpdateProfilePicture 更新用户头像
+func (r *userRepository) UpdateProfilePicture(ctx context.Context, id uint64, profilePicture string) error {
+	query := r.db.Rebind(`UPDATE users SET profile_picture = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`)
+	if err := r.updateWithInvalidation(ctx, id, query, profilePicture, id); err != nil {
+		return fmt.Errorf("failed to update profile picture: %w", err)
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	log.InfoCtx(ctx, "更新用户头像成功",
+		zap.Uint64("user_id", id),
+		zap.String("profile_picture", profilePicture),
+	)
+
+	return nil
+}
+
+// ClearProfilePicture 清空头像（比较后清空：头像已被更新为其他值时不修改）
+func (r *userRepository) ClearProfilePicture(ctx context.Context, id uint64, expected string) (bool, error) {
+	query := r.db.Rebind(`UPDATE users SET profile_picture = '', updated_at = CURRENT_TIMESTAMP WHERE id = ? AND profile_picture = ?`)
+	rowsAffected, err := r.execWithInvalidation(ctx, id, query, id, expected)
 	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
+		return false, fmt.Errorf("failed to clear profile picture: %w", err)
 	}
-
 	if rowsAffected == 0 {
-		return fmt.Errorf("user not found: %d", id)
+		return false, nil
 	}
 
-	//延迟 doubleDeleteDelayTime 再次删除缓存
-	uid := id                                                                       // 防止闭包捕获循环变量
-	delay := doubleDeleteDelayTime + time.Duration(rand.Intn(200))*time.Millisecond //延迟抖动
-
-	time.AfterFunc(delay, func() {
-		ctx2, cancel := context.WithTimeout(context.Background(), 2*time.Second)
-		defer cancel()
-		if err := r.redisManager.GetUserCache().DeleteUser(ctx2, uid); err != nil {
-			log.Error("delay delete failed", zap.Error(err), zap.Uint64("user_id", uid))
-		}
-	})
-
-	log.Info("更新用户昵称成功",
+	log.InfoCtx(ctx, "清空用户头像成功",
 		zap.Uint64("user_id", id),
-		zap.String("nickname", nickname),
+		zap.String("profile_picture", expected),
 	)
 
+	return true, nil
+}
+
+// ListProfilePictures 分页列出已设置头像的用户（从库读取，调用方需容忍复制延迟）
+func (r *userRepository) ListProfilePictures(ctx context.Context, afterID uint64, limit int) ([]model.UserProfilePicture, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.readTimeout)
+	defer cancel()
+
+	reader := r.cluster.Replica()
+	query := reader.Rebind(`SELECT id, profile_picture FROM users 
+              WHERE id > ? AND profile_picture <> '' ORDER BY id LIMIT ?`)
+
+	var pictures []model.UserProfilePicture
+	if err := reader.SelectContext(ctx, &pictures, query, afterID, limit); err != nil {
+		return nil, fmt.Errorf("failed to list profile pictures: %w", wrapCtxErr(ctx, err))
+	}
+	return pictures, nil
+}
+
+// updateWithInvalidation 更新用户数据并保证缓存最终被删除，用户不存在时返回错误
+func (r *userRepository) updateWithInvalidation(ctx context.Context, id uint64, query string, args ...interface{}) error {
+	rowsAffected, err := r.execWithInvalidation(ctx, id, query, args...)
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: %d", ErrUserNotFound, id)
+	}
 	return nil
 }
 
-// UpdateProfilePicture 更新用户头像
-func (r *userRepository) UpdateProfilePicture(ctx context.Context, id uint64, profilePicture string) error {
-	// 1. 删除缓存（降级策略：失败不影响主流程）
+// execWithInvalidation 执行更新并保证缓存最终被删除（延迟双删），返回影响行数
+// 第一次删除在更新前同步执行；第二次删除以失效记录的形式与更新写入同一事务，
+// 由 CacheInvalidationWorker 在 invalidationDelay 后执行，进程退出也不会丢失；
+// 没有行被更新时回滚事务，不写入失效记录
+func (r *userRepository) execWithInvalidation(ctx context.Context, id uint64, query string, args ...interface{}) (int64, error) {
+	// 1. 删除缓存（降级策略：失败不影响主流程，由第二次删除兜底）
 	if err := r.redisManager.GetUserCache().DeleteUser(ctx, id); err != nil {
-		log.Error("删除用户缓存失败（第一次）",
-			zap.Error(err),
-			zap.Uint64("user_id", id),
-			zap.String("profile_picture", profilePicture))
-		// 不返回错误，继续执行数据库更新
+		log.ErrorCtx(ctx, "删除用户缓存失败（第一次）", zap.Error(err), zap.Uint64("user_id", id))
 	}
+	r.markPrimarySticky(ctx, stickyUserKey(id))
+
+	ctx, cancel := context.WithTimeout(ctx, r.writeTimeout)
+	defer cancel()
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", wrapCtxErr(ctx, err))
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.ErrorCtx(ctx, "事务回滚失败", zap.Error(err))
+		}
+	}()
 
 	// 2. 更新数据库
-	query := `UPDATE users SET profile_picture = ? WHERE id = ?`
-	result, err := r.db.Exec(query, profilePicture, id)
+	result, err := tx.ExecContext(ctx, query, args...)
 	if err != nil {
-		return fmt.Errorf("failed to update profile picture: %w", err)
+		return 0, wrapCtxErr(ctx, err)
 	}
 
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("user not found: %d", id)
+		return 0, nil
 	}
 
-	//延迟 doubleDeleteDelayTime 再次删除缓存
-	uid := id                                                                       // 防止闭包捕获循环变量
-	delay := doubleDeleteDelayTime + time.Duration(rand.Intn(200))*time.Millisecond //延迟抖动
+	// 3. 写入缓存失效记录（与更新同时提交）
+	if err := enqueueCacheInvalidation(ctx, tx, id, r.invalidationDelay); err != nil {
+		return 0, wrapCtxErr(ctx, err)
+	}
 
-	time.AfterFunc(delay, func() {
-		ctx2, cancel := context.WithTimeout(context.Background(), 2*time.Second)
-		defer cancel()
-		if err := r.redisManager.GetUserCache().DeleteUser(ctx2, uid); err != nil {
-			log.Error("delay delete failed", zap.Error(err), zap.Uint64("user_id", uid))
-		}
-	})
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", wrapCtxErr(ctx, err))
+	}
 
-	log.Info("更新用户头像成功",
-		zap.Uint64("user_id", id),
-		zap.String("profile_picture", profilePicture),
-	)
+	return rowsAffected, nil
+}
+
+// UpdatePassword 更新用户密码哈希
+// 用户缓存中不包含password_hash，无需处理缓存
+func (r *userRepository) UpdatePassword(ctx context.Context, id uint64, passwordHash string) error {
+	r.markPrimarySticky(ctx, stickyUserKey(id))
+
+	ctx, cancel := context.WithTimeout(ctx, r.writeTimeout)
+	defer cancel()
+
+	query := r.db.Rebind(`UPDATE users SET password_hash = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`)
+	result, err := r.db.ExecContext(ctx, query, passwordHash, id)
+	if err != nil {
+		return fmt.Errorf("failed to update password: %w", wrapCtxErr(ctx, err))
+	}
 
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: %d", ErrUserNotFound, id)
+	}
+
+	log.InfoCtx(ctx, "更新用户密码成功", zap.Uint64("user_id", id))
 	return nil
 }
 
@@ -259,40 +490,96 @@ func (r *userRepository) BatchCreate(ctx context.Context, users []*model.User) e
 		return nil
 	}
 
+	ctx, cancel := context.WithTimeout(ctx, r.batchTimeout)
+	defer cancel()
+
 	// 使用事务批量插入
-	tx, err := r.db.Beginx()
+	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+		return fmt.Errorf("failed to begin transaction: %w", wrapCtxErr(ctx, err))
 	}
 	defer func() {
 		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
-			log.Error("事务回滚失败", zap.Error(err))
+			log.ErrorCtx(ctx, "事务回滚失败", zap.Error(err))
 		}
 	}()
 
-	query := `INSERT INTO users (id, username, password_hash, nickname, profile_picture) 
-              VALUES (?, ?, ?, ?, ?)`
+	query := r.db.Rebind(`INSERT INTO users (id, username, password_hash, nickname, profile_picture) 
+              VALUES (?, ?, ?, ?, ?)`)
 
-	stmt, err := tx.Prepare(query)
+	stmt, err := tx.PrepareContext(ctx, query)
 	if err != nil {
-		return fmt.Errorf("failed to prepare statement: %w", err)
+		return fmt.Errorf("failed to prepare statement: %w", wrapCtxErr(ctx, err))
 	}
 	defer func() {
 		if err := stmt.Close(); err != nil {
-			log.Error("关闭statement失败", zap.Error(err))
+			log.ErrorCtx(ctx, "关闭statement失败", zap.Error(err))
 		}
 	}()
 
 	for _, user := range users {
-		_, err := stmt.Exec(user.ID, user.Username, user.PasswordHash, user.Nickname, user.ProfilePicture)
+		_, err := stmt.ExecContext(ctx, user.ID, user.Username, user.PasswordHash, user.Nickname, user.ProfilePicture)
 		if err != nil {
-			return fmt.Errorf("failed to insert user %s: %w", user.Username, err)
+			return fmt.Errorf("failed to insert user %s: %w", user.Username, wrapCtxErr(ctx, err))
 		}
 	}
 
 	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+		return fmt.Errorf("failed to commit transaction: %w", wrapCtxErr(ctx, err))
 	}
 
 	return nil
 }
+
+// ============================================================================
+// 读写分离
+// ============================================================================
+
+// stickyUserKey 用户ID的主库标记键
+func stickyUserKey(id uint64) string {
+	return "user:" + strconv.FormatUint(id, 10)
+}
+
+// stickyUsernameKey 用户名的主库标记键
+func stickyUsernameKey(username string) string {
+	return "username:" + username
+}
+
+// markPrimarySticky 写入前标记用户，标记有效期内该用户的读请求走主库（读己之写）
+// 标记失败只记录日志：最坏情况是短时间内从从库读到旧数据
+func (r *userRepository) markPrimarySticky(ctx context.Context, keys ...string) {
+	if !r.cluster.HasReplicas() {
+		return
+	}
+	if err := r.redisManager.GetPrimarySticky().Mark(ctx, keys...); err != nil {
+		log.WarnCtx(ctx, "设置主库标记失败", zap.Strings("keys", keys), zap.Error(err))
+	}
+}
+
+// reader 选择读库：键被标记（用户最近有写入）或标记状态未知时走主库，否则走从库
+func (r *userRepository) reader(ctx context.Context, key string) *sqlx.DB {
+	if !r.cluster.HasReplicas() {
+		return r.db
+	}
+
+	marked, err := r.redisManager.GetPrimarySticky().IsMarked(ctx, key)
+	if err != nil {
+		log.WarnCtx(ctx, "查询主库标记失败，使用主库", zap.String("key", key), zap.Error(err))
+		return r.db
+	}
+	if marked {
+		return r.db
+	}
+	return r.cluster.Replica()
+}
+
+// wrapCtxErr 数据库操作因 ctx 取消或超时失败时，在错误链中加入 ctx.Err()，
+// 上层可通过 errors.Is(err, context.Canceled / context.DeadlineExceeded) 识别
+// （不同驱动在取消时返回的错误不同，例如 PostgreSQL 返回 57014 query_canceled）
+func wrapCtxErr(ctx context.Context, err error) error {
+	ctxErr := ctx.Err()
+	if err == nil || ctxErr == nil || errors.Is(err, ctxErr) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", ctxErr, err)
+}
@@ -0,0 +1,60 @@
+package rpchandler
+
+import (
+	"context"
+
+	pbv2 "entry-task/proto/user/v2"
+	"entry-task/tcpserver/internal/dto"
+	"entry-task/tcpserver/internal/service"
+
+	log "entry-task/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// ============================================================================
+// UserAdminServiceHandler 内部管理服务 gRPC Handler（v2）
+// 供 HTTP Server 的头像对账任务调用，鉴权由 InternalAuthInterceptor 完成
+// ============================================================================
+
+type UserAdminServiceHandler struct {
+	pbv2.UnimplementedUserAdminServiceServer // 嵌入未实现的服务器，保证向前兼容
+	userService                              service.UserService
+}
+
+// NewUserAdminServiceHandler 创建内部管理服务 Handler
+func NewUserAdminServiceHandler(userService service.UserService) *UserAdminServiceHandler {
+	return &UserAdminServiceHandler{
+		userService: userService,
+	}
+}
+
+// ListProfilePictures 分页列出已设置头像的用户
+func (h *UserAdminServiceHandler) ListProfilePictures(ctx context.Context, req *pbv2.ListProfilePicturesRequest) (*pbv2.ListProfilePicturesResponse, error) {
+	pictures, err := h.userService.ListProfilePictures(ctx, dto.FromProtoV2ListProfilePicturesRequest(req))
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return dto.ToProtoV2ListProfilePicturesResponse(pictures), nil
+}
+
+// ClearProfilePicture 头像仍为请求中的值时清空
+func (h *UserAdminServiceHandler) ClearProfilePicture(ctx context.Context, req *pbv2.ClearProfilePictureRequest) (*pbv2.ClearProfilePictureResponse, error) {
+	cleared, err := h.userService.ClearProfilePicture(ctx, dto.FromProtoV2ClearProfilePictureRequest(req))
+	if err != nil {
+		st := statusFromError(err)
+		log.WarnCtx(ctx, "清空头像失败",
+			zap.Uint64("user_id", req.UserId),
+			zap.String("profile_picture", req.ProfilePicture),
+			zap.Stringer("code", st.Code()),
+			zap.Error(err))
+		return nil, st.Err()
+	}
+
+	if cleared {
+		log.InfoCtx(ctx, "清空头像成功",
+			zap.Uint64("user_id", req.UserId),
+			zap.String("profile_picture", req.ProfilePicture))
+	}
+	return &pbv2.ClearProfilePictureResponse{Cleared: cleared}, nil
+}
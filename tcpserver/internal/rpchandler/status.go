@@ -0,0 +1,179 @@
+package rpchandler
+
+import (
+	"context"
+	"errors"
+
+	"entry-task/proto/errcode"
+	pbv2 "entry-task/proto/user/v2"
+	"entry-task/tcpserver/internal/dto"
+	"entry-task/tcpserver/internal/service"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// ErrorDomain v2 错误详情 ErrorInfo 的 domain
+const ErrorDomain = "user.entry-task"
+
+// invalidFields 参数校验错误对应的请求字段，用于 BadRequest 错误详情
+var invalidFields = map[error]string{
+	dto.ErrUsernameEmpty:     "username",
+	dto.ErrUsernameInvalid:   "username",
+	dto.ErrPasswordEmpty:     "password",
+	dto.ErrPasswordTooShort:  "password",
+	dto.ErrPasswordTooLong:   "password",
+	dto.ErrNicknameEmpty:     "nickname",
+	dto.ErrNicknameTooLong:   "nickname",
+	dto.ErrTokenEmpty:        "token",
+	dto.ErrPictureURLEmpty:   "profile_picture",
+	dto.ErrUserIDInvalid:     "user_id",
+	dto.ErrPasswordUnchanged: "new_password",
+	dto.ErrSessionIDEmpty:    "session_id",
+	dto.ErrRefreshTokenEmpty: "refresh_token",
+}
+
+// fieldError 为参数校验错误指定请求字段（同一个校验错误在不同请求中对应的字段名可能不同）
+type fieldError struct {
+	field string
+	err   error
+}
+
+func (e *fieldError) Error() string { return e.err.Error() }
+
+func (e *fieldError) Unwrap() error { return e.err }
+
+// withField 将参数校验错误的字段 from 替换为 to，其他错误原样返回
+func withField(err error, from, to string) error {
+	if invalidFields[err] == from {
+		return &fieldError{field: to, err: err}
+	}
+	return err
+}
+
+// rpcStatus Service 层错误的分类结果，v1 转换为响应体中的错误码，v2 转换为 gRPC 状态
+type rpcStatus struct {
+	code    codes.Code
+	reason  pbv2.ErrorReason
+	message string
+	details []protoadapt.MessageV1
+}
+
+// statusError 将 Service 层错误转换为带错误详情的 gRPC 错误
+func statusError(err error) error {
+	return statusFromError(err).Err()
+}
+
+// statusFromError 将 Service 层错误转换为带错误详情的 gRPC 状态
+func statusFromError(err error) *status.Status {
+	return classifyError(err).status()
+}
+
+// mapServiceError 将 Service 层错误映射为 v1 响应体中的错误码和消息
+// 错误码由 user.v2.ErrorReason 的 (errcode.v1_code) 选项定义
+func mapServiceError(err error) (int32, string) {
+	s := classifyError(err)
+	code, ok := errcode.V1CodeFromReason(s.reason)
+	if !ok {
+		code, _ = errcode.V1CodeFromReason(pbv2.ErrorReason_INTERNAL)
+	}
+	return code, s.message
+}
+
+// classifyError 将 Service 层错误映射为 gRPC 状态码、错误原因、错误消息和错误详情
+func classifyError(err error) *rpcStatus {
+	// 取消和超时错误通常被多层包装，需先于下面的等值比较判断
+	switch {
+	case errors.Is(err, context.Canceled):
+		return newRPCStatus(codes.Canceled, pbv2.ErrorReason_REQUEST_CANCELED, "请求已取消")
+	case errors.Is(err, context.DeadlineExceeded):
+		return newRPCStatus(codes.DeadlineExceeded, pbv2.ErrorReason_REQUEST_TIMEOUT, "请求超时")
+	}
+
+	var limitErr *service.LoginLimitError
+	if errors.As(err, &limitErr) {
+		var details []protoadapt.MessageV1
+		if limitErr.RetryAfter > 0 {
+			details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(limitErr.RetryAfter)})
+		}
+		return newRPCStatus(codes.ResourceExhausted, pbv2.ErrorReason_LOGIN_LOCKED, "登录失败次数过多，请稍后再试", details...)
+	}
+
+	// 参数校验错误
+	field := invalidFields[err]
+	var fe *fieldError
+	if errors.As(err, &fe) {
+		field, err = fe.field, fe.err
+	}
+	if field != "" {
+		reason := pbv2.ErrorReason_INVALID_ARGUMENT
+		if field == "nickname" {
+			reason = pbv2.ErrorReason_INVALID_NICKNAME
+		}
+		return newRPCStatus(codes.InvalidArgument, reason, err.Error(), badRequest(field, err.Error()))
+	}
+
+	switch err {
+	// 登录错误
+	case service.ErrInvalidCredentials:
+		return newRPCStatus(codes.Unauthenticated, pbv2.ErrorReason_INVALID_CREDENTIALS, "用户名或密码错误")
+
+	case service.ErrOldPasswordWrong:
+		return newRPCStatus(codes.InvalidArgument, pbv2.ErrorReason_OLD_PASSWORD_WRONG, "原密码错误",
+			badRequest("old_password", "原密码错误"))
+
+	// 注册错误
+	case service.ErrUsernameExists:
+		return newRPCStatus(codes.AlreadyExists, pbv2.ErrorReason_USERNAME_EXISTS, "用户名已存在")
+
+	// Token错误
+	case service.ErrInvalidToken:
+		return newRPCStatus(codes.Unauthenticated, pbv2.ErrorReason_TOKEN_INVALID, "Token无效或已过期")
+
+	case service.ErrRefreshTokenInvalid:
+		return newRPCStatus(codes.Unauthenticated, pbv2.ErrorReason_REFRESH_TOKEN_INVALID, "刷新凭证无效或已过期")
+
+	// 资源不存在
+	case service.ErrUserNotFound:
+		return newRPCStatus(codes.NotFound, pbv2.ErrorReason_USER_NOT_FOUND, "用户不存在")
+
+	case service.ErrSessionNotFound:
+		return newRPCStatus(codes.NotFound, pbv2.ErrorReason_SESSION_NOT_FOUND, "Session不存在")
+
+	// 其他内部错误（不向调用方暴露内部细节）
+	default:
+		return newRPCStatus(codes.Internal, pbv2.ErrorReason_INTERNAL, "内部错误")
+	}
+}
+
+// newRPCStatus 创建分类结果，details 为 ErrorInfo 之外的错误详情
+func newRPCStatus(code codes.Code, reason pbv2.ErrorReason, message string, details ...protoadapt.MessageV1) *rpcStatus {
+	return &rpcStatus{code: code, reason: reason, message: message, details: details}
+}
+
+// status 创建带 ErrorInfo 的 gRPC 状态
+func (s *rpcStatus) status() *status.Status {
+	st := status.New(s.code, s.message)
+	details := append([]protoadapt.MessageV1{&errdetails.ErrorInfo{
+		Reason: s.reason.String(),
+		Domain: ErrorDomain,
+	}}, s.details...)
+	withDetails, err := st.WithDetails(details...)
+	if err != nil {
+		// 错误详情均为已知类型，不会序列化失败；兜底返回不带详情的状态
+		return st
+	}
+	return withDetails
+}
+
+// badRequest 创建单个字段的 BadRequest 错误详情
+func badRequest(field, description string) *errdetails.BadRequest {
+	return &errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{
+			{Field: field, Description: description},
+		},
+	}
+}
@@ -0,0 +1,152 @@
+package rpchandler
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	pbv2 "entry-task/proto/user/v2"
+	"entry-task/tcpserver/internal/dto"
+	"entry-task/tcpserver/internal/service"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// details 提取错误详情
+func details(t *testing.T, err error) (*status.Status, *errdetails.ErrorInfo, *errdetails.BadRequest, *errdetails.RetryInfo) {
+	t.Helper()
+	st, ok := status.FromError(err)
+	if !ok {
+		t.Fatalf("不是 gRPC 状态错误: %v", err)
+	}
+	var info *errdetails.ErrorInfo
+	var br *errdetails.BadRequest
+	var retry *errdetails.RetryInfo
+	for _, d := range st.Details() {
+		switch d := d.(type) {
+		case *errdetails.ErrorInfo:
+			info = d
+		case *errdetails.BadRequest:
+			br = d
+		case *errdetails.RetryInfo:
+			retry = d
+		}
+	}
+	if info == nil || info.Domain != ErrorDomain {
+		t.Fatalf("缺少 ErrorInfo: %v", st.Details())
+	}
+	return st, info, br, retry
+}
+
+// TestStatusError 测试 Service 层错误到 gRPC 状态码和错误原因的映射
+func TestStatusError(t *testing.T) {
+	tests := []struct {
+		err    error
+		code   codes.Code
+		reason pbv2.ErrorReason
+	}{
+		{service.ErrInvalidCredentials, codes.Unauthenticated, pbv2.ErrorReason_INVALID_CREDENTIALS},
+		{service.ErrUsernameExists, codes.AlreadyExists, pbv2.ErrorReason_USERNAME_EXISTS},
+		{service.ErrInvalidToken, codes.Unauthenticated, pbv2.ErrorReason_TOKEN_INVALID},
+		{service.ErrRefreshTokenInvalid, codes.Unauthenticated, pbv2.ErrorReason_REFRESH_TOKEN_INVALID},
+		{service.ErrUserNotFound, codes.NotFound, pbv2.ErrorReason_USER_NOT_FOUND},
+		{service.ErrSessionNotFound, codes.NotFound, pbv2.ErrorReason_SESSION_NOT_FOUND},
+		{fmt.Errorf("查询失败: %w", context.DeadlineExceeded), codes.DeadlineExceeded, pbv2.ErrorReason_REQUEST_TIMEOUT},
+		{fmt.Errorf("查询失败: %w", context.Canceled), codes.Canceled, pbv2.ErrorReason_REQUEST_CANCELED},
+		{service.ErrPasswordHashFailed, codes.Internal, pbv2.ErrorReason_INTERNAL},
+	}
+
+	for _, tt := range tests {
+		st, info, _, _ := details(t, statusError(tt.err))
+		if st.Code() != tt.code || info.Reason != tt.reason.String() {
+			t.Errorf("%v: 期望 %v/%v, 实际 %v/%s", tt.err, tt.code, tt.reason, st.Code(), info.Reason)
+		}
+	}
+
+	// 内部错误不暴露细节
+	if st, _, _, _ := details(t, statusError(service.ErrPasswordHashFailed)); st.Message() != "内部错误" {
+		t.Errorf("内部错误消息不应暴露细节: %s", st.Message())
+	}
+}
+
+// TestMapServiceError 测试 v1 响应体中的错误码保持不变
+func TestMapServiceError(t *testing.T) {
+	tests := []struct {
+		err     error
+		code    int32
+		message string
+	}{
+		{dto.ErrUsernameEmpty, 40001, dto.ErrUsernameEmpty.Error()},
+		{dto.ErrNicknameTooLong, 40001, dto.ErrNicknameTooLong.Error()},
+		{service.ErrInvalidCredentials, 40002, "用户名或密码错误"},
+		{service.ErrOldPasswordWrong, 40002, "原密码错误"},
+		{service.ErrInvalidToken, 40003, "Token无效或已过期"},
+		{service.ErrUserNotFound, 40004, "用户不存在"},
+		{service.ErrSessionNotFound, 40005, "Session不存在"},
+		{service.ErrUsernameExists, 40902, "用户名已存在"},
+		{&service.LoginLimitError{}, 42901, "登录失败次数过多，请稍后再试"},
+		{context.Canceled, 49901, "请求已取消"},
+		{service.ErrPasswordHashFailed, 50001, "内部错误"},
+		{context.DeadlineExceeded, 50401, "请求超时"},
+	}
+
+	for _, tt := range tests {
+		code, message := mapServiceError(tt.err)
+		if code != tt.code || message != tt.message {
+			t.Errorf("%v: 期望 %d/%s, 实际 %d/%s", tt.err, tt.code, tt.message, code, message)
+		}
+	}
+}
+
+// TestStatusError_BadRequest 测试参数校验错误携带 BadRequest 字段
+func TestStatusError_BadRequest(t *testing.T) {
+	st, info, br, _ := details(t, statusError(dto.ErrUsernameInvalid))
+	if st.Code() != codes.InvalidArgument || info.Reason != pbv2.ErrorReason_INVALID_ARGUMENT.String() {
+		t.Fatalf("期望 INVALID_ARGUMENT, 实际 %v/%s", st.Code(), info.Reason)
+	}
+	if br == nil || len(br.FieldViolations) != 1 || br.FieldViolations[0].Field != "username" {
+		t.Fatalf("BadRequest 字段不正确: %v", br)
+	}
+	if st.Message() != dto.ErrUsernameInvalid.Error() {
+		t.Errorf("消息应为校验错误: %s", st.Message())
+	}
+
+	// 昵称错误单独的错误原因
+	st, info, br, _ = details(t, statusError(dto.ErrNicknameTooLong))
+	if st.Code() != codes.InvalidArgument || info.Reason != pbv2.ErrorReason_INVALID_NICKNAME.String() ||
+		br == nil || br.FieldViolations[0].Field != "nickname" {
+		t.Errorf("昵称错误映射不正确: %v %v %v", st.Code(), info, br)
+	}
+
+	// 修改密码时的密码格式错误对应 new_password
+	_, _, br, _ = details(t, statusError(withField(dto.ErrPasswordTooShort, "password", "new_password")))
+	if br == nil || br.FieldViolations[0].Field != "new_password" {
+		t.Errorf("BadRequest 字段应为 new_password: %v", br)
+	}
+
+	// 原密码错误
+	st, info, br, _ = details(t, statusError(withField(service.ErrOldPasswordWrong, "password", "new_password")))
+	if st.Code() != codes.InvalidArgument || info.Reason != pbv2.ErrorReason_OLD_PASSWORD_WRONG.String() ||
+		br == nil || br.FieldViolations[0].Field != "old_password" {
+		t.Errorf("原密码错误映射不正确: %v %v %v", st.Code(), info, br)
+	}
+}
+
+// TestStatusError_LoginLocked 测试登录锁定携带 RetryInfo
+func TestStatusError_LoginLocked(t *testing.T) {
+	st, info, _, retry := details(t, statusError(&service.LoginLimitError{RetryAfter: 90 * time.Second}))
+	if st.Code() != codes.ResourceExhausted || info.Reason != pbv2.ErrorReason_LOGIN_LOCKED.String() {
+		t.Fatalf("期望 RESOURCE_EXHAUSTED/LOGIN_LOCKED, 实际 %v/%s", st.Code(), info.Reason)
+	}
+	if retry == nil || retry.RetryDelay.AsDuration() != 90*time.Second {
+		t.Errorf("RetryInfo 不正确: %v", retry)
+	}
+
+	// 剩余时间未知时不携带 RetryInfo
+	if _, _, _, retry := details(t, statusError(&service.LoginLimitError{})); retry != nil {
+		t.Errorf("不应携带 RetryInfo: %v", retry)
+	}
+}
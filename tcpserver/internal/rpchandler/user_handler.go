@@ -6,25 +6,22 @@ import (
 	"entry-task/tcpserver/internal/dto"
 	"entry-task/tcpserver/internal/service"
 
-	log "entry-task/tcpserver/pkg/logger"
+	log "entry-task/pkg/logger"
 
 	"go.uber.org/zap"
+	"google.golang.org/grpc/metadata"
 )
 
-// ============================================================================
-// RPC 错误码映射
-// ============================================================================
-
 const (
-	CodeSuccess           = 0     // 成功
-	CodeInvalidParams     = 40001 // 参数错误
-	CodeInvalidCredential = 40002 // 用户名或密码错误
-	CodeUnauthorized      = 40003 // Token无效或已过期
-	CodeUserNotFound      = 40004 // 用户不存在
-	CodeTooManyRequests   = 42901 // 请求过于频繁
-	CodeInternalError     = 50001 // 内部错误
+	// MetadataClientIP HTTP网关透传的客户端IP
+	MetadataClientIP = "x-client-ip"
+	// MetadataClientUserAgent HTTP网关透传的客户端User-Agent
+	MetadataClientUserAgent = "x-client-user-agent"
 )
 
+// CodeSuccess v1 响应体中的成功码，失败时的错误码见 mapServiceError
+const CodeSuccess = 0
+
 // ============================================================================
 // UserServiceHandler gRPC Handler
 // ============================================================================
@@ -41,13 +38,47 @@ func NewUserServiceHandler(userService service.UserService) *UserServiceHandler
 	}
 }
 
+// ============================================================================
+// Register 注册
+// ============================================================================
+
+func (h *UserServiceHandler) Register(ctx context.Context, req *pb.RegisterRequest) (*pb.RegisterResponse, error) {
+	// 1. Proto → DTO
+	registerDTO := dto.FromProtoRegisterRequest(req)
+
+	// 2. 调用 Service 层
+	profileDTO, err := h.userService.Register(ctx, registerDTO)
+
+	// 3. 错误处理
+	if err != nil {
+		code, message := mapServiceError(err)
+		log.WarnCtx(ctx, "注册失败",
+			zap.String("username", req.Username),
+			zap.Int32("code", code),
+			zap.Error(err))
+
+		return &pb.RegisterResponse{
+			Code:    code,
+			Message: message,
+			User:    nil,
+		}, nil
+	}
+
+	// 4. DTO → Proto（成功）
+	log.InfoCtx(ctx, "注册成功",
+		zap.String("username", req.Username),
+		zap.Uint64("user_id", profileDTO.ID))
+	return profileDTO.ToProtoRegisterResponse(CodeSuccess, "注册成功"), nil
+}
+
 // ============================================================================
 // Login 登录
 // ============================================================================
 
 func (h *UserServiceHandler) Login(ctx context.Context, req *pb.LoginRequest) (*pb.LoginResponse, error) {
-	// 1. Proto → DTO
+	// 1. Proto → DTO（附带网关透传的客户端信息）
 	loginDTO := dto.FromProtoLoginRequest(req)
+	loginDTO.ClientIP, loginDTO.UserAgent = clientInfoFromContext(ctx)
 
 	// 2. 调用 Service 层
 	result, err := h.userService.Login(ctx, loginDTO)
@@ -55,7 +86,7 @@ func (h *UserServiceHandler) Login(ctx context.Context, req *pb.LoginRequest) (*
 	// 3. 错误处理
 	if err != nil {
 		code, message := mapServiceError(err)
-		log.Warn("登录失败",
+		log.WarnCtx(ctx, "登录失败",
 			zap.String("username", req.Username),
 			zap.Int32("code", code),
 			zap.Error(err))
@@ -69,10 +100,38 @@ func (h *UserServiceHandler) Login(ctx context.Context, req *pb.LoginRequest) (*
 	}
 
 	// 4. DTO → Proto（成功）
-	log.Info("登录成功", zap.String("username", req.Username))
+	log.InfoCtx(ctx, "登录成功", zap.String("username", req.Username))
 	return result.ToProtoResponse(CodeSuccess, "登录成功"), nil
 }
 
+// ============================================================================
+// RefreshToken 换发登录凭证
+// ============================================================================
+
+func (h *UserServiceHandler) RefreshToken(ctx context.Context, req *pb.RefreshTokenRequest) (*pb.RefreshTokenResponse, error) {
+	// 1. Proto → DTO
+	refreshDTO := dto.FromProtoRefreshTokenRequest(req)
+
+	// 2. 调用 Service 层
+	result, err := h.userService.RefreshToken(ctx, refreshDTO)
+
+	// 3. 错误处理
+	if err != nil {
+		code, message := mapServiceError(err)
+		log.WarnCtx(ctx, "换发登录凭证失败",
+			zap.Int32("code", code),
+			zap.Error(err))
+
+		return &pb.RefreshTokenResponse{
+			Code:    code,
+			Message: message,
+		}, nil
+	}
+
+	// 4. DTO → Proto（成功）
+	return result.ToProtoRefreshTokenResponse(CodeSuccess, "刷新成功"), nil
+}
+
 // ============================================================================
 // Logout 登出
 // ============================================================================
@@ -87,7 +146,7 @@ func (h *UserServiceHandler) Logout(ctx context.Context, req *pb.LogoutRequest)
 	// 3. 错误处理
 	if err != nil {
 		code, message := mapServiceError(err)
-		log.Warn("登出失败",
+		log.WarnCtx(ctx, "登出失败",
 			zap.String("token", maskToken(req.Token)),
 			zap.Int32("code", code),
 			zap.Error(err))
@@ -99,7 +158,7 @@ func (h *UserServiceHandler) Logout(ctx context.Context, req *pb.LogoutRequest)
 	}
 
 	// 4. 成功响应
-	log.Info("登出成功", zap.String("token", maskToken(req.Token)))
+	log.InfoCtx(ctx, "登出成功", zap.String("token", maskToken(req.Token)))
 	return dto.ToProtoLogoutResponse(CodeSuccess, "登出成功"), nil
 }
 
@@ -117,7 +176,7 @@ func (h *UserServiceHandler) GetProfile(ctx context.Context, req *pb.GetProfileR
 	// 3. 错误处理
 	if err != nil {
 		code, message := mapServiceError(err)
-		log.Warn("获取用户信息失败",
+		log.WarnCtx(ctx, "获取用户信息失败",
 			zap.String("token", maskToken(req.Token)),
 			zap.Int32("code", code),
 			zap.Error(err))
@@ -130,7 +189,7 @@ func (h *UserServiceHandler) GetProfile(ctx context.Context, req *pb.GetProfileR
 	}
 
 	// 4. DTO → Proto（成功）
-	log.Debug("获取用户信息成功", zap.Uint64("user_id", profileDTO.ID))
+	log.DebugCtx(ctx, "获取用户信息成功", zap.Uint64("user_id", profileDTO.ID))
 	return profileDTO.ToProtoGetProfileResponse(CodeSuccess, "获取成功"), nil
 }
 
@@ -144,7 +203,7 @@ func (h *UserServiceHandler) UpdateNickname(ctx context.Context, req *pb.UpdateN
 	profileDTO, err := h.userService.GetProfile(ctx, validateDTO)
 	if err != nil {
 		code, message := mapServiceError(err)
-		log.Warn("Token验证失败",
+		log.WarnCtx(ctx, "Token验证失败",
 			zap.String("token", maskToken(req.Token)),
 			zap.Int32("code", code),
 			zap.Error(err))
@@ -165,7 +224,7 @@ func (h *UserServiceHandler) UpdateNickname(ctx context.Context, req *pb.UpdateN
 	// 4. 错误处理
 	if err != nil {
 		code, message := mapServiceError(err)
-		log.Warn("更新昵称失败",
+		log.WarnCtx(ctx, "更新昵称失败",
 			zap.Uint64("user_id", profileDTO.ID),
 			zap.String("nickname", req.Nickname),
 			zap.Int32("code", code),
@@ -179,7 +238,7 @@ func (h *UserServiceHandler) UpdateNickname(ctx context.Context, req *pb.UpdateN
 	}
 
 	// 5. DTO → Proto（成功）
-	log.Info("更新昵称成功",
+	log.InfoCtx(ctx, "更新昵称成功",
 		zap.Uint64("user_id", updatedProfile.ID),
 		zap.String("nickname", req.Nickname))
 	return updatedProfile.ToProtoUpdateNicknameResponse(CodeSuccess, "更新成功"), nil
@@ -195,7 +254,7 @@ func (h *UserServiceHandler) UpdateProfilePicture(ctx context.Context, req *pb.U
 	profileDTO, err := h.userService.GetProfile(ctx, validateDTO)
 	if err != nil {
 		code, message := mapServiceError(err)
-		log.Warn("Token验证失败",
+		log.WarnCtx(ctx, "Token验证失败",
 			zap.String("token", maskToken(req.Token)),
 			zap.Int32("code", code),
 			zap.Error(err))
@@ -216,7 +275,7 @@ func (h *UserServiceHandler) UpdateProfilePicture(ctx context.Context, req *pb.U
 	// 4. 错误处理
 	if err != nil {
 		code, message := mapServiceError(err)
-		log.Warn("更新头像失败",
+		log.WarnCtx(ctx, "更新头像失败",
 			zap.Uint64("user_id", profileDTO.ID),
 			zap.String("profile_picture", req.ProfilePicture),
 			zap.Int32("code", code),
@@ -230,51 +289,231 @@ func (h *UserServiceHandler) UpdateProfilePicture(ctx context.Context, req *pb.U
 	}
 
 	// 5. DTO → Proto（成功）
-	log.Info("更新头像成功",
+	log.InfoCtx(ctx, "更新头像成功",
 		zap.Uint64("user_id", updatedProfile.ID),
 		zap.String("profile_picture", req.ProfilePicture))
 	return updatedProfile.ToProtoUpdateProfilePictureResponse(CodeSuccess, "更新成功"), nil
 }
 
 // ============================================================================
-// 错误映射函数
+// ChangePassword 修改密码
+// ============================================================================
+
+func (h *UserServiceHandler) ChangePassword(ctx context.Context, req *pb.ChangePasswordRequest) (*pb.ChangePasswordResponse, error) {
+	// 1. 先验证 Token，获取 UserID
+	validateDTO := &dto.ValidateTokenDTO{Token: req.Token}
+	profileDTO, err := h.userService.GetProfile(ctx, validateDTO)
+	if err != nil {
+		code, message := mapServiceError(err)
+		log.WarnCtx(ctx, "Token验证失败",
+			zap.String("token", maskToken(req.Token)),
+			zap.Int32("code", code),
+			zap.Error(err))
+
+		return &pb.ChangePasswordResponse{
+			Code:    code,
+			Message: message,
+		}, nil
+	}
+
+	// 2. Proto → DTO
+	changeDTO := dto.FromProtoChangePasswordRequest(req, profileDTO.ID)
+
+	// 3. 调用 Service 层
+	revoked, err := h.userService.ChangePassword(ctx, changeDTO)
+
+	// 4. 错误处理
+	if err != nil {
+		code, message := mapServiceError(err)
+		log.WarnCtx(ctx, "修改密码失败",
+			zap.Uint64("user_id", profileDTO.ID),
+			zap.Int32("code", code),
+			zap.Error(err))
+
+		return &pb.ChangePasswordResponse{
+			Code:    code,
+			Message: message,
+		}, nil
+	}
+
+	// 5. 成功响应
+	log.InfoCtx(ctx, "修改密码成功",
+		zap.Uint64("user_id", profileDTO.ID),
+		zap.Int("revoked_sessions", revoked))
+	return dto.ToProtoChangePasswordResponse(CodeSuccess, "修改成功", revoked), nil
+}
+
+// ============================================================================
+// ListSessions 列出Session
+// ============================================================================
+
+func (h *UserServiceHandler) ListSessions(ctx context.Context, req *pb.ListSessionsRequest) (*pb.ListSessionsResponse, error) {
+	// 1. 先验证 Token，获取 UserID
+	validateDTO := &dto.ValidateTokenDTO{Token: req.Token}
+	profileDTO, err := h.userService.GetProfile(ctx, validateDTO)
+	if err != nil {
+		code, message := mapServiceError(err)
+		log.WarnCtx(ctx, "Token验证失败",
+			zap.String("token", maskToken(req.Token)),
+			zap.Int32("code", code),
+			zap.Error(err))
+
+		return &pb.ListSessionsResponse{
+			Code:    code,
+			Message: message,
+		}, nil
+	}
+
+	// 2. Proto → DTO
+	listDTO := dto.FromProtoListSessionsRequest(req, profileDTO.ID)
+
+	// 3. 调用 Service 层
+	sessions, err := h.userService.ListSessions(ctx, listDTO)
+
+	// 4. 错误处理
+	if err != nil {
+		code, message := mapServiceError(err)
+		log.WarnCtx(ctx, "查询Session失败",
+			zap.Uint64("user_id", profileDTO.ID),
+			zap.Int32("code", code),
+			zap.Error(err))
+
+		return &pb.ListSessionsResponse{
+			Code:    code,
+			Message: message,
+		}, nil
+	}
+
+	// 5. DTO → Proto（成功）
+	log.DebugCtx(ctx, "查询Session成功",
+		zap.Uint64("user_id", profileDTO.ID),
+		zap.Int("count", len(sessions)))
+	return dto.ToProtoListSessionsResponse(CodeSuccess, "获取成功", sessions), nil
+}
+
+// ============================================================================
+// RevokeSession 注销指定Session
+// ============================================================================
+
+func (h *UserServiceHandler) RevokeSession(ctx context.Context, req *pb.RevokeSessionRequest) (*pb.RevokeSessionResponse, error) {
+	// 1. 先验证 Token，获取 UserID
+	validateDTO := &dto.ValidateTokenDTO{Token: req.Token}
+	profileDTO, err := h.userService.GetProfile(ctx, validateDTO)
+	if err != nil {
+		code, message := mapServiceError(err)
+		log.WarnCtx(ctx, "Token验证失败",
+			zap.String("token", maskToken(req.Token)),
+			zap.Int32("code", code),
+			zap.Error(err))
+
+		return &pb.RevokeSessionResponse{
+			Code:    code,
+			Message: message,
+		}, nil
+	}
+
+	// 2. Proto → DTO
+	revokeDTO := dto.FromProtoRevokeSessionRequest(req, profileDTO.ID)
+
+	// 3. 调用 Service 层
+	if err := h.userService.RevokeSession(ctx, revokeDTO); err != nil {
+		code, message := mapServiceError(err)
+		log.WarnCtx(ctx, "注销Session失败",
+			zap.Uint64("user_id", profileDTO.ID),
+			zap.String("session_id", req.SessionId),
+			zap.Int32("code", code),
+			zap.Error(err))
+
+		return &pb.RevokeSessionResponse{
+			Code:    code,
+			Message: message,
+		}, nil
+	}
+
+	// 4. 成功响应
+	log.InfoCtx(ctx, "注销Session成功",
+		zap.Uint64("user_id", profileDTO.ID),
+		zap.String("session_id", req.SessionId))
+	return &pb.RevokeSessionResponse{
+		Code:    CodeSuccess,
+		Message: "注销成功",
+	}, nil
+}
+
+// ============================================================================
+// RevokeAllSessions 注销所有Session
 // ============================================================================
 
-// mapServiceError 将 Service 层错误映射为 RPC 错误码和消息
-func mapServiceError(err error) (int32, string) {
-	switch err {
-	// 验证错误
-	case dto.ErrUsernameEmpty, dto.ErrUsernameInvalid,
-		dto.ErrPasswordEmpty, dto.ErrPasswordTooShort, dto.ErrPasswordTooLong,
-		dto.ErrNicknameEmpty, dto.ErrNicknameTooLong,
-		dto.ErrTokenEmpty, dto.ErrPictureURLEmpty, dto.ErrUserIDInvalid:
-		return CodeInvalidParams, err.Error()
-
-	// 登录错误
-	case service.ErrInvalidCredentials:
-		return CodeInvalidCredential, "用户名或密码错误"
-
-	case service.ErrLoginLimitExceeded:
-		return CodeTooManyRequests, "登录失败次数过多，请稍后再试"
-
-	// Token错误
-	case service.ErrInvalidToken:
-		return CodeUnauthorized, "Token无效或已过期"
-
-	// 用户不存在
-	case service.ErrUserNotFound:
-		return CodeUserNotFound, "用户不存在"
-
-	// 其他内部错误
-	default:
-		return CodeInternalError, "内部错误"
+func (h *UserServiceHandler) RevokeAllSessions(ctx context.Context, req *pb.RevokeAllSessionsRequest) (*pb.RevokeAllSessionsResponse, error) {
+	// 1. 先验证 Token，获取 UserID
+	validateDTO := &dto.ValidateTokenDTO{Token: req.Token}
+	profileDTO, err := h.userService.GetProfile(ctx, validateDTO)
+	if err != nil {
+		code, message := mapServiceError(err)
+		log.WarnCtx(ctx, "Token验证失败",
+			zap.String("token", maskToken(req.Token)),
+			zap.Int32("code", code),
+			zap.Error(err))
+
+		return &pb.RevokeAllSessionsResponse{
+			Code:    code,
+			Message: message,
+		}, nil
 	}
+
+	// 2. Proto → DTO
+	revokeDTO := dto.FromProtoRevokeAllSessionsRequest(req, profileDTO.ID)
+
+	// 3. 调用 Service 层
+	revoked, err := h.userService.RevokeAllSessions(ctx, revokeDTO)
+
+	// 4. 错误处理
+	if err != nil {
+		code, message := mapServiceError(err)
+		log.WarnCtx(ctx, "注销所有Session失败",
+			zap.Uint64("user_id", profileDTO.ID),
+			zap.Int32("code", code),
+			zap.Error(err))
+
+		return &pb.RevokeAllSessionsResponse{
+			Code:    code,
+			Message: message,
+		}, nil
+	}
+
+	// 5. 成功响应
+	log.InfoCtx(ctx, "注销所有Session成功",
+		zap.Uint64("user_id", profileDTO.ID),
+		zap.Bool("include_current", req.IncludeCurrent),
+		zap.Int("revoked_sessions", revoked))
+	return &pb.RevokeAllSessionsResponse{
+		Code:            CodeSuccess,
+		Message:         "注销成功",
+		RevokedSessions: int32(revoked),
+	}, nil
 }
 
 // ============================================================================
 // 辅助函数
 // ============================================================================
 
+// clientInfoFromContext 从 gRPC metadata 中提取网关透传的客户端IP和User-Agent
+func clientInfoFromContext(ctx context.Context) (string, string) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", ""
+	}
+	return firstMetadataValue(md, MetadataClientIP), firstMetadataValue(md, MetadataClientUserAgent)
+}
+
+// firstMetadataValue 获取 metadata 中指定键的第一个值
+func firstMetadataValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
 // maskToken 脱敏 Token，隐藏后 10 个字符
 func maskToken(token string) string {
 	if len(token) <= 10 {
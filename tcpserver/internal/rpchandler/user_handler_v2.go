@@ -0,0 +1,285 @@
+package rpchandler
+
+import (
+	"context"
+
+	pbv2 "entry-task/proto/user/v2"
+	"entry-task/tcpserver/internal/dto"
+	"entry-task/tcpserver/internal/service"
+
+	log "entry-task/pkg/logger"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+)
+
+// ============================================================================
+// UserServiceV2Handler gRPC Handler（v2）
+// 失败时返回带错误详情的 gRPC 状态（见 statusFromError），响应中不再携带 code/message
+// ============================================================================
+
+type UserServiceV2Handler struct {
+	pbv2.UnimplementedUserServiceServer // 嵌入未实现的服务器，保证向前兼容
+	userService                         service.UserService
+}
+
+// NewUserServiceV2Handler 创建 v2 gRPC Handler
+func NewUserServiceV2Handler(userService service.UserService) *UserServiceV2Handler {
+	return &UserServiceV2Handler{
+		userService: userService,
+	}
+}
+
+// Register 注册
+func (h *UserServiceV2Handler) Register(ctx context.Context, req *pbv2.RegisterRequest) (*pbv2.RegisterResponse, error) {
+	profileDTO, err := h.userService.Register(ctx, dto.FromProtoV2RegisterRequest(req))
+	if err != nil {
+		st := statusFromError(err)
+		log.WarnCtx(ctx, "注册失败",
+			zap.String("username", req.Username),
+			zap.Stringer("code", st.Code()),
+			zap.Error(err))
+		return nil, st.Err()
+	}
+
+	log.InfoCtx(ctx, "注册成功",
+		zap.String("username", req.Username),
+		zap.Uint64("user_id", profileDTO.ID))
+	return &pbv2.RegisterResponse{User: profileDTO.ToProtoV2()}, nil
+}
+
+// Login 登录
+func (h *UserServiceV2Handler) Login(ctx context.Context, req *pbv2.LoginRequest) (*pbv2.LoginResponse, error) {
+	// 附带网关透传的客户端信息
+	loginDTO := dto.FromProtoV2LoginRequest(req)
+	loginDTO.ClientIP, loginDTO.UserAgent = clientInfoFromContext(ctx)
+
+	result, err := h.userService.Login(ctx, loginDTO)
+	if err != nil {
+		st := statusFromError(err)
+		log.WarnCtx(ctx, "登录失败",
+			zap.String("username", req.Username),
+			zap.Stringer("code", st.Code()),
+			zap.Error(err))
+		return nil, st.Err()
+	}
+
+	log.InfoCtx(ctx, "登录成功", zap.String("username", req.Username))
+	return result.ToProtoV2LoginResponse(), nil
+}
+
+// RefreshToken 换发登录凭证
+func (h *UserServiceV2Handler) RefreshToken(ctx context.Context, req *pbv2.RefreshTokenRequest) (*pbv2.RefreshTokenResponse, error) {
+	result, err := h.userService.RefreshToken(ctx, dto.FromProtoV2RefreshTokenRequest(req))
+	if err != nil {
+		st := statusFromError(err)
+		log.WarnCtx(ctx, "换发登录凭证失败",
+			zap.Stringer("code", st.Code()),
+			zap.Error(err))
+		return nil, st.Err()
+	}
+
+	return result.ToProtoV2RefreshTokenResponse(), nil
+}
+
+// Logout 登出
+func (h *UserServiceV2Handler) Logout(ctx context.Context, req *pbv2.LogoutRequest) (*pbv2.LogoutResponse, error) {
+	if err := h.userService.Logout(ctx, dto.FromProtoV2LogoutRequest(req)); err != nil {
+		st := statusFromError(err)
+		log.WarnCtx(ctx, "登出失败",
+			zap.String("token", maskToken(req.Token)),
+			zap.Stringer("code", st.Code()),
+			zap.Error(err))
+		return nil, st.Err()
+	}
+
+	log.InfoCtx(ctx, "登出成功", zap.String("token", maskToken(req.Token)))
+	return &pbv2.LogoutResponse{}, nil
+}
+
+// GetProfile 获取用户信息
+func (h *UserServiceV2Handler) GetProfile(ctx context.Context, req *pbv2.GetProfileRequest) (*pbv2.GetProfileResponse, error) {
+	profileDTO, err := h.authenticate(ctx, req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	log.DebugCtx(ctx, "获取用户信息成功", zap.Uint64("user_id", profileDTO.ID))
+	return &pbv2.GetProfileResponse{User: profileDTO.ToProtoV2()}, nil
+}
+
+// GetPublicProfile 获取指定用户的公开信息（无需登录）
+func (h *UserServiceV2Handler) GetPublicProfile(ctx context.Context, req *pbv2.GetPublicProfileRequest) (*pbv2.GetPublicProfileResponse, error) {
+	profileDTO, err := h.userService.GetPublicProfile(ctx, dto.FromProtoV2GetPublicProfileRequest(req))
+	if err != nil {
+		st := statusFromError(err)
+		if st.Code() == codes.NotFound {
+			// 不存在的用户ID属于正常请求（如已注销的用户），不记录告警
+			log.DebugCtx(ctx, "获取公开信息失败", zap.Uint64("user_id", req.UserId), zap.Error(err))
+		} else {
+			log.WarnCtx(ctx, "获取公开信息失败",
+				zap.Uint64("user_id", req.UserId),
+				zap.Stringer("code", st.Code()),
+				zap.Error(err))
+		}
+		return nil, st.Err()
+	}
+
+	return &pbv2.GetPublicProfileResponse{User: profileDTO.ToProtoV2PublicProfile()}, nil
+}
+
+// UpdateNickname 更新昵称
+func (h *UserServiceV2Handler) UpdateNickname(ctx context.Context, req *pbv2.UpdateNicknameRequest) (*pbv2.UpdateNicknameResponse, error) {
+	profileDTO, err := h.authenticate(ctx, req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	updatedProfile, err := h.userService.UpdateNickname(ctx, dto.FromProtoV2UpdateNicknameRequest(req, profileDTO.ID))
+	if err != nil {
+		st := statusFromError(err)
+		log.WarnCtx(ctx, "更新昵称失败",
+			zap.Uint64("user_id", profileDTO.ID),
+			zap.String("nickname", req.Nickname),
+			zap.Stringer("code", st.Code()),
+			zap.Error(err))
+		return nil, st.Err()
+	}
+
+	log.InfoCtx(ctx, "更新昵称成功",
+		zap.Uint64("user_id", updatedProfile.ID),
+		zap.String("nickname", req.Nickname))
+	return &pbv2.UpdateNicknameResponse{User: updatedProfile.ToProtoV2()}, nil
+}
+
+// UpdateProfilePicture 更新头像
+func (h *UserServiceV2Handler) UpdateProfilePicture(ctx context.Context, req *pbv2.UpdateProfilePictureRequest) (*pbv2.UpdateProfilePictureResponse, error) {
+	profileDTO, err := h.authenticate(ctx, req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	updatedProfile, err := h.userService.UpdateProfilePicture(ctx, dto.FromProtoV2UpdateProfilePictureRequest(req, profileDTO.ID))
+	if err != nil {
+		st := statusFromError(err)
+		log.WarnCtx(ctx, "更新头像失败",
+			zap.Uint64("user_id", profileDTO.ID),
+			zap.String("profile_picture", req.ProfilePicture),
+			zap.Stringer("code", st.Code()),
+			zap.Error(err))
+		return nil, st.Err()
+	}
+
+	log.InfoCtx(ctx, "更新头像成功",
+		zap.Uint64("user_id", updatedProfile.ID),
+		zap.String("profile_picture", req.ProfilePicture))
+	return &pbv2.UpdateProfilePictureResponse{User: updatedProfile.ToProtoV2()}, nil
+}
+
+// ChangePassword 修改密码
+func (h *UserServiceV2Handler) ChangePassword(ctx context.Context, req *pbv2.ChangePasswordRequest) (*pbv2.ChangePasswordResponse, error) {
+	profileDTO, err := h.authenticate(ctx, req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	revoked, err := h.userService.ChangePassword(ctx, dto.FromProtoV2ChangePasswordRequest(req, profileDTO.ID))
+	if err != nil {
+		// 密码格式校验针对的是新密码
+		st := statusFromError(withField(err, "password", "new_password"))
+		log.WarnCtx(ctx, "修改密码失败",
+			zap.Uint64("user_id", profileDTO.ID),
+			zap.Stringer("code", st.Code()),
+			zap.Error(err))
+		return nil, st.Err()
+	}
+
+	log.InfoCtx(ctx, "修改密码成功",
+		zap.Uint64("user_id", profileDTO.ID),
+		zap.Int("revoked_sessions", revoked))
+	return &pbv2.ChangePasswordResponse{RevokedSessions: int32(revoked)}, nil
+}
+
+// ListSessions 列出Session
+func (h *UserServiceV2Handler) ListSessions(ctx context.Context, req *pbv2.ListSessionsRequest) (*pbv2.ListSessionsResponse, error) {
+	profileDTO, err := h.authenticate(ctx, req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := h.userService.ListSessions(ctx, dto.FromProtoV2ListSessionsRequest(req, profileDTO.ID))
+	if err != nil {
+		st := statusFromError(err)
+		log.WarnCtx(ctx, "查询Session失败",
+			zap.Uint64("user_id", profileDTO.ID),
+			zap.Stringer("code", st.Code()),
+			zap.Error(err))
+		return nil, st.Err()
+	}
+
+	log.DebugCtx(ctx, "查询Session成功",
+		zap.Uint64("user_id", profileDTO.ID),
+		zap.Int("count", len(sessions)))
+	return dto.ToProtoV2ListSessionsResponse(sessions), nil
+}
+
+// RevokeSession 注销指定Session
+func (h *UserServiceV2Handler) RevokeSession(ctx context.Context, req *pbv2.RevokeSessionRequest) (*pbv2.RevokeSessionResponse, error) {
+	profileDTO, err := h.authenticate(ctx, req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.userService.RevokeSession(ctx, dto.FromProtoV2RevokeSessionRequest(req, profileDTO.ID)); err != nil {
+		st := statusFromError(err)
+		log.WarnCtx(ctx, "注销Session失败",
+			zap.Uint64("user_id", profileDTO.ID),
+			zap.String("session_id", req.SessionId),
+			zap.Stringer("code", st.Code()),
+			zap.Error(err))
+		return nil, st.Err()
+	}
+
+	log.InfoCtx(ctx, "注销Session成功",
+		zap.Uint64("user_id", profileDTO.ID),
+		zap.String("session_id", req.SessionId))
+	return &pbv2.RevokeSessionResponse{}, nil
+}
+
+// RevokeAllSessions 注销所有Session
+func (h *UserServiceV2Handler) RevokeAllSessions(ctx context.Context, req *pbv2.RevokeAllSessionsRequest) (*pbv2.RevokeAllSessionsResponse, error) {
+	profileDTO, err := h.authenticate(ctx, req.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	revoked, err := h.userService.RevokeAllSessions(ctx, dto.FromProtoV2RevokeAllSessionsRequest(req, profileDTO.ID))
+	if err != nil {
+		st := statusFromError(err)
+		log.WarnCtx(ctx, "注销所有Session失败",
+			zap.Uint64("user_id", profileDTO.ID),
+			zap.Stringer("code", st.Code()),
+			zap.Error(err))
+		return nil, st.Err()
+	}
+
+	log.InfoCtx(ctx, "注销所有Session成功",
+		zap.Uint64("user_id", profileDTO.ID),
+		zap.Bool("include_current", req.IncludeCurrent),
+		zap.Int("revoked_sessions", revoked))
+	return &pbv2.RevokeAllSessionsResponse{RevokedSessions: int32(revoked)}, nil
+}
+
+// authenticate 验证 Token，返回当前用户信息；失败时返回带错误详情的 gRPC 错误
+func (h *UserServiceV2Handler) authenticate(ctx context.Context, token string) (*dto.UserProfileDTO, error) {
+	profileDTO, err := h.userService.GetProfile(ctx, &dto.ValidateTokenDTO{Token: token})
+	if err != nil {
+		st := statusFromError(err)
+		log.WarnCtx(ctx, "Token验证失败",
+			zap.String("token", maskToken(token)),
+			zap.Stringer("code", st.Code()),
+			zap.Error(err))
+		return nil, st.Err()
+	}
+	return profileDTO, nil
+}
@@ -7,11 +7,12 @@ import (
 	"entry-task/tcpserver/pkg/redis"
 	"errors"
 	"fmt"
+	"time"
 
 	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
 
-	log "entry-task/tcpserver/pkg/logger"
+	log "entry-task/pkg/logger"
 )
 
 // ============================================================================
@@ -25,6 +26,11 @@ var (
 	ErrSessionCreateFailed = errors.New("创建会话失败")
 	ErrInvalidToken        = errors.New("无效的Token")
 	ErrLoginLimitExceeded  = errors.New("登录失败次数过多，请稍后再试")
+	ErrUsernameExists      = errors.New("用户名已存在")
+	ErrIDGenerateFailed    = errors.New("生成用户ID失败")
+	ErrOldPasswordWrong    = errors.New("原密码错误")
+	ErrSessionNotFound     = errors.New("Session不存在")
+	ErrRefreshTokenInvalid = errors.New("刷新凭证无效或已过期")
 )
 
 const (
@@ -32,25 +38,67 @@ const (
 	MaxLoginFailures = 5
 )
 
+// LoginLimitError 登录被锁定错误，携带距离解锁的剩余时间
+// errors.Is(err, ErrLoginLimitExceeded) 为 true
+type LoginLimitError struct {
+	RetryAfter time.Duration // 剩余锁定时间，获取失败时为 0
+}
+
+func (e *LoginLimitError) Error() string { return ErrLoginLimitExceeded.Error() }
+
+func (e *LoginLimitError) Unwrap() error { return ErrLoginLimitExceeded }
+
 // ============================================================================
 // UserService 接口
 // ============================================================================
 
 type UserService interface {
+	// Register 用户注册
+	Register(ctx context.Context, registerDTO *dto.RegisterDTO) (*dto.UserProfileDTO, error)
+
 	// Login 用户登录
 	Login(ctx context.Context, loginDTO *dto.LoginDTO) (*dto.LoginResultDTO, error)
 
 	// Logout 用户登出
 	Logout(ctx context.Context, logoutDTO *dto.LogoutDTO) error
 
+	// RefreshToken 使用刷新凭证换发登录凭证（仅token模式）
+	RefreshToken(ctx context.Context, refreshDTO *dto.RefreshTokenDTO) (*dto.LoginResultDTO, error)
+
 	// GetProfile 获取用户信息（通过Token）
 	GetProfile(ctx context.Context, validateDTO *dto.ValidateTokenDTO) (*dto.UserProfileDTO, error)
 
+	// GetPublicProfile 获取指定用户的公开信息（无需登录）
+	GetPublicProfile(ctx context.Context, getDTO *dto.GetPublicProfileDTO) (*dto.UserProfileDTO, error)
+
 	// UpdateNickname 更新用户昵称
 	UpdateNickname(ctx context.Context, updateDTO *dto.UpdateNicknameDTO) (*dto.UserProfileDTO, error)
 
 	// UpdateProfilePicture 更新用户头像URL
 	UpdateProfilePicture(ctx context.Context, updateDTO *dto.UpdateProfilePictureDTO) (*dto.UserProfileDTO, error)
+
+	// ClearProfilePicture 当前头像等于期望值时清空头像，返回是否清空
+	ClearProfilePicture(ctx context.Context, clearDTO *dto.ClearProfilePictureDTO) (bool, error)
+
+	// ListProfilePictures 按用户ID分页列出已设置头像的用户
+	ListProfilePictures(ctx context.Context, listDTO *dto.ListProfilePicturesDTO) ([]*dto.ProfilePictureDTO, error)
+
+	// ChangePassword 修改密码，并注销该用户的其他Session，返回被注销的Session数量
+	ChangePassword(ctx context.Context, changeDTO *dto.ChangePasswordDTO) (int, error)
+
+	// ListSessions 列出用户的所有登录Session
+	ListSessions(ctx context.Context, listDTO *dto.ListSessionsDTO) ([]*dto.SessionDTO, error)
+
+	// RevokeSession 注销用户的指定Session
+	RevokeSession(ctx context.Context, revokeDTO *dto.RevokeSessionDTO) error
+
+	// RevokeAllSessions 注销用户的所有Session，返回被注销的Session数量
+	RevokeAllSessions(ctx context.Context, revokeDTO *dto.RevokeAllSessionsDTO) (int, error)
+}
+
+// IDGenerator 用户ID生成器（由雪花算法实现）
+type IDGenerator interface {
+	NextID() (int64, error)
 }
 
 // ============================================================================
@@ -60,16 +108,84 @@ type UserService interface {
 type userService struct {
 	userRepo     repository.UserRepository
 	redisManager redis.Manager
+	idGenerator  IDGenerator
 }
 
 // NewUserService 创建UserService实例
-func NewUserService(userRepo repository.UserRepository, redisManager redis.Manager) UserService {
+func NewUserService(userRepo repository.UserRepository, redisManager redis.Manager, idGenerator IDGenerator) UserService {
 	return &userService{
 		userRepo:     userRepo,
 		redisManager: redisManager,
+		idGenerator:  idGenerator,
 	}
 }
 
+// ============================================================================
+// Register 注册
+// ============================================================================
+
+func (s *userService) Register(ctx context.Context, registerDTO *dto.RegisterDTO) (*dto.UserProfileDTO, error) {
+	// 1. 验证DTO
+	if err := registerDTO.Validate(); err != nil {
+		log.WarnCtx(ctx, "注册参数验证失败", zap.Error(err), zap.String("username", registerDTO.Username))
+		return nil, err
+	}
+
+	// 2. 检查用户名是否已存在
+	_, err := s.userRepo.GetByUsername(ctx, registerDTO.Username)
+	if err == nil {
+		log.WarnCtx(ctx, "用户名已存在", zap.String("username", registerDTO.Username))
+		return nil, ErrUsernameExists
+	}
+	if !errors.Is(err, repository.ErrUserNotFound) {
+		log.ErrorCtx(ctx, "查询用户名失败", zap.Error(err), zap.String("username", registerDTO.Username))
+		return nil, fmt.Errorf("查询用户名失败: %w", err)
+	}
+
+	// 3. 密码哈希（bcrypt）
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(registerDTO.Password), bcrypt.DefaultCost)
+	if err != nil {
+		log.ErrorCtx(ctx, "密码哈希失败", zap.Error(err))
+		return nil, ErrPasswordHashFailed
+	}
+
+	// 4. 生成雪花ID
+	id, err := s.idGenerator.NextID()
+	if err != nil {
+		log.ErrorCtx(ctx, "生成用户ID失败", zap.Error(err))
+		return nil, ErrIDGenerateFailed
+	}
+
+	// 5. 昵称为空时默认使用用户名
+	nickname := registerDTO.Nickname
+	if nickname == "" {
+		nickname = registerDTO.Username
+	}
+
+	userDTO := &dto.UserDTO{
+		ID:           uint64(id),
+		Username:     registerDTO.Username,
+		PasswordHash: string(passwordHash),
+		Nickname:     nickname,
+	}
+
+	// 6. 写入数据库（并发注册同名用户时由唯一索引兜底）
+	if err := s.userRepo.Create(ctx, userDTO.ToModel()); err != nil {
+		if errors.Is(err, repository.ErrUsernameDuplicate) {
+			log.WarnCtx(ctx, "用户名已存在（唯一索引冲突）", zap.String("username", registerDTO.Username))
+			return nil, ErrUsernameExists
+		}
+		log.ErrorCtx(ctx, "创建用户失败", zap.Error(err), zap.String("username", registerDTO.Username))
+		return nil, fmt.Errorf("创建用户失败: %w", err)
+	}
+
+	log.InfoCtx(ctx, "用户注册成功",
+		zap.String("username", registerDTO.Username),
+		zap.Uint64("user_id", userDTO.ID))
+
+	return userDTO.ToProfile(), nil
+}
+
 // ============================================================================
 // Login 登录
 // ============================================================================
@@ -77,68 +193,111 @@ func NewUserService(userRepo repository.UserRepository, redisManager redis.Manag
 func (s *userService) Login(ctx context.Context, loginDTO *dto.LoginDTO) (*dto.LoginResultDTO, error) {
 	// 1. 验证DTO
 	if err := loginDTO.Validate(); err != nil {
-		log.Warn("登录参数验证失败", zap.Error(err), zap.String("username", loginDTO.Username))
+		log.WarnCtx(ctx, "登录参数验证失败", zap.Error(err), zap.String("username", loginDTO.Username))
 		return nil, err
 	}
 
 	// 2. 检查登录失败次数限制
 	failCount, err := s.redisManager.GetLoginLimiter().GetLoginFailCount(ctx, loginDTO.Username)
 	if err != nil {
-		log.Error("获取登录失败次数失败", zap.Error(err), zap.String("username", loginDTO.Username))
+		log.ErrorCtx(ctx, "获取登录失败次数失败", zap.Error(err), zap.String("username", loginDTO.Username))
 		// 降级策略：失败不影响登录流程
 	}
 	if failCount >= MaxLoginFailures {
-		log.Warn("登录失败次数过多",
+		log.WarnCtx(ctx, "登录失败次数过多",
 			zap.String("username", loginDTO.Username),
 			zap.Int64("fail_count", failCount))
-		return nil, ErrLoginLimitExceeded
+		retryAfter, ttlErr := s.redisManager.GetLoginLimiter().GetLockoutTTL(ctx, loginDTO.Username)
+		if ttlErr != nil {
+			log.WarnCtx(ctx, "获取登录锁定剩余时间失败", zap.Error(ttlErr), zap.String("username", loginDTO.Username))
+		}
+		return nil, &LoginLimitError{RetryAfter: retryAfter}
 	}
 
 	// 3. 查询用户（从Repository获取，包含password_hash）
 	user, err := s.userRepo.GetByUsername(ctx, loginDTO.Username)
-	if err != nil {
-		log.Warn("用户不存在", zap.String("username", loginDTO.Username))
+	if errors.Is(err, repository.ErrUserNotFound) {
+		log.WarnCtx(ctx, "用户不存在", zap.String("username", loginDTO.Username))
 		// 记录登录失败
 		if _, recordErr := s.redisManager.GetLoginLimiter().RecordLoginFail(ctx, loginDTO.Username); recordErr != nil {
-			log.Error("记录登录失败次数失败", zap.Error(recordErr))
+			log.ErrorCtx(ctx, "记录登录失败次数失败", zap.Error(recordErr))
 		}
 		return nil, ErrInvalidCredentials
 	}
+	if err != nil {
+		// 数据库超时、请求取消等不是凭证错误：不计入失败次数，保留原错误以映射为对应的状态码
+		log.ErrorCtx(ctx, "查询用户失败", zap.Error(err), zap.String("username", loginDTO.Username))
+		return nil, fmt.Errorf("查询用户失败: %w", err)
+	}
 
 	// 4. 验证密码
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(loginDTO.Password)); err != nil {
-		log.Warn("密码错误",
+		log.WarnCtx(ctx, "密码错误",
 			zap.String("username", loginDTO.Username),
 			zap.Error(err))
 		// 记录登录失败
 		if _, recordErr := s.redisManager.GetLoginLimiter().RecordLoginFail(ctx, loginDTO.Username); recordErr != nil {
-			log.Error("记录登录失败次数失败", zap.Error(recordErr))
+			log.ErrorCtx(ctx, "记录登录失败次数失败", zap.Error(recordErr))
 		}
 		return nil, ErrInvalidCredentials
 	}
 
 	// 5. 创建Session
-	token, err := s.redisManager.GetSession().CreateSession(ctx, user.ID)
+	client := &redis.ClientInfo{IP: loginDTO.ClientIP, UserAgent: loginDTO.UserAgent}
+	issued, err := s.redisManager.GetSession().CreateSession(ctx, user.ID, client)
 	if err != nil {
-		log.Error("创建Session失败", zap.Error(err), zap.Uint64("user_id", user.ID))
+		log.ErrorCtx(ctx, "创建Session失败", zap.Error(err), zap.Uint64("user_id", user.ID))
 		return nil, ErrSessionCreateFailed
 	}
 
 	// 6. 清空登录失败次数
 	if err := s.redisManager.GetLoginLimiter().ResetLoginFail(ctx, loginDTO.Username); err != nil {
-		log.Error("重置登录失败次数失败", zap.Error(err))
+		log.ErrorCtx(ctx, "重置登录失败次数失败", zap.Error(err))
 		// 不影响主流程
 	}
 
 	// 7. 转换为DTO并返回
 	userDTO := dto.FromModel(user)
-	log.Info("用户登录成功",
+	log.InfoCtx(ctx, "用户登录成功",
 		zap.String("username", loginDTO.Username),
 		zap.Uint64("user_id", user.ID))
 
 	return &dto.LoginResultDTO{
-		Token:   token,
-		Profile: userDTO.ToProfile(),
+		Token:            issued.Token,
+		ExpiresIn:        int64(issued.ExpiresIn.Seconds()),
+		RefreshToken:     issued.RefreshToken,
+		RefreshExpiresIn: int64(issued.RefreshExpiresIn.Seconds()),
+		Profile:          userDTO.ToProfile(),
+	}, nil
+}
+
+// ============================================================================
+// RefreshToken 换发登录凭证
+// ============================================================================
+
+func (s *userService) RefreshToken(ctx context.Context, refreshDTO *dto.RefreshTokenDTO) (*dto.LoginResultDTO, error) {
+	// 1. 验证DTO
+	if err := refreshDTO.Validate(); err != nil {
+		return nil, err
+	}
+
+	// 2. 换发并轮换刷新凭证
+	issued, err := s.redisManager.GetSession().RenewSession(ctx, refreshDTO.RefreshToken)
+	if err != nil {
+		if errors.Is(err, redis.ErrRefreshTokenInvalid) || errors.Is(err, redis.ErrRenewNotSupported) {
+			log.WarnCtx(ctx, "刷新凭证无效", zap.Error(err))
+			return nil, ErrRefreshTokenInvalid
+		}
+		log.ErrorCtx(ctx, "换发登录凭证失败", zap.Error(err))
+		return nil, fmt.Errorf("换发登录凭证失败: %w", err)
+	}
+
+	// 3. 返回新凭证（不包含用户信息）
+	return &dto.LoginResultDTO{
+		Token:            issued.Token,
+		ExpiresIn:        int64(issued.ExpiresIn.Seconds()),
+		RefreshToken:     issued.RefreshToken,
+		RefreshExpiresIn: int64(issued.RefreshExpiresIn.Seconds()),
 	}, nil
 }
 
@@ -154,11 +313,11 @@ func (s *userService) Logout(ctx context.Context, logoutDTO *dto.LogoutDTO) erro
 
 	// 2. 销毁Session
 	if err := s.redisManager.GetSession().DestroySession(ctx, logoutDTO.Token); err != nil {
-		log.Error("销毁Session失败", zap.Error(err), zap.String("token", logoutDTO.Token))
+		log.ErrorCtx(ctx, "销毁Session失败", zap.Error(err), zap.String("token", logoutDTO.Token))
 		return fmt.Errorf("登出失败: %w", err)
 	}
 
-	log.Info("用户登出成功", zap.String("token", logoutDTO.Token))
+	log.InfoCtx(ctx, "用户登出成功", zap.String("token", logoutDTO.Token))
 	return nil
 }
 
@@ -175,29 +334,55 @@ func (s *userService) GetProfile(ctx context.Context, validateDTO *dto.ValidateT
 	// 2. 验证Token，获取UserID
 	userID, err := s.redisManager.GetSession().ValidateSession(ctx, validateDTO.Token)
 	if err != nil {
-		log.Warn("Token验证失败", zap.Error(err), zap.String("token", validateDTO.Token))
+		log.WarnCtx(ctx, "Token验证失败", zap.Error(err), zap.String("token", validateDTO.Token))
 		return nil, ErrInvalidToken
 	}
 
 	// 3. 从Repository获取用户信息（优先缓存，返回 CachedUser）
 	cachedUser, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil {
-		log.Error("获取用户信息失败", zap.Error(err), zap.Uint64("user_id", userID))
+		log.ErrorCtx(ctx, "获取用户信息失败", zap.Error(err), zap.Uint64("user_id", userID))
 		return nil, fmt.Errorf("获取用户信息失败: %w", err)
 	}
 
 	if cachedUser == nil {
-		log.Warn("用户不存在", zap.Uint64("user_id", userID))
+		log.WarnCtx(ctx, "用户不存在", zap.Uint64("user_id", userID))
 		return nil, ErrUserNotFound
 	}
 
 	// 4. 转换为DTO
 	profileDTO := dto.FromCachedUser(cachedUser)
-	log.Debug("获取用户信息成功", zap.Uint64("user_id", userID))
+	log.DebugCtx(ctx, "获取用户信息成功", zap.Uint64("user_id", userID))
 
 	return profileDTO, nil
 }
 
+// ============================================================================
+// GetPublicProfile 获取指定用户的公开信息
+// ============================================================================
+
+func (s *userService) GetPublicProfile(ctx context.Context, getDTO *dto.GetPublicProfileDTO) (*dto.UserProfileDTO, error) {
+	// 1. 验证DTO
+	if err := getDTO.Validate(); err != nil {
+		return nil, err
+	}
+
+	// 2. 从Repository获取用户信息（优先缓存，不存在的用户有负缓存，避免被遍历ID击穿数据库）
+	cachedUser, err := s.userRepo.GetByID(ctx, getDTO.UserID)
+	if err != nil {
+		log.ErrorCtx(ctx, "获取用户信息失败", zap.Error(err), zap.Uint64("user_id", getDTO.UserID))
+		return nil, fmt.Errorf("获取用户信息失败: %w", err)
+	}
+
+	if cachedUser == nil {
+		log.DebugCtx(ctx, "用户不存在", zap.Uint64("user_id", getDTO.UserID))
+		return nil, ErrUserNotFound
+	}
+
+	// 3. 转换为DTO（由 Handler 只返回公开字段）
+	return dto.FromCachedUser(cachedUser), nil
+}
+
 // ============================================================================
 // UpdateNickname 更新昵称
 // ============================================================================
@@ -205,7 +390,7 @@ func (s *userService) GetProfile(ctx context.Context, validateDTO *dto.ValidateT
 func (s *userService) UpdateNickname(ctx context.Context, updateDTO *dto.UpdateNicknameDTO) (*dto.UserProfileDTO, error) {
 	// 1. 验证DTO
 	if err := updateDTO.Validate(); err != nil {
-		log.Warn("更新昵称参数验证失败",
+		log.WarnCtx(ctx, "更新昵称参数验证失败",
 			zap.Error(err),
 			zap.Uint64("user_id", updateDTO.UserID),
 			zap.String("nickname", updateDTO.Nickname))
@@ -214,7 +399,7 @@ func (s *userService) UpdateNickname(ctx context.Context, updateDTO *dto.UpdateN
 
 	// 2. 调用Repository更新（自动处理缓存）
 	if err := s.userRepo.UpdateNickname(ctx, updateDTO.UserID, updateDTO.Nickname); err != nil {
-		log.Error("更新昵称失败",
+		log.ErrorCtx(ctx, "更新昵称失败",
 			zap.Error(err),
 			zap.Uint64("user_id", updateDTO.UserID),
 			zap.String("nickname", updateDTO.Nickname))
@@ -224,18 +409,18 @@ func (s *userService) UpdateNickname(ctx context.Context, updateDTO *dto.UpdateN
 	// 3. 重新查询用户信息（从缓存或数据库）
 	cachedUser, err := s.userRepo.GetByID(ctx, updateDTO.UserID)
 	if err != nil {
-		log.Error("更新后查询用户信息失败", zap.Error(err), zap.Uint64("user_id", updateDTO.UserID))
+		log.ErrorCtx(ctx, "更新后查询用户信息失败", zap.Error(err), zap.Uint64("user_id", updateDTO.UserID))
 		return nil, fmt.Errorf("更新后查询用户信息失败: %w", err)
 	}
 
 	if cachedUser == nil {
-		log.Warn("更新后用户不存在", zap.Uint64("user_id", updateDTO.UserID))
+		log.WarnCtx(ctx, "更新后用户不存在", zap.Uint64("user_id", updateDTO.UserID))
 		return nil, ErrUserNotFound
 	}
 
 	// 4. 转换为DTO并返回
 	profileDTO := dto.FromCachedUser(cachedUser)
-	log.Info("更新昵称成功",
+	log.InfoCtx(ctx, "更新昵称成功",
 		zap.Uint64("user_id", updateDTO.UserID),
 		zap.String("nickname", updateDTO.Nickname))
 
@@ -249,7 +434,7 @@ func (s *userService) UpdateNickname(ctx context.Context, updateDTO *dto.UpdateN
 func (s *userService) UpdateProfilePicture(ctx context.Context, updateDTO *dto.UpdateProfilePictureDTO) (*dto.UserProfileDTO, error) {
 	// 1. 验证DTO
 	if err := updateDTO.Validate(); err != nil {
-		log.Warn("更新头像参数验证失败",
+		log.WarnCtx(ctx, "更新头像参数验证失败",
 			zap.Error(err),
 			zap.Uint64("user_id", updateDTO.UserID),
 			zap.String("profile_picture", updateDTO.ProfilePicture))
@@ -258,7 +443,7 @@ func (s *userService) UpdateProfilePicture(ctx context.Context, updateDTO *dto.U
 
 	// 2. 调用Repository更新（自动处理缓存）
 	if err := s.userRepo.UpdateProfilePicture(ctx, updateDTO.UserID, updateDTO.ProfilePicture); err != nil {
-		log.Error("更新头像失败",
+		log.ErrorCtx(ctx, "更新头像失败",
 			zap.Error(err),
 			zap.Uint64("user_id", updateDTO.UserID),
 			zap.String("profile_picture", updateDTO.ProfilePicture))
@@ -268,20 +453,203 @@ func (s *userService) UpdateProfilePicture(ctx context.Context, updateDTO *dto.U
 	// 3. 重新查询用户信息（从缓存或数据库）
 	cachedUser, err := s.userRepo.GetByID(ctx, updateDTO.UserID)
 	if err != nil {
-		log.Error("更新后查询用户信息失败", zap.Error(err), zap.Uint64("user_id", updateDTO.UserID))
+		log.ErrorCtx(ctx, "更新后查询用户信息失败", zap.Error(err), zap.Uint64("user_id", updateDTO.UserID))
 		return nil, fmt.Errorf("更新后查询用户信息失败: %w", err)
 	}
 
 	if cachedUser == nil {
-		log.Warn("更新后用户不存在", zap.Uint64("user_id", updateDTO.UserID))
+		log.WarnCtx(ctx, "更新后用户不存在", zap.Uint64("user_id", updateDTO.UserID))
 		return nil, ErrUserNotFound
 	}
 
 	// 4. 转换为DTO并返回
 	profileDTO := dto.FromCachedUser(cachedUser)
-	log.Info("更新头像成功",
+	log.InfoCtx(ctx, "更新头像成功",
 		zap.Uint64("user_id", updateDTO.UserID),
 		zap.String("profile_picture", updateDTO.ProfilePicture))
 
 	return profileDTO, nil
 }
+
+// ============================================================================
+// ClearProfilePicture 清空头像（头像对账使用）
+// ============================================================================
+
+func (s *userService) ClearProfilePicture(ctx context.Context, clearDTO *dto.ClearProfilePictureDTO) (bool, error) {
+	// 1. 验证DTO
+	if err := clearDTO.Validate(); err != nil {
+		return false, err
+	}
+
+	// 2. 比较后清空：对账期间用户上传了新头像时不修改
+	cleared, err := s.userRepo.ClearProfilePicture(ctx, clearDTO.UserID, clearDTO.ProfilePicture)
+	if err != nil {
+		log.ErrorCtx(ctx, "清空头像失败",
+			zap.Error(err),
+			zap.Uint64("user_id", clearDTO.UserID),
+			zap.String("profile_picture", clearDTO.ProfilePicture))
+		return false, fmt.Errorf("清空头像失败: %w", err)
+	}
+
+	return cleared, nil
+}
+
+// ============================================================================
+// ListProfilePictures 分页列出已设置头像的用户（头像对账使用）
+// ============================================================================
+
+func (s *userService) ListProfilePictures(ctx context.Context, listDTO *dto.ListProfilePicturesDTO) ([]*dto.ProfilePictureDTO, error) {
+	pictures, err := s.userRepo.ListProfilePictures(ctx, listDTO.AfterUserID, listDTO.GetLimit())
+	if err != nil {
+		log.ErrorCtx(ctx, "列出头像失败", zap.Error(err), zap.Uint64("after_user_id", listDTO.AfterUserID))
+		return nil, fmt.Errorf("列出头像失败: %w", err)
+	}
+
+	result := make([]*dto.ProfilePictureDTO, 0, len(pictures))
+	for _, p := range pictures {
+		result = append(result, &dto.ProfilePictureDTO{UserID: p.ID, ProfilePicture: p.ProfilePicture})
+	}
+	return result, nil
+}
+
+// ============================================================================
+// ChangePassword 修改密码
+// ============================================================================
+
+func (s *userService) ChangePassword(ctx context.Context, changeDTO *dto.ChangePasswordDTO) (int, error) {
+	// 1. 验证DTO
+	if err := changeDTO.Validate(); err != nil {
+		log.WarnCtx(ctx, "修改密码参数验证失败", zap.Error(err), zap.Uint64("user_id", changeDTO.UserID))
+		return 0, err
+	}
+
+	// 2. 查询用户（直接查数据库，缓存中不包含password_hash）
+	user, err := s.userRepo.GetByIDFromDB(ctx, changeDTO.UserID)
+	if err != nil {
+		log.ErrorCtx(ctx, "查询用户失败", zap.Error(err), zap.Uint64("user_id", changeDTO.UserID))
+		return 0, fmt.Errorf("查询用户失败: %w", err)
+	}
+	if user == nil {
+		log.WarnCtx(ctx, "用户不存在", zap.Uint64("user_id", changeDTO.UserID))
+		return 0, ErrUserNotFound
+	}
+
+	// 3. 验证原密码（与登录一致，使用bcrypt比对）
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(changeDTO.OldPassword)); err != nil {
+		log.WarnCtx(ctx, "原密码错误", zap.Uint64("user_id", changeDTO.UserID))
+		return 0, ErrOldPasswordWrong
+	}
+
+	// 4. 生成新密码哈希
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(changeDTO.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		log.ErrorCtx(ctx, "密码哈希失败", zap.Error(err))
+		return 0, ErrPasswordHashFailed
+	}
+
+	// 5. 更新数据库
+	if err := s.userRepo.UpdatePassword(ctx, changeDTO.UserID, string(passwordHash)); err != nil {
+		log.ErrorCtx(ctx, "更新密码失败", zap.Error(err), zap.Uint64("user_id", changeDTO.UserID))
+		return 0, fmt.Errorf("更新密码失败: %w", err)
+	}
+
+	// 6. 注销该用户的其他Session（保留当前Session）
+	revoked, err := s.redisManager.GetSession().DestroyUserSessions(ctx, changeDTO.UserID, changeDTO.Token)
+	if err != nil {
+		log.ErrorCtx(ctx, "注销其他Session失败", zap.Error(err), zap.Uint64("user_id", changeDTO.UserID))
+		// 降级策略：密码已修改成功，残留Session会在过期后失效
+	}
+
+	log.InfoCtx(ctx, "修改密码成功",
+		zap.Uint64("user_id", changeDTO.UserID),
+		zap.Int("revoked_sessions", revoked))
+
+	return revoked, nil
+}
+
+// ============================================================================
+// ListSessions 列出Session
+// ============================================================================
+
+func (s *userService) ListSessions(ctx context.Context, listDTO *dto.ListSessionsDTO) ([]*dto.SessionDTO, error) {
+	// 1. 验证DTO
+	if err := listDTO.Validate(); err != nil {
+		return nil, err
+	}
+
+	// 2. 查询用户Session
+	infos, err := s.redisManager.GetSession().ListUserSessions(ctx, listDTO.UserID, listDTO.Token)
+	if err != nil {
+		log.ErrorCtx(ctx, "查询用户Session失败", zap.Error(err), zap.Uint64("user_id", listDTO.UserID))
+		return nil, fmt.Errorf("查询用户Session失败: %w", err)
+	}
+
+	// 3. 转换为DTO（不暴露token）
+	sessions := make([]*dto.SessionDTO, 0, len(infos))
+	for _, info := range infos {
+		sessions = append(sessions, dto.FromSessionInfo(info))
+	}
+
+	log.DebugCtx(ctx, "查询用户Session成功",
+		zap.Uint64("user_id", listDTO.UserID),
+		zap.Int("count", len(sessions)))
+	return sessions, nil
+}
+
+// ============================================================================
+// RevokeSession 注销指定Session
+// ============================================================================
+
+func (s *userService) RevokeSession(ctx context.Context, revokeDTO *dto.RevokeSessionDTO) error {
+	// 1. 验证DTO
+	if err := revokeDTO.Validate(); err != nil {
+		return err
+	}
+
+	// 2. 只能注销自己名下的Session
+	err := s.redisManager.GetSession().DestroyUserSession(ctx, revokeDTO.UserID, revokeDTO.SessionID)
+	if err != nil {
+		if errors.Is(err, redis.ErrSessionNotFound) {
+			log.WarnCtx(ctx, "Session不存在",
+				zap.Uint64("user_id", revokeDTO.UserID),
+				zap.String("session_id", revokeDTO.SessionID))
+			return ErrSessionNotFound
+		}
+		log.ErrorCtx(ctx, "注销Session失败", zap.Error(err), zap.Uint64("user_id", revokeDTO.UserID))
+		return fmt.Errorf("注销Session失败: %w", err)
+	}
+
+	log.InfoCtx(ctx, "注销Session成功",
+		zap.Uint64("user_id", revokeDTO.UserID),
+		zap.String("session_id", revokeDTO.SessionID))
+	return nil
+}
+
+// ============================================================================
+// RevokeAllSessions 注销所有Session
+// ============================================================================
+
+func (s *userService) RevokeAllSessions(ctx context.Context, revokeDTO *dto.RevokeAllSessionsDTO) (int, error) {
+	// 1. 验证DTO
+	if err := revokeDTO.Validate(); err != nil {
+		return 0, err
+	}
+
+	// 2. 是否保留当前Session
+	exceptToken := revokeDTO.Token
+	if revokeDTO.IncludeCurrent {
+		exceptToken = ""
+	}
+
+	revoked, err := s.redisManager.GetSession().DestroyUserSessions(ctx, revokeDTO.UserID, exceptToken)
+	if err != nil {
+		log.ErrorCtx(ctx, "注销所有Session失败", zap.Error(err), zap.Uint64("user_id", revokeDTO.UserID))
+		return 0, fmt.Errorf("注销所有Session失败: %w", err)
+	}
+
+	log.InfoCtx(ctx, "注销所有Session成功",
+		zap.Uint64("user_id", revokeDTO.UserID),
+		zap.Bool("include_current", revokeDTO.IncludeCurrent),
+		zap.Int("revoked_sessions", revoked))
+	return revoked, nil
+}
@@ -3,11 +3,14 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
+	"time"
 
+	"entry-task/pkg/logger"
 	"entry-task/tcpserver/internal/dto"
 	"entry-task/tcpserver/internal/model"
-	"entry-task/tcpserver/pkg/logger"
+	"entry-task/tcpserver/internal/repository"
 	"entry-task/tcpserver/pkg/redis"
 
 	"github.com/stretchr/testify/assert"
@@ -59,6 +62,14 @@ func (m *MockUserRepository) GetByID(ctx context.Context, id uint64) (*redis.Cac
 	return args.Get(0).(*redis.CachedUser), args.Error(1)
 }
 
+func (m *MockUserRepository) GetByIDFromDB(ctx context.Context, id uint64) (*model.User, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.User), args.Error(1)
+}
+
 func (m *MockUserRepository) Create(ctx context.Context, user *model.User) error {
 	args := m.Called(ctx, user)
 	return args.Error(0)
@@ -74,6 +85,24 @@ func (m *MockUserRepository) UpdateProfilePicture(ctx context.Context, id uint64
 	return args.Error(0)
 }
 
+func (m *MockUserRepository) ClearProfilePicture(ctx context.Context, id uint64, expected string) (bool, error) {
+	args := m.Called(ctx, id, expected)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockUserRepository) ListProfilePictures(ctx context.Context, afterID uint64, limit int) ([]model.UserProfilePicture, error) {
+	args := m.Called(ctx, afterID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.UserProfilePicture), args.Error(1)
+}
+
+func (m *MockUserRepository) UpdatePassword(ctx context.Context, id uint64, passwordHash string) error {
+	args := m.Called(ctx, id, passwordHash)
+	return args.Error(0)
+}
+
 func (m *MockUserRepository) BatchCreate(ctx context.Context, users []*model.User) error {
 	args := m.Called(ctx, users)
 	return args.Error(0)
@@ -84,9 +113,12 @@ type MockSessionManager struct {
 	mock.Mock
 }
 
-func (m *MockSessionManager) CreateSession(ctx context.Context, userID uint64) (string, error) {
-	args := m.Called(ctx, userID)
-	return args.String(0), args.Error(1)
+func (m *MockSessionManager) CreateSession(ctx context.Context, userID uint64, client *redis.ClientInfo) (*redis.IssuedSession, error) {
+	args := m.Called(ctx, userID, client)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*redis.IssuedSession), args.Error(1)
 }
 
 func (m *MockSessionManager) ValidateSession(ctx context.Context, token string) (uint64, error) {
@@ -99,8 +131,34 @@ func (m *MockSessionManager) DestroySession(ctx context.Context, token string) e
 	return args.Error(0)
 }
 
-func (m *MockSessionManager) RefreshSession(ctx context.Context, token string) error {
+func (m *MockSessionManager) RefreshSession(ctx context.Context, token string) (time.Duration, error) {
 	args := m.Called(ctx, token)
+	return args.Get(0).(time.Duration), args.Error(1)
+}
+
+func (m *MockSessionManager) RenewSession(ctx context.Context, refreshToken string) (*redis.IssuedSession, error) {
+	args := m.Called(ctx, refreshToken)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*redis.IssuedSession), args.Error(1)
+}
+
+func (m *MockSessionManager) DestroyUserSessions(ctx context.Context, userID uint64, exceptToken string) (int, error) {
+	args := m.Called(ctx, userID, exceptToken)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockSessionManager) ListUserSessions(ctx context.Context, userID uint64, currentToken string) ([]*redis.SessionInfo, error) {
+	args := m.Called(ctx, userID, currentToken)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*redis.SessionInfo), args.Error(1)
+}
+
+func (m *MockSessionManager) DestroyUserSession(ctx context.Context, userID uint64, sessionID string) error {
+	args := m.Called(ctx, userID, sessionID)
 	return args.Error(0)
 }
 
@@ -129,6 +187,11 @@ func (m *MockLoginLimiter) ResetLoginFail(ctx context.Context, username string)
 	return args.Error(0)
 }
 
+func (m *MockLoginLimiter) GetLockoutTTL(ctx context.Context, username string) (time.Duration, error) {
+	args := m.Called(ctx, username)
+	return args.Get(0).(time.Duration), args.Error(1)
+}
+
 // MockUserCache 模拟 UserCache
 type MockUserCache struct {
 	mock.Mock
@@ -193,6 +256,23 @@ func (m *MockRedisManager) GetUserCache() redis.UserCache {
 	return m.userCache
 }
 
+// GetPrimarySticky Service 层不直接使用主库标记（由 Repository 使用）
+func (m *MockRedisManager) GetPrimarySticky() redis.PrimarySticky {
+	return nil
+}
+
+func (m *MockRedisManager) Close() {}
+
+// MockIDGenerator 模拟 IDGenerator
+type MockIDGenerator struct {
+	mock.Mock
+}
+
+func (m *MockIDGenerator) NextID() (int64, error) {
+	args := m.Called()
+	return args.Get(0).(int64), args.Error(1)
+}
+
 // ============================================================================
 // 测试辅助函数
 // ============================================================================
@@ -204,6 +284,7 @@ func setupTestService() (*userService, *MockUserRepository, *MockRedisManager) {
 	service := &userService{
 		userRepo:     mockRepo,
 		redisManager: mockRedis,
+		idGenerator:  new(MockIDGenerator),
 	}
 
 	return service, mockRepo, mockRedis
@@ -215,6 +296,137 @@ func hashPassword(password string) string {
 	return string(hash)
 }
 
+// ============================================================================
+// Register 测试
+// ============================================================================
+
+func TestRegister_Success(t *testing.T) {
+	service, mockRepo, _ := setupTestService()
+	mockIDGen := service.idGenerator.(*MockIDGenerator)
+	ctx := context.Background()
+
+	registerDTO := &dto.RegisterDTO{
+		Username: "newuser",
+		Password: "Test@123",
+		Nickname: "新用户",
+	}
+
+	// 设置 Mock 期望
+	mockRepo.On("GetByUsername", ctx, "newuser").Return(nil, repository.ErrUserNotFound)
+	mockIDGen.On("NextID").Return(int64(987654), nil)
+	mockRepo.On("Create", ctx, mock.MatchedBy(func(u *model.User) bool {
+		return u.ID == 987654 &&
+			u.Username == "newuser" &&
+			u.Nickname == "新用户" &&
+			bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte("Test@123")) == nil
+	})).Return(nil)
+
+	// 执行测试
+	profile, err := service.Register(ctx, registerDTO)
+
+	// 断言
+	assert.NoError(t, err)
+	assert.NotNil(t, profile)
+	assert.Equal(t, uint64(987654), profile.ID)
+	assert.Equal(t, "newuser", profile.Username)
+	assert.Equal(t, "新用户", profile.Nickname)
+
+	mockRepo.AssertExpectations(t)
+	mockIDGen.AssertExpectations(t)
+}
+
+func TestRegister_DefaultNickname(t *testing.T) {
+	service, mockRepo, _ := setupTestService()
+	mockIDGen := service.idGenerator.(*MockIDGenerator)
+	ctx := context.Background()
+
+	registerDTO := &dto.RegisterDTO{
+		Username: "newuser",
+		Password: "Test@123",
+	}
+
+	// 设置 Mock 期望
+	mockRepo.On("GetByUsername", ctx, "newuser").Return(nil, repository.ErrUserNotFound)
+	mockIDGen.On("NextID").Return(int64(987654), nil)
+	mockRepo.On("Create", ctx, mock.AnythingOfType("*model.User")).Return(nil)
+
+	// 执行测试
+	profile, err := service.Register(ctx, registerDTO)
+
+	// 断言
+	assert.NoError(t, err)
+	assert.Equal(t, "newuser", profile.Nickname)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRegister_UsernameExists(t *testing.T) {
+	service, mockRepo, _ := setupTestService()
+	ctx := context.Background()
+
+	registerDTO := &dto.RegisterDTO{
+		Username: "testuser",
+		Password: "Test@123",
+	}
+
+	// 设置 Mock 期望 - 用户名已存在
+	mockRepo.On("GetByUsername", ctx, "testuser").Return(&model.User{ID: 123456, Username: "testuser"}, nil)
+
+	// 执行测试
+	profile, err := service.Register(ctx, registerDTO)
+
+	// 断言
+	assert.Error(t, err)
+	assert.Nil(t, profile)
+	assert.Equal(t, ErrUsernameExists, err)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRegister_DuplicateOnCreate(t *testing.T) {
+	service, mockRepo, _ := setupTestService()
+	mockIDGen := service.idGenerator.(*MockIDGenerator)
+	ctx := context.Background()
+
+	registerDTO := &dto.RegisterDTO{
+		Username: "newuser",
+		Password: "Test@123",
+	}
+
+	// 设置 Mock 期望 - 并发注册，插入时触发唯一索引冲突
+	mockRepo.On("GetByUsername", ctx, "newuser").Return(nil, repository.ErrUserNotFound)
+	mockIDGen.On("NextID").Return(int64(987654), nil)
+	mockRepo.On("Create", ctx, mock.AnythingOfType("*model.User")).Return(repository.ErrUsernameDuplicate)
+
+	// 执行测试
+	profile, err := service.Register(ctx, registerDTO)
+
+	// 断言
+	assert.Error(t, err)
+	assert.Nil(t, profile)
+	assert.Equal(t, ErrUsernameExists, err)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRegister_InvalidDTO_ShortPassword(t *testing.T) {
+	service, _, _ := setupTestService()
+	ctx := context.Background()
+
+	registerDTO := &dto.RegisterDTO{
+		Username: "newuser",
+		Password: "123",
+	}
+
+	// 执行测试
+	profile, err := service.Register(ctx, registerDTO)
+
+	// 断言
+	assert.Error(t, err)
+	assert.Nil(t, profile)
+	assert.Equal(t, dto.ErrPasswordTooShort, err)
+}
+
 // ============================================================================
 // Login 测试
 // ============================================================================
@@ -245,7 +457,7 @@ func TestLogin_Success(t *testing.T) {
 	// 设置 Mock 期望
 	mockRedis.loginLimiter.On("GetLoginFailCount", ctx, username).Return(int64(0), nil)
 	mockRepo.On("GetByUsername", ctx, username).Return(mockUser, nil)
-	mockRedis.session.On("CreateSession", ctx, userID).Return(token, nil)
+	mockRedis.session.On("CreateSession", ctx, userID, mock.Anything).Return(&redis.IssuedSession{Token: token, ExpiresIn: 2 * time.Hour}, nil)
 	mockRedis.loginLimiter.On("ResetLoginFail", ctx, username).Return(nil)
 
 	// 执行测试
@@ -255,6 +467,7 @@ func TestLogin_Success(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
 	assert.Equal(t, token, result.Token)
+	assert.Equal(t, int64(7200), result.ExpiresIn)
 	assert.Equal(t, username, result.Profile.Username)
 	assert.Equal(t, "测试用户", result.Profile.Nickname)
 
@@ -278,7 +491,7 @@ func TestLogin_InvalidCredentials(t *testing.T) {
 
 	// 设置 Mock 期望 - 用户不存在
 	mockRedis.loginLimiter.On("GetLoginFailCount", ctx, username).Return(int64(0), nil)
-	mockRepo.On("GetByUsername", ctx, username).Return(nil, errors.New("user not found"))
+	mockRepo.On("GetByUsername", ctx, username).Return(nil, repository.ErrUserNotFound)
 	mockRedis.loginLimiter.On("RecordLoginFail", ctx, username).Return(int64(1), nil)
 
 	// 执行测试
@@ -293,6 +506,32 @@ func TestLogin_InvalidCredentials(t *testing.T) {
 	mockRedis.loginLimiter.AssertExpectations(t)
 }
 
+func TestLogin_RepositoryError(t *testing.T) {
+	service, mockRepo, mockRedis := setupTestService()
+	ctx := context.Background()
+
+	username := "testuser"
+	loginDTO := &dto.LoginDTO{
+		Username: username,
+		Password: "Test@123",
+	}
+
+	// 设置 Mock 期望 - 数据库查询超时
+	mockRedis.loginLimiter.On("GetLoginFailCount", ctx, username).Return(int64(0), nil)
+	mockRepo.On("GetByUsername", ctx, username).Return(nil, fmt.Errorf("failed to get user by username: %w", context.DeadlineExceeded))
+
+	// 执行测试
+	result, err := service.Login(ctx, loginDTO)
+
+	// 断言：保留原错误，不当作凭证错误，也不计入失败次数
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.NotErrorIs(t, err, ErrInvalidCredentials)
+	mockRedis.loginLimiter.AssertNotCalled(t, "RecordLoginFail", mock.Anything, mock.Anything)
+
+	mockRepo.AssertExpectations(t)
+}
+
 func TestLogin_WrongPassword(t *testing.T) {
 	service, mockRepo, mockRedis := setupTestService()
 	ctx := context.Background()
@@ -342,6 +581,7 @@ func TestLogin_ExceedMaxAttempts(t *testing.T) {
 
 	// 设置 Mock 期望 - 登录失败次数已达上限
 	mockRedis.loginLimiter.On("GetLoginFailCount", ctx, username).Return(int64(5), nil)
+	mockRedis.loginLimiter.On("GetLockoutTTL", ctx, username).Return(10*time.Minute, nil)
 
 	// 执行测试
 	result, err := service.Login(ctx, loginDTO)
@@ -349,7 +589,11 @@ func TestLogin_ExceedMaxAttempts(t *testing.T) {
 	// 断言
 	assert.Error(t, err)
 	assert.Nil(t, result)
-	assert.Equal(t, ErrLoginLimitExceeded, err)
+	assert.ErrorIs(t, err, ErrLoginLimitExceeded)
+	var limitErr *LoginLimitError
+	if assert.ErrorAs(t, err, &limitErr) {
+		assert.Equal(t, 10*time.Minute, limitErr.RetryAfter)
+	}
 
 	mockRedis.loginLimiter.AssertExpectations(t)
 }
@@ -377,7 +621,7 @@ func TestLogin_SessionCreateFailed(t *testing.T) {
 	// 设置 Mock 期望
 	mockRedis.loginLimiter.On("GetLoginFailCount", ctx, username).Return(int64(0), nil)
 	mockRepo.On("GetByUsername", ctx, username).Return(mockUser, nil)
-	mockRedis.session.On("CreateSession", ctx, userID).Return("", errors.New("redis error"))
+	mockRedis.session.On("CreateSession", ctx, userID, mock.Anything).Return(nil, errors.New("redis error"))
 
 	// 执行测试
 	result, err := service.Login(ctx, loginDTO)
@@ -525,6 +769,58 @@ func TestGetProfile_UserNotFound(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+// ============================================================================
+// GetPublicProfile 测试
+// ============================================================================
+
+func TestGetPublicProfile_Success(t *testing.T) {
+	service, mockRepo, mockRedis := setupTestService()
+	ctx := context.Background()
+
+	userID := uint64(123456)
+	cachedUser := &redis.CachedUser{
+		ID:             userID,
+		Username:       "testuser",
+		Nickname:       "测试用户",
+		ProfilePicture: "avatars/123456/a.jpg",
+	}
+
+	// 不需要验证Token
+	mockRepo.On("GetByID", ctx, userID).Return(cachedUser, nil)
+
+	profile, err := service.GetPublicProfile(ctx, &dto.GetPublicProfileDTO{UserID: userID})
+
+	assert.NoError(t, err)
+	assert.Equal(t, userID, profile.ID)
+	assert.Equal(t, "avatars/123456/a.jpg", profile.ProfilePicture)
+
+	mockRepo.AssertExpectations(t)
+	mockRedis.session.AssertNotCalled(t, "ValidateSession", mock.Anything, mock.Anything)
+}
+
+func TestGetPublicProfile_UserNotFound(t *testing.T) {
+	service, mockRepo, _ := setupTestService()
+	ctx := context.Background()
+
+	mockRepo.On("GetByID", ctx, uint64(404)).Return(nil, nil)
+
+	profile, err := service.GetPublicProfile(ctx, &dto.GetPublicProfileDTO{UserID: 404})
+
+	assert.Equal(t, ErrUserNotFound, err)
+	assert.Nil(t, profile)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetPublicProfile_InvalidDTO_ZeroUserID(t *testing.T) {
+	service, mockRepo, _ := setupTestService()
+
+	profile, err := service.GetPublicProfile(context.Background(), &dto.GetPublicProfileDTO{})
+
+	assert.ErrorIs(t, err, dto.ErrUserIDInvalid)
+	assert.Nil(t, profile)
+	mockRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+}
+
 // ============================================================================
 // UpdateNickname 测试
 // ============================================================================
@@ -703,6 +999,306 @@ func TestUpdateProfilePicture_UserNotFoundAfterUpdate(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+// ============================================================================
+// ChangePassword 测试
+// ============================================================================
+
+func TestChangePassword_Success(t *testing.T) {
+	service, mockRepo, mockRedis := setupTestService()
+	ctx := context.Background()
+
+	userID := uint64(123456)
+	token := "current-token"
+	oldPassword := "Test@123"
+	newPassword := "New@12345"
+
+	changeDTO := &dto.ChangePasswordDTO{
+		UserID:      userID,
+		Token:       token,
+		OldPassword: oldPassword,
+		NewPassword: newPassword,
+	}
+
+	mockUser := &model.User{
+		ID:           userID,
+		Username:     "testuser",
+		PasswordHash: hashPassword(oldPassword),
+	}
+
+	// 设置 Mock 期望
+	mockRepo.On("GetByIDFromDB", ctx, userID).Return(mockUser, nil)
+	mockRepo.On("UpdatePassword", ctx, userID, mock.MatchedBy(func(hash string) bool {
+		return bcrypt.CompareHashAndPassword([]byte(hash), []byte(newPassword)) == nil
+	})).Return(nil)
+	mockRedis.session.On("DestroyUserSessions", ctx, userID, token).Return(2, nil)
+
+	// 执行测试
+	revoked, err := service.ChangePassword(ctx, changeDTO)
+
+	// 断言
+	assert.NoError(t, err)
+	assert.Equal(t, 2, revoked)
+
+	mockRepo.AssertExpectations(t)
+	mockRedis.session.AssertExpectations(t)
+}
+
+func TestChangePassword_WrongOldPassword(t *testing.T) {
+	service, mockRepo, mockRedis := setupTestService()
+	ctx := context.Background()
+
+	userID := uint64(123456)
+	changeDTO := &dto.ChangePasswordDTO{
+		UserID:      userID,
+		Token:       "current-token",
+		OldPassword: "WrongPass",
+		NewPassword: "New@12345",
+	}
+
+	mockUser := &model.User{
+		ID:           userID,
+		PasswordHash: hashPassword("Test@123"),
+	}
+
+	// 设置 Mock 期望
+	mockRepo.On("GetByIDFromDB", ctx, userID).Return(mockUser, nil)
+
+	// 执行测试
+	revoked, err := service.ChangePassword(ctx, changeDTO)
+
+	// 断言
+	assert.Equal(t, ErrOldPasswordWrong, err)
+	assert.Equal(t, 0, revoked)
+
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything, mock.Anything)
+	mockRedis.session.AssertNotCalled(t, "DestroyUserSessions", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestChangePassword_RevokeFailedStillSucceeds(t *testing.T) {
+	service, mockRepo, mockRedis := setupTestService()
+	ctx := context.Background()
+
+	userID := uint64(123456)
+	token := "current-token"
+	changeDTO := &dto.ChangePasswordDTO{
+		UserID:      userID,
+		Token:       token,
+		OldPassword: "Test@123",
+		NewPassword: "New@12345",
+	}
+
+	mockUser := &model.User{
+		ID:           userID,
+		PasswordHash: hashPassword("Test@123"),
+	}
+
+	// 设置 Mock 期望 - 注销其他Session失败（降级）
+	mockRepo.On("GetByIDFromDB", ctx, userID).Return(mockUser, nil)
+	mockRepo.On("UpdatePassword", ctx, userID, mock.AnythingOfType("string")).Return(nil)
+	mockRedis.session.On("DestroyUserSessions", ctx, userID, token).Return(0, errors.New("redis error"))
+
+	// 执行测试
+	_, err := service.ChangePassword(ctx, changeDTO)
+
+	// 断言
+	assert.NoError(t, err)
+
+	mockRepo.AssertExpectations(t)
+	mockRedis.session.AssertExpectations(t)
+}
+
+func TestChangePassword_InvalidDTO_SamePassword(t *testing.T) {
+	service, _, _ := setupTestService()
+	ctx := context.Background()
+
+	changeDTO := &dto.ChangePasswordDTO{
+		UserID:      123456,
+		Token:       "current-token",
+		OldPassword: "Test@123",
+		NewPassword: "Test@123",
+	}
+
+	// 执行测试
+	_, err := service.ChangePassword(ctx, changeDTO)
+
+	// 断言
+	assert.Equal(t, dto.ErrPasswordUnchanged, err)
+}
+
+// ============================================================================
+// Session 管理测试
+// ============================================================================
+
+func TestRefreshToken_Success(t *testing.T) {
+	service, _, mockRedis := setupTestService()
+	ctx := context.Background()
+
+	issued := &redis.IssuedSession{
+		Token:            "new-access-token",
+		RefreshToken:     "sid.new-secret",
+		ExpiresIn:        15 * time.Minute,
+		RefreshExpiresIn: 2 * time.Hour,
+	}
+
+	// 设置 Mock 期望
+	mockRedis.session.On("RenewSession", ctx, "sid.old-secret").Return(issued, nil)
+
+	// 执行测试
+	result, err := service.RefreshToken(ctx, &dto.RefreshTokenDTO{RefreshToken: "sid.old-secret"})
+
+	// 断言
+	assert.NoError(t, err)
+	assert.Equal(t, "new-access-token", result.Token)
+	assert.Equal(t, "sid.new-secret", result.RefreshToken)
+	assert.Equal(t, int64(900), result.ExpiresIn)
+	assert.Equal(t, int64(7200), result.RefreshExpiresIn)
+
+	mockRedis.session.AssertExpectations(t)
+}
+
+func TestRefreshToken_Invalid(t *testing.T) {
+	service, _, mockRedis := setupTestService()
+	ctx := context.Background()
+
+	// 设置 Mock 期望 - 刷新凭证已被轮换（重复使用）
+	mockRedis.session.On("RenewSession", ctx, "sid.reused").Return(nil, redis.ErrRefreshTokenInvalid)
+
+	// 执行测试
+	result, err := service.RefreshToken(ctx, &dto.RefreshTokenDTO{RefreshToken: "sid.reused"})
+
+	// 断言
+	assert.Nil(t, result)
+	assert.Equal(t, ErrRefreshTokenInvalid, err)
+
+	mockRedis.session.AssertExpectations(t)
+}
+
+func TestRefreshToken_NotSupportedInRedisMode(t *testing.T) {
+	service, _, mockRedis := setupTestService()
+	ctx := context.Background()
+
+	// 设置 Mock 期望
+	mockRedis.session.On("RenewSession", ctx, "whatever").Return(nil, redis.ErrRenewNotSupported)
+
+	// 执行测试
+	_, err := service.RefreshToken(ctx, &dto.RefreshTokenDTO{RefreshToken: "whatever"})
+
+	// 断言
+	assert.Equal(t, ErrRefreshTokenInvalid, err)
+}
+
+func TestListSessions_Success(t *testing.T) {
+	service, _, mockRedis := setupTestService()
+	ctx := context.Background()
+
+	userID := uint64(123456)
+	token := "current-token"
+
+	infos := []*redis.SessionInfo{
+		{ID: redis.SessionID(token), CreatedAt: 100, LastSeenAt: 200, ClientIP: "10.0.0.1", Current: true},
+		{ID: redis.SessionID("other-token"), CreatedAt: 50, LastSeenAt: 60},
+	}
+
+	// 设置 Mock 期望 - 由 SessionManager 根据当前token标记 Current
+	mockRedis.session.On("ListUserSessions", ctx, userID, token).Return(infos, nil)
+
+	// 执行测试
+	sessions, err := service.ListSessions(ctx, &dto.ListSessionsDTO{UserID: userID, Token: token})
+
+	// 断言
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 2)
+	assert.True(t, sessions[0].Current)
+	assert.Equal(t, "10.0.0.1", sessions[0].ClientIP)
+	assert.False(t, sessions[1].Current)
+
+	mockRedis.session.AssertExpectations(t)
+}
+
+func TestRevokeSession_Success(t *testing.T) {
+	service, _, mockRedis := setupTestService()
+	ctx := context.Background()
+
+	userID := uint64(123456)
+	sessionID := redis.SessionID("other-token")
+
+	// 设置 Mock 期望
+	mockRedis.session.On("DestroyUserSession", ctx, userID, sessionID).Return(nil)
+
+	// 执行测试
+	err := service.RevokeSession(ctx, &dto.RevokeSessionDTO{UserID: userID, SessionID: sessionID})
+
+	// 断言
+	assert.NoError(t, err)
+
+	mockRedis.session.AssertExpectations(t)
+}
+
+func TestRevokeSession_NotFound(t *testing.T) {
+	service, _, mockRedis := setupTestService()
+	ctx := context.Background()
+
+	userID := uint64(123456)
+
+	// 设置 Mock 期望 - Session 不属于该用户或已过期
+	mockRedis.session.On("DestroyUserSession", ctx, userID, "deadbeefdeadbeef").Return(redis.ErrSessionNotFound)
+
+	// 执行测试
+	err := service.RevokeSession(ctx, &dto.RevokeSessionDTO{UserID: userID, SessionID: "deadbeefdeadbeef"})
+
+	// 断言
+	assert.Equal(t, ErrSessionNotFound, err)
+
+	mockRedis.session.AssertExpectations(t)
+}
+
+func TestRevokeAllSessions_IncludeCurrent(t *testing.T) {
+	service, _, mockRedis := setupTestService()
+	ctx := context.Background()
+
+	userID := uint64(123456)
+
+	// 设置 Mock 期望 - include_current 时不保留任何 Session
+	mockRedis.session.On("DestroyUserSessions", ctx, userID, "").Return(3, nil)
+
+	// 执行测试
+	revoked, err := service.RevokeAllSessions(ctx, &dto.RevokeAllSessionsDTO{
+		UserID:         userID,
+		Token:          "current-token",
+		IncludeCurrent: true,
+	})
+
+	// 断言
+	assert.NoError(t, err)
+	assert.Equal(t, 3, revoked)
+
+	mockRedis.session.AssertExpectations(t)
+}
+
+func TestRevokeAllSessions_KeepCurrent(t *testing.T) {
+	service, _, mockRedis := setupTestService()
+	ctx := context.Background()
+
+	userID := uint64(123456)
+	token := "current-token"
+
+	// 设置 Mock 期望
+	mockRedis.session.On("DestroyUserSessions", ctx, userID, token).Return(2, nil)
+
+	// 执行测试
+	revoked, err := service.RevokeAllSessions(ctx, &dto.RevokeAllSessionsDTO{
+		UserID: userID,
+		Token:  token,
+	})
+
+	// 断言
+	assert.NoError(t, err)
+	assert.Equal(t, 2, revoked)
+
+	mockRedis.session.AssertExpectations(t)
+}
+
 // ============================================================================
 // DTO 验证测试
 // ============================================================================
@@ -772,3 +1368,46 @@ func TestUpdateNickname_InvalidDTO_EmptyNickname(t *testing.T) {
 	assert.Error(t, err)
 	assert.Nil(t, profile)
 }
+
+func TestClearProfilePicture_Success(t *testing.T) {
+	service, mockRepo, _ := setupTestService()
+	ctx := context.Background()
+
+	mockRepo.On("ClearProfilePicture", ctx, uint64(123456), "avatars/123456/a.jpg").Return(true, nil)
+
+	cleared, err := service.ClearProfilePicture(ctx, &dto.ClearProfilePictureDTO{
+		UserID:         123456,
+		ProfilePicture: "avatars/123456/a.jpg",
+	})
+
+	assert.NoError(t, err)
+	assert.True(t, cleared)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestClearProfilePicture_InvalidDTO_EmptyPicture(t *testing.T) {
+	service, mockRepo, _ := setupTestService()
+
+	cleared, err := service.ClearProfilePicture(context.Background(), &dto.ClearProfilePictureDTO{UserID: 123456})
+
+	assert.ErrorIs(t, err, dto.ErrPictureURLEmpty)
+	assert.False(t, cleared)
+	mockRepo.AssertNotCalled(t, "ClearProfilePicture", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestListProfilePictures_Limit(t *testing.T) {
+	service, mockRepo, _ := setupTestService()
+	ctx := context.Background()
+
+	pictures := []model.UserProfilePicture{{ID: 2, ProfilePicture: "avatars/2/b.jpg"}}
+	mockRepo.On("ListProfilePictures", ctx, uint64(1), dto.DefaultProfilePicturePageSize).Return(pictures, nil)
+	mockRepo.On("ListProfilePictures", ctx, uint64(1), dto.MaxProfilePicturePageSize).Return(pictures, nil)
+
+	result, err := service.ListProfilePictures(ctx, &dto.ListProfilePicturesDTO{AfterUserID: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, []*dto.ProfilePictureDTO{{UserID: 2, ProfilePicture: "avatars/2/b.jpg"}}, result)
+
+	_, err = service.ListProfilePictures(ctx, &dto.ListProfilePicturesDTO{AfterUserID: 1, Limit: 100000})
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
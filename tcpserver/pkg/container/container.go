@@ -30,9 +30,14 @@ func Init() error {
 
 // registerProviders 注册所有提供者
 func registerProviders() error {
-	// 注册数据库连接（sqlx）
-	if err := Container.Provide(func(cfg *config.Config) (*sqlx.DB, error) {
-		return db.InitDB(cfg)
+	// 注册数据库主从集群（读写分离）
+	if err := Container.Provide(db.InitCluster); err != nil {
+		return err
+	}
+
+	// 注册主库连接（sqlx），供迁移、缓存失效 Worker 等只使用主库的组件
+	if err := Container.Provide(func(cluster *db.Cluster) *sqlx.DB {
+		return cluster.Primary()
 	}); err != nil {
 		return err
 	}
@@ -44,6 +49,13 @@ func registerProviders() error {
 		return err
 	}
 
+	// 注册用户ID生成器（雪花算法，机器ID来自配置）
+	if err := Container.Provide(func(cfg *config.Config) (service.IDGenerator, error) {
+		return db.NewSnowflake(cfg.Snowflake.MachineID)
+	}); err != nil {
+		return err
+	}
+
 	// 注册Redis管理器
 	if err := Container.Provide(redis.NewManager); err != nil {
 		return err
@@ -54,6 +66,11 @@ func registerProviders() error {
 		return err
 	}
 
+	// 注册缓存失效 Worker
+	if err := Container.Provide(repository.NewCacheInvalidationWorker); err != nil {
+		return err
+	}
+
 	// 注册 UserService
 	if err := Container.Provide(service.NewUserService); err != nil {
 		return err
@@ -64,6 +81,16 @@ func registerProviders() error {
 		return err
 	}
 
+	// 注册 UserServiceV2Handler (gRPC Handler v2，返回带错误详情的 gRPC 状态)
+	if err := Container.Provide(rpchandler.NewUserServiceV2Handler); err != nil {
+		return err
+	}
+
+	// 注册 UserAdminServiceHandler (内部管理服务，供头像对账使用)
+	if err := Container.Provide(rpchandler.NewUserAdminServiceHandler); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -0,0 +1,207 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+
+	log "entry-task/pkg/logger"
+	"entry-task/tcpserver/config"
+)
+
+// ============================================================================
+// 读写分离
+// ============================================================================
+//
+// 写操作和事务始终使用主库；读操作轮询选择健康的从库，没有健康从库时回退到主库。
+// 后台定期 Ping 从库，失败的从库暂时摘除，恢复后重新加入。
+// 从库存在复制延迟，需要读到最新数据的场景（读己之写）由调用方选择主库。
+
+const (
+	// DefaultReplicaCheckInterval 默认从库健康检查间隔
+	DefaultReplicaCheckInterval = 5 * time.Second
+
+	// replicaPingTimeout 单次健康检查超时时间
+	replicaPingTimeout = time.Second
+)
+
+// replica 从库连接及健康状态
+type replica struct {
+	db      *sqlx.DB
+	addr    string
+	healthy atomic.Bool
+}
+
+// Cluster 主从数据库连接
+type Cluster struct {
+	primary  *sqlx.DB
+	replicas []*replica
+	next     atomic.Uint64 // 轮询计数
+
+	stopCheck context.CancelFunc // 停止健康检查（未启动时为 nil）
+	checkDone chan struct{}
+	closeOnce sync.Once
+}
+
+// NewCluster 使用已建立的连接创建主从集群（从库初始为健康状态，不启动健康检查）
+func NewCluster(primary *sqlx.DB, replicas ...*sqlx.DB) *Cluster {
+	c := &Cluster{primary: primary}
+	for i, db := range replicas {
+		r := &replica{db: db, addr: "replica-" + strconv.Itoa(i)}
+		r.healthy.Store(true)
+		c.replicas = append(c.replicas, r)
+	}
+	return c
+}
+
+// InitCluster 按配置连接主库和所有从库，并启动从库健康检查
+// 主库连接失败时返回错误；从库连接失败只记录日志，由健康检查在其恢复后加入
+func InitCluster(cfg *config.Config) (*Cluster, error) {
+	primary, err := InitDB(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Cluster{primary: primary}
+	for _, rc := range cfg.Database.Replicas {
+		replicaCfg := cfg.Database.ForReplica(rc)
+		db, err := openDB(&replicaCfg)
+		if err != nil {
+			_ = c.Close()
+			return nil, err
+		}
+		c.replicas = append(c.replicas, &replica{
+			db:   db,
+			addr: net.JoinHostPort(rc.Host, strconv.Itoa(rc.Port)),
+		})
+	}
+
+	if len(c.replicas) == 0 {
+		return c, nil
+	}
+
+	c.checkReplicas(context.Background())
+	log.Info("数据库从库初始化完成",
+		zap.Int("replicas", len(c.replicas)),
+		zap.Int("healthy", c.healthyReplicas()),
+	)
+
+	interval := cfg.Database.GetReplicaCheckInterval()
+	if interval <= 0 {
+		interval = DefaultReplicaCheckInterval
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c.stopCheck = cancel
+	c.checkDone = make(chan struct{})
+	go c.healthCheckLoop(ctx, interval)
+
+	return c, nil
+}
+
+// Primary 获取主库连接（写操作、事务、需要强一致的读）
+func (c *Cluster) Primary() *sqlx.DB {
+	return c.primary
+}
+
+// Replica 轮询获取健康的从库连接，没有健康从库时返回主库
+func (c *Cluster) Replica() *sqlx.DB {
+	n := len(c.replicas)
+	if n == 0 {
+		return c.primary
+	}
+
+	start := c.next.Add(1)
+	for i := 0; i < n; i++ {
+		r := c.replicas[(start+uint64(i))%uint64(n)]
+		if r.healthy.Load() {
+			return r.db
+		}
+	}
+	return c.primary
+}
+
+// Pools 所有连接池，键为 primary 或从库地址（用于导出连接池指标）
+func (c *Cluster) Pools() map[string]*sql.DB {
+	pools := make(map[string]*sql.DB, len(c.replicas)+1)
+	pools["primary"] = c.primary.DB
+	for _, r := range c.replicas {
+		pools[r.addr] = r.db.DB
+	}
+	return pools
+}
+
+// HasReplicas 是否配置了从库
+func (c *Cluster) HasReplicas() bool {
+	return len(c.replicas) > 0
+}
+
+// Close 停止健康检查并关闭所有连接
+func (c *Cluster) Close() error {
+	var errs []error
+	c.closeOnce.Do(func() {
+		if c.stopCheck != nil {
+			c.stopCheck()
+			<-c.checkDone
+		}
+		for _, r := range c.replicas {
+			errs = append(errs, r.db.Close())
+		}
+		errs = append(errs, c.primary.Close())
+	})
+	return errors.Join(errs...)
+}
+
+// healthCheckLoop 定期检查从库健康状态，直到 ctx 被取消
+func (c *Cluster) healthCheckLoop(ctx context.Context, interval time.Duration) {
+	defer close(c.checkDone)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.checkReplicas(ctx)
+		}
+	}
+}
+
+// checkReplicas Ping 所有从库并更新健康状态（状态变化时记录日志）
+func (c *Cluster) checkReplicas(ctx context.Context) {
+	for _, r := range c.replicas {
+		pingCtx, cancel := context.WithTimeout(ctx, replicaPingTimeout)
+		err := r.db.PingContext(pingCtx)
+		cancel()
+
+		healthy := err == nil
+		if r.healthy.Swap(healthy) == healthy {
+			continue
+		}
+		if healthy {
+			log.Info("数据库从库已恢复", zap.String("addr", r.addr))
+		} else {
+			log.Warn("数据库从库不可用，暂时摘除", zap.String("addr", r.addr), zap.Error(err))
+		}
+	}
+}
+
+// healthyReplicas 健康从库数量
+func (c *Cluster) healthyReplicas() int {
+	n := 0
+	for _, r := range c.replicas {
+		if r.healthy.Load() {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,90 @@
+package db
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+
+	"entry-task/pkg/logger"
+)
+
+// TestMain 在所有测试运行前初始化日志
+func TestMain(m *testing.M) {
+	if err := logger.Init(&logger.Config{Level: "fatal", Output: "stdout"}); err != nil {
+		panic("初始化日志失败: " + err.Error())
+	}
+	os.Exit(m.Run())
+}
+
+// openUnreachable 创建指向不可达地址的连接池（sqlx.Open 不会建立连接）
+func openUnreachable(t *testing.T) *sqlx.DB {
+	db, err := sqlx.Open("mysql", "root:root@tcp(127.0.0.1:1)/test?timeout=200ms")
+	if err != nil {
+		t.Fatalf("创建连接池失败: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	return db
+}
+
+// TestCluster_Replica 测试从库轮询以及不健康从库的摘除和回退
+func TestCluster_Replica(t *testing.T) {
+	primary, r1, r2 := openUnreachable(t), openUnreachable(t), openUnreachable(t)
+	c := NewCluster(primary, r1, r2)
+
+	if !c.HasReplicas() {
+		t.Fatal("应包含从库")
+	}
+	if c.Primary() != primary {
+		t.Error("Primary 应返回主库")
+	}
+
+	// 两个从库都健康：轮询
+	first, second := c.Replica(), c.Replica()
+	if first == second || (first != r1 && first != r2) || (second != r1 && second != r2) {
+		t.Error("健康从库应被轮询使用")
+	}
+
+	// 一个从库不健康：只使用另一个
+	c.replicas[0].healthy.Store(false)
+	for i := 0; i < 4; i++ {
+		if got := c.Replica(); got != r2 {
+			t.Fatal("不健康的从库不应被使用")
+		}
+	}
+
+	// 全部不健康：回退主库
+	c.replicas[1].healthy.Store(false)
+	if c.Replica() != primary {
+		t.Error("没有健康从库时应回退主库")
+	}
+}
+
+// TestCluster_NoReplicas 测试未配置从库时读写都走主库
+func TestCluster_NoReplicas(t *testing.T) {
+	primary := openUnreachable(t)
+	c := NewCluster(primary)
+
+	if c.HasReplicas() {
+		t.Error("不应包含从库")
+	}
+	if c.Replica() != primary {
+		t.Error("未配置从库时应返回主库")
+	}
+}
+
+// TestCluster_CheckReplicas 测试健康检查摘除不可达的从库
+func TestCluster_CheckReplicas(t *testing.T) {
+	primary, replica := openUnreachable(t), openUnreachable(t)
+	c := NewCluster(primary, replica)
+
+	c.checkReplicas(context.Background())
+
+	if c.healthyReplicas() != 0 {
+		t.Error("不可达的从库应被标记为不健康")
+	}
+	if c.Replica() != primary {
+		t.Error("从库不健康时应回退主库")
+	}
+}
@@ -1,15 +1,21 @@
 package db
 
 import (
+	"context"
+	"database/sql/driver"
 	"fmt"
 	"time"
 
+	"github.com/XSAM/otelsql"
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq" // PostgreSQL 驱动
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
 
+	log "entry-task/pkg/logger"
+	"entry-task/pkg/tracing"
 	"entry-task/tcpserver/config"
-	log "entry-task/tcpserver/pkg/logger"
 
 	"go.uber.org/zap"
 )
@@ -23,59 +29,83 @@ func InitDB(cfg *config.Config) (*sqlx.DB, error) {
 		zap.String("database", cfg.Database.Database),
 	)
 
-	var driverName string
-	var dsn string
+	db, err := openDB(&cfg.Database)
+	if err != nil {
+		return nil, err
+	}
+
+	// 测试连接
+	log.Debug("测试数据库连接...")
+	if err := db.Ping(); err != nil {
+		log.Error("数据库连接测试失败", zap.Error(err))
+		_ = db.Close()
+		return nil, fmt.Errorf("数据库连接测试失败: %w", err)
+	}
+
+	log.Info("数据库连接成功",
+		zap.String("driver", cfg.Database.Driver),
+		zap.String("database", cfg.Database.Database),
+	)
+
+	return db, nil
+}
 
+// openDB 按配置创建连接池（不测试连接）
+func openDB(dbCfg *config.DatabaseConfig) (*sqlx.DB, error) {
 	// 根据驱动类型选择驱动和 DSN
-	switch cfg.Database.Driver {
-	case "mysql":
-		driverName = "mysql"
-		dsn = cfg.Database.GetDSN()
+	driverName := dbCfg.GetDriverName()
+	var dbSystem attribute.KeyValue
+	switch driverName {
+	case config.DriverMySQL:
 		log.Debug("使用 MySQL 驱动")
+		dbSystem = semconv.DBSystemNameMySQL
 
-	case "postgres", "pgsql":
-		driverName = "postgres"
-		dsn = cfg.Database.GetDSN()
+	case config.DriverPostgres:
 		log.Debug("使用 PostgreSQL 驱动")
+		dbSystem = semconv.DBSystemNamePostgreSQL
 
 	default:
-		log.Error("不支持的数据库驱动", zap.String("driver", cfg.Database.Driver))
-		return nil, fmt.Errorf("不支持的数据库驱动: %s", cfg.Database.Driver)
+		log.Error("不支持的数据库驱动", zap.String("driver", dbCfg.Driver))
+		return nil, fmt.Errorf("不支持的数据库驱动: %s", dbCfg.Driver)
 	}
+	dsn := dbCfg.GetDSN()
 
-	// 打开数据库连接
+	// 打开数据库连接（包装驱动，为请求链路中的 SQL 调用创建 Span）
 	log.Debug("正在建立数据库连接...")
-	db, err := sqlx.Connect(driverName, dsn)
+	sqlDB, err := otelsql.Open(driverName, dsn,
+		otelsql.WithAttributes(
+			dbSystem,
+			semconv.DBNamespace(dbCfg.Database),
+			semconv.ServerAddress(dbCfg.Host),
+			semconv.ServerPort(dbCfg.Port),
+		),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{
+			OmitConnResetSession: true,
+			OmitRows:             true,
+			SpanFilter: func(ctx context.Context, _ otelsql.Method, _ string, _ []driver.NamedValue) bool {
+				return tracing.HasParent(ctx)
+			},
+		}),
+	)
 	if err != nil {
 		log.Error("连接数据库失败",
 			zap.Error(err),
-			zap.String("driver", cfg.Database.Driver),
-			zap.String("host", cfg.Database.Host),
+			zap.String("driver", dbCfg.Driver),
+			zap.String("host", dbCfg.Host),
 		)
 		return nil, fmt.Errorf("连接数据库失败: %w", err)
 	}
+	db := sqlx.NewDb(sqlDB, driverName)
 
 	// 配置连接池
 	log.Debug("配置数据库连接池",
-		zap.Int("max_open_conns", cfg.Database.MaxOpenConns),
-		zap.Int("max_idle_conns", cfg.Database.MaxIdleConns),
-		zap.Int("conn_max_lifetime", cfg.Database.ConnMaxLifetime),
-	)
-	db.SetMaxOpenConns(cfg.Database.MaxOpenConns)
-	db.SetMaxIdleConns(cfg.Database.MaxIdleConns)
-	db.SetConnMaxLifetime(time.Duration(cfg.Database.ConnMaxLifetime) * time.Second)
-
-	// 测试连接
-	log.Debug("测试数据库连接...")
-	if err := db.Ping(); err != nil {
-		log.Error("数据库连接测试失败", zap.Error(err))
-		return nil, fmt.Errorf("数据库连接测试失败: %w", err)
-	}
-
-	log.Info("数据库连接成功",
-		zap.String("driver", cfg.Database.Driver),
-		zap.String("database", cfg.Database.Database),
+		zap.Int("max_open_conns", dbCfg.MaxOpenConns),
+		zap.Int("max_idle_conns", dbCfg.MaxIdleConns),
+		zap.Int("conn_max_lifetime_sec", dbCfg.ConnMaxLifetimeSec),
 	)
+	db.SetMaxOpenConns(dbCfg.MaxOpenConns)
+	db.SetMaxIdleConns(dbCfg.MaxIdleConns)
+	db.SetConnMaxLifetime(time.Duration(dbCfg.ConnMaxLifetimeSec) * time.Second)
 
 	return db, nil
 }
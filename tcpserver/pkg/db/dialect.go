@@ -0,0 +1,51 @@
+package db
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+
+	"entry-task/tcpserver/config"
+)
+
+const (
+	// mysqlErrDuplicateEntry MySQL 唯一键冲突错误码
+	mysqlErrDuplicateEntry = 1062
+
+	// postgresErrUniqueViolation PostgreSQL 唯一约束冲突错误码
+	postgresErrUniqueViolation = "23505"
+)
+
+// ErrNoConflictColumns 未指定冲突列
+var ErrNoConflictColumns = errors.New("未指定冲突列")
+
+// IsDuplicateKey 判断是否为主键/唯一键冲突错误（兼容 MySQL 和 PostgreSQL）
+func IsDuplicateKey(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number == mysqlErrDuplicateEntry
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == postgresErrUniqueViolation
+	}
+
+	return false
+}
+
+// OnConflictDoNothing 返回"主键/唯一键冲突时跳过该行"的 INSERT 语句后缀
+// MySQL:      ON DUPLICATE KEY UPDATE id = id（不使用 INSERT IGNORE，避免吞掉其他错误）
+// PostgreSQL: ON CONFLICT (id) DO NOTHING
+// 未指定冲突列时返回 ErrNoConflictColumns
+func OnConflictDoNothing(driverName string, conflictColumns ...string) (string, error) {
+	if len(conflictColumns) == 0 {
+		return "", ErrNoConflictColumns
+	}
+	if driverName == config.DriverPostgres {
+		return " ON CONFLICT (" + strings.Join(conflictColumns, ", ") + ") DO NOTHING", nil
+	}
+	return " ON DUPLICATE KEY UPDATE " + conflictColumns[0] + " = " + conflictColumns[0], nil
+}
@@ -0,0 +1,58 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+)
+
+// TestIsDuplicateKey 测试两种数据库的唯一键冲突识别
+func TestIsDuplicateKey(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"MySQL 1062", &mysql.MySQLError{Number: 1062}, true},
+		{"MySQL 其他错误", &mysql.MySQLError{Number: 1045}, false},
+		{"PostgreSQL 23505", &pq.Error{Code: "23505"}, true},
+		{"PostgreSQL 其他错误", &pq.Error{Code: "23503"}, false},
+		{"包装后的错误", fmt.Errorf("insert: %w", &pq.Error{Code: "23505"}), true},
+		{"nil", nil, false},
+	}
+
+	for _, c := range cases {
+		if got := IsDuplicateKey(c.err); got != c.want {
+			t.Errorf("%s: 期望 %v, 实际 %v", c.name, c.want, got)
+		}
+	}
+}
+
+// TestOnConflict 测试两种数据库的冲突处理语句
+func TestOnConflict(t *testing.T) {
+	cases := []struct {
+		driver  string
+		columns []string
+		want    string
+	}{
+		{"mysql", []string{"id"}, " ON DUPLICATE KEY UPDATE id = id"},
+		{"postgres", []string{"id"}, " ON CONFLICT (id) DO NOTHING"},
+		{"postgres", []string{"user_id", "day"}, " ON CONFLICT (user_id, day) DO NOTHING"},
+	}
+
+	for _, c := range cases {
+		got, err := OnConflictDoNothing(c.driver, c.columns...)
+		if err != nil || got != c.want {
+			t.Errorf("%s %v: 期望 %q, 实际 %q (err=%v)", c.driver, c.columns, c.want, got, err)
+		}
+	}
+
+	for _, driver := range []string{"mysql", "postgres"} {
+		if _, err := OnConflictDoNothing(driver); !errors.Is(err, ErrNoConflictColumns) {
+			t.Errorf("%s 未指定冲突列时应返回 ErrNoConflictColumns, 实际 %v", driver, err)
+		}
+	}
+}
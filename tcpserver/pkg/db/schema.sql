@@ -1,6 +1,8 @@
 -- =============================================================================
 -- 用户表设计（单表 + 雪花ID）
 -- =============================================================================
+-- 注意：表结构以 pkg/migrate/migrations 下的版本化迁移为准（tcpserver migrate up），
+-- 本文件仅作为设计说明和参考，修改表结构请新增迁移文件。
 -- 设计说明：
 -- - 单表设计，支持 1000 万用户数据
 -- - 主键使用雪花ID（Snowflake ID），趋势递增，利于B+树索引
@@ -28,6 +30,23 @@ CREATE TABLE IF NOT EXISTS `users` (
     KEY `idx_created_at` (`created_at`) COMMENT '创建时间索引，用于按时间排序'
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='用户表（单表设计，支持1000万数据）';
 
+-- =============================================================================
+-- 缓存失效发件箱（Outbox）
+-- =============================================================================
+-- 更新 users 时在同一事务中写入，由 tcpserver 的缓存失效 Worker 在 next_attempt_at
+-- 之后删除对应的用户缓存（延迟双删的第二次删除），处理成功后删除记录
+CREATE TABLE IF NOT EXISTS `cache_invalidations` (
+    `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '自增ID',
+    `user_id` BIGINT UNSIGNED NOT NULL COMMENT '需要删除缓存的用户ID',
+    `attempts` INT UNSIGNED NOT NULL DEFAULT 0 COMMENT '已失败次数',
+    `next_attempt_at` BIGINT NOT NULL COMMENT '下次处理时间（Unix毫秒）',
+    `last_error` VARCHAR(512) NOT NULL DEFAULT '' COMMENT '最近一次失败原因',
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+
+    PRIMARY KEY (`id`),
+    KEY `idx_next_attempt_at` (`next_attempt_at`) COMMENT 'Worker 按到期时间轮询'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='缓存失效发件箱';
+
 -- =============================================================================
 -- 索引说明
 -- =============================================================================
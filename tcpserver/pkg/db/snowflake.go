@@ -100,6 +100,21 @@ func (s *Snowflake) NextID() (int64, error) {
 	return id, nil
 }
 
+// Ready 检查当前能否生成ID（时钟回拨或早于起始时间时不可用），用于健康检查
+func (s *Snowflake) Ready() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UnixMilli()
+	if now < s.timestamp {
+		return fmt.Errorf("时钟回拨检测：当前时间 %d < 上次时间 %d", now, s.timestamp)
+	}
+	if now < s.epoch {
+		return fmt.Errorf("当前时间早于起始时间")
+	}
+	return nil
+}
+
 // NewSnowflake 创建雪花ID生成器
 // machineID: 机器ID (0-1023)，用于区分不同的服务实例
 func NewSnowflake(machineID int64) (*Snowflake, error) {
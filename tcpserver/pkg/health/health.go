@@ -0,0 +1,137 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	log "entry-task/pkg/logger"
+)
+
+// ============================================================================
+// 健康检查（grpc.health.v1）
+// ============================================================================
+//
+// 后台定期检查数据库、Redis 等依赖，全部通过时服务状态为 SERVING，否则为 NOT_SERVING。
+// 存活探针只需 Health/Check 能够响应；就绪探针要求状态为 SERVING。
+
+const (
+	// DefaultInterval 默认检查间隔
+	DefaultInterval = 5 * time.Second
+
+	// DefaultTimeout 默认单项检查超时时间
+	DefaultTimeout = 2 * time.Second
+)
+
+// Check 单项依赖检查
+type Check struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// Checker 定期执行依赖检查并更新 gRPC 健康状态
+type Checker struct {
+	server   *health.Server
+	services []string // 状态随检查结果变化的服务名（"" 表示整个 Server）
+	checks   []Check
+	interval time.Duration
+	timeout  time.Duration
+
+	mu     sync.Mutex
+	failed map[string]string // 上次检查失败的依赖及原因，用于只在状态变化时输出日志
+}
+
+// NewChecker 创建健康检查器，services 为需要随检查结果更新状态的服务名
+// 创建后所有服务为 NOT_SERVING，首次检查通过后变为 SERVING
+func NewChecker(server *health.Server, services []string, checks []Check, interval, timeout time.Duration) *Checker {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	services = append([]string{""}, services...)
+	for _, svc := range services {
+		server.SetServingStatus(svc, healthpb.HealthCheckResponse_NOT_SERVING)
+	}
+	return &Checker{
+		server:   server,
+		services: services,
+		checks:   checks,
+		interval: interval,
+		timeout:  timeout,
+		failed:   map[string]string{},
+	}
+}
+
+// Run 立即检查一次，之后按间隔检查，直到 ctx 取消
+func (c *Checker) Run(ctx context.Context) {
+	c.CheckOnce(ctx)
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.CheckOnce(ctx)
+		}
+	}
+}
+
+// CheckOnce 并发执行所有检查并更新服务状态，返回失败的依赖及原因
+func (c *Checker) CheckOnce(ctx context.Context) map[string]string {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		failed = map[string]string{}
+	)
+	for _, check := range c.checks {
+		wg.Add(1)
+		go func(check Check) {
+			defer wg.Done()
+			if err := check.Check(ctx); err != nil {
+				mu.Lock()
+				failed[check.Name] = err.Error()
+				mu.Unlock()
+			}
+		}(check)
+	}
+	wg.Wait()
+
+	status := healthpb.HealthCheckResponse_SERVING
+	if len(failed) > 0 {
+		status = healthpb.HealthCheckResponse_NOT_SERVING
+	}
+	for _, svc := range c.services {
+		c.server.SetServingStatus(svc, status)
+	}
+	c.logChanges(failed)
+	return failed
+}
+
+// logChanges 依赖状态变化时输出日志（持续失败时不重复输出）
+func (c *Checker) logChanges(failed map[string]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for name, reason := range failed {
+		if _, ok := c.failed[name]; !ok {
+			log.Warn("依赖健康检查失败", zap.String("dependency", name), zap.String("reason", reason))
+		}
+	}
+	for name := range c.failed {
+		if _, ok := failed[name]; !ok {
+			log.Info("依赖健康检查恢复", zap.String("dependency", name))
+		}
+	}
+	c.failed = failed
+}
@@ -0,0 +1,88 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"entry-task/pkg/logger"
+)
+
+// TestMain 在所有测试运行前初始化日志
+func TestMain(m *testing.M) {
+	if err := logger.Init(&logger.Config{Level: "fatal", Output: "stdout"}); err != nil {
+		panic("初始化日志失败: " + err.Error())
+	}
+	os.Exit(m.Run())
+}
+
+// servingStatus 查询服务的健康状态
+func servingStatus(t *testing.T, server *health.Server, service string) healthpb.HealthCheckResponse_ServingStatus {
+	t.Helper()
+	resp, err := server.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+	if err != nil {
+		t.Fatalf("查询健康状态失败: %v", err)
+	}
+	return resp.Status
+}
+
+// TestChecker 测试依赖检查结果决定服务状态
+func TestChecker(t *testing.T) {
+	server := health.NewServer()
+	var redisErr error
+	checker := NewChecker(server, []string{"user.UserService"}, []Check{
+		{Name: "database", Check: func(context.Context) error { return nil }},
+		{Name: "redis", Check: func(context.Context) error { return redisErr }},
+	}, 0, 0)
+
+	// 首次检查前为 NOT_SERVING
+	if s := servingStatus(t, server, ""); s != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("首次检查前期望 NOT_SERVING, 实际 %v", s)
+	}
+
+	if failed := checker.CheckOnce(context.Background()); len(failed) != 0 {
+		t.Errorf("期望全部通过, 实际失败 %v", failed)
+	}
+	for _, svc := range []string{"", "user.UserService"} {
+		if s := servingStatus(t, server, svc); s != healthpb.HealthCheckResponse_SERVING {
+			t.Errorf("服务 %q 期望 SERVING, 实际 %v", svc, s)
+		}
+	}
+
+	// Redis 不可用
+	redisErr = errors.New("connection refused")
+	failed := checker.CheckOnce(context.Background())
+	if failed["redis"] != "connection refused" || len(failed) != 1 {
+		t.Errorf("期望 redis 失败, 实际 %v", failed)
+	}
+	if s := servingStatus(t, server, "user.UserService"); s != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("依赖失败时期望 NOT_SERVING, 实际 %v", s)
+	}
+
+	// 恢复
+	redisErr = nil
+	checker.CheckOnce(context.Background())
+	if s := servingStatus(t, server, ""); s != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("依赖恢复后期望 SERVING, 实际 %v", s)
+	}
+}
+
+// TestChecker_Timeout 测试检查超时视为失败
+func TestChecker_Timeout(t *testing.T) {
+	server := health.NewServer()
+	checker := NewChecker(server, nil, []Check{
+		{Name: "slow", Check: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}},
+	}, 0, 10*time.Millisecond)
+
+	if failed := checker.CheckOnce(context.Background()); failed["slow"] == "" {
+		t.Error("超时的检查应视为失败")
+	}
+}
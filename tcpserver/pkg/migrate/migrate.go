@@ -0,0 +1,401 @@
+package migrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+
+	log "entry-task/pkg/logger"
+	"entry-task/tcpserver/config"
+)
+
+// ============================================================================
+// 数据库版本化迁移
+// ============================================================================
+//
+// 迁移文件内嵌在二进制中，按驱动分目录：migrations/<driver>/<版本>_<名称>.<up|down>.sql
+// 已执行的版本记录在 schema_migrations 表中（含文件校验和，已执行的文件被修改时拒绝继续）。
+// 多实例同时启动时通过数据库咨询锁（MySQL GET_LOCK / PostgreSQL pg_advisory_lock）串行执行。
+//
+// 注意：MySQL 的 DDL 会隐式提交，无法随事务回滚，迁移语句应尽量可重复执行（IF NOT EXISTS）。
+
+//go:embed migrations
+var migrationsFS embed.FS
+
+const (
+	// migrationsTable 迁移记录表
+	migrationsTable = "schema_migrations"
+
+	// lockName MySQL 咨询锁名称
+	lockName = "entry_task_schema_migrations"
+
+	// lockKey PostgreSQL 咨询锁键
+	lockKey = 727274001
+
+	// lockTimeoutSeconds 获取 MySQL 咨询锁的超时时间（秒）
+	lockTimeoutSeconds = 60
+)
+
+var (
+	// ErrChecksumMismatch 已执行的迁移文件被修改
+	ErrChecksumMismatch = errors.New("已执行的迁移文件被修改")
+
+	// ErrLockTimeout 获取迁移锁超时
+	ErrLockTimeout = errors.New("获取迁移锁超时")
+)
+
+// Migration 单个迁移版本
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string
+	Checksum string // Up 脚本的 SHA-256
+}
+
+// Status 迁移版本的执行状态
+type Status struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// appliedMigration schema_migrations 表记录
+type appliedMigration struct {
+	Version   int64     `db:"version"`
+	Name      string    `db:"name"`
+	Checksum  string    `db:"checksum"`
+	AppliedAt time.Time `db:"applied_at"`
+}
+
+// Migrator 迁移执行器
+type Migrator struct {
+	db         *sqlx.DB
+	migrations []Migration
+}
+
+// New 创建迁移执行器，按数据库驱动加载内嵌的迁移文件
+func New(db *sqlx.DB) (*Migrator, error) {
+	dir := path.Join("migrations", db.DriverName())
+	sub, err := fs.Sub(migrationsFS, dir)
+	if err != nil {
+		return nil, fmt.Errorf("加载迁移文件失败: %w", err)
+	}
+	return newMigrator(db, sub)
+}
+
+// newMigrator 从指定文件系统加载迁移文件
+func newMigrator(db *sqlx.DB, fsys fs.FS) (*Migrator, error) {
+	migrations, err := loadMigrations(fsys)
+	if err != nil {
+		return nil, err
+	}
+	if len(migrations) == 0 {
+		return nil, fmt.Errorf("驱动 %s 没有可用的迁移文件", db.DriverName())
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Up 执行所有未执行的迁移，返回本次执行的版本
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var executed []Migration
+	err := m.withLock(ctx, func(conn *sqlx.Conn) error {
+		applied, err := m.verifyApplied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, mig := range m.migrations {
+			if _, ok := applied[mig.Version]; ok {
+				continue
+			}
+
+			log.Info("执行数据库迁移", zap.Int64("version", mig.Version), zap.String("name", mig.Name))
+			insert := conn.Rebind(`INSERT INTO ` + migrationsTable + ` (version, name, checksum) VALUES (?, ?, ?)`)
+			if err := m.exec(ctx, conn, mig.Up, insert, mig.Version, mig.Name, mig.Checksum); err != nil {
+				return fmt.Errorf("执行迁移 %d_%s 失败: %w", mig.Version, mig.Name, err)
+			}
+			executed = append(executed, mig)
+		}
+		return nil
+	})
+	return executed, err
+}
+
+// Down 回滚最近执行的 steps 个迁移，返回本次回滚的版本
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var reverted []Migration
+	err := m.withLock(ctx, func(conn *sqlx.Conn) error {
+		applied, err := m.verifyApplied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+			mig := m.migrations[i]
+			if _, ok := applied[mig.Version]; !ok {
+				continue
+			}
+
+			log.Info("回滚数据库迁移", zap.Int64("version", mig.Version), zap.String("name", mig.Name))
+			remove := conn.Rebind(`DELETE FROM ` + migrationsTable + ` WHERE version = ?`)
+			if err := m.exec(ctx, conn, mig.Down, remove, mig.Version); err != nil {
+				return fmt.Errorf("回滚迁移 %d_%s 失败: %w", mig.Version, mig.Name, err)
+			}
+			reverted = append(reverted, mig)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Status 获取所有迁移版本的执行状态
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	if err := m.ensureTable(ctx, m.db); err != nil {
+		return nil, err
+	}
+
+	applied, err := m.loadApplied(ctx, m.db)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		s := Status{Migration: mig}
+		if a, ok := applied[mig.Version]; ok {
+			s.Applied = true
+			s.AppliedAt = a.AppliedAt
+		}
+		statuses = append(statuses, s)
+	}
+	return statuses, nil
+}
+
+// exec 在事务中执行迁移脚本并更新迁移记录
+func (m *Migrator) exec(ctx context.Context, conn *sqlx.Conn, script, record string, args ...interface{}) error {
+	tx, err := conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	for _, stmt := range splitStatements(script) {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, record, args...); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// verifyApplied 加载已执行的迁移并校验文件未被修改
+func (m *Migrator) verifyApplied(ctx context.Context, conn *sqlx.Conn) (map[int64]appliedMigration, error) {
+	if err := m.ensureTable(ctx, conn); err != nil {
+		return nil, err
+	}
+
+	applied, err := m.loadApplied(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, mig := range m.migrations {
+		if a, ok := applied[mig.Version]; ok && a.Checksum != mig.Checksum {
+			return nil, fmt.Errorf("%w: %d_%s", ErrChecksumMismatch, mig.Version, mig.Name)
+		}
+	}
+	return applied, nil
+}
+
+// ensureTable 创建迁移记录表（MySQL 和 PostgreSQL 通用语法）
+func (m *Migrator) ensureTable(ctx context.Context, db sqlx.ExecerContext) error {
+	query := `CREATE TABLE IF NOT EXISTS ` + migrationsTable + ` (
+        version BIGINT NOT NULL PRIMARY KEY,
+        name VARCHAR(255) NOT NULL,
+        checksum CHAR(64) NOT NULL,
+        applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+    )`
+	if _, err := db.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("创建迁移记录表失败: %w", err)
+	}
+	return nil
+}
+
+// loadApplied 加载已执行的迁移记录
+func (m *Migrator) loadApplied(ctx context.Context, db sqlx.QueryerContext) (map[int64]appliedMigration, error) {
+	var rows []appliedMigration
+	query := `SELECT version, name, checksum, applied_at FROM ` + migrationsTable
+	if err := sqlx.SelectContext(ctx, db, &rows, query); err != nil {
+		return nil, fmt.Errorf("查询迁移记录失败: %w", err)
+	}
+
+	applied := make(map[int64]appliedMigration, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+	return applied, nil
+}
+
+// withLock 持有咨询锁执行 fn（锁与连接绑定，所有操作在同一连接上执行）
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sqlx.Conn) error) error {
+	conn, err := m.db.Connx(ctx)
+	if err != nil {
+		return fmt.Errorf("获取数据库连接失败: %w", err)
+	}
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	var unlockQuery string
+	switch m.db.DriverName() {
+	case config.DriverPostgres:
+		// pg_advisory_lock 阻塞直到获得锁（受 ctx 控制）
+		if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock("+strconv.Itoa(lockKey)+")"); err != nil {
+			return fmt.Errorf("获取迁移锁失败: %w", err)
+		}
+		unlockQuery = "SELECT pg_advisory_unlock(" + strconv.Itoa(lockKey) + ")"
+
+	default:
+		// GET_LOCK 获得锁返回 1，超时返回 0
+		var locked sql.NullInt64
+		lockQuery := "SELECT GET_LOCK('" + lockName + "', " + strconv.Itoa(lockTimeoutSeconds) + ")"
+		if err := conn.QueryRowxContext(ctx, lockQuery).Scan(&locked); err != nil {
+			return fmt.Errorf("获取迁移锁失败: %w", err)
+		}
+		if locked.Int64 != 1 {
+			return ErrLockTimeout
+		}
+		unlockQuery = "SELECT RELEASE_LOCK('" + lockName + "')"
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.WithoutCancel(ctx), unlockQuery); err != nil {
+			log.Warn("释放迁移锁失败", zap.Error(err))
+		}
+	}()
+
+	return fn(conn)
+}
+
+// loadMigrations 解析迁移文件：<版本>_<名称>.up.sql / <版本>_<名称>.down.sql，每个版本必须同时有 up 和 down
+func loadMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("读取迁移目录失败: %w", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+
+		version, name, direction, err := parseFileName(entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("读取迁移文件 %s 失败: %w", entry.Name(), err)
+		}
+
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: name}
+			byVersion[version] = mig
+		} else if mig.Name != name {
+			return nil, fmt.Errorf("迁移版本 %d 重复: %s, %s", version, mig.Name, name)
+		}
+
+		if direction == "up" {
+			mig.Up = string(data)
+			sum := sha256.Sum256(data)
+			mig.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			mig.Down = string(data)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.Up == "" || mig.Down == "" {
+			return nil, fmt.Errorf("迁移 %d_%s 缺少 up 或 down 脚本", mig.Version, mig.Name)
+		}
+		migrations = append(migrations, *mig)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// parseFileName 解析迁移文件名，例如 0001_create_users.up.sql
+func parseFileName(fileName string) (int64, string, string, error) {
+	base := strings.TrimSuffix(fileName, ".sql")
+
+	var direction string
+	switch {
+	case strings.HasSuffix(base, ".up"):
+		direction = "up"
+	case strings.HasSuffix(base, ".down"):
+		direction = "down"
+	default:
+		return 0, "", "", fmt.Errorf("迁移文件名缺少 .up/.down: %s", fileName)
+	}
+	base = strings.TrimSuffix(base, "."+direction)
+
+	versionStr, name, ok := strings.Cut(base, "_")
+	if !ok || name == "" {
+		return 0, "", "", fmt.Errorf("迁移文件名格式错误: %s", fileName)
+	}
+	version, err := strconv.ParseInt(versionStr, 10, 64)
+	if err != nil || version <= 0 {
+		return 0, "", "", fmt.Errorf("迁移文件版本号无效: %s", fileName)
+	}
+	return version, name, direction, nil
+}
+
+// splitStatements 拆分SQL脚本：忽略整行注释，以行尾分号作为语句结束
+func splitStatements(script string) []string {
+	var (
+		statements []string
+		current    strings.Builder
+	)
+
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+
+		current.WriteString(line)
+		current.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.TrimSuffix(strings.TrimSpace(current.String()), ";"))
+			current.Reset()
+		}
+	}
+
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+	return statements
+}
@@ -0,0 +1,180 @@
+package migrate
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"testing"
+	"testing/fstest"
+
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+
+	"entry-task/pkg/logger"
+)
+
+// TestMain 在所有测试运行前初始化
+func TestMain(m *testing.M) {
+	if err := logger.Init(&logger.Config{Level: "fatal", Output: "stdout"}); err != nil {
+		panic("初始化日志失败: " + err.Error())
+	}
+	os.Exit(m.Run())
+}
+
+// TestParseFileName 测试迁移文件名解析
+func TestParseFileName(t *testing.T) {
+	version, name, direction, err := parseFileName("0002_create_cache_invalidations.down.sql")
+	if err != nil || version != 2 || name != "create_cache_invalidations" || direction != "down" {
+		t.Errorf("解析结果错误: %d %s %s %v", version, name, direction, err)
+	}
+
+	for _, bad := range []string{"0001_users.sql", "users.up.sql", "abc_users.up.sql", "0000_users.up.sql", "0001_.up.sql"} {
+		if _, _, _, err := parseFileName(bad); err == nil {
+			t.Errorf("%s: 期望返回错误", bad)
+		}
+	}
+}
+
+// TestSplitStatements 测试SQL脚本拆分
+func TestSplitStatements(t *testing.T) {
+	script := `-- 注释中的分号;
+CREATE TABLE t (
+    a INT, -- 行内注释保留
+    b VARCHAR(10) DEFAULT 'x;y'
+);
+
+CREATE INDEX idx_a ON t (a);
+COMMENT ON TABLE t IS 'no trailing semicolon'`
+
+	stmts := splitStatements(script)
+	if len(stmts) != 3 {
+		t.Fatalf("期望3条语句, 实际 %d: %q", len(stmts), stmts)
+	}
+	if stmts[1] != "CREATE INDEX idx_a ON t (a)" {
+		t.Errorf("语句2错误: %q", stmts[1])
+	}
+}
+
+// TestLoadMigrations 测试迁移文件加载与校验
+func TestLoadMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0002_b.up.sql":   {Data: []byte("CREATE TABLE b (id INT);")},
+		"0002_b.down.sql": {Data: []byte("DROP TABLE b;")},
+		"0001_a.up.sql":   {Data: []byte("CREATE TABLE a (id INT);")},
+		"0001_a.down.sql": {Data: []byte("DROP TABLE a;")},
+		"README.md":       {Data: []byte("ignored")},
+	}
+
+	migrations, err := loadMigrations(fsys)
+	if err != nil {
+		t.Fatalf("加载失败: %v", err)
+	}
+	if len(migrations) != 2 || migrations[0].Version != 1 || migrations[1].Version != 2 {
+		t.Fatalf("版本顺序错误: %+v", migrations)
+	}
+	if len(migrations[0].Checksum) != 64 {
+		t.Errorf("校验和错误: %s", migrations[0].Checksum)
+	}
+
+	missingDown := fstest.MapFS{"0001_a.up.sql": {Data: []byte("SELECT 1;")}}
+	if _, err := loadMigrations(missingDown); err == nil {
+		t.Error("缺少 down 脚本时应返回错误")
+	}
+
+	duplicate := fstest.MapFS{
+		"0001_a.up.sql":   {Data: []byte("SELECT 1;")},
+		"0001_a.down.sql": {Data: []byte("SELECT 1;")},
+		"0001_b.up.sql":   {Data: []byte("SELECT 1;")},
+	}
+	if _, err := loadMigrations(duplicate); err == nil {
+		t.Error("版本号重复时应返回错误")
+	}
+}
+
+// TestEmbeddedMigrations 测试内嵌的 MySQL 和 PostgreSQL 迁移版本一致
+func TestEmbeddedMigrations(t *testing.T) {
+	versions := make(map[string][]int64)
+	for _, driver := range []string{"mysql", "postgres"} {
+		sub, err := fs.Sub(migrationsFS, "migrations/"+driver)
+		if err != nil {
+			t.Fatalf("%s: %v", driver, err)
+		}
+		migrations, err := loadMigrations(sub)
+		if err != nil {
+			t.Fatalf("%s: 加载失败: %v", driver, err)
+		}
+		for _, m := range migrations {
+			versions[driver] = append(versions[driver], m.Version)
+		}
+	}
+
+	mysql, postgres := versions["mysql"], versions["postgres"]
+	if len(mysql) == 0 || len(mysql) != len(postgres) {
+		t.Fatalf("两种数据库的迁移数量不一致: mysql=%v postgres=%v", mysql, postgres)
+	}
+	for i := range mysql {
+		if mysql[i] != postgres[i] {
+			t.Errorf("迁移版本不一致: mysql=%v postgres=%v", mysql, postgres)
+			break
+		}
+	}
+}
+
+// TestMigrator_UpDown 在真实数据库上测试 up/down/status（通过 TEST_MYSQL_DSN / TEST_POSTGRES_DSN 指定测试库，未设置时跳过）
+// 注意：测试会回滚全部迁移（删除业务表），请使用独立的测试库
+func TestMigrator_UpDown(t *testing.T) {
+	for driver, env := range map[string]string{"mysql": "TEST_MYSQL_DSN", "postgres": "TEST_POSTGRES_DSN"} {
+		t.Run(driver, func(t *testing.T) {
+			dsn := os.Getenv(env)
+			if dsn == "" {
+				t.Skipf("未设置 %s，跳过 %s 测试", env, driver)
+			}
+
+			db, err := sqlx.Connect(driver, dsn)
+			if err != nil {
+				t.Fatalf("连接数据库失败: %v", err)
+			}
+			defer db.Close()
+
+			m, err := New(db)
+			if err != nil {
+				t.Fatalf("创建迁移执行器失败: %v", err)
+			}
+			ctx := context.Background()
+			total := len(m.migrations)
+
+			if _, err := m.Down(ctx, total); err != nil {
+				t.Fatalf("回滚失败: %v", err)
+			}
+
+			executed, err := m.Up(ctx)
+			if err != nil || len(executed) != total {
+				t.Fatalf("执行迁移失败: %d/%d, %v", len(executed), total, err)
+			}
+
+			// 重复执行为空操作
+			if executed, err := m.Up(ctx); err != nil || len(executed) != 0 {
+				t.Fatalf("重复执行应为空操作: %d, %v", len(executed), err)
+			}
+
+			reverted, err := m.Down(ctx, 1)
+			if err != nil || len(reverted) != 1 || reverted[0].Version != m.migrations[total-1].Version {
+				t.Fatalf("回滚最近一个迁移失败: %+v, %v", reverted, err)
+			}
+
+			statuses, err := m.Status(ctx)
+			if err != nil {
+				t.Fatalf("查询状态失败: %v", err)
+			}
+			if statuses[0].Applied != true || statuses[total-1].Applied != false {
+				t.Errorf("迁移状态错误: %+v", statuses)
+			}
+
+			// 恢复到最新版本，供其他测试使用
+			if _, err := m.Up(ctx); err != nil {
+				t.Fatalf("恢复迁移失败: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,14 @@
+-- 用户表（单表 + 雪花ID），字段说明见 pkg/db/schema.sql
+CREATE TABLE IF NOT EXISTS `users` (
+    `id` BIGINT UNSIGNED NOT NULL COMMENT '用户ID，雪花ID（分布式唯一，不使用自增）',
+    `username` VARCHAR(64) NOT NULL COMMENT '用户名，唯一，用于登录（不可更改）',
+    `password_hash` VARCHAR(255) NOT NULL COMMENT '密码哈希值（使用bcrypt算法，cost=10）',
+    `nickname` VARCHAR(128) CHARACTER SET utf8mb4 COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '' COMMENT '昵称（支持完整Unicode字符集，包括中文、emoji等）',
+    `profile_picture` VARCHAR(512) NOT NULL DEFAULT '' COMMENT '头像URL或文件路径',
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    `updated_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+
+    PRIMARY KEY (`id`),
+    UNIQUE KEY `idx_username` (`username`) COMMENT '用户名唯一索引，用于登录查询',
+    KEY `idx_created_at` (`created_at`) COMMENT '创建时间索引，用于按时间排序'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='用户表（单表设计，支持1000万数据）';
@@ -0,0 +1,12 @@
+-- 缓存失效发件箱：更新 users 时在同一事务中写入，由缓存失效 Worker 执行延迟删除
+CREATE TABLE IF NOT EXISTS `cache_invalidations` (
+    `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT '自增ID',
+    `user_id` BIGINT UNSIGNED NOT NULL COMMENT '需要删除缓存的用户ID',
+    `attempts` INT UNSIGNED NOT NULL DEFAULT 0 COMMENT '已失败次数',
+    `next_attempt_at` BIGINT NOT NULL COMMENT '下次处理时间（Unix毫秒）',
+    `last_error` VARCHAR(512) NOT NULL DEFAULT '' COMMENT '最近一次失败原因',
+    `created_at` TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+
+    PRIMARY KEY (`id`),
+    KEY `idx_next_attempt_at` (`next_attempt_at`) COMMENT 'Worker 按到期时间轮询'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='缓存失效发件箱';
@@ -0,0 +1,22 @@
+-- 用户表（与 MySQL 版本结构一致）
+-- PostgreSQL 没有 UNSIGNED，id 使用 BIGINT（雪花ID不会超过 2^63-1）；
+-- 没有 ON UPDATE CURRENT_TIMESTAMP，updated_at 由 UPDATE 语句显式更新
+CREATE TABLE IF NOT EXISTS users (
+    id BIGINT NOT NULL,
+    username VARCHAR(64) NOT NULL,
+    password_hash VARCHAR(255) NOT NULL,
+    nickname VARCHAR(128) NOT NULL DEFAULT '',
+    profile_picture VARCHAR(512) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (id),
+    CONSTRAINT idx_username UNIQUE (username)
+);
+
+CREATE INDEX IF NOT EXISTS idx_created_at ON users (created_at);
+
+COMMENT ON TABLE users IS '用户表（单表设计，支持1000万数据）';
+COMMENT ON COLUMN users.id IS '用户ID，雪花ID（分布式唯一，不使用自增）';
+COMMENT ON COLUMN users.username IS '用户名，唯一，用于登录（不可更改）';
+COMMENT ON COLUMN users.password_hash IS '密码哈希值（使用bcrypt算法，cost=10）';
@@ -0,0 +1,14 @@
+-- 缓存失效发件箱：更新 users 时在同一事务中写入，由缓存失效 Worker 执行延迟删除
+CREATE TABLE IF NOT EXISTS cache_invalidations (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at BIGINT NOT NULL,
+    last_error VARCHAR(512) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_next_attempt_at ON cache_invalidations (next_attempt_at);
+
+COMMENT ON TABLE cache_invalidations IS '缓存失效发件箱';
+COMMENT ON COLUMN cache_invalidations.next_attempt_at IS '下次处理时间（Unix毫秒）';
@@ -0,0 +1,399 @@
+package redis
+
+import (
+	"container/list"
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	log "entry-task/pkg/logger"
+	"entry-task/tcpserver/internal/model"
+)
+
+// ============================================================================
+// 进程内用户缓存（L1）
+// ============================================================================
+//
+// 读取顺序：本地LRU -> Redis（L2）-> 数据库。
+// 本地缓存有界（按容量LRU淘汰）且带TTL；DeleteUser 除删除本实例和Redis中的缓存外，
+// 还会通过Redis Pub/Sub广播失效消息，所有实例收到后删除本地条目。
+// 失效消息可能在订阅断线期间丢失，此时本地TTL即为最大不一致时间。
+// 每个分片记录失效次数（generation），回填前后不一致时放弃回填，
+// 避免失效前从Redis读到的旧值在失效之后才写入本地。
+
+const (
+	// UserCacheInvalidateChannel 用户缓存失效广播频道（消息内容为用户ID）
+	UserCacheInvalidateChannel = "user_cache:invalidate"
+
+	// DefaultLocalCacheCapacity 默认本地缓存最大用户数
+	DefaultLocalCacheCapacity = 10000
+
+	// DefaultLocalCacheTTL 默认本地缓存有效期
+	DefaultLocalCacheTTL = 10 * time.Second
+
+	// DefaultLocalCacheStatsInterval 默认统计信息输出间隔
+	DefaultLocalCacheStatsInterval = time.Minute
+
+	// localCacheShards 分片数（降低锁竞争）
+	localCacheShards = 16
+
+	// invalidatePublishTimeout 发布失效消息超时时间
+	invalidatePublishTimeout = time.Second
+
+	// resubscribeDelay 订阅通道关闭后重新订阅的等待时间
+	resubscribeDelay = time.Second
+)
+
+// LocalCacheOptions 本地缓存配置
+type LocalCacheOptions struct {
+	Capacity      int           // 最大缓存用户数
+	TTL           time.Duration // 本地缓存有效期
+	StatsInterval time.Duration // 统计信息输出间隔，小于0时不输出
+}
+
+// withDefaults 未配置的字段使用默认值
+func (o LocalCacheOptions) withDefaults() LocalCacheOptions {
+	if o.Capacity <= 0 {
+		o.Capacity = DefaultLocalCacheCapacity
+	}
+	if o.TTL <= 0 {
+		o.TTL = DefaultLocalCacheTTL
+	}
+	if o.StatsInterval == 0 {
+		o.StatsInterval = DefaultLocalCacheStatsInterval
+	}
+	return o
+}
+
+// LocalCacheStats 本地缓存统计信息（计数器自创建起累计）
+type LocalCacheStats struct {
+	Hits          uint64 // 本地命中次数
+	Misses        uint64 // 本地未命中次数（含过期）
+	Evictions     uint64 // 因容量不足被淘汰的条目数
+	Expirations   uint64 // 因TTL过期被删除的条目数
+	Invalidations uint64 // 收到的失效消息数（含本实例发出的）
+	Size          int    // 当前条目数
+}
+
+// HitRatio 本地命中率
+func (s LocalCacheStats) HitRatio() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+// LocalUserCache 带本地缓存的用户缓存管理器
+type LocalUserCache interface {
+	UserCache
+
+	// Stats 获取本地缓存统计信息
+	Stats() LocalCacheStats
+}
+
+// localUserCache 本地缓存实现，包装Redis用户缓存
+type localUserCache struct {
+	client Client
+	next   UserCache
+	opts   LocalCacheOptions
+	shards [localCacheShards]*lruShard
+
+	hits          atomic.Uint64
+	misses        atomic.Uint64
+	evictions     atomic.Uint64
+	expirations   atomic.Uint64
+	invalidations atomic.Uint64
+}
+
+// NewLocalUserCache 创建带本地缓存的用户缓存管理器，并订阅失效广播
+// 订阅和统计协程在 ctx 取消后退出
+func NewLocalUserCache(ctx context.Context, client Client, next UserCache, opts LocalCacheOptions) LocalUserCache {
+	opts = opts.withDefaults()
+	c := &localUserCache{
+		client: client,
+		next:   next,
+		opts:   opts,
+	}
+
+	capacity := (opts.Capacity + localCacheShards - 1) / localCacheShards
+	for i := range c.shards {
+		c.shards[i] = newLRUShard(capacity)
+	}
+
+	go c.subscribeLoop(ctx)
+	if opts.StatsInterval > 0 {
+		go c.statsLoop(ctx)
+	}
+
+	return c
+}
+
+// GetUser 获取用户缓存：先查本地，未命中时查Redis并回填本地
+func (c *localUserCache) GetUser(ctx context.Context, userID uint64) (*CachedUser, error) {
+	user, hit, expired := c.shard(userID).get(userID, time.Now())
+	if expired {
+		c.expirations.Add(1)
+	}
+	if hit {
+		c.hits.Add(1)
+		userCacheRequests.WithLabelValues("local", cacheResultHit).Inc()
+		return user, nil
+	}
+	c.misses.Add(1)
+	userCacheRequests.WithLabelValues("local", cacheResultMiss).Inc()
+
+	gen := c.shard(userID).generation()
+	user, err := c.next.GetUser(ctx, userID)
+	if err != nil || user == nil {
+		return user, err
+	}
+
+	c.store(user, gen)
+	return user, nil
+}
+
+// SetUser 设置用户缓存（Redis和本地）
+func (c *localUserCache) SetUser(ctx context.Context, user *model.User) error {
+	gen := c.shard(user.ID).generation()
+	if err := c.next.SetUser(ctx, user); err != nil {
+		return err
+	}
+
+	c.store(&CachedUser{
+		ID:             user.ID,
+		Username:       user.Username,
+		Nickname:       user.Nickname,
+		ProfilePicture: user.ProfilePicture,
+		ExpiresAt:      time.Now().Add(UserCacheTTL).UnixMilli(),
+	}, gen)
+	return nil
+}
+
+// SetNullCache 设置负缓存（只写Redis，本地删除可能残留的条目）
+func (c *localUserCache) SetNullCache(ctx context.Context, userID uint64) error {
+	c.shard(userID).remove(userID)
+	return c.next.SetNullCache(ctx, userID)
+}
+
+// DeleteUser 删除用户缓存（本地和Redis），并广播失效消息
+func (c *localUserCache) DeleteUser(ctx context.Context, userID uint64) error {
+	c.shard(userID).remove(userID)
+
+	err := c.next.DeleteUser(ctx, userID)
+
+	// 广播失败不影响本次删除结果，其他实例的本地条目最迟在TTL后过期
+	pubCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), invalidatePublishTimeout)
+	defer cancel()
+	if pubErr := c.client.Publish(pubCtx, UserCacheInvalidateChannel, strconv.FormatUint(userID, 10)); pubErr != nil {
+		log.Warn("广播用户缓存失效消息失败",
+			zap.Uint64("user_id", userID),
+			zap.Error(pubErr),
+		)
+	}
+
+	return err
+}
+
+// Stats 获取本地缓存统计信息
+func (c *localUserCache) Stats() LocalCacheStats {
+	size := 0
+	for _, s := range c.shards {
+		size += s.len()
+	}
+	return LocalCacheStats{
+		Hits:          c.hits.Load(),
+		Misses:        c.misses.Load(),
+		Evictions:     c.evictions.Load(),
+		Expirations:   c.expirations.Load(),
+		Invalidations: c.invalidations.Load(),
+		Size:          size,
+	}
+}
+
+// store 写入本地缓存，gen 为读取 user 之前分片的失效次数，期间发生过失效时不写入
+func (c *localUserCache) store(user *CachedUser, gen uint64) {
+	if c.shard(user.ID).setIfGeneration(user, time.Now().Add(c.opts.TTL), gen) {
+		c.evictions.Add(1)
+	}
+}
+
+// shard 按用户ID选择分片
+func (c *localUserCache) shard(userID uint64) *lruShard {
+	return c.shards[userID%localCacheShards]
+}
+
+// subscribeLoop 订阅失效广播，通道关闭（取消订阅）后重新订阅，ctx 取消时取消订阅并退出
+func (c *localUserCache) subscribeLoop(ctx context.Context) {
+	for {
+		messages, unsubscribe := c.client.Subscribe(ctx, UserCacheInvalidateChannel)
+		stop := context.AfterFunc(ctx, func() { _ = unsubscribe() })
+		for msg := range messages {
+			c.handleInvalidate(msg)
+		}
+		stop()
+
+		if ctx.Err() != nil {
+			return
+		}
+		log.Warn("用户缓存失效订阅已断开，准备重新订阅")
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(resubscribeDelay):
+		}
+	}
+}
+
+// handleInvalidate 处理失效消息
+func (c *localUserCache) handleInvalidate(msg string) {
+	userID, err := strconv.ParseUint(msg, 10, 64)
+	if err != nil {
+		log.Warn("无效的用户缓存失效消息", zap.String("message", msg))
+		return
+	}
+
+	c.invalidations.Add(1)
+	c.shard(userID).remove(userID)
+}
+
+// statsLoop 定期输出统计信息，ctx 取消时退出
+func (c *localUserCache) statsLoop(ctx context.Context) {
+	ticker := time.NewTicker(c.opts.StatsInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		stats := c.Stats()
+		log.Info("本地用户缓存统计",
+			zap.Uint64("hits", stats.Hits),
+			zap.Uint64("misses", stats.Misses),
+			zap.Float64("hit_ratio", stats.HitRatio()),
+			zap.Uint64("evictions", stats.Evictions),
+			zap.Uint64("expirations", stats.Expirations),
+			zap.Uint64("invalidations", stats.Invalidations),
+			zap.Int("size", stats.Size),
+		)
+	}
+}
+
+// ============================================================================
+// LRU分片
+// ============================================================================
+
+// lruEntry 缓存条目
+type lruEntry struct {
+	user     CachedUser
+	expireAt time.Time
+}
+
+// lruShard 带TTL的LRU分片（链表头部为最近使用）
+type lruShard struct {
+	mu       sync.Mutex
+	capacity int
+	items    map[uint64]*list.Element
+	order    *list.List
+	gen      uint64 // 失效次数，每次 remove 加一
+}
+
+func newLRUShard(capacity int) *lruShard {
+	return &lruShard{
+		capacity: capacity,
+		items:    make(map[uint64]*list.Element, capacity),
+		order:    list.New(),
+	}
+}
+
+// get 获取条目副本，返回是否命中以及是否因过期被删除
+func (s *lruShard) get(userID uint64, now time.Time) (*CachedUser, bool, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elem, ok := s.items[userID]
+	if !ok {
+		return nil, false, false
+	}
+
+	entry := elem.Value.(*lruEntry)
+	if !now.Before(entry.expireAt) {
+		s.order.Remove(elem)
+		delete(s.items, userID)
+		return nil, false, true
+	}
+
+	s.order.MoveToFront(elem)
+	user := entry.user
+	return &user, true, false
+}
+
+// generation 当前失效次数
+func (s *lruShard) generation() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.gen
+}
+
+// set 写入条目，返回是否淘汰了最久未使用的条目
+func (s *lruShard) set(user *CachedUser, expireAt time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.setLocked(user, expireAt)
+}
+
+// setIfGeneration 失效次数仍为 gen 时写入条目（否则放弃），返回是否淘汰了最久未使用的条目
+func (s *lruShard) setIfGeneration(user *CachedUser, expireAt time.Time, gen uint64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.gen != gen {
+		return false
+	}
+	return s.setLocked(user, expireAt)
+}
+
+// setLocked 写入条目，调用方需持有锁
+func (s *lruShard) setLocked(user *CachedUser, expireAt time.Time) bool {
+	if elem, ok := s.items[user.ID]; ok {
+		entry := elem.Value.(*lruEntry)
+		entry.user = *user
+		entry.expireAt = expireAt
+		s.order.MoveToFront(elem)
+		return false
+	}
+
+	s.items[user.ID] = s.order.PushFront(&lruEntry{user: *user, expireAt: expireAt})
+	if s.order.Len() <= s.capacity {
+		return false
+	}
+
+	oldest := s.order.Back()
+	s.order.Remove(oldest)
+	delete(s.items, oldest.Value.(*lruEntry).user.ID)
+	return true
+}
+
+// remove 删除条目（条目不存在时同样计入失效次数，正在进行的回填会被放弃）
+func (s *lruShard) remove(userID uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.gen++
+	if elem, ok := s.items[userID]; ok {
+		s.order.Remove(elem)
+		delete(s.items, userID)
+	}
+}
+
+// len 当前条目数
+func (s *lruShard) len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.order.Len()
+}
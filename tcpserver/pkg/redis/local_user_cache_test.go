@@ -0,0 +1,331 @@
+package redis
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	log "entry-task/pkg/logger"
+	"entry-task/tcpserver/internal/model"
+)
+
+// fakePubSubClient 只实现 Publish/Subscribe 的内存Client，消息广播给所有订阅者
+type fakePubSubClient struct {
+	Client
+
+	mu   sync.Mutex
+	subs []chan string
+}
+
+func (f *fakePubSubClient) Publish(_ context.Context, _ string, message string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, ch := range f.subs {
+		ch <- message
+	}
+	return nil
+}
+
+func (f *fakePubSubClient) Subscribe(_ context.Context, _ string) (<-chan string, func() error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	ch := make(chan string, 16)
+	f.subs = append(f.subs, ch)
+
+	// 取消订阅：移除订阅者并关闭消息通道
+	var once sync.Once
+	return ch, func() error {
+		once.Do(func() {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			f.subs = slices.DeleteFunc(f.subs, func(c chan string) bool { return c == ch })
+			close(ch)
+		})
+		return nil
+	}
+}
+
+func (f *fakePubSubClient) subscribers() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.subs)
+}
+
+// countingUserCache 记录调用次数的内存UserCache（模拟Redis缓存）
+type countingUserCache struct {
+	mu    sync.Mutex
+	users map[uint64]CachedUser
+	gets  int
+
+	afterGet func() // GetUser 读取后、返回前调用（模拟读取期间收到失效消息）
+}
+
+func newCountingUserCache() *countingUserCache {
+	return &countingUserCache{users: make(map[uint64]CachedUser)}
+}
+
+func (c *countingUserCache) GetUser(_ context.Context, userID uint64) (*CachedUser, error) {
+	c.mu.Lock()
+	c.gets++
+	u, ok := c.users[userID]
+	afterGet := c.afterGet
+	c.mu.Unlock()
+
+	if afterGet != nil {
+		afterGet()
+	}
+	if ok {
+		return &u, nil
+	}
+	return nil, nil
+}
+
+func (c *countingUserCache) SetUser(_ context.Context, user *model.User) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.users[user.ID] = CachedUser{ID: user.ID, Username: user.Username, Nickname: user.Nickname}
+	return nil
+}
+
+func (c *countingUserCache) SetNullCache(context.Context, uint64) error { return nil }
+
+func (c *countingUserCache) DeleteUser(_ context.Context, userID uint64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.users, userID)
+	return nil
+}
+
+func (c *countingUserCache) getCount() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.gets
+}
+
+func newTestLocalCache(t *testing.T, client *fakePubSubClient, next UserCache, opts LocalCacheOptions) *localUserCache {
+	t.Helper()
+	log.Logger = zap.NewNop()
+
+	opts.StatsInterval = -1
+	before := client.subscribers()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	c := NewLocalUserCache(ctx, client, next, opts).(*localUserCache)
+
+	// 等待订阅建立
+	deadline := time.Now().Add(time.Second)
+	for client.subscribers() == before {
+		if time.Now().After(deadline) {
+			t.Fatal("订阅未建立")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return c
+}
+
+// TestLocalUserCache_HitAfterFill 测试Redis命中后回填本地，后续读取不再访问Redis
+func TestLocalUserCache_HitAfterFill(t *testing.T) {
+	next := newCountingUserCache()
+	c := newTestLocalCache(t, &fakePubSubClient{}, next, LocalCacheOptions{})
+	ctx := context.Background()
+
+	_ = next.SetUser(ctx, &model.User{ID: 1, Username: "alice", Nickname: "A"})
+
+	for i := 0; i < 3; i++ {
+		user, err := c.GetUser(ctx, 1)
+		if err != nil || user == nil || user.Nickname != "A" {
+			t.Fatalf("第%d次读取结果错误: %+v, %v", i, user, err)
+		}
+	}
+
+	if got := next.getCount(); got != 1 {
+		t.Errorf("期望访问Redis 1次, 实际 %d", got)
+	}
+	stats := c.Stats()
+	if stats.Hits != 2 || stats.Misses != 1 || stats.Size != 1 {
+		t.Errorf("统计信息错误: %+v", stats)
+	}
+}
+
+// TestLocalUserCache_ReturnsCopy 测试调用方修改返回值不影响缓存
+func TestLocalUserCache_ReturnsCopy(t *testing.T) {
+	c := newTestLocalCache(t, &fakePubSubClient{}, newCountingUserCache(), LocalCacheOptions{})
+	ctx := context.Background()
+
+	_ = c.SetUser(ctx, &model.User{ID: 1, Nickname: "A"})
+	user, _ := c.GetUser(ctx, 1)
+	user.Nickname = "B"
+
+	if user, _ = c.GetUser(ctx, 1); user.Nickname != "A" {
+		t.Errorf("缓存被调用方修改: %+v", user)
+	}
+}
+
+// TestLocalUserCache_TTL 测试本地条目过期后回源Redis
+func TestLocalUserCache_TTL(t *testing.T) {
+	next := newCountingUserCache()
+	c := newTestLocalCache(t, &fakePubSubClient{}, next, LocalCacheOptions{TTL: 20 * time.Millisecond})
+	ctx := context.Background()
+
+	_ = c.SetUser(ctx, &model.User{ID: 1, Nickname: "A"})
+	time.Sleep(30 * time.Millisecond)
+
+	if _, _ = c.GetUser(ctx, 1); next.getCount() != 1 {
+		t.Errorf("过期后应回源Redis")
+	}
+	if stats := c.Stats(); stats.Expirations != 1 {
+		t.Errorf("期望过期1次, 实际 %d", stats.Expirations)
+	}
+}
+
+// TestLRUShard_Eviction 测试超出容量时淘汰最久未使用的条目
+func TestLRUShard_Eviction(t *testing.T) {
+	s := newLRUShard(2)
+	now := time.Now()
+	exp := now.Add(time.Minute)
+
+	s.set(&CachedUser{ID: 1}, exp)
+	s.set(&CachedUser{ID: 2}, exp)
+	s.get(1, now) // 1 变为最近使用
+
+	if evicted := s.set(&CachedUser{ID: 3}, exp); !evicted {
+		t.Fatal("超出容量时应淘汰条目")
+	}
+	if _, hit, _ := s.get(2, now); hit {
+		t.Error("最久未使用的条目2应被淘汰")
+	}
+	if _, hit, _ := s.get(1, now); !hit {
+		t.Error("条目1应保留")
+	}
+	if s.len() != 2 {
+		t.Errorf("期望2个条目, 实际 %d", s.len())
+	}
+}
+
+// TestLocalUserCache_CrossInstanceInvalidation 测试一个实例删除缓存后，其他实例的本地条目被清除
+func TestLocalUserCache_CrossInstanceInvalidation(t *testing.T) {
+	client := &fakePubSubClient{}
+	shared := newCountingUserCache()
+	a := newTestLocalCache(t, client, shared, LocalCacheOptions{})
+	b := newTestLocalCache(t, client, shared, LocalCacheOptions{})
+	ctx := context.Background()
+
+	_ = a.SetUser(ctx, &model.User{ID: 7, Nickname: "old"})
+	if user, _ := b.GetUser(ctx, 7); user == nil || user.Nickname != "old" {
+		t.Fatalf("实例B应读到缓存: %+v", user)
+	}
+
+	if err := a.DeleteUser(ctx, 7); err != nil {
+		t.Fatalf("删除失败: %v", err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for b.Stats().Size != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("实例B的本地条目未被失效")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if user, _ := b.GetUser(ctx, 7); user != nil {
+		t.Errorf("失效后不应读到旧值: %+v", user)
+	}
+}
+
+// TestLocalUserCache_InvalidMessage 测试非法失效消息被忽略
+func TestLocalUserCache_InvalidMessage(t *testing.T) {
+	c := newTestLocalCache(t, &fakePubSubClient{}, newCountingUserCache(), LocalCacheOptions{})
+	_ = c.SetUser(context.Background(), &model.User{ID: 1})
+
+	c.handleInvalidate("not-a-number")
+	c.handleInvalidate(strconv.FormatUint(2, 10))
+
+	if stats := c.Stats(); stats.Size != 1 || stats.Invalidations != 1 {
+		t.Errorf("统计信息错误: %+v", stats)
+	}
+}
+
+// TestLocalUserCache_InvalidateDuringFill 测试从Redis读取期间收到失效消息时，读到的旧值不回填本地
+func TestLocalUserCache_InvalidateDuringFill(t *testing.T) {
+	next := newCountingUserCache()
+	next.users[7] = CachedUser{ID: 7, Nickname: "old"}
+	c := newTestLocalCache(t, &fakePubSubClient{}, next, LocalCacheOptions{})
+	ctx := context.Background()
+
+	next.afterGet = func() { c.handleInvalidate("7") }
+	if user, _ := c.GetUser(ctx, 7); user == nil || user.Nickname != "old" {
+		t.Fatalf("本次读取应返回Redis中的值: %+v", user)
+	}
+	if size := c.Stats().Size; size != 0 {
+		t.Fatalf("读取期间发生失效，不应回填本地, size=%d", size)
+	}
+
+	// 失效之后的读取正常回填
+	next.afterGet = nil
+	_, _ = c.GetUser(ctx, 7)
+	_, _ = c.GetUser(ctx, 7)
+	if gets := next.getCount(); gets != 2 {
+		t.Errorf("Redis读取次数 = %d, 期望 2", gets)
+	}
+}
+
+// TestLRUShard_SetIfGeneration 测试失效次数变化后放弃写入
+func TestLRUShard_SetIfGeneration(t *testing.T) {
+	s := newLRUShard(2)
+	exp := time.Now().Add(time.Minute)
+
+	gen := s.generation()
+	s.remove(1) // 条目不存在时同样计入失效
+	s.setIfGeneration(&CachedUser{ID: 1}, exp, gen)
+	if s.len() != 0 {
+		t.Fatal("失效后不应写入旧值")
+	}
+
+	s.setIfGeneration(&CachedUser{ID: 1}, exp, s.generation())
+	if s.len() != 1 {
+		t.Error("失效次数未变化时应写入")
+	}
+}
+
+// TestLocalUserCache_Stop 测试 ctx 取消后取消订阅，订阅和统计协程退出
+func TestLocalUserCache_Stop(t *testing.T) {
+	client := &fakePubSubClient{}
+	c := newTestLocalCache(t, client, newCountingUserCache(), LocalCacheOptions{})
+	c.opts.StatsInterval = time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	before := client.subscribers()
+	subDone, statsDone := make(chan struct{}), make(chan struct{})
+	go func() {
+		c.subscribeLoop(ctx)
+		close(subDone)
+	}()
+	go func() {
+		c.statsLoop(ctx)
+		close(statsDone)
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for client.subscribers() == before {
+		if time.Now().After(deadline) {
+			t.Fatal("订阅未建立")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+
+	for name, done := range map[string]chan struct{}{"订阅": subDone, "统计": statsDone} {
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatalf("ctx 取消后%s协程应退出", name)
+		}
+	}
+	if client.subscribers() != before {
+		t.Errorf("ctx 取消后应取消订阅, 订阅者 %d, 期望 %d", client.subscribers(), before)
+	}
+}
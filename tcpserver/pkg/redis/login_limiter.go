@@ -10,7 +10,7 @@ import (
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 
-	log "entry-task/tcpserver/pkg/logger"
+	log "entry-task/pkg/logger"
 )
 
 const (
@@ -37,6 +37,9 @@ type LoginLimiter interface {
 
 	// ResetLoginFail 重置登录失败计数（登录成功后调用）
 	ResetLoginFail(ctx context.Context, username string) error
+
+	// GetLockoutTTL 获取登录失败计数的剩余有效期（即被锁定时距离解锁的时间）
+	GetLockoutTTL(ctx context.Context, username string) (time.Duration, error)
 }
 
 // loginLimiter 登录限制器实现
@@ -56,13 +59,18 @@ func (ll *loginLimiter) RecordLoginFail(ctx context.Context, username string) (i
 
 	count, err := ll.client.Incr(ctx, key)
 	if err != nil {
-		log.Error("记录登录失败次数失败", zap.Error(err), zap.String("username", username))
+		log.ErrorCtx(ctx, "记录登录失败次数失败", zap.Error(err), zap.String("username", username))
 		return 0, err
 	}
 
+	loginFailures.Inc()
+	if count == MaxLoginAttempts {
+		loginLockouts.Inc()
+	}
+
 	if count == 1 {
 		if err := ll.client.Expire(ctx, key, LoginFailTTL); err != nil {
-			log.Error("设置登录失败计数过期时间失败",
+			log.ErrorCtx(ctx, "设置登录失败计数过期时间失败",
 				zap.Error(err),
 				zap.String("username", username),
 				zap.String("key", key))
@@ -70,7 +78,7 @@ func (ll *loginLimiter) RecordLoginFail(ctx context.Context, username string) (i
 		}
 	}
 
-	log.Warn("记录登录失败", zap.String("username", username), zap.Int64("fail_count", count))
+	log.WarnCtx(ctx, "记录登录失败", zap.String("username", username), zap.Int64("fail_count", count))
 	return count, nil
 }
 
@@ -88,7 +96,7 @@ func (ll *loginLimiter) GetLoginFailCount(ctx context.Context, username string)
 
 	count, err := strconv.ParseInt(countStr, 10, 64)
 	if err != nil {
-		log.Error("解析登录失败计数失败",
+		log.ErrorCtx(ctx, "解析登录失败计数失败",
 			zap.Error(err),
 			zap.String("username", username),
 			zap.String("count_str", countStr))
@@ -106,7 +114,7 @@ func (ll *loginLimiter) IsLoginAllowed(ctx context.Context, username string) (bo
 
 	allowed := count < MaxLoginAttempts
 	if !allowed {
-		log.Warn("登录尝试次数过多", zap.String("username", username), zap.Int64("fail_count", count))
+		log.WarnCtx(ctx, "登录尝试次数过多", zap.String("username", username), zap.Int64("fail_count", count))
 	}
 	return allowed, nil
 }
@@ -114,11 +122,26 @@ func (ll *loginLimiter) IsLoginAllowed(ctx context.Context, username string) (bo
 // ResetLoginFail 重置登录失败计数
 func (ll *loginLimiter) ResetLoginFail(ctx context.Context, username string) error {
 	key := LoginFailKeyPrefix + username
-	err := ll.client.Del(ctx, key)
+	_, err := ll.client.Del(ctx, key)
 	if err != nil {
-		log.Error("重置登录失败计数失败", zap.Error(err), zap.String("username", username))
+		log.ErrorCtx(ctx, "重置登录失败计数失败", zap.Error(err), zap.String("username", username))
 		return err
 	}
-	log.Info("重置登录失败计数", zap.String("username", username))
+	log.InfoCtx(ctx, "重置登录失败计数", zap.String("username", username))
 	return nil
 }
+
+// GetLockoutTTL 获取登录失败计数的剩余有效期
+// 计数不存在或未设置过期时间时返回 0
+func (ll *loginLimiter) GetLockoutTTL(ctx context.Context, username string) (time.Duration, error) {
+	key := LoginFailKeyPrefix + username
+	ttl, err := ll.client.TTL(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	// go-redis 对不存在的键返回 -2，对未设置过期时间的键返回 -1
+	if ttl < 0 {
+		return 0, nil
+	}
+	return ttl, nil
+}
@@ -1,5 +1,14 @@
 package redis
 
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	"entry-task/tcpserver/config"
+	"entry-task/tcpserver/pkg/token"
+)
+
 // Manager Redis统一管理器接口
 type Manager interface {
 	// GetClient 获取基础Redis客户端
@@ -13,6 +22,12 @@ type Manager interface {
 
 	// GetUserCache 获取用户缓存管理器
 	GetUserCache() UserCache
+
+	// GetPrimarySticky 获取主库标记管理器（读写分离时的读己之写）
+	GetPrimarySticky() PrimarySticky
+
+	// Close 停止后台任务（吊销列表同步等），不关闭Redis连接
+	Close()
 }
 
 // manager Redis统一管理器实现
@@ -21,15 +36,79 @@ type manager struct {
 	session      SessionManager
 	loginLimiter LoginLimiter
 	userCache    UserCache
+	sticky       PrimarySticky
+	cancel       context.CancelFunc
 }
 
 // NewManager 创建Redis管理器
-func NewManager(client Client) Manager {
+func NewManager(client Client, cfg *config.Config) (Manager, error) {
+	// 后台任务随 Close 停止
+	ctx, cancel := context.WithCancel(context.Background())
+
+	session, err := newSessionManagerFromConfig(ctx, client, &cfg.Session)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	userCache := NewUserCache(client)
+	if local := cfg.UserCache.Local; local.Enabled {
+		userCache = NewLocalUserCache(ctx, client, userCache, LocalCacheOptions{
+			Capacity:      local.Capacity,
+			TTL:           local.GetTTL(),
+			StatsInterval: local.GetStatsInterval(),
+		})
+	}
+
 	return &manager{
 		client:       client,
-		session:      NewSessionManager(client),
+		session:      session,
 		loginLimiter: NewLoginLimiter(client),
-		userCache:    NewUserCache(client),
+		userCache:    userCache,
+		sticky:       NewPrimarySticky(client, cfg.Database.GetPrimarySticky()),
+		cancel:       cancel,
+	}, nil
+}
+
+// newSessionManagerFromConfig 按配置的Session模式创建Session管理器
+func newSessionManagerFromConfig(ctx context.Context, client Client, cfg *config.SessionConfig) (SessionManager, error) {
+	opts := SessionOptions{
+		IdleTTL:         cfg.GetIdleTimeout(),
+		MaxLifetime:     cfg.GetMaxLifetime(),
+		RefreshInterval: cfg.GetRefreshInterval(),
+	}
+
+	switch cfg.Mode {
+	case "", config.SessionModeRedis:
+		return NewSessionManager(client, opts), nil
+
+	case config.SessionModeToken:
+		keys := make([]token.Key, 0, len(cfg.Token.Keys))
+		for _, k := range cfg.Token.Keys {
+			encoded, err := k.GetSecret()
+			if err != nil {
+				return nil, fmt.Errorf("读取签名密钥 %s 失败: %w", k.ID, err)
+			}
+			secret, err := base64.StdEncoding.DecodeString(encoded)
+			if err != nil {
+				return nil, fmt.Errorf("签名密钥 %s 不是有效的base64: %w", k.ID, err)
+			}
+			keys = append(keys, token.Key{ID: k.ID, Secret: secret})
+		}
+
+		signer, err := token.NewSigner(cfg.Token.Algorithm, cfg.Token.ActiveKeyID, keys)
+		if err != nil {
+			return nil, fmt.Errorf("初始化Token签名失败: %w", err)
+		}
+
+		return NewTokenSessionManager(ctx, client, opts, TokenOptions{
+			Signer:                 signer,
+			AccessTTL:              cfg.Token.GetAccessTTL(),
+			RevocationSyncInterval: cfg.Token.GetRevocationSyncInterval(),
+		}), nil
+
+	default:
+		return nil, fmt.Errorf("不支持的Session模式: %s", cfg.Mode)
 	}
 }
 
@@ -52,3 +131,13 @@ func (m *manager) GetLoginLimiter() LoginLimiter {
 func (m *manager) GetUserCache() UserCache {
 	return m.userCache
 }
+
+// GetPrimarySticky 获取主库标记管理器
+func (m *manager) GetPrimarySticky() PrimarySticky {
+	return m.sticky
+}
+
+// Close 停止后台任务
+func (m *manager) Close() {
+	m.cancel()
+}
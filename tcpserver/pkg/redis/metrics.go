@@ -0,0 +1,82 @@
+package redis
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// ============================================================================
+// Prometheus 指标
+// ============================================================================
+
+// 用户缓存查询结果
+const (
+	cacheResultHit         = "hit"
+	cacheResultMiss        = "miss"
+	cacheResultNegativeHit = "negative_hit" // 命中负缓存（用户不存在）
+	cacheResultError       = "error"
+)
+
+var (
+	// userCacheRequests 用户缓存查询数（layer: local 本地缓存 / redis）
+	userCacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "tcpserver_user_cache_requests_total",
+		Help: "用户缓存查询数",
+	}, []string{"layer", "result"})
+
+	// loginFailures 登录失败次数
+	loginFailures = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "tcpserver_login_failures_total",
+		Help: "登录失败次数",
+	})
+
+	// loginLockouts 登录失败次数达到上限、账号被暂时锁定的次数
+	loginLockouts = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "tcpserver_login_lockouts_total",
+		Help: "登录失败次数达到上限被锁定的次数",
+	})
+)
+
+// ============================================================================
+// 连接池指标
+// ============================================================================
+
+var (
+	poolConnectionsDesc = prometheus.NewDesc("tcpserver_redis_pool_connections",
+		"Redis连接池连接数", []string{"state"}, nil)
+	poolHitsDesc = prometheus.NewDesc("tcpserver_redis_pool_hits_total",
+		"从Redis连接池获取到空闲连接的次数", nil, nil)
+	poolMissesDesc = prometheus.NewDesc("tcpserver_redis_pool_misses_total",
+		"Redis连接池没有空闲连接、需要新建连接的次数", nil, nil)
+	poolTimeoutsDesc = prometheus.NewDesc("tcpserver_redis_pool_timeouts_total",
+		"等待Redis连接池连接超时的次数", nil, nil)
+)
+
+// poolCollector Redis连接池指标采集器（采集时读取 PoolStats）
+type poolCollector struct {
+	client Client
+}
+
+// NewPoolCollector 创建Redis连接池指标采集器
+func NewPoolCollector(client Client) prometheus.Collector {
+	return &poolCollector{client: client}
+}
+
+// Describe 实现 prometheus.Collector
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- poolConnectionsDesc
+	ch <- poolHitsDesc
+	ch <- poolMissesDesc
+	ch <- poolTimeoutsDesc
+}
+
+// Collect 实现 prometheus.Collector
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.client.PoolStats()
+	ch <- prometheus.MustNewConstMetric(poolConnectionsDesc, prometheus.GaugeValue, float64(stats.TotalConns), "total")
+	ch <- prometheus.MustNewConstMetric(poolConnectionsDesc, prometheus.GaugeValue, float64(stats.IdleConns), "idle")
+	ch <- prometheus.MustNewConstMetric(poolConnectionsDesc, prometheus.GaugeValue, float64(stats.StaleConns), "stale")
+	ch <- prometheus.MustNewConstMetric(poolHitsDesc, prometheus.CounterValue, float64(stats.Hits))
+	ch <- prometheus.MustNewConstMetric(poolMissesDesc, prometheus.CounterValue, float64(stats.Misses))
+	ch <- prometheus.MustNewConstMetric(poolTimeoutsDesc, prometheus.CounterValue, float64(stats.Timeouts))
+}
@@ -0,0 +1,52 @@
+package redis
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/redis/go-redis/v9"
+)
+
+// fakePoolStatsClient 只实现 PoolStats 的Client
+type fakePoolStatsClient struct {
+	Client
+	stats redis.PoolStats
+}
+
+func (c *fakePoolStatsClient) PoolStats() *redis.PoolStats {
+	return &c.stats
+}
+
+// TestPoolCollector 测试采集时读取Redis连接池统计
+func TestPoolCollector(t *testing.T) {
+	client := &fakePoolStatsClient{stats: redis.PoolStats{
+		Hits: 7, Misses: 2, Timeouts: 1,
+		TotalConns: 5, IdleConns: 3, StaleConns: 0,
+	}}
+	c := NewPoolCollector(client)
+
+	expected := `
+# HELP tcpserver_redis_pool_connections Redis连接池连接数
+# TYPE tcpserver_redis_pool_connections gauge
+tcpserver_redis_pool_connections{state="idle"} 3
+tcpserver_redis_pool_connections{state="stale"} 0
+tcpserver_redis_pool_connections{state="total"} 5
+# HELP tcpserver_redis_pool_hits_total 从Redis连接池获取到空闲连接的次数
+# TYPE tcpserver_redis_pool_hits_total counter
+tcpserver_redis_pool_hits_total 7
+# HELP tcpserver_redis_pool_timeouts_total 等待Redis连接池连接超时的次数
+# TYPE tcpserver_redis_pool_timeouts_total counter
+tcpserver_redis_pool_timeouts_total 1
+`
+	if err := testutil.CollectAndCompare(c, strings.NewReader(expected),
+		"tcpserver_redis_pool_connections", "tcpserver_redis_pool_hits_total", "tcpserver_redis_pool_timeouts_total"); err != nil {
+		t.Error(err)
+	}
+
+	// 统计变化后再次采集应读取最新值
+	client.stats.Misses = 4
+	if n := testutil.CollectAndCount(c, "tcpserver_redis_pool_misses_total"); n != 1 {
+		t.Errorf("misses 指标数量期望 1, 实际 %d", n)
+	}
+}
@@ -0,0 +1,64 @@
+package redis
+
+import (
+	"context"
+	"time"
+)
+
+// ============================================================================
+// 读己之写：写入后固定读主库
+// ============================================================================
+//
+// 写入用户数据前在Redis中标记该用户，标记有效期内该用户的数据库读请求走主库，
+// 避免从库复制延迟导致用户看不到自己的修改（或将旧数据回填到缓存）。
+// 标记保存在Redis中，对所有实例生效。
+
+const (
+	// PrimaryStickyKeyPrefix 主库标记键前缀
+	PrimaryStickyKeyPrefix = "db_sticky:"
+
+	// DefaultPrimaryStickyTTL 默认标记有效期（应大于主从复制延迟和延迟双删的时间）
+	DefaultPrimaryStickyTTL = 5 * time.Second
+)
+
+// PrimarySticky 主库标记管理器接口
+type PrimarySticky interface {
+	// Mark 标记键在有效期内读主库
+	Mark(ctx context.Context, keys ...string) error
+
+	// IsMarked 检查键是否被标记
+	IsMarked(ctx context.Context, key string) (bool, error)
+}
+
+// primarySticky 主库标记管理器实现
+type primarySticky struct {
+	client Client
+	ttl    time.Duration
+}
+
+// NewPrimarySticky 创建主库标记管理器，ttl 小于等于0时使用默认值
+func NewPrimarySticky(client Client, ttl time.Duration) PrimarySticky {
+	if ttl <= 0 {
+		ttl = DefaultPrimaryStickyTTL
+	}
+	return &primarySticky{client: client, ttl: ttl}
+}
+
+// Mark 标记键在有效期内读主库
+func (p *primarySticky) Mark(ctx context.Context, keys ...string) error {
+	for _, key := range keys {
+		if err := p.client.Set(ctx, PrimaryStickyKeyPrefix+key, "1", p.ttl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsMarked 检查键是否被标记
+func (p *primarySticky) IsMarked(ctx context.Context, key string) (bool, error) {
+	n, err := p.client.Exists(ctx, PrimaryStickyKeyPrefix+key)
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
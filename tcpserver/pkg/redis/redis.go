@@ -9,8 +9,8 @@ import (
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 
+	log "entry-task/pkg/logger"
 	"entry-task/tcpserver/config"
-	log "entry-task/tcpserver/pkg/logger"
 )
 
 // Client Redis客户端接口
@@ -24,8 +24,8 @@ type Client interface {
 	// GetUint64 获取uint64类型的值
 	GetUint64(ctx context.Context, key string) (uint64, error)
 
-	// Del 删除一个或多个键
-	Del(ctx context.Context, keys ...string) error
+	// Del 删除一个或多个键，返回实际删除的键数量（不存在的键不计入）
+	Del(ctx context.Context, keys ...string) (int64, error)
 
 	// Exists 检查键是否存在
 	Exists(ctx context.Context, keys ...string) (int64, error)
@@ -42,6 +42,39 @@ type Client interface {
 	// IncrBy 将键的值增加指定数值
 	IncrBy(ctx context.Context, key string, value int64) (int64, error)
 
+	// SAdd 向集合添加成员
+	SAdd(ctx context.Context, key string, members ...interface{}) error
+
+	// SRem 从集合移除成员
+	SRem(ctx context.Context, key string, members ...interface{}) error
+
+	// SMembers 获取集合所有成员
+	SMembers(ctx context.Context, key string) ([]string, error)
+
+	// HSet 设置哈希字段（values 为 field, value 交替排列）
+	HSet(ctx context.Context, key string, values ...interface{}) error
+
+	// HGetAll 获取哈希的所有字段
+	HGetAll(ctx context.Context, key string) (map[string]string, error)
+
+	// ZAdd 向有序集合添加成员
+	ZAdd(ctx context.Context, key string, score float64, member string) error
+
+	// ZRangeByScoreWithScores 按分数范围获取有序集合成员及分数（min/max 支持 "-inf"、"+inf"、"(" 开区间）
+	ZRangeByScoreWithScores(ctx context.Context, key string, min, max string) ([]redis.Z, error)
+
+	// ZRemRangeByScore 按分数范围删除有序集合成员
+	ZRemRangeByScore(ctx context.Context, key string, min, max string) error
+
+	// RunScript 执行Lua脚本（优先EVALSHA，脚本未缓存时回退EVAL），脚本内的多个命令原子执行
+	RunScript(ctx context.Context, script *redis.Script, keys []string, args ...interface{}) (interface{}, error)
+
+	// Publish 向频道发布消息
+	Publish(ctx context.Context, channel string, message string) error
+
+	// Subscribe 订阅频道，返回消息通道（连接断开时自动重连）和取消订阅函数
+	Subscribe(ctx context.Context, channel string) (<-chan string, func() error)
+
 	// SetJSON 设置JSON格式的值
 	SetJSON(ctx context.Context, key string, value interface{}, expiration time.Duration) error
 
@@ -51,6 +84,9 @@ type Client interface {
 	// Ping 测试Redis连接
 	Ping(ctx context.Context) error
 
+	// PoolStats 获取连接池统计信息
+	PoolStats() *redis.PoolStats
+
 	// Close 关闭Redis连接
 	Close() error
 }
@@ -79,6 +115,7 @@ func InitRedis(cfg *config.Config) (Client, error) {
 		ReadTimeout:  cfg.Redis.GetReadTimeout(),
 		WriteTimeout: cfg.Redis.GetWriteTimeout(),
 	})
+	client.AddHook(newTracingHook(nil, cfg.Redis.GetAddr()))
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -112,8 +149,8 @@ func (r *redisClient) GetUint64(ctx context.Context, key string) (uint64, error)
 }
 
 // Del 删除一个或多个键
-func (r *redisClient) Del(ctx context.Context, keys ...string) error {
-	return r.client.Del(ctx, keys...).Err()
+func (r *redisClient) Del(ctx context.Context, keys ...string) (int64, error) {
+	return r.client.Del(ctx, keys...).Result()
 }
 
 // Exists 检查键是否存在
@@ -141,6 +178,69 @@ func (r *redisClient) IncrBy(ctx context.Context, key string, value int64) (int6
 	return r.client.IncrBy(ctx, key, value).Result()
 }
 
+// SAdd 向集合添加成员
+func (r *redisClient) SAdd(ctx context.Context, key string, members ...interface{}) error {
+	return r.client.SAdd(ctx, key, members...).Err()
+}
+
+// SRem 从集合移除成员
+func (r *redisClient) SRem(ctx context.Context, key string, members ...interface{}) error {
+	return r.client.SRem(ctx, key, members...).Err()
+}
+
+// SMembers 获取集合所有成员
+func (r *redisClient) SMembers(ctx context.Context, key string) ([]string, error) {
+	return r.client.SMembers(ctx, key).Result()
+}
+
+// HSet 设置哈希字段
+func (r *redisClient) HSet(ctx context.Context, key string, values ...interface{}) error {
+	return r.client.HSet(ctx, key, values...).Err()
+}
+
+// HGetAll 获取哈希的所有字段
+func (r *redisClient) HGetAll(ctx context.Context, key string) (map[string]string, error) {
+	return r.client.HGetAll(ctx, key).Result()
+}
+
+// ZAdd 向有序集合添加成员
+func (r *redisClient) ZAdd(ctx context.Context, key string, score float64, member string) error {
+	return r.client.ZAdd(ctx, key, redis.Z{Score: score, Member: member}).Err()
+}
+
+// ZRangeByScoreWithScores 按分数范围获取有序集合成员及分数
+func (r *redisClient) ZRangeByScoreWithScores(ctx context.Context, key string, min, max string) ([]redis.Z, error) {
+	return r.client.ZRangeByScoreWithScores(ctx, key, &redis.ZRangeBy{Min: min, Max: max}).Result()
+}
+
+// ZRemRangeByScore 按分数范围删除有序集合成员
+func (r *redisClient) ZRemRangeByScore(ctx context.Context, key string, min, max string) error {
+	return r.client.ZRemRangeByScore(ctx, key, min, max).Err()
+}
+
+// RunScript 执行Lua脚本
+func (r *redisClient) RunScript(ctx context.Context, script *redis.Script, keys []string, args ...interface{}) (interface{}, error) {
+	return script.Run(ctx, r.client, keys, args...).Result()
+}
+
+// Publish 向频道发布消息
+func (r *redisClient) Publish(ctx context.Context, channel string, message string) error {
+	return r.client.Publish(ctx, channel, message).Err()
+}
+
+// Subscribe 订阅频道
+func (r *redisClient) Subscribe(ctx context.Context, channel string) (<-chan string, func() error) {
+	pubsub := r.client.Subscribe(ctx, channel)
+	messages := make(chan string)
+	go func() {
+		defer close(messages)
+		for msg := range pubsub.Channel() {
+			messages <- msg.Payload
+		}
+	}()
+	return messages, pubsub.Close
+}
+
 // SetJSON 设置JSON格式的值
 func (r *redisClient) SetJSON(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
 	data, err := json.Marshal(value)
@@ -171,6 +271,11 @@ func (r *redisClient) Ping(ctx context.Context) error {
 	return r.client.Ping(ctx).Err()
 }
 
+// PoolStats 获取连接池统计信息
+func (r *redisClient) PoolStats() *redis.PoolStats {
+	return r.client.PoolStats()
+}
+
 // Close 关闭Redis连接
 func (r *redisClient) Close() error {
 	return r.client.Close()
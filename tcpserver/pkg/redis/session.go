@@ -2,27 +2,108 @@ package redis
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 
-	log "entry-task/tcpserver/pkg/logger"
+	log "entry-task/pkg/logger"
 )
 
 const (
-	// SessionTTL Session默认过期时间（2小时）
+	// SessionTTL Session默认空闲过期时间（2小时，有活动时滑动续期）
 	SessionTTL = 2 * time.Hour
 
+	// DefaultSessionMaxLifetime Session默认绝对最长有效期（7天，超过后无论是否活跃都需重新登录）
+	DefaultSessionMaxLifetime = 7 * 24 * time.Hour
+
+	// DefaultSessionRefreshInterval 默认续期最小间隔（避免每个请求都写Redis）
+	DefaultSessionRefreshInterval = time.Minute
+
 	// SessionKeyPrefix Session键前缀
 	SessionKeyPrefix = "sess:"
+
+	// SessionMetaKeyPrefix Session元数据键前缀（Hash）
+	SessionMetaKeyPrefix = "sess_meta:"
+
+	// UserSessionsKeyPrefix 用户Session索引键前缀（Set，成员为token）
+	UserSessionsKeyPrefix = "user_sess:"
+
+	// sessionIDLength 对外展示的Session ID长度（token哈希的前缀）
+	sessionIDLength = 16
+)
+
+var (
+	// ErrSessionNotFound Session不存在或不属于该用户
+	ErrSessionNotFound = errors.New("Session不存在")
+
+	// ErrRefreshTokenInvalid 刷新凭证无效、已过期或已被使用
+	ErrRefreshTokenInvalid = errors.New("刷新凭证无效")
+
+	// ErrRenewNotSupported 当前Session模式不支持刷新凭证
+	ErrRenewNotSupported = errors.New("当前Session模式不支持刷新凭证")
 )
 
+// ClientInfo 客户端信息（由HTTP网关通过gRPC metadata透传）
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+}
+
+// IssuedSession 新签发的登录凭证
+type IssuedSession struct {
+	Token        string        // 访问凭证（redis模式为Session Token，token模式为签名Access Token）
+	RefreshToken string        // 刷新凭证（仅token模式）
+	ExpiresIn    time.Duration // 访问凭证有效期
+
+	RefreshExpiresIn time.Duration // 刷新凭证有效期（仅token模式）
+}
+
+// SessionInfo Session元数据
+type SessionInfo struct {
+	ID         string // Session ID（不暴露token本身）
+	CreatedAt  int64  // 创建时间（Unix秒）
+	LastSeenAt int64  // 最近活跃时间（Unix秒）
+	ClientIP   string
+	UserAgent  string
+	Current    bool // 是否为发起查询的Session
+}
+
+// SessionOptions Session有效期配置
+type SessionOptions struct {
+	IdleTTL         time.Duration // 空闲过期时间（滑动窗口）
+	MaxLifetime     time.Duration // 绝对最长有效期（从登录时算起）
+	RefreshInterval time.Duration // 同一Session两次续期的最小间隔
+}
+
+// withDefaults 未配置的字段使用默认值
+func (o SessionOptions) withDefaults() SessionOptions {
+	if o.IdleTTL <= 0 {
+		o.IdleTTL = SessionTTL
+	}
+	if o.MaxLifetime <= 0 {
+		o.MaxLifetime = DefaultSessionMaxLifetime
+	}
+	if o.MaxLifetime < o.IdleTTL {
+		o.MaxLifetime = o.IdleTTL
+	}
+	if o.RefreshInterval <= 0 {
+		o.RefreshInterval = DefaultSessionRefreshInterval
+	}
+	return o
+}
+
 // SessionManager Session管理器接口
 type SessionManager interface {
-	// CreateSession 创建Session（生成token并存储到Redis）
-	CreateSession(ctx context.Context, userID uint64) (string, error)
+	// CreateSession 创建Session（签发登录凭证，同时记录客户端信息）
+	CreateSession(ctx context.Context, userID uint64, client *ClientInfo) (*IssuedSession, error)
 
 	// ValidateSession 验证Session（根据token获取userID）
 	ValidateSession(ctx context.Context, token string) (uint64, error)
@@ -30,34 +111,103 @@ type SessionManager interface {
 	// DestroySession 销毁Session（登出时删除token）
 	DestroySession(ctx context.Context, token string) error
 
-	// RefreshSession 刷新Session（延长有效期）
-	RefreshSession(ctx context.Context, token string) error
+	// RefreshSession 滑动续期并更新最近活跃时间（按 RefreshInterval 节流，不超过绝对最长有效期）
+	// 返回续期后的剩余有效期，本次未实际续期时返回0；Session已过期或已注销时返回 ErrSessionNotFound
+	RefreshSession(ctx context.Context, token string) (time.Duration, error)
+
+	// RenewSession 使用刷新凭证换发新的登录凭证（刷新凭证一次性使用，同时轮换）
+	RenewSession(ctx context.Context, refreshToken string) (*IssuedSession, error)
+
+	// ListUserSessions 列出用户当前有效的所有Session（currentToken 对应的Session标记为 Current）
+	ListUserSessions(ctx context.Context, userID uint64, currentToken string) ([]*SessionInfo, error)
+
+	// DestroyUserSession 按Session ID销毁用户的某个Session
+	DestroyUserSession(ctx context.Context, userID uint64, sessionID string) error
+
+	// DestroyUserSessions 销毁用户的所有Session（exceptToken 不为空时保留该Session），返回销毁数量
+	DestroyUserSessions(ctx context.Context, userID uint64, exceptToken string) (int, error)
 }
 
 // sessionManager Session管理器实现
 type sessionManager struct {
-	client Client
+	client   Client
+	opts     SessionOptions
+	throttle *touchThrottle
 }
 
 // NewSessionManager 创建Session管理器
-func NewSessionManager(client Client) SessionManager {
-	return &sessionManager{client: client}
+func NewSessionManager(client Client, opts SessionOptions) SessionManager {
+	opts = opts.withDefaults()
+	return &sessionManager{
+		client:   client,
+		opts:     opts,
+		throttle: newTouchThrottle(opts.RefreshInterval),
+	}
+}
+
+// SessionID 根据token计算对外展示的Session ID
+func SessionID(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])[:sessionIDLength]
+}
+
+// userSessionsKey 用户Session索引键
+// 索引键设计示例：user_sess:123 -> {token1, token2}
+func userSessionsKey(userID uint64) string {
+	return UserSessionsKeyPrefix + strconv.FormatUint(userID, 10)
 }
 
 // CreateSession 创建Session
 // session key示例：sess:(uuid)123123123123
-func (sm *sessionManager) CreateSession(ctx context.Context, userID uint64) (string, error) {
+// 元数据key示例：sess_meta:(uuid) -> {created_at, last_seen_at, client_ip, user_agent}
+func (sm *sessionManager) CreateSession(ctx context.Context, userID uint64, client *ClientInfo) (*IssuedSession, error) {
 	token := uuid.New().String()
 	key := SessionKeyPrefix + token
 
-	err := sm.client.Set(ctx, key, userID, SessionTTL)
+	err := sm.client.Set(ctx, key, userID, sm.opts.IdleTTL)
 	if err != nil {
-		log.Error("创建Session失败", zap.Error(err), zap.Uint64("user_id", userID))
-		return "", fmt.Errorf("创建Session失败: %w", err)
+		log.ErrorCtx(ctx, "创建Session失败", zap.Error(err), zap.Uint64("user_id", userID))
+		return nil, fmt.Errorf("创建Session失败: %w", err)
+	}
+
+	// 写入用户Session索引
+	// 索引有效期设为绝对最长有效期：任何更早创建的Session都不会比最新登录活得更久
+	indexKey := userSessionsKey(userID)
+	if err := sm.client.SAdd(ctx, indexKey, token); err != nil {
+		log.ErrorCtx(ctx, "写入用户Session索引失败", zap.Error(err), zap.Uint64("user_id", userID))
+		// 回滚已创建的Session，避免出现无法被统一注销的Session
+		if _, delErr := sm.client.Del(ctx, key); delErr != nil {
+			log.ErrorCtx(ctx, "回滚Session失败", zap.Error(delErr), zap.Uint64("user_id", userID))
+		}
+		return nil, fmt.Errorf("创建Session失败: %w", err)
+	}
+	if err := sm.client.Expire(ctx, indexKey, sm.opts.MaxLifetime); err != nil {
+		log.ErrorCtx(ctx, "设置用户Session索引过期时间失败", zap.Error(err), zap.Uint64("user_id", userID))
+		// 不影响主流程
+	}
+
+	// 写入Session元数据（失败不影响登录）
+	if client == nil {
+		client = &ClientInfo{}
+	}
+	now := time.Now().Unix()
+	metaKey := SessionMetaKeyPrefix + token
+	if err := sm.client.HSet(ctx, metaKey,
+		"created_at", now,
+		"last_seen_at", now,
+		"client_ip", client.IP,
+		"user_agent", client.UserAgent,
+	); err != nil {
+		log.ErrorCtx(ctx, "写入Session元数据失败", zap.Error(err), zap.Uint64("user_id", userID))
+	} else if err := sm.client.Expire(ctx, metaKey, sm.opts.IdleTTL); err != nil {
+		log.ErrorCtx(ctx, "设置Session元数据过期时间失败", zap.Error(err), zap.Uint64("user_id", userID))
 	}
 
-	log.Info("创建Session成功", zap.String("token", token), zap.Uint64("user_id", userID))
-	return token, nil
+	log.InfoCtx(ctx, "创建Session成功",
+		zap.String("session_id", SessionID(token)),
+		zap.Uint64("user_id", userID),
+		zap.String("client_ip", client.IP))
+	return &IssuedSession{Token: token, ExpiresIn: sm.opts.IdleTTL}, nil
 }
 
 // ValidateSession 验证Session
@@ -73,17 +223,251 @@ func (sm *sessionManager) ValidateSession(ctx context.Context, token string) (ui
 // DestroySession 销毁Session
 func (sm *sessionManager) DestroySession(ctx context.Context, token string) error {
 	key := SessionKeyPrefix + token
-	err := sm.client.Del(ctx, key)
+
+	// 先取出userID，用于清理用户Session索引
+	userID, getErr := sm.client.GetUint64(ctx, key)
+
+	_, err := sm.client.Del(ctx, key, SessionMetaKeyPrefix+token)
 	if err != nil {
-		log.Error("销毁Session失败", zap.Error(err), zap.String("token", token))
+		log.ErrorCtx(ctx, "销毁Session失败", zap.Error(err), zap.String("token", token))
 		return err
 	}
-	log.Info("销毁Session成功", zap.String("token", token))
+	sm.throttle.forget(token)
+
+	if getErr == nil {
+		if err := sm.client.SRem(ctx, userSessionsKey(userID), token); err != nil {
+			log.ErrorCtx(ctx, "清理用户Session索引失败", zap.Error(err), zap.Uint64("user_id", userID))
+			// 索引中残留的token在下次统一注销时会被清理，不影响主流程
+		}
+	} else if !errors.Is(getErr, redis.Nil) {
+		log.WarnCtx(ctx, "获取Session所属用户失败", zap.Error(getErr), zap.String("token", token))
+	}
+
+	log.InfoCtx(ctx, "销毁Session成功", zap.String("token", token))
 	return nil
 }
 
-// RefreshSession 刷新Session
-func (sm *sessionManager) RefreshSession(ctx context.Context, token string) error {
-	key := SessionKeyPrefix + token
-	return sm.client.Expire(ctx, key, SessionTTL)
+// refreshScript 原子地续期Session并更新元数据，Session已过期或已注销时不写入，避免重建孤立的元数据
+// KEYS[1] Session键，KEYS[2] 元数据键；ARGV[1] 新的有效期（毫秒），其余为元数据 field, value 交替排列
+// 返回 1 表示已续期，0 表示Session不存在
+var refreshScript = redis.NewScript(`
+if redis.call('PEXPIRE', KEYS[1], ARGV[1]) == 0 then
+	return 0
+end
+redis.call('HSET', KEYS[2], unpack(ARGV, 2))
+redis.call('PEXPIRE', KEYS[2], ARGV[1])
+return 1
+`)
+
+// RefreshSession 滑动续期Session
+// 同一token在 RefreshInterval 内只写一次Redis；新的有效期为 min(空闲过期时间, 距绝对过期的剩余时间)
+func (sm *sessionManager) RefreshSession(ctx context.Context, token string) (time.Duration, error) {
+	now := time.Now()
+	if !sm.throttle.allow(token, now) {
+		return 0, nil
+	}
+
+	metaKey := SessionMetaKeyPrefix + token
+	meta, err := sm.client.HGetAll(ctx, metaKey)
+	if err != nil {
+		return 0, fmt.Errorf("查询Session元数据失败: %w", err)
+	}
+
+	fields := []interface{}{"last_seen_at", now.Unix()}
+	createdAt, err := strconv.ParseInt(meta["created_at"], 10, 64)
+	if err != nil {
+		// 元数据缺失（写入失败或升级前创建的Session），从现在开始计算绝对有效期
+		createdAt = now.Unix()
+		fields = append(fields, "created_at", createdAt)
+	}
+
+	ttl := sm.opts.IdleTTL
+	if remaining := time.Unix(createdAt, 0).Add(sm.opts.MaxLifetime).Sub(now); remaining < ttl {
+		ttl = remaining
+	}
+	if ttl <= 0 {
+		// 已到绝对有效期，不再续期，等待自然过期
+		return 0, nil
+	}
+
+	args := append([]interface{}{ttl.Milliseconds()}, fields...)
+	res, err := sm.client.RunScript(ctx, refreshScript, []string{SessionKeyPrefix + token, metaKey}, args...)
+	if err != nil {
+		return 0, fmt.Errorf("续期Session失败: %w", err)
+	}
+	if refreshed, _ := res.(int64); refreshed == 0 {
+		// 校验通过后Session已过期或被注销
+		sm.throttle.forget(token)
+		return 0, ErrSessionNotFound
+	}
+
+	return ttl, nil
+}
+
+// RenewSession redis模式的Session本身滑动续期，不使用刷新凭证
+func (sm *sessionManager) RenewSession(ctx context.Context, refreshToken string) (*IssuedSession, error) {
+	return nil, ErrRenewNotSupported
+}
+
+// ListUserSessions 列出用户当前有效的所有Session
+func (sm *sessionManager) ListUserSessions(ctx context.Context, userID uint64, currentToken string) ([]*SessionInfo, error) {
+	indexKey := userSessionsKey(userID)
+	tokens, err := sm.client.SMembers(ctx, indexKey)
+	if err != nil {
+		log.ErrorCtx(ctx, "获取用户Session索引失败", zap.Error(err), zap.Uint64("user_id", userID))
+		return nil, fmt.Errorf("获取用户Session索引失败: %w", err)
+	}
+
+	sessions := make([]*SessionInfo, 0, len(tokens))
+	var stale []interface{}
+	for _, token := range tokens {
+		exists, err := sm.client.Exists(ctx, SessionKeyPrefix+token)
+		if err != nil {
+			return nil, fmt.Errorf("查询Session失败: %w", err)
+		}
+		if exists == 0 {
+			// Session已过期，索引中的token顺带清理
+			stale = append(stale, token)
+			continue
+		}
+
+		meta, err := sm.client.HGetAll(ctx, SessionMetaKeyPrefix+token)
+		if err != nil {
+			return nil, fmt.Errorf("查询Session元数据失败: %w", err)
+		}
+		createdAt, _ := strconv.ParseInt(meta["created_at"], 10, 64)
+		lastSeenAt, _ := strconv.ParseInt(meta["last_seen_at"], 10, 64)
+
+		sessions = append(sessions, &SessionInfo{
+			ID:         SessionID(token),
+			CreatedAt:  createdAt,
+			LastSeenAt: lastSeenAt,
+			ClientIP:   meta["client_ip"],
+			UserAgent:  meta["user_agent"],
+			Current:    token == currentToken,
+		})
+	}
+
+	if len(stale) > 0 {
+		if err := sm.client.SRem(ctx, indexKey, stale...); err != nil {
+			log.WarnCtx(ctx, "清理过期Session索引失败", zap.Error(err), zap.Uint64("user_id", userID))
+		}
+	}
+
+	return sessions, nil
+}
+
+// DestroyUserSession 按Session ID销毁用户的某个Session
+func (sm *sessionManager) DestroyUserSession(ctx context.Context, userID uint64, sessionID string) error {
+	tokens, err := sm.client.SMembers(ctx, userSessionsKey(userID))
+	if err != nil {
+		log.ErrorCtx(ctx, "获取用户Session索引失败", zap.Error(err), zap.Uint64("user_id", userID))
+		return fmt.Errorf("获取用户Session索引失败: %w", err)
+	}
+
+	// 只在该用户自己的索引中查找，防止注销他人的Session
+	for _, token := range tokens {
+		if SessionID(token) == sessionID {
+			return sm.DestroySession(ctx, token)
+		}
+	}
+	return ErrSessionNotFound
+}
+
+// DestroyUserSessions 销毁用户的所有Session
+func (sm *sessionManager) DestroyUserSessions(ctx context.Context, userID uint64, exceptToken string) (int, error) {
+	indexKey := userSessionsKey(userID)
+	tokens, err := sm.client.SMembers(ctx, indexKey)
+	if err != nil {
+		log.ErrorCtx(ctx, "获取用户Session索引失败", zap.Error(err), zap.Uint64("user_id", userID))
+		return 0, fmt.Errorf("获取用户Session索引失败: %w", err)
+	}
+
+	sessionKeys := make([]string, 0, len(tokens))
+	metaKeys := make([]string, 0, len(tokens))
+	members := make([]interface{}, 0, len(tokens))
+	for _, token := range tokens {
+		if token == exceptToken {
+			continue
+		}
+		sessionKeys = append(sessionKeys, SessionKeyPrefix+token)
+		metaKeys = append(metaKeys, SessionMetaKeyPrefix+token)
+		members = append(members, token)
+	}
+
+	if len(members) == 0 {
+		return 0, nil
+	}
+
+	// 索引中可能残留已过期的Session，只统计实际删除的Session
+	deleted, err := sm.client.Del(ctx, sessionKeys...)
+	if err != nil {
+		log.ErrorCtx(ctx, "批量销毁Session失败", zap.Error(err), zap.Uint64("user_id", userID))
+		return 0, fmt.Errorf("批量销毁Session失败: %w", err)
+	}
+	if _, err := sm.client.Del(ctx, metaKeys...); err != nil {
+		log.ErrorCtx(ctx, "清理Session元信息失败", zap.Error(err), zap.Uint64("user_id", userID))
+		// Session已删除，元信息残留不影响安全性
+	}
+	if err := sm.client.SRem(ctx, indexKey, members...); err != nil {
+		log.ErrorCtx(ctx, "清理用户Session索引失败", zap.Error(err), zap.Uint64("user_id", userID))
+		// Session已删除，索引残留不影响安全性
+	}
+	for _, member := range members {
+		sm.throttle.forget(member.(string))
+	}
+
+	log.InfoCtx(ctx, "销毁用户Session成功",
+		zap.Uint64("user_id", userID),
+		zap.Int64("count", deleted))
+	return int(deleted), nil
+}
+
+// ============================================================================
+// touchThrottle 续期写入节流
+// ============================================================================
+
+// touchThrottle 记录每个token最近一次写入时间（进程内），用于节流
+type touchThrottle struct {
+	mu        sync.Mutex
+	interval  time.Duration
+	lastTouch map[string]time.Time
+	lastSweep time.Time
+}
+
+func newTouchThrottle(interval time.Duration) *touchThrottle {
+	return &touchThrottle{
+		interval:  interval,
+		lastTouch: make(map[string]time.Time),
+		lastSweep: time.Now(),
+	}
+}
+
+// allow 判断该token本次是否需要写入，需要写入时记录写入时间
+func (t *touchThrottle) allow(token string, now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// 定期清理过期记录，避免已失效的token常驻内存
+	if now.Sub(t.lastSweep) > t.interval {
+		for k, v := range t.lastTouch {
+			if now.Sub(v) >= t.interval {
+				delete(t.lastTouch, k)
+			}
+		}
+		t.lastSweep = now
+	}
+
+	if last, ok := t.lastTouch[token]; ok && now.Sub(last) < t.interval {
+		return false
+	}
+	t.lastTouch[token] = now
+	return true
+}
+
+// forget 删除token的节流记录（Session销毁时调用）
+func (t *touchThrottle) forget(token string) {
+	t.mu.Lock()
+	delete(t.lastTouch, token)
+	t.mu.Unlock()
 }
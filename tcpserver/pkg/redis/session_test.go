@@ -0,0 +1,87 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	log "entry-task/pkg/logger"
+)
+
+// newTestSessionManager 创建连接 miniredis 的redis模式Session管理器
+func newTestSessionManager(t *testing.T) (SessionManager, *miniredis.Miniredis) {
+	t.Helper()
+	log.Logger = zap.NewNop()
+
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+
+	return NewSessionManager(&redisClient{client: rdb}, SessionOptions{}), mr
+}
+
+// TestDestroyUserSessions_StaleIndex 测试索引中残留的已过期Session不计入销毁数量
+func TestDestroyUserSessions_StaleIndex(t *testing.T) {
+	sm, mr := newTestSessionManager(t)
+	ctx := context.Background()
+
+	var tokens []string
+	for i := 0; i < 3; i++ {
+		issued, err := sm.CreateSession(ctx, 42, nil)
+		if err != nil {
+			t.Fatalf("创建Session失败: %v", err)
+		}
+		tokens = append(tokens, issued.Token)
+	}
+	// 模拟Session过期：键已删除，索引仍残留
+	mr.Del(SessionKeyPrefix + tokens[1])
+
+	count, err := sm.DestroyUserSessions(ctx, 42, tokens[0])
+	if err != nil {
+		t.Fatalf("销毁Session失败: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("销毁数量 = %d，期望 1（不含已过期的Session）", count)
+	}
+	if _, err := sm.ValidateSession(ctx, tokens[0]); err != nil {
+		t.Errorf("保留的Session应有效: %v", err)
+	}
+	if _, err := sm.ValidateSession(ctx, tokens[2]); err == nil {
+		t.Error("其他Session应已失效")
+	}
+	if members, _ := mr.Members(userSessionsKey(42)); len(members) != 1 || members[0] != tokens[0] {
+		t.Errorf("索引应只剩保留的Session，实际 %v", members)
+	}
+}
+
+// TestRefreshSession_AfterDestroy 测试Session注销后续期不重建元数据，也不返回有效期
+func TestRefreshSession_AfterDestroy(t *testing.T) {
+	sm, mr := newTestSessionManager(t)
+	ctx := context.Background()
+
+	issued, err := sm.CreateSession(ctx, 42, nil)
+	if err != nil {
+		t.Fatalf("创建Session失败: %v", err)
+	}
+	ttl, err := sm.RefreshSession(ctx, issued.Token)
+	if err != nil || ttl <= 0 {
+		t.Fatalf("有效Session应续期成功: ttl=%v, err=%v", ttl, err)
+	}
+
+	if err := sm.DestroySession(ctx, issued.Token); err != nil {
+		t.Fatalf("注销Session失败: %v", err)
+	}
+	sm.(*sessionManager).throttle.forget(issued.Token) // 跳过节流，模拟校验通过后才被注销
+
+	ttl, err = sm.RefreshSession(ctx, issued.Token)
+	if !errors.Is(err, ErrSessionNotFound) || ttl != 0 {
+		t.Errorf("注销后续期应返回 ErrSessionNotFound: ttl=%v, err=%v", ttl, err)
+	}
+	if mr.Exists(SessionMetaKeyPrefix+issued.Token) || mr.Exists(SessionKeyPrefix+issued.Token) {
+		t.Errorf("注销后不应重建Session或元数据，实际 %v", mr.Keys())
+	}
+}
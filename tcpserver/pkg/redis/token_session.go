@@ -0,0 +1,593 @@
+package redis
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	log "entry-task/pkg/logger"
+	"entry-task/tcpserver/pkg/token"
+)
+
+// ============================================================================
+// token 模式：签名 Access Token + Redis 刷新凭证
+// ============================================================================
+//
+// Access Token 为短有效期的签名Token，验证时只做本地验签和本地吊销列表检查，不访问Redis；
+// 刷新凭证（Refresh Token）与Session元数据保存在Redis中，用于换发Access Token；
+// 注销时把Session ID写入Redis吊销列表，各节点定期同步到本地。
+
+const (
+	// TokenSessionKeyPrefix token模式Session键前缀（Hash：user_id, refresh_hash, created_at, last_seen_at, client_ip, user_agent）
+	TokenSessionKeyPrefix = "tsess:"
+
+	// UserTokenSessionsKeyPrefix token模式用户Session索引键前缀（Set，成员为Session ID）
+	UserTokenSessionsKeyPrefix = "user_tsess:"
+
+	// RevokedTokenSessionsKey 吊销列表（ZSet，成员为Session ID，分数为该Session最后一个Access Token的过期时间）
+	RevokedTokenSessionsKey = "tsess_revoked"
+
+	// DefaultAccessTokenTTL 默认Access Token有效期
+	DefaultAccessTokenTTL = 15 * time.Minute
+
+	// DefaultRevocationSyncInterval 默认吊销列表同步间隔
+	DefaultRevocationSyncInterval = 5 * time.Second
+
+	// revocationClockSkew 增量同步时向前回溯的时间（秒），容忍各节点之间的时钟偏差
+	revocationClockSkew = 10
+)
+
+// TokenOptions token模式配置
+type TokenOptions struct {
+	Signer                 *token.Signer
+	AccessTTL              time.Duration // Access Token有效期
+	RevocationSyncInterval time.Duration // 吊销列表同步间隔（吊销在其他节点生效的最大延迟）
+}
+
+// withDefaults 未配置的字段使用默认值
+func (o TokenOptions) withDefaults() TokenOptions {
+	if o.AccessTTL <= 0 {
+		o.AccessTTL = DefaultAccessTokenTTL
+	}
+	if o.RevocationSyncInterval <= 0 {
+		o.RevocationSyncInterval = DefaultRevocationSyncInterval
+	}
+	return o
+}
+
+// tokenSessionManager token模式Session管理器
+type tokenSessionManager struct {
+	client  Client
+	opts    SessionOptions
+	tokens  TokenOptions
+	signer  *token.Signer
+	revoked *revocationList
+}
+
+// NewTokenSessionManager 创建token模式Session管理器
+// Session（刷新凭证）的有效期沿用 SessionOptions：IdleTTL 内未刷新即过期，且不超过 MaxLifetime
+// ctx 取消后停止后台同步吊销列表
+func NewTokenSessionManager(ctx context.Context, client Client, opts SessionOptions, tokenOpts TokenOptions) SessionManager {
+	tokenOpts = tokenOpts.withDefaults()
+	sm := &tokenSessionManager{
+		client:  client,
+		opts:    opts.withDefaults(),
+		tokens:  tokenOpts,
+		signer:  tokenOpts.Signer,
+		revoked: newRevocationList(),
+	}
+
+	// 启动时先同步一次，之后后台定期同步
+	sm.syncRevocations()
+	go sm.syncRevocationsLoop(ctx)
+
+	return sm
+}
+
+// tokenSessionKey token模式Session键
+func tokenSessionKey(sessionID string) string {
+	return TokenSessionKeyPrefix + sessionID
+}
+
+// userTokenSessionsKey token模式用户Session索引键
+func userTokenSessionsKey(userID uint64) string {
+	return UserTokenSessionsKeyPrefix + strconv.FormatUint(userID, 10)
+}
+
+// newTokenSessionID 生成随机Session ID
+func newTokenSessionID() (string, error) {
+	b := make([]byte, sessionIDLength/2)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// newRefreshToken 生成刷新凭证：<sessionID>.<随机串>，返回凭证及其哈希（Redis中只保存哈希）
+func newRefreshToken(sessionID string) (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	refreshToken := sessionID + "." + base64.RawURLEncoding.EncodeToString(b)
+	return refreshToken, hashRefreshToken(refreshToken), nil
+}
+
+// hashRefreshToken 计算刷新凭证哈希
+func hashRefreshToken(refreshToken string) string {
+	sum := sha256.Sum256([]byte(refreshToken))
+	return hex.EncodeToString(sum[:])
+}
+
+// issueAccessToken 签发Access Token
+func (sm *tokenSessionManager) issueAccessToken(userID uint64, sessionID string, now time.Time) (string, error) {
+	return sm.signer.Sign(&token.Claims{
+		UserID:    userID,
+		SessionID: sessionID,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(sm.tokens.AccessTTL).Unix(),
+	})
+}
+
+// sessionIDOf 从Access Token中取出Session ID（只验签，不检查过期），无效时返回空串
+func (sm *tokenSessionManager) sessionIDOf(accessToken string) string {
+	if accessToken == "" {
+		return ""
+	}
+	claims, err := sm.signer.Verify(accessToken)
+	if err != nil {
+		return ""
+	}
+	return claims.SessionID
+}
+
+// CreateSession 创建Session，签发Access Token和刷新凭证
+func (sm *tokenSessionManager) CreateSession(ctx context.Context, userID uint64, client *ClientInfo) (*IssuedSession, error) {
+	sessionID, err := newTokenSessionID()
+	if err != nil {
+		return nil, fmt.Errorf("生成Session ID失败: %w", err)
+	}
+	refreshToken, refreshHash, err := newRefreshToken(sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("生成刷新凭证失败: %w", err)
+	}
+
+	if client == nil {
+		client = &ClientInfo{}
+	}
+	now := time.Now()
+	key := tokenSessionKey(sessionID)
+	if err := sm.client.HSet(ctx, key,
+		"user_id", userID,
+		"refresh_hash", refreshHash,
+		"created_at", now.Unix(),
+		"last_seen_at", now.Unix(),
+		"client_ip", client.IP,
+		"user_agent", client.UserAgent,
+	); err != nil {
+		log.ErrorCtx(ctx, "创建Session失败", zap.Error(err), zap.Uint64("user_id", userID))
+		return nil, fmt.Errorf("创建Session失败: %w", err)
+	}
+	if err := sm.client.Expire(ctx, key, sm.opts.IdleTTL); err != nil {
+		log.ErrorCtx(ctx, "设置Session过期时间失败", zap.Error(err), zap.Uint64("user_id", userID))
+		// 回滚，避免留下永不过期的刷新凭证
+		if _, delErr := sm.client.Del(ctx, key); delErr != nil {
+			log.ErrorCtx(ctx, "回滚Session失败", zap.Error(delErr), zap.Uint64("user_id", userID))
+		}
+		return nil, fmt.Errorf("创建Session失败: %w", err)
+	}
+
+	// 写入用户Session索引，用于列表展示和统一注销
+	indexKey := userTokenSessionsKey(userID)
+	if err := sm.client.SAdd(ctx, indexKey, sessionID); err != nil {
+		log.ErrorCtx(ctx, "写入用户Session索引失败", zap.Error(err), zap.Uint64("user_id", userID))
+		if _, delErr := sm.client.Del(ctx, key); delErr != nil {
+			log.ErrorCtx(ctx, "回滚Session失败", zap.Error(delErr), zap.Uint64("user_id", userID))
+		}
+		return nil, fmt.Errorf("创建Session失败: %w", err)
+	}
+	if err := sm.client.Expire(ctx, indexKey, sm.opts.MaxLifetime); err != nil {
+		log.ErrorCtx(ctx, "设置用户Session索引过期时间失败", zap.Error(err), zap.Uint64("user_id", userID))
+		// 不影响主流程
+	}
+
+	accessToken, err := sm.issueAccessToken(userID, sessionID, now)
+	if err != nil {
+		return nil, fmt.Errorf("签发Access Token失败: %w", err)
+	}
+
+	log.InfoCtx(ctx, "创建Session成功",
+		zap.String("session_id", sessionID),
+		zap.Uint64("user_id", userID),
+		zap.String("client_ip", client.IP))
+	return &IssuedSession{
+		Token:            accessToken,
+		RefreshToken:     refreshToken,
+		ExpiresIn:        sm.tokens.AccessTTL,
+		RefreshExpiresIn: sm.opts.IdleTTL,
+	}, nil
+}
+
+// ValidateSession 本地验证Access Token（验签 + 有效期 + 本地吊销列表），不访问Redis
+func (sm *tokenSessionManager) ValidateSession(ctx context.Context, accessToken string) (uint64, error) {
+	now := time.Now()
+	claims, err := sm.signer.Parse(accessToken, now)
+	if err != nil {
+		return 0, fmt.Errorf("Session无效或已过期: %w", err)
+	}
+	if sm.revoked.contains(claims.SessionID) {
+		return 0, fmt.Errorf("Session无效或已过期: %w", ErrSessionNotFound)
+	}
+	return claims.UserID, nil
+}
+
+// DestroySession 销毁Session（登出），允许使用已过期的Access Token
+func (sm *tokenSessionManager) DestroySession(ctx context.Context, accessToken string) error {
+	claims, err := sm.signer.Verify(accessToken)
+	if err != nil {
+		return fmt.Errorf("Access Token无效: %w", err)
+	}
+
+	if _, err := sm.revoke(ctx, claims.UserID, claims.SessionID); err != nil {
+		log.ErrorCtx(ctx, "销毁Session失败", zap.Error(err), zap.String("session_id", claims.SessionID))
+		return err
+	}
+
+	log.InfoCtx(ctx, "销毁Session成功", zap.String("session_id", claims.SessionID))
+	return nil
+}
+
+// RefreshSession token模式下Access Token不做滑动续期，到期后由客户端使用刷新凭证换发
+func (sm *tokenSessionManager) RefreshSession(ctx context.Context, accessToken string) (time.Duration, error) {
+	return 0, nil
+}
+
+// renewScript 原子地校验并轮换刷新凭证，避免并发刷新都通过校验、或在读写之间被注销的Session被HSet重建
+// KEYS[1] Session键；ARGV: 提交的凭证哈希、新凭证哈希、当前时间（毫秒）、空闲过期时间（毫秒）、绝对最长有效期（毫秒）
+// 返回 {1, user_id, 新的有效期毫秒数}；{-1, user_id} 表示凭证已被轮换（重复使用）；{0} 表示Session不存在或已到期
+// 比较的是凭证的SHA-256哈希，哈希比较的时间差不会泄露凭证本身
+var renewScript = redis.NewScript(`
+local meta = redis.call('HMGET', KEYS[1], 'user_id', 'refresh_hash', 'created_at')
+if not meta[1] then
+	return {0}
+end
+if meta[2] ~= ARGV[1] then
+	return {-1, meta[1]}
+end
+local now = tonumber(ARGV[3])
+local ttl = tonumber(ARGV[4])
+local remaining = (tonumber(meta[3]) or 0) * 1000 + tonumber(ARGV[5]) - now
+if remaining < ttl then
+	ttl = remaining
+end
+if ttl <= 0 then
+	return {0}
+end
+redis.call('HSET', KEYS[1], 'refresh_hash', ARGV[2], 'last_seen_at', math.floor(now / 1000))
+redis.call('PEXPIRE', KEYS[1], ttl)
+return {1, meta[1], ttl}
+`)
+
+// 刷新凭证轮换结果
+const (
+	renewRotated  = 1
+	renewMissing  = 0
+	renewMismatch = -1
+)
+
+// RenewSession 使用刷新凭证换发Access Token，并轮换刷新凭证
+// 已轮换掉的旧刷新凭证再次出现时视为泄露，直接注销整个Session
+func (sm *tokenSessionManager) RenewSession(ctx context.Context, refreshToken string) (*IssuedSession, error) {
+	sessionID, _, ok := strings.Cut(refreshToken, ".")
+	if !ok || sessionID == "" {
+		return nil, ErrRefreshTokenInvalid
+	}
+
+	newRefresh, newHash, err := newRefreshToken(sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("生成刷新凭证失败: %w", err)
+	}
+
+	// 新的Session有效期：min(空闲过期时间, 距绝对过期的剩余时间)，在脚本中计算
+	now := time.Now()
+	res, err := sm.client.RunScript(ctx, renewScript, []string{tokenSessionKey(sessionID)},
+		hashRefreshToken(refreshToken),
+		newHash,
+		now.UnixMilli(),
+		sm.opts.IdleTTL.Milliseconds(),
+		sm.opts.MaxLifetime.Milliseconds(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("轮换刷新凭证失败: %w", err)
+	}
+	status, userID, ttl, err := parseRenewResult(res)
+	if err != nil {
+		return nil, fmt.Errorf("轮换刷新凭证失败: %w", err)
+	}
+
+	switch status {
+	case renewRotated:
+	case renewMismatch:
+		log.WarnCtx(ctx, "检测到刷新凭证重复使用，注销Session",
+			zap.Uint64("user_id", userID),
+			zap.String("session_id", sessionID))
+		if _, err := sm.revoke(ctx, userID, sessionID); err != nil {
+			log.ErrorCtx(ctx, "注销Session失败", zap.Error(err), zap.String("session_id", sessionID))
+		}
+		return nil, ErrRefreshTokenInvalid
+	default:
+		return nil, ErrRefreshTokenInvalid
+	}
+
+	accessToken, err := sm.issueAccessToken(userID, sessionID, now)
+	if err != nil {
+		return nil, fmt.Errorf("签发Access Token失败: %w", err)
+	}
+
+	log.DebugCtx(ctx, "换发Access Token成功",
+		zap.Uint64("user_id", userID),
+		zap.String("session_id", sessionID))
+	return &IssuedSession{
+		Token:            accessToken,
+		RefreshToken:     newRefresh,
+		ExpiresIn:        sm.tokens.AccessTTL,
+		RefreshExpiresIn: ttl,
+	}, nil
+}
+
+// parseRenewResult 解析 renewScript 的返回值
+func parseRenewResult(res interface{}) (status int64, userID uint64, ttl time.Duration, err error) {
+	values, ok := res.([]interface{})
+	if !ok || len(values) == 0 {
+		return 0, 0, 0, fmt.Errorf("脚本返回值格式错误: %v", res)
+	}
+	if status, ok = values[0].(int64); !ok {
+		return 0, 0, 0, fmt.Errorf("脚本返回值格式错误: %v", res)
+	}
+	if status == renewMissing {
+		return status, 0, 0, nil
+	}
+
+	if len(values) < 2 {
+		return 0, 0, 0, fmt.Errorf("脚本返回值格式错误: %v", res)
+	}
+	raw, _ := values[1].(string)
+	if userID, err = strconv.ParseUint(raw, 10, 64); err != nil {
+		// user_id 损坏的Session按无效处理
+		return renewMissing, 0, 0, nil
+	}
+	if status == renewMismatch {
+		return status, userID, 0, nil
+	}
+
+	if len(values) < 3 {
+		return 0, 0, 0, fmt.Errorf("脚本返回值格式错误: %v", res)
+	}
+	ms, _ := values[2].(int64)
+	return status, userID, time.Duration(ms) * time.Millisecond, nil
+}
+
+// ListUserSessions 列出用户当前有效的所有Session
+func (sm *tokenSessionManager) ListUserSessions(ctx context.Context, userID uint64, currentToken string) ([]*SessionInfo, error) {
+	indexKey := userTokenSessionsKey(userID)
+	sessionIDs, err := sm.client.SMembers(ctx, indexKey)
+	if err != nil {
+		log.ErrorCtx(ctx, "获取用户Session索引失败", zap.Error(err), zap.Uint64("user_id", userID))
+		return nil, fmt.Errorf("获取用户Session索引失败: %w", err)
+	}
+
+	currentID := sm.sessionIDOf(currentToken)
+	sessions := make([]*SessionInfo, 0, len(sessionIDs))
+	var stale []interface{}
+	for _, sessionID := range sessionIDs {
+		meta, err := sm.client.HGetAll(ctx, tokenSessionKey(sessionID))
+		if err != nil {
+			return nil, fmt.Errorf("查询Session失败: %w", err)
+		}
+		if len(meta) == 0 {
+			// Session已过期，索引顺带清理
+			stale = append(stale, sessionID)
+			continue
+		}
+
+		createdAt, _ := strconv.ParseInt(meta["created_at"], 10, 64)
+		lastSeenAt, _ := strconv.ParseInt(meta["last_seen_at"], 10, 64)
+		sessions = append(sessions, &SessionInfo{
+			ID:         sessionID,
+			CreatedAt:  createdAt,
+			LastSeenAt: lastSeenAt,
+			ClientIP:   meta["client_ip"],
+			UserAgent:  meta["user_agent"],
+			Current:    sessionID == currentID,
+		})
+	}
+
+	if len(stale) > 0 {
+		if err := sm.client.SRem(ctx, indexKey, stale...); err != nil {
+			log.WarnCtx(ctx, "清理过期Session索引失败", zap.Error(err), zap.Uint64("user_id", userID))
+		}
+	}
+
+	return sessions, nil
+}
+
+// DestroyUserSession 按Session ID销毁用户的某个Session
+func (sm *tokenSessionManager) DestroyUserSession(ctx context.Context, userID uint64, sessionID string) error {
+	sessionIDs, err := sm.client.SMembers(ctx, userTokenSessionsKey(userID))
+	if err != nil {
+		log.ErrorCtx(ctx, "获取用户Session索引失败", zap.Error(err), zap.Uint64("user_id", userID))
+		return fmt.Errorf("获取用户Session索引失败: %w", err)
+	}
+
+	// 只在该用户自己的索引中查找，防止注销他人的Session
+	for _, id := range sessionIDs {
+		if id == sessionID {
+			_, err := sm.revoke(ctx, userID, sessionID)
+			return err
+		}
+	}
+	return ErrSessionNotFound
+}
+
+// DestroyUserSessions 销毁用户的所有Session（exceptToken 对应的Session保留）
+func (sm *tokenSessionManager) DestroyUserSessions(ctx context.Context, userID uint64, exceptToken string) (int, error) {
+	sessionIDs, err := sm.client.SMembers(ctx, userTokenSessionsKey(userID))
+	if err != nil {
+		log.ErrorCtx(ctx, "获取用户Session索引失败", zap.Error(err), zap.Uint64("user_id", userID))
+		return 0, fmt.Errorf("获取用户Session索引失败: %w", err)
+	}
+
+	exceptID := sm.sessionIDOf(exceptToken)
+	targets := make([]string, 0, len(sessionIDs))
+	for _, id := range sessionIDs {
+		if id != exceptID {
+			targets = append(targets, id)
+		}
+	}
+	if len(targets) == 0 {
+		return 0, nil
+	}
+
+	// 索引中可能残留已过期的Session，只统计实际删除的Session
+	deleted, err := sm.revoke(ctx, userID, targets...)
+	if err != nil {
+		log.ErrorCtx(ctx, "销毁用户Session失败", zap.Error(err), zap.Uint64("user_id", userID))
+		return 0, err
+	}
+
+	log.InfoCtx(ctx, "销毁用户Session成功",
+		zap.Uint64("user_id", userID),
+		zap.Int("count", deleted))
+	return deleted, nil
+}
+
+// revoke 吊销Session：先写吊销列表（使已签发的Access Token失效），再删除刷新凭证和索引
+// 返回实际删除的Session数量（已过期的Session不计入）
+func (sm *tokenSessionManager) revoke(ctx context.Context, userID uint64, sessionIDs ...string) (int, error) {
+	// 已签发的Access Token最晚在 now+AccessTTL 过期，吊销记录保留到那时即可
+	expireAt := time.Now().Add(sm.tokens.AccessTTL).Unix()
+	keys := make([]string, 0, len(sessionIDs))
+	members := make([]interface{}, 0, len(sessionIDs))
+	for _, id := range sessionIDs {
+		if err := sm.client.ZAdd(ctx, RevokedTokenSessionsKey, float64(expireAt), id); err != nil {
+			return 0, fmt.Errorf("写入吊销列表失败: %w", err)
+		}
+		sm.revoked.add(id, expireAt)
+		keys = append(keys, tokenSessionKey(id))
+		members = append(members, id)
+	}
+
+	deleted, err := sm.client.Del(ctx, keys...)
+	if err != nil {
+		return 0, fmt.Errorf("删除Session失败: %w", err)
+	}
+	if err := sm.client.SRem(ctx, userTokenSessionsKey(userID), members...); err != nil {
+		log.ErrorCtx(ctx, "清理用户Session索引失败", zap.Error(err), zap.Uint64("user_id", userID))
+		// Session已删除，索引残留不影响安全性
+	}
+	return int(deleted), nil
+}
+
+// syncRevocationsLoop 定期同步吊销列表，直到 ctx 取消
+func (sm *tokenSessionManager) syncRevocationsLoop(ctx context.Context) {
+	ticker := time.NewTicker(sm.tokens.RevocationSyncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			sm.syncRevocations()
+		}
+	}
+}
+
+// syncRevocations 从Redis增量拉取吊销记录合并到本地列表，并清理过期记录
+// 吊销记录的分数（过期时间）随吊销时间递增，只需拉取分数不低于上次已见最大分数的记录；
+// 合并而非替换，拉取期间本节点新增的吊销记录不会被覆盖
+func (sm *tokenSessionManager) syncRevocations() {
+	ctx, cancel := context.WithTimeout(context.Background(), sm.tokens.RevocationSyncInterval)
+	defer cancel()
+
+	now := time.Now().Unix()
+	from := max(sm.revoked.lastScore()-revocationClockSkew, now)
+	entries, err := sm.client.ZRangeByScoreWithScores(ctx, RevokedTokenSessionsKey, "("+strconv.FormatInt(from, 10), "+inf")
+	if err != nil {
+		// 同步失败时保留本地列表，下个周期重试
+		log.WarnCtx(ctx, "同步吊销列表失败", zap.Error(err))
+		return
+	}
+	for _, e := range entries {
+		if id, ok := e.Member.(string); ok {
+			sm.revoked.add(id, int64(e.Score))
+		}
+	}
+	sm.revoked.prune(now)
+
+	if err := sm.client.ZRemRangeByScore(ctx, RevokedTokenSessionsKey, "-inf", strconv.FormatInt(now, 10)); err != nil {
+		log.WarnCtx(ctx, "清理过期吊销记录失败", zap.Error(err))
+	}
+}
+
+// ============================================================================
+// revocationList 本地吊销列表
+// ============================================================================
+
+// revocationList 进程内的已吊销Session ID集合
+type revocationList struct {
+	mu         sync.RWMutex
+	sessionIDs map[string]int64 // Session ID -> 吊销记录过期时间（Unix秒）
+	maxScore   int64            // 已见的最大过期时间，作为下次增量同步的起点
+}
+
+func newRevocationList() *revocationList {
+	return &revocationList{sessionIDs: make(map[string]int64)}
+}
+
+// contains 判断Session是否已吊销
+func (r *revocationList) contains(sessionID string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.sessionIDs[sessionID]
+	return ok
+}
+
+// add 添加吊销记录，expireAt 为记录过期时间（Unix秒）
+func (r *revocationList) add(sessionID string, expireAt int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if expireAt > r.sessionIDs[sessionID] {
+		r.sessionIDs[sessionID] = expireAt
+	}
+	if expireAt > r.maxScore {
+		r.maxScore = expireAt
+	}
+}
+
+// lastScore 返回已见的最大过期时间
+func (r *revocationList) lastScore() int64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.maxScore
+}
+
+// prune 删除已过期的吊销记录（对应的Access Token均已过期）
+func (r *revocationList) prune(now int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, expireAt := range r.sessionIDs {
+		if expireAt <= now {
+			delete(r.sessionIDs, id)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	log "entry-task/pkg/logger"
+	"entry-task/tcpserver/pkg/token"
+)
+
+// newTestTokenSessionManager 创建连接 miniredis 的token模式Session管理器
+func newTestTokenSessionManager(t *testing.T) (*tokenSessionManager, *miniredis.Miniredis) {
+	t.Helper()
+	log.Logger = zap.NewNop()
+
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+
+	signer, err := token.NewSigner("hmac", "k1", []token.Key{{ID: "k1", Secret: []byte("0123456789abcdef0123456789abcdef")}})
+	if err != nil {
+		t.Fatalf("创建签名器失败: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	sm := NewTokenSessionManager(ctx, &redisClient{client: rdb}, SessionOptions{}, TokenOptions{Signer: signer})
+	return sm.(*tokenSessionManager), mr
+}
+
+// TestRenewSession_Rotate 测试刷新凭证轮换及旧凭证重复使用时注销Session
+func TestRenewSession_Rotate(t *testing.T) {
+	sm, mr := newTestTokenSessionManager(t)
+	ctx := context.Background()
+
+	issued, err := sm.CreateSession(ctx, 42, nil)
+	if err != nil {
+		t.Fatalf("创建Session失败: %v", err)
+	}
+
+	renewed, err := sm.RenewSession(ctx, issued.RefreshToken)
+	if err != nil {
+		t.Fatalf("换发失败: %v", err)
+	}
+	if renewed.RefreshToken == issued.RefreshToken {
+		t.Fatal("换发后刷新凭证应轮换")
+	}
+	if renewed.RefreshExpiresIn != SessionTTL {
+		t.Errorf("刷新凭证有效期 = %v，期望 %v", renewed.RefreshExpiresIn, SessionTTL)
+	}
+	userID, err := sm.ValidateSession(ctx, renewed.Token)
+	if err != nil || userID != 42 {
+		t.Fatalf("新的Access Token应有效: user_id=%d, err=%v", userID, err)
+	}
+
+	// 旧凭证再次出现视为泄露：整个Session被注销
+	if _, err := sm.RenewSession(ctx, issued.RefreshToken); !errors.Is(err, ErrRefreshTokenInvalid) {
+		t.Fatalf("重复使用旧凭证应返回 ErrRefreshTokenInvalid，实际 %v", err)
+	}
+	if _, err := sm.RenewSession(ctx, renewed.RefreshToken); !errors.Is(err, ErrRefreshTokenInvalid) {
+		t.Errorf("Session注销后新凭证也应失效，实际 %v", err)
+	}
+	if _, err := sm.ValidateSession(ctx, renewed.Token); err == nil {
+		t.Error("Session注销后Access Token应失效")
+	}
+	if keys := mr.Keys(); len(keys) != 1 || keys[0] != RevokedTokenSessionsKey {
+		t.Errorf("注销后应只剩吊销列表，实际 %v", keys)
+	}
+}
+
+// TestRenewSession_Concurrent 测试同一刷新凭证并发换发时只有一次成功
+func TestRenewSession_Concurrent(t *testing.T) {
+	sm, _ := newTestTokenSessionManager(t)
+	ctx := context.Background()
+
+	issued, err := sm.CreateSession(ctx, 42, nil)
+	if err != nil {
+		t.Fatalf("创建Session失败: %v", err)
+	}
+
+	const workers = 10
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		successes int
+	)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := sm.RenewSession(ctx, issued.RefreshToken); err == nil {
+				mu.Lock()
+				successes++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if successes != 1 {
+		t.Errorf("并发换发成功次数 = %d，期望 1", successes)
+	}
+}
+
+// TestRenewSession_Revoked 测试已注销的Session不能换发，也不会被重建
+func TestRenewSession_Revoked(t *testing.T) {
+	sm, mr := newTestTokenSessionManager(t)
+	ctx := context.Background()
+
+	issued, err := sm.CreateSession(ctx, 42, nil)
+	if err != nil {
+		t.Fatalf("创建Session失败: %v", err)
+	}
+	if err := sm.DestroySession(ctx, issued.Token); err != nil {
+		t.Fatalf("注销Session失败: %v", err)
+	}
+
+	if _, err := sm.RenewSession(ctx, issued.RefreshToken); !errors.Is(err, ErrRefreshTokenInvalid) {
+		t.Fatalf("已注销的Session应返回 ErrRefreshTokenInvalid，实际 %v", err)
+	}
+	sessionID, _, _ := strings.Cut(issued.RefreshToken, ".")
+	if mr.Exists(tokenSessionKey(sessionID)) {
+		t.Error("换发失败时不应重建Session")
+	}
+}
+
+// TestRenewSession_MaxLifetime 测试换发后的有效期不超过绝对最长有效期
+func TestRenewSession_MaxLifetime(t *testing.T) {
+	sm, mr := newTestTokenSessionManager(t)
+	ctx := context.Background()
+
+	issued, err := sm.CreateSession(ctx, 42, nil)
+	if err != nil {
+		t.Fatalf("创建Session失败: %v", err)
+	}
+	sessionID, _, _ := strings.Cut(issued.RefreshToken, ".")
+	key := tokenSessionKey(sessionID)
+
+	// 距绝对过期还剩1分钟
+	createdAt := time.Now().Add(-DefaultSessionMaxLifetime + time.Minute).Unix()
+	mr.HSet(key, "created_at", strconv.FormatInt(createdAt, 10))
+	renewed, err := sm.RenewSession(ctx, issued.RefreshToken)
+	if err != nil {
+		t.Fatalf("换发失败: %v", err)
+	}
+	if renewed.RefreshExpiresIn > time.Minute || renewed.RefreshExpiresIn < 55*time.Second {
+		t.Errorf("刷新凭证有效期 = %v，期望约1分钟", renewed.RefreshExpiresIn)
+	}
+	if ttl := mr.TTL(key); ttl > time.Minute {
+		t.Errorf("Session过期时间 = %v，不应超过剩余的绝对有效期", ttl)
+	}
+
+	// 已超过绝对有效期
+	mr.HSet(key, "created_at", strconv.FormatInt(time.Now().Add(-DefaultSessionMaxLifetime).Unix(), 10))
+	if _, err := sm.RenewSession(ctx, renewed.RefreshToken); !errors.Is(err, ErrRefreshTokenInvalid) {
+		t.Errorf("超过绝对有效期应返回 ErrRefreshTokenInvalid，实际 %v", err)
+	}
+}
+
+// TestSyncRevocationsLoop_Stop 测试 ctx 取消后吊销列表同步协程退出
+func TestSyncRevocationsLoop_Stop(t *testing.T) {
+	sm, _ := newTestTokenSessionManager(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		sm.syncRevocationsLoop(ctx)
+		close(done)
+	}()
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("ctx 取消后同步协程应退出")
+	}
+}
+
+// TestTokenDestroyUserSessions_StaleIndex 测试索引中残留的已过期Session不计入销毁数量
+func TestTokenDestroyUserSessions_StaleIndex(t *testing.T) {
+	sm, mr := newTestTokenSessionManager(t)
+	ctx := context.Background()
+
+	var sessions []*IssuedSession
+	for i := 0; i < 3; i++ {
+		issued, err := sm.CreateSession(ctx, 42, nil)
+		if err != nil {
+			t.Fatalf("创建Session失败: %v", err)
+		}
+		sessions = append(sessions, issued)
+	}
+	// 模拟Session过期：刷新凭证已删除，索引仍残留
+	expiredID, _, _ := strings.Cut(sessions[1].RefreshToken, ".")
+	mr.Del(tokenSessionKey(expiredID))
+
+	count, err := sm.DestroyUserSessions(ctx, 42, sessions[0].Token)
+	if err != nil {
+		t.Fatalf("销毁Session失败: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("销毁数量 = %d，期望 1（不含已过期的Session）", count)
+	}
+	if _, err := sm.ValidateSession(ctx, sessions[0].Token); err != nil {
+		t.Errorf("保留的Session应有效: %v", err)
+	}
+	if _, err := sm.ValidateSession(ctx, sessions[2].Token); err == nil {
+		t.Error("其他Session应已失效")
+	}
+}
+
+// TestSyncRevocations_Merge 测试同步吊销列表时合并其他节点写入的记录，且不丢失本节点已有的记录
+func TestSyncRevocations_Merge(t *testing.T) {
+	sm, mr := newTestTokenSessionManager(t)
+	now := time.Now().Unix()
+
+	// 本节点吊销的记录（模拟在拉取与写入本地列表之间新增，Redis中尚不可见）
+	sm.revoked.add("local", now+60)
+	// 其他节点写入的记录，分数早于本节点已见的最大分数但在回溯范围内
+	if _, err := mr.ZAdd(RevokedTokenSessionsKey, float64(now+55), "remote"); err != nil {
+		t.Fatalf("写入吊销列表失败: %v", err)
+	}
+	// 已过期的记录
+	sm.revoked.add("expired", now-1)
+
+	sm.syncRevocations()
+
+	if !sm.revoked.contains("local") {
+		t.Error("同步后本节点的吊销记录不应丢失")
+	}
+	if !sm.revoked.contains("remote") {
+		t.Error("同步后应包含其他节点写入的吊销记录")
+	}
+	if sm.revoked.contains("expired") {
+		t.Error("同步后应清理已过期的吊销记录")
+	}
+}
@@ -0,0 +1,108 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"entry-task/pkg/tracing"
+)
+
+// ============================================================================
+// 链路追踪
+// ============================================================================
+
+// tracerName Redis Span 的 Tracer 名
+const tracerName = "entry-task/tcpserver/pkg/redis"
+
+// tracingHook 为每个Redis命令（及Pipeline）创建客户端 Span
+// 只在请求链路中创建（ctx 中有 Span），后台任务的命令不产生 Span
+type tracingHook struct {
+	tracer trace.Tracer
+	attrs  []attribute.KeyValue
+}
+
+// newTracingHook 创建Redis链路追踪 Hook，tp 为 nil 时使用全局 TracerProvider
+func newTracingHook(tp trace.TracerProvider, addr string) *tracingHook {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	attrs := []attribute.KeyValue{semconv.DBSystemNameRedis}
+	if host, port, err := net.SplitHostPort(addr); err == nil {
+		attrs = append(attrs, semconv.ServerAddress(host))
+		if p, err := strconv.Atoi(port); err == nil {
+			attrs = append(attrs, semconv.ServerPort(p))
+		}
+	}
+	return &tracingHook{tracer: tp.Tracer(tracerName), attrs: attrs}
+}
+
+// DialHook 实现 redis.Hook（建立连接不单独创建 Span）
+func (h *tracingHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+// ProcessHook 实现 redis.Hook
+func (h *tracingHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if !tracing.HasParent(ctx) {
+			return next(ctx, cmd)
+		}
+
+		ctx, span := h.tracer.Start(ctx, "redis."+cmd.Name(),
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(h.attrs...),
+			trace.WithAttributes(semconv.DBOperationName(cmd.Name())),
+		)
+		defer span.End()
+
+		err := next(ctx, cmd)
+		recordError(span, err)
+		return err
+	}
+}
+
+// ProcessPipelineHook 实现 redis.Hook（事务和 Pipeline 作为一个 Span）
+func (h *tracingHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		if !tracing.HasParent(ctx) {
+			return next(ctx, cmds)
+		}
+
+		names := make([]string, len(cmds))
+		for i, cmd := range cmds {
+			names[i] = cmd.Name()
+		}
+		ctx, span := h.tracer.Start(ctx, "redis.pipeline",
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(h.attrs...),
+			trace.WithAttributes(
+				semconv.DBOperationName("pipeline"),
+				semconv.DBOperationBatchSize(len(cmds)),
+				attribute.StringSlice("db.redis.commands", names),
+			),
+		)
+		defer span.End()
+
+		err := next(ctx, cmds)
+		recordError(span, err)
+		return err
+	}
+}
+
+// recordError 记录命令错误（键不存在不视为错误）
+func recordError(span trace.Span, err error) {
+	if err == nil || errors.Is(err, redis.Nil) {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
@@ -0,0 +1,81 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// TestTracingHook 测试只在请求链路中为命令创建 Span
+func TestTracingHook(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	hook := newTracingHook(tp, "127.0.0.1:6379")
+
+	var nextErr error
+	process := hook.ProcessHook(func(ctx context.Context, cmd redis.Cmder) error {
+		return nextErr
+	})
+	cmd := redis.NewStringCmd(context.Background(), "get", "user:1")
+
+	// 没有上游 Span：不创建
+	_ = process(context.Background(), cmd)
+	if n := len(recorder.Ended()); n != 0 {
+		t.Fatalf("无上游 Span 时不应创建 Span, 实际 %d 个", n)
+	}
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+	defer parent.End()
+
+	// 键不存在不视为错误
+	nextErr = redis.Nil
+	_ = process(ctx, cmd)
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("期望 1 个 Span, 实际 %d 个", len(spans))
+	}
+	if spans[0].Name() != "redis.get" {
+		t.Errorf("Span 名期望 redis.get, 实际 %s", spans[0].Name())
+	}
+	if spans[0].Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Error("Span 应挂在上游 Span 下")
+	}
+	if spans[0].Status().Code == codes.Error {
+		t.Error("redis.Nil 不应标记为错误")
+	}
+
+	// 命令失败
+	nextErr = errors.New("connection refused")
+	_ = process(ctx, cmd)
+	spans = recorder.Ended()
+	if spans[1].Status().Code != codes.Error {
+		t.Error("命令失败时 Span 应标记为错误")
+	}
+}
+
+// TestTracingHook_Pipeline 测试 Pipeline 作为一个 Span
+func TestTracingHook_Pipeline(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	hook := newTracingHook(tp, "127.0.0.1:6379")
+
+	process := hook.ProcessPipelineHook(func(ctx context.Context, cmds []redis.Cmder) error {
+		return nil
+	})
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+	defer parent.End()
+
+	_ = process(ctx, []redis.Cmder{
+		redis.NewStatusCmd(ctx, "set", "k", "v"),
+		redis.NewIntCmd(ctx, "expire", "k", 10),
+	})
+	spans := recorder.Ended()
+	if len(spans) != 1 || spans[0].Name() != "redis.pipeline" {
+		t.Fatalf("期望 1 个 redis.pipeline Span, 实际 %v", spans)
+	}
+}
@@ -2,13 +2,15 @@ package redis
 
 import (
 	"context"
+	"math"
+	"math/rand/v2"
 	"strconv"
 	"time"
 
 	"go.uber.org/zap"
 
+	log "entry-task/pkg/logger"
 	"entry-task/tcpserver/internal/model"
-	log "entry-task/tcpserver/pkg/logger"
 )
 
 const (
@@ -32,6 +34,19 @@ type CachedUser struct {
 	Username       string `json:"username"`
 	Nickname       string `json:"nickname"`
 	ProfilePicture string `json:"profile_picture"`
+	ExpiresAt      int64  `json:"expires_at,omitempty"` // Redis缓存过期时间（Unix毫秒），用于概率提前刷新
+}
+
+// ShouldRefreshEarly 概率提前刷新（XFetch）：越接近过期、回源耗时越长，刷新概率越高
+// delta 为回源耗时，beta 越大越倾向于提前刷新（1.0 为推荐值）
+func (u *CachedUser) ShouldRefreshEarly(now time.Time, delta time.Duration, beta float64) bool {
+	if u.ExpiresAt == 0 || delta <= 0 || beta <= 0 {
+		return false
+	}
+
+	// 1-rand.Float64() 取值 (0, 1]，避免 log(0)
+	gap := time.Duration(-float64(delta) * beta * math.Log(1-rand.Float64()))
+	return !now.Add(gap).Before(time.UnixMilli(u.ExpiresAt))
 }
 
 // UserCache 用户缓存管理器接口
@@ -70,18 +85,22 @@ func (uc *userCache) GetUser(ctx context.Context, userID uint64) (*CachedUser, e
 	if err != nil {
 		// 使用redis.Nil判断键不存在
 		if err.Error() == "redis: nil" {
+			userCacheRequests.WithLabelValues("redis", cacheResultMiss).Inc()
 			return nil, nil
 		}
+		userCacheRequests.WithLabelValues("redis", cacheResultError).Inc()
 		return nil, err
 	}
 
 	// 检查是否是负缓存
 	if user.Username == NullCacheValue {
-		log.Debug("命中负缓存", zap.Uint64("user_id", userID))
+		userCacheRequests.WithLabelValues("redis", cacheResultNegativeHit).Inc()
+		log.DebugCtx(ctx, "命中负缓存", zap.Uint64("user_id", userID))
 		return nil, nil
 	}
 
-	log.Debug("命中用户缓存", zap.Uint64("user_id", userID))
+	userCacheRequests.WithLabelValues("redis", cacheResultHit).Inc()
+	log.DebugCtx(ctx, "命中用户缓存", zap.Uint64("user_id", userID))
 	return &user, nil
 }
 
@@ -96,15 +115,16 @@ func (uc *userCache) SetUser(ctx context.Context, user *model.User) error {
 		Username:       user.Username,
 		Nickname:       user.Nickname,
 		ProfilePicture: user.ProfilePicture,
+		ExpiresAt:      time.Now().Add(UserCacheTTL).UnixMilli(),
 	}
 
 	err := uc.client.SetJSON(ctx, key, cachedUser, UserCacheTTL)
 	if err != nil {
-		log.Error("设置用户缓存失败", zap.Error(err), zap.Uint64("user_id", user.ID))
+		log.ErrorCtx(ctx, "设置用户缓存失败", zap.Error(err), zap.Uint64("user_id", user.ID))
 		return err
 	}
 
-	log.Debug("设置用户缓存成功", zap.Uint64("user_id", user.ID))
+	log.DebugCtx(ctx, "设置用户缓存成功", zap.Uint64("user_id", user.ID))
 	return nil
 }
 
@@ -115,22 +135,22 @@ func (uc *userCache) SetNullCache(ctx context.Context, userID uint64) error {
 
 	err := uc.client.SetJSON(ctx, key, nullUser, NullCacheTTL)
 	if err != nil {
-		log.Error("设置负缓存失败", zap.Error(err), zap.Uint64("user_id", userID))
+		log.ErrorCtx(ctx, "设置负缓存失败", zap.Error(err), zap.Uint64("user_id", userID))
 		return err
 	}
 
-	log.Debug("设置负缓存成功", zap.Uint64("user_id", userID))
+	log.DebugCtx(ctx, "设置负缓存成功", zap.Uint64("user_id", userID))
 	return nil
 }
 
 // DeleteUser 删除用户缓存
 func (uc *userCache) DeleteUser(ctx context.Context, userID uint64) error {
 	key := UserCacheKeyPrefix + strconv.FormatUint(userID, 10)
-	err := uc.client.Del(ctx, key)
+	_, err := uc.client.Del(ctx, key)
 	if err != nil {
-		log.Error("删除用户缓存失败", zap.Error(err), zap.Uint64("user_id", userID))
+		log.ErrorCtx(ctx, "删除用户缓存失败", zap.Error(err), zap.Uint64("user_id", userID))
 		return err
 	}
-	log.Debug("删除用户缓存成功", zap.Uint64("user_id", userID))
+	log.DebugCtx(ctx, "删除用户缓存成功", zap.Uint64("user_id", userID))
 	return nil
 }
@@ -0,0 +1,51 @@
+package redis
+
+import (
+	"testing"
+	"time"
+)
+
+// TestShouldRefreshEarly 测试概率提前刷新的边界情况
+func TestShouldRefreshEarly(t *testing.T) {
+	now := time.Now()
+
+	expired := &CachedUser{ExpiresAt: now.Add(-time.Second).UnixMilli()}
+	if !expired.ShouldRefreshEarly(now, time.Millisecond, 1) {
+		t.Error("已过期的条目应刷新")
+	}
+
+	noExpiry := &CachedUser{}
+	if noExpiry.ShouldRefreshEarly(now, time.Second, 1) {
+		t.Error("未记录过期时间的条目不应刷新")
+	}
+
+	fresh := &CachedUser{ExpiresAt: now.Add(UserCacheTTL).UnixMilli()}
+	if fresh.ShouldRefreshEarly(now, time.Millisecond, 1) {
+		t.Error("刚写入的条目不应刷新")
+	}
+	if fresh.ShouldRefreshEarly(now, 0, 1) || fresh.ShouldRefreshEarly(now, time.Second, 0) {
+		t.Error("delta 或 beta 为0时不应刷新")
+	}
+}
+
+// TestShouldRefreshEarly_Probability 测试越接近过期，刷新概率越高
+func TestShouldRefreshEarly_Probability(t *testing.T) {
+	now := time.Now()
+	delta := 100 * time.Millisecond
+
+	rate := func(remaining time.Duration) int {
+		u := &CachedUser{ExpiresAt: now.Add(remaining).UnixMilli()}
+		n := 0
+		for i := 0; i < 2000; i++ {
+			if u.ShouldRefreshEarly(now, delta, 1) {
+				n++
+			}
+		}
+		return n
+	}
+
+	near, far := rate(10*time.Millisecond), rate(500*time.Millisecond)
+	if near <= far {
+		t.Errorf("临近过期的刷新次数(%d)应多于较远的(%d)", near, far)
+	}
+}
@@ -0,0 +1,183 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// 签名算法
+const (
+	AlgorithmHMAC    = "hmac"    // HMAC-SHA256，密钥为任意长度的共享密钥
+	AlgorithmEd25519 = "ed25519" // Ed25519，密钥为32字节seed
+)
+
+// tokenVersion Token格式版本：v1.<kid>.<payload>.<signature>
+const tokenVersion = "v1"
+
+var (
+	ErrMalformed    = errors.New("Token格式错误")
+	ErrUnknownKey   = errors.New("Token签名密钥不存在")
+	ErrBadSignature = errors.New("Token签名无效")
+	ErrExpired      = errors.New("Token已过期")
+)
+
+// Claims Token携带的声明
+type Claims struct {
+	UserID    uint64 `json:"uid"`
+	SessionID string `json:"sid"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// Key 签名密钥
+type Key struct {
+	ID     string // 密钥ID（写入Token，用于轮换时选择验签密钥）
+	Secret []byte // HMAC共享密钥 或 Ed25519 seed
+}
+
+// keyPair 单个密钥的签名/验签实现
+type keyPair interface {
+	sign(msg []byte) []byte
+	verify(msg, sig []byte) bool
+}
+
+type hmacKey struct {
+	secret []byte
+}
+
+func (k *hmacKey) sign(msg []byte) []byte {
+	mac := hmac.New(sha256.New, k.secret)
+	mac.Write(msg)
+	return mac.Sum(nil)
+}
+
+func (k *hmacKey) verify(msg, sig []byte) bool {
+	return hmac.Equal(k.sign(msg), sig)
+}
+
+type ed25519Key struct {
+	private ed25519.PrivateKey
+	public  ed25519.PublicKey
+}
+
+func (k *ed25519Key) sign(msg []byte) []byte {
+	return ed25519.Sign(k.private, msg)
+}
+
+func (k *ed25519Key) verify(msg, sig []byte) bool {
+	return ed25519.Verify(k.public, msg, sig)
+}
+
+// Signer Token签发与校验（纯本地计算，不访问Redis）
+// 使用 activeKeyID 对应的密钥签发，可用任意已配置的密钥验签，实现密钥轮换
+type Signer struct {
+	activeKeyID string
+	keys        map[string]keyPair
+}
+
+// NewSigner 创建Signer
+func NewSigner(algorithm, activeKeyID string, keys []Key) (*Signer, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("未配置签名密钥")
+	}
+
+	s := &Signer{
+		activeKeyID: activeKeyID,
+		keys:        make(map[string]keyPair, len(keys)),
+	}
+	for _, k := range keys {
+		if k.ID == "" || strings.Contains(k.ID, ".") {
+			return nil, fmt.Errorf("密钥ID无效: %q", k.ID)
+		}
+		if _, ok := s.keys[k.ID]; ok {
+			return nil, fmt.Errorf("密钥ID重复: %s", k.ID)
+		}
+
+		switch algorithm {
+		case AlgorithmHMAC:
+			if len(k.Secret) < 32 {
+				return nil, fmt.Errorf("HMAC密钥 %s 长度不足32字节", k.ID)
+			}
+			s.keys[k.ID] = &hmacKey{secret: k.Secret}
+		case AlgorithmEd25519:
+			if len(k.Secret) != ed25519.SeedSize {
+				return nil, fmt.Errorf("Ed25519密钥 %s 必须为%d字节seed", k.ID, ed25519.SeedSize)
+			}
+			private := ed25519.NewKeyFromSeed(k.Secret)
+			s.keys[k.ID] = &ed25519Key{
+				private: private,
+				public:  private.Public().(ed25519.PublicKey),
+			}
+		default:
+			return nil, fmt.Errorf("不支持的签名算法: %s", algorithm)
+		}
+	}
+
+	if _, ok := s.keys[activeKeyID]; !ok {
+		return nil, fmt.Errorf("当前签名密钥 %s 未配置", activeKeyID)
+	}
+	return s, nil
+}
+
+// Sign 签发Token
+func (s *Signer) Sign(claims *Claims) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("序列化Token声明失败: %w", err)
+	}
+
+	signingInput := tokenVersion + "." + s.activeKeyID + "." + base64.RawURLEncoding.EncodeToString(payload)
+	sig := s.keys[s.activeKeyID].sign([]byte(signingInput))
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// Verify 校验Token签名并返回声明（不检查过期时间，用于登出等允许过期Token的场景）
+func (s *Signer) Verify(token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 4 || parts[0] != tokenVersion {
+		return nil, ErrMalformed
+	}
+
+	key, ok := s.keys[parts[1]]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[3])
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	signingInput := token[:len(token)-len(parts[3])-1]
+	if !key.verify([]byte(signingInput), sig) {
+		return nil, ErrBadSignature
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, ErrMalformed
+	}
+	return &claims, nil
+}
+
+// Parse 校验Token签名和有效期
+func (s *Signer) Parse(token string, now time.Time) (*Claims, error) {
+	claims, err := s.Verify(token)
+	if err != nil {
+		return nil, err
+	}
+	if now.Unix() >= claims.ExpiresAt {
+		return claims, ErrExpired
+	}
+	return claims, nil
+}
@@ -0,0 +1,116 @@
+package token
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testKey(id string, b byte) Key {
+	return Key{ID: id, Secret: bytes.Repeat([]byte{b}, 32)}
+}
+
+// TestSignAndParse 测试两种算法的签发与校验
+func TestSignAndParse(t *testing.T) {
+	for _, alg := range []string{AlgorithmHMAC, AlgorithmEd25519} {
+		signer, err := NewSigner(alg, "k1", []Key{testKey("k1", 1)})
+		if err != nil {
+			t.Fatalf("%s: 创建Signer失败: %v", alg, err)
+		}
+
+		now := time.Now()
+		tok, err := signer.Sign(&Claims{UserID: 42, SessionID: "abc", IssuedAt: now.Unix(), ExpiresAt: now.Add(time.Minute).Unix()})
+		if err != nil {
+			t.Fatalf("%s: 签发失败: %v", alg, err)
+		}
+
+		claims, err := signer.Parse(tok, now)
+		if err != nil {
+			t.Fatalf("%s: 校验失败: %v", alg, err)
+		}
+		if claims.UserID != 42 || claims.SessionID != "abc" {
+			t.Errorf("%s: 声明不一致: %+v", alg, claims)
+		}
+	}
+}
+
+// TestParseExpired 测试过期Token
+func TestParseExpired(t *testing.T) {
+	signer, _ := NewSigner(AlgorithmHMAC, "k1", []Key{testKey("k1", 1)})
+	now := time.Now()
+	tok, _ := signer.Sign(&Claims{UserID: 1, SessionID: "s", ExpiresAt: now.Unix()})
+
+	if _, err := signer.Parse(tok, now); err != ErrExpired {
+		t.Errorf("期望 ErrExpired, 实际 %v", err)
+	}
+	// Verify 不检查过期时间
+	if _, err := signer.Verify(tok); err != nil {
+		t.Errorf("Verify 不应检查过期: %v", err)
+	}
+}
+
+// TestParseTampered 测试篡改后的Token
+func TestParseTampered(t *testing.T) {
+	signer, _ := NewSigner(AlgorithmHMAC, "k1", []Key{testKey("k1", 1)})
+	tok, _ := signer.Sign(&Claims{UserID: 1, SessionID: "s", ExpiresAt: time.Now().Add(time.Minute).Unix()})
+
+	parts := strings.Split(tok, ".")
+	forged, _ := signer.Sign(&Claims{UserID: 2, SessionID: "s", ExpiresAt: time.Now().Add(time.Minute).Unix()})
+	parts[2] = strings.Split(forged, ".")[2]
+
+	if _, err := signer.Verify(strings.Join(parts, ".")); err != ErrBadSignature {
+		t.Errorf("期望 ErrBadSignature, 实际 %v", err)
+	}
+	if _, err := signer.Verify("not-a-token"); err != ErrMalformed {
+		t.Errorf("期望 ErrMalformed, 实际 %v", err)
+	}
+}
+
+// TestKeyRotation 测试密钥轮换：旧密钥签发的Token在新Signer中仍可验证
+func TestKeyRotation(t *testing.T) {
+	oldSigner, _ := NewSigner(AlgorithmEd25519, "k1", []Key{testKey("k1", 1)})
+	tok, _ := oldSigner.Sign(&Claims{UserID: 1, SessionID: "s", ExpiresAt: time.Now().Add(time.Minute).Unix()})
+
+	rotated, err := NewSigner(AlgorithmEd25519, "k2", []Key{testKey("k1", 1), testKey("k2", 2)})
+	if err != nil {
+		t.Fatalf("创建Signer失败: %v", err)
+	}
+	if _, err := rotated.Verify(tok); err != nil {
+		t.Errorf("旧密钥签发的Token应仍可验证: %v", err)
+	}
+
+	newTok, _ := rotated.Sign(&Claims{UserID: 1, SessionID: "s", ExpiresAt: time.Now().Add(time.Minute).Unix()})
+	if !strings.HasPrefix(newTok, "v1.k2.") {
+		t.Errorf("应使用新密钥签发: %s", newTok)
+	}
+
+	// 旧密钥下线后，旧Token失效
+	retired, _ := NewSigner(AlgorithmEd25519, "k2", []Key{testKey("k2", 2)})
+	if _, err := retired.Verify(tok); err != ErrUnknownKey {
+		t.Errorf("期望 ErrUnknownKey, 实际 %v", err)
+	}
+}
+
+// TestNewSignerInvalidConfig 测试无效配置
+func TestNewSignerInvalidConfig(t *testing.T) {
+	cases := []struct {
+		name   string
+		alg    string
+		active string
+		keys   []Key
+	}{
+		{"无密钥", AlgorithmHMAC, "k1", nil},
+		{"HMAC密钥过短", AlgorithmHMAC, "k1", []Key{{ID: "k1", Secret: []byte("short")}}},
+		{"Ed25519长度错误", AlgorithmEd25519, "k1", []Key{{ID: "k1", Secret: make([]byte, 16)}}},
+		{"当前密钥未配置", AlgorithmHMAC, "k9", []Key{testKey("k1", 1)}},
+		{"密钥ID含点号", AlgorithmHMAC, "k.1", []Key{testKey("k.1", 1)}},
+		{"未知算法", "rsa", "k1", []Key{testKey("k1", 1)}},
+	}
+
+	for _, c := range cases {
+		if _, err := NewSigner(c.alg, c.active, c.keys); err == nil {
+			t.Errorf("%s: 期望返回错误", c.name)
+		}
+	}
+}
@@ -1,14 +1,16 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"sync"
 	"time"
 
+	logger "entry-task/pkg/logger"
 	"entry-task/tcpserver/config"
 	"entry-task/tcpserver/pkg/db"
-	logger "entry-task/tcpserver/pkg/logger"
+	"entry-task/tcpserver/pkg/migrate"
 
 	"github.com/jmoiron/sqlx"
 	"go.uber.org/zap"
@@ -61,6 +63,17 @@ func main() {
 	}
 	defer database.Close()
 
+	// 确保表结构已创建（执行未执行的数据库迁移）
+	migrator, err := migrate.New(database)
+	if err != nil {
+		logger.Fatal("❌ 加载数据库迁移失败", zap.Error(err))
+		return
+	}
+	if _, err := migrator.Up(context.Background()); err != nil {
+		logger.Fatal("❌ 执行数据库迁移失败", zap.Error(err))
+		return
+	}
+
 	// 4. 调整连接池配置（优化批量插入）
 	database.SetMaxOpenConns(20)
 	database.SetMaxIdleConns(10)
@@ -75,6 +88,13 @@ func main() {
 	}
 	passwordHashStr := string(passwordHash)
 
+	// 已存在的ID跳过，便于中断后重新执行
+	onConflict, err := db.OnConflictDoNothing(database.DriverName(), "id")
+	if err != nil {
+		logger.Fatal("生成冲突处理语句失败", zap.Error(err))
+		return
+	}
+
 	logger.Info("========================================")
 	logger.Info("开始生成用户数据",
 		zap.Int("total_users", TotalUsers),
@@ -83,6 +103,12 @@ func main() {
 		zap.Int("num_workers", NumWorkers))
 	logger.Info("========================================")
 
+	// 每批插入的超时时间
+	batchTimeout := cfg.Database.GetBatchTimeout()
+	if batchTimeout <= 0 {
+		batchTimeout = time.Minute
+	}
+
 	startTime := time.Now()
 
 	// 计算每个worker负责的范围
@@ -99,7 +125,7 @@ func main() {
 
 		go func(workerID, start, end int) {
 			defer wg.Done()
-			insertBatch(database, workerID, start, end, passwordHashStr)
+			insertBatch(database, batchTimeout, onConflict, workerID, start, end, passwordHashStr)
 		}(i, startID, endID)
 	}
 
@@ -116,7 +142,7 @@ func main() {
 		zap.String("password", DefaultPassword))
 }
 
-func insertBatch(database *sqlx.DB, workerID, start, end int, passwordHash string) {
+func insertBatch(database *sqlx.DB, batchTimeout time.Duration, onConflict string, workerID, start, end int, passwordHash string) {
 	total := end - start + 1
 	processed := 0
 	startTime := time.Now()
@@ -152,9 +178,13 @@ func insertBatch(database *sqlx.DB, workerID, start, end int, passwordHash strin
 			)
 		}
 
+		query += onConflict
+
 		// 执行批量插入
 		batchStart := time.Now()
-		_, err := database.Exec(query, values...)
+		ctx, cancel := context.WithTimeout(context.Background(), batchTimeout)
+		_, err := database.ExecContext(ctx, database.Rebind(query), values...)
+		cancel()
 		batchDuration := time.Since(batchStart)
 
 		if err != nil {
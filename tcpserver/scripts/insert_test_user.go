@@ -2,12 +2,12 @@ package main
 
 import (
 	"context"
+	"entry-task/pkg/logger"
 	"entry-task/tcpserver/config"
 	"entry-task/tcpserver/internal/model"
 	"entry-task/tcpserver/internal/repository"
 	"entry-task/tcpserver/pkg/container"
 	"entry-task/tcpserver/pkg/db"
-	"entry-task/tcpserver/pkg/logger"
 	"flag"
 	"fmt"
 